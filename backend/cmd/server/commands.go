@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/card"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/export"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/web"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+const usage = `usage: server [command]
+
+Without a command the HTTP server is started.
+
+Commands:
+  export <uuid> [out.zip]   write the static site of an invitation
+  reconcile-rsvps           rebuild the RSVP counters from the responses`
+
+// runCommand runs a maintenance command given on the command line.
+func runCommand(args []string, invUC *usecase.InvitationUseCase, adminUC *usecase.AdminUseCase, pages *web.PageRenderer, cards *card.Renderer) error {
+	switch args[0] {
+	case "export":
+		if len(args) < 2 || len(args) > 3 {
+			return errors.New(usage)
+		}
+		return exportStatic(args[1], args[2:], invUC, pages, cards)
+	case "reconcile-rsvps":
+		if len(args) != 1 {
+			return errors.New(usage)
+		}
+		fixed, err := adminUC.ReconcileRSVPCounters()
+		if err != nil {
+			return fmt.Errorf("reconcile-rsvps: %w", err)
+		}
+		fmt.Println("Reconciled RSVP counters of", fixed, "invitations")
+		return nil
+	default:
+		return errors.New(usage)
+	}
+}
+
+func exportStatic(uuid string, out []string, invUC *usecase.InvitationUseCase, pages *web.PageRenderer, cards *card.Renderer) error {
+	inv, err := invUC.FindInvitation(uuid)
+	if err != nil {
+		return fmt.Errorf("export %s: %w", uuid, err)
+	}
+	name := export.FileName(inv, ".zip")
+	if len(out) > 0 {
+		name = out[0]
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := export.StaticSite(f, inv, pages, cards); err != nil {
+		f.Close()
+		os.Remove(name)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Println("Exported", name)
+	return nil
+}
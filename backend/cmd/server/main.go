@@ -3,10 +3,20 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // Event timezones of reminders; the image has no zoneinfo
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib" // Standard library driver
@@ -15,7 +25,13 @@ import (
 
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/api"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/api/handlers"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/card"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/database"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/geoip"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/notify"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/outbound"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/payment"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/web"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
 	"github.com/madiyarrakhman/wedding-invitation/backend/migrations"
 )
@@ -62,6 +78,19 @@ func main() {
 	// 2. Dependencies
 	invRepo := database.NewPostgresInvitationRepository(pool)
 	adminRepo := database.NewPostgresAdminRepository(pool)
+	guestRepo := database.NewPostgresGuestRepository(pool)
+	idempotencyRepo := database.NewPostgresIdempotencyRepository(pool)
+	clickRepo := database.NewPostgresClickRepository(pool)
+	engagementRepo := database.NewPostgresEngagementRepository(pool)
+	orderRepo := database.NewPostgresOrderRepository(pool)
+	pricingRepo := database.NewPostgresPricingRepository(pool)
+	jobRepo := database.NewPostgresJobRepository(pool)
+	lifecycleRepo := database.NewPostgresLifecycleRepository(pool)
+	queueRepo := database.NewPostgresQueueRepository(pool)
+	webhookRepo := database.NewPostgresWebhookRepository(pool)
+	notificationRepo := database.NewPostgresNotificationRepository(pool)
+	conversationRepo := database.NewPostgresConversationRepository(pool)
+	reminderRepo := database.NewPostgresReminderRepository(pool)
 
 	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
 	if len(jwtSecret) == 0 {
@@ -76,11 +105,51 @@ func main() {
 		adminPass = "admin123"
 	}
 
-	invUC := usecase.NewInvitationUseCase(invRepo)
+	// Short links: SHORT_CODE_LENGTH and SHORT_CODE_ALPHABET override the
+	// defaults for new random codes; existing links are unaffected.
+	codes := usecase.ShortCodeGenerator{Alphabet: os.Getenv("SHORT_CODE_ALPHABET")}
+	if v := os.Getenv("SHORT_CODE_LENGTH"); v != "" {
+		if codes.Length, err = strconv.Atoi(v); err != nil {
+			log.Fatal("Invalid SHORT_CODE_LENGTH:", err)
+		}
+	}
+	if err := codes.Validate(); err != nil {
+		log.Fatal("Invalid short code settings:", err)
+	}
+
+	// Trials: TRIAL_DURATION ("1h" by default) is how long an unpaid
+	// invitation stays online, and TRIAL_DURATIONS overrides it per
+	// template, e.g. "silk-ivory=24h,starry-night=2h".
+	trial, err := usecase.ParseTrialPolicy(os.Getenv("TRIAL_DURATION"), os.Getenv("TRIAL_DURATIONS"))
+	if err != nil {
+		log.Fatal("Invalid trial settings:", err)
+	}
+
+	invUC := usecase.NewInvitationUseCase(invRepo, codes, trial)
 	adminUC := usecase.NewAdminUseCase(adminRepo, adminUser, adminPass, jwtSecret)
+	guestUC := usecase.NewGuestUseCase(guestRepo, invRepo)
+	idempotencyUC := usecase.NewIdempotencyUseCase(idempotencyRepo)
 
-	invHandler := handlers.NewInvitationHandler(invUC)
-	adminHandler := handlers.NewAdminHandler(adminUC, invUC)
+	// Click analytics: visitor hashes are keyed with CLICK_HASH_SECRET (the
+	// JWT secret by default), and GEOIP_DB points to an optional .mmdb
+	// country database.
+	clickSecret := []byte(os.Getenv("CLICK_HASH_SECRET"))
+	if len(clickSecret) == 0 {
+		clickSecret = jwtSecret
+	}
+	var geo usecase.CountryLookup
+	if path := os.Getenv("GEOIP_DB"); path != "" {
+		db, err := geoip.Open(path)
+		if err != nil {
+			log.Fatal("Failed to open GeoIP database:", err)
+		}
+		geo = db
+	}
+	clickUC := usecase.NewClickUseCase(clickRepo, invRepo, geo, clickSecret)
+	engagementUC := usecase.NewEngagementUseCase(engagementRepo, invRepo, clickSecret)
+
+	invHandler := handlers.NewInvitationHandler(invUC, clickUC)
+	guestHandler := handlers.NewGuestHandler(guestUC)
 
 	// 3. Router
 	// Determine frontend dist location
@@ -94,13 +163,200 @@ func main() {
 		apiKey = "dev-api-key"
 	}
 
-	r := api.SetupRouter(invHandler, adminHandler, jwtSecret, apiKey, rootDir)
+	baseURL := os.Getenv("PUBLIC_BASE_URL")
+	if baseURL == "" {
+		baseURL = "https://card-go.asia"
+	}
+	pages, err := web.NewPageRenderer()
+	if err != nil {
+		log.Fatal("Failed to load page templates:", err)
+	}
+	cards := card.NewRenderer(256)
+
+	// Maintenance commands share the setup above and exit instead of serving.
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], invUC, adminUC, pages, cards); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	adminHandler := handlers.NewAdminHandler(adminUC, invUC, baseURL)
+	pageHandler := handlers.NewPageHandler(invUC, cards, pages, filepath.Join(rootDir, "index.html"), baseURL)
+	exportHandler := handlers.NewExportHandler(invUC, adminUC, pages, cards, baseURL)
+	analyticsHandler := handlers.NewAnalyticsHandler(clickUC, engagementUC)
+
+	// Payments: PAYMENT_WEBHOOK_SECRET enables the generic HMAC provider
+	// (named by PAYMENT_PROVIDER_NAME, "hmac" by default) with its checkout
+	// page at PAYMENT_CHECKOUT_URL; PAYMENT_FAKE=true adds the fake provider
+	// for local testing, and is refused with GIN_MODE=release.
+	var providers []usecase.PaymentProvider
+	if secret := os.Getenv("PAYMENT_WEBHOOK_SECRET"); secret != "" {
+		name := os.Getenv("PAYMENT_PROVIDER_NAME")
+		if name == "" {
+			name = "hmac"
+		}
+		providers = append(providers, payment.NewHMACProvider(name, []byte(secret), os.Getenv("PAYMENT_CHECKOUT_URL")))
+	}
+	if fake, _ := strconv.ParseBool(os.Getenv("PAYMENT_FAKE")); fake {
+		if os.Getenv("GIN_MODE") == "release" {
+			log.Fatal("PAYMENT_FAKE must not be set with GIN_MODE=release: anyone could mark orders paid")
+		}
+		log.Println("Fake payment provider enabled: orders can be settled without paying")
+		providers = append(providers, payment.NewFakeProvider(baseURL))
+	}
+	paymentHandler := handlers.NewPaymentHandler(usecase.NewPaymentUseCase(orderRepo, pricingRepo, invRepo, providers...))
+	pricingHandler := handlers.NewPricingHandler(usecase.NewPricingUseCase(pricingRepo))
+
+	// Job queue: QUEUE_WORKERS (4 by default) is how many queued jobs this
+	// replica runs at once, and QUEUE_ENABLED=false leaves them to the
+	// other replicas.
+	var queueOpts usecase.QueueOptions
+	if v := os.Getenv("QUEUE_WORKERS"); v != "" {
+		if queueOpts.Workers, err = strconv.Atoi(v); err != nil {
+			log.Fatal("Invalid QUEUE_WORKERS:", err)
+		}
+	}
+	queue := usecase.NewQueueUseCase(queueRepo, queueOpts)
+	usecase.HandleQueue(queue, usecase.HTTPCallKind, outbound.NewHTTPCallHandler(&http.Client{Timeout: 30 * time.Second}))
+	queueHandler := handlers.NewQueueHandler(queue)
+	// Outbound webhooks are set up in the admin; their deliveries are queue
+	// jobs, so they are only sent by replicas that run the queue.
+	webhooks := usecase.NewWebhookUseCase(webhookRepo, queue, outbound.NewWebhookSender(&http.Client{Timeout: 15 * time.Second}))
+	webhookHandler := handlers.NewWebhookHandler(webhooks)
+
+	// Notifications to clients go out on the channels configured here, as
+	// queue jobs: WhatsApp with WHATSAPP_TOKEN and WHATSAPP_PHONE_NUMBER_ID
+	// (WHATSAPP_API_URL overrides the Cloud API endpoint), email with
+	// SMTP_ADDR ("host:port"), SMTP_FROM and, to log in, SMTP_USERNAME and
+	// SMTP_PASSWORD, and SMS with SMS_API_URL, SMS_API_TOKEN and SMS_FROM.
+	// NOTIFY_FAKE=true adds the fake channel, which only logs messages, for
+	// local testing.
+	notifyClient := &http.Client{Timeout: 30 * time.Second}
+	var channels []usecase.NotificationChannel
+	var whatsapp usecase.NotificationChannel
+	if token := os.Getenv("WHATSAPP_TOKEN"); token != "" {
+		whatsapp = notify.NewWhatsApp(notifyClient, os.Getenv("WHATSAPP_API_URL"), os.Getenv("WHATSAPP_PHONE_NUMBER_ID"), token)
+		channels = append(channels, whatsapp)
+	}
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		email, err := notify.NewEmail(addr, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM"))
+		if err != nil {
+			log.Fatal("Invalid SMTP settings:", err)
+		}
+		channels = append(channels, email)
+	}
+	if url := os.Getenv("SMS_API_URL"); url != "" {
+		channels = append(channels, notify.NewSMS(notifyClient, url, os.Getenv("SMS_API_TOKEN"), os.Getenv("SMS_FROM")))
+	}
+	if fake, _ := strconv.ParseBool(os.Getenv("NOTIFY_FAKE")); fake {
+		log.Println("Fake notification channel enabled: messages are logged, not sent")
+		channels = append(channels, notify.NewFake())
+	}
+	notifications := usecase.NewNotificationUseCase(notificationRepo, invRepo, queue, baseURL, channels...)
+	notificationHandler := handlers.NewNotificationHandler(notifications)
+
+	// Guest reminders go out on REMINDER_CHANNEL (whatsapp by default, fake
+	// for local testing), never in REMINDER_QUIET_HOURS ("22:00-09:00" by
+	// default, "off" for none) of the event's timezone. REMINDER_TIMEZONE
+	// (Asia/Almaty by default) is the timezone of events that set none.
+	reminders, err := usecase.NewReminderUseCase(reminderRepo, invRepo, guestRepo, notifications, baseURL, usecase.ReminderOptions{
+		Channel:    os.Getenv("REMINDER_CHANNEL"),
+		Timezone:   os.Getenv("REMINDER_TIMEZONE"),
+		QuietHours: os.Getenv("REMINDER_QUIET_HOURS"),
+	})
+	if err != nil {
+		log.Fatal("Invalid reminder settings:", err)
+	}
+	reminderHandler := handlers.NewReminderHandler(reminders, pages, baseURL)
+
+	// The WhatsApp onboarding bot answers clients who write to the business
+	// number. It needs the WhatsApp channel above, WHATSAPP_APP_SECRET to
+	// check the webhook calls and WHATSAPP_VERIFY_TOKEN, the token the
+	// webhook is subscribed with in the Meta app.
+	var inbound usecase.WhatsAppInbound
+	if secret := os.Getenv("WHATSAPP_APP_SECRET"); secret != "" {
+		inbound = notify.NewWhatsAppWebhook(secret)
+	}
+	onboarding := usecase.NewOnboardingUseCase(conversationRepo, invUC, adminRepo, queue, whatsapp, inbound, os.Getenv("WHATSAPP_VERIFY_TOKEN"), baseURL)
+	onboardingHandler := handlers.NewOnboardingHandler(onboarding)
+	questionnaireHandler := handlers.NewQuestionnaireHandler(usecase.NewQuestionnaireUseCase(adminRepo))
+
+	// Scheduled jobs: TRIAL_RETENTION (720h by default) is how long an
+	// abandoned trial is kept before it may be purged, and
+	// SCHEDULER_ENABLED=false keeps this replica from running jobs on
+	// schedule. Jobs are turned on and off for all replicas in the admin.
+	var retention time.Duration
+	if v := os.Getenv("TRIAL_RETENTION"); v != "" {
+		if retention, err = time.ParseDuration(v); err != nil {
+			log.Fatal("Invalid TRIAL_RETENTION:", err)
+		}
+	}
+	scheduler, err := usecase.NewSchedulerUseCase(jobRepo, slices.Concat(
+		usecase.LifecycleJobs(lifecycleRepo, retention), queue.ScheduledJobs(0), webhooks.ScheduledJobs(0),
+		reminders.ScheduledJobs())...)
+	if err != nil {
+		log.Fatal("Invalid job schedule:", err)
+	}
+	jobHandler := handlers.NewJobHandler(scheduler)
+
+	r := api.SetupRouter(invHandler, adminHandler, pageHandler, exportHandler, guestHandler, analyticsHandler, paymentHandler, pricingHandler, jobHandler, queueHandler, webhookHandler, notificationHandler, onboardingHandler, questionnaireHandler, reminderHandler, idempotencyUC, jwtSecret, apiKey, rootDir)
+
+	// TRUSTED_PROXIES is a comma-separated list of the addresses or CIDRs
+	// of the reverse proxies whose X-Forwarded-For is believed. By default
+	// none is, so clients can't forge the IPs visitor hashes are built from.
+	var proxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			proxies = append(proxies, p)
+		}
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "3000"
 	}
 
+	// Background workers outlive the HTTP server on shutdown, so they still
+	// see what the last requests queued.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	runners := []func(context.Context){clickUC.Run, engagementUC.Run, webhooks.Run}
+	if enabled, err := strconv.ParseBool(os.Getenv("SCHEDULER_ENABLED")); err != nil || enabled {
+		runners = append(runners, scheduler.Run)
+	}
+	if enabled, err := strconv.ParseBool(os.Getenv("QUEUE_ENABLED")); err != nil || enabled {
+		runners = append(runners, queue.Run)
+	}
+	for _, run := range runners {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+
+	srv := &http.Server{Addr: "0.0.0.0:" + port, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed:", err)
+		}
+	}()
 	fmt.Printf("🚀 DDD Go Backend started on 0.0.0.0:%s\n", port)
-	r.Run("0.0.0.0:" + port)
+
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-signals.Done()
+
+	log.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown:", err)
+	}
+	stopWorkers()
+	workers.Wait()
 }
@@ -1,6 +1,9 @@
 package domain
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 )
 
@@ -16,21 +19,338 @@ type Invitation struct {
 	EventDate     string                 `json:"eventDate"`
 	EventLocation string                 `json:"eventLocation"`
 	ShortCode     string                 `json:"shortCode"`
-	IsPaid        bool                   `json:"isPaid"`
-	ExpiresAt     *time.Time             `json:"expiresAt"`
-	CreatedAt     time.Time              `json:"createdAt"`
-	UpdatedAt     time.Time              `json:"updatedAt"`
+	// PlanCode is the Plan the invitation is sold on, "" until one is
+	// chosen.
+	PlanCode  string     `json:"planCode"`
+	IsPaid    bool       `json:"isPaid"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// HostingUntil is how long the paid plan keeps the invitation online.
+	HostingUntil *time.Time `json:"hostingUntil"`
+	// Lifecycle is one of the Lifecycle* states, kept up to date by the
+	// scheduled jobs.
+	Lifecycle string    `json:"lifecycle"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// Lifecycle states of an invitation. The scheduled jobs move an invitation
+// to expired once it goes offline, back to active if its trial or hosting
+// is extended, and to post_event the day after the wedding.
+const (
+	LifecycleActive    = "active"
+	LifecycleExpired   = "expired"
+	LifecyclePostEvent = "post_event"
+)
+
+// Well-known keys of Invitation.Content. Content is free-form JSON filled by
+// the admin form and n8n; these are the keys the server understands.
+const (
+	ContentStory     = "story"
+	ContentSchedule  = "schedule"
+	ContentAddress   = "address"
+	ContentMapURL    = "mapUrl"
+	ContentDressCode = "dressCode"
+	// ContentPhotoMediaID is the WhatsApp media id of the photo the client
+	// sent to the onboarding bot, for the admin to download.
+	ContentPhotoMediaID = "photoMediaId"
+	// ContentRSVPDeadline is the day, or time, guests are asked to answer
+	// by, in the formats of EventDate.
+	ContentRSVPDeadline = "rsvpDeadline"
+	// ContentTimezone is the IANA timezone of the event, "Asia/Almaty".
+	ContentTimezone = "timezone"
+)
+
+type ScheduleItem struct {
+	Time        string `json:"time"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ContentString returns a string value from Content, or "" when the key is
+// missing or not a string.
+func (i *Invitation) ContentString(key string) string {
+	s, _ := i.Content[key].(string)
+	return strings.TrimSpace(s)
+}
+
+// Schedule returns the program of the day from Content, skipping malformed
+// entries.
+func (i *Invitation) Schedule() []ScheduleItem {
+	raw, _ := i.Content[ContentSchedule].([]interface{})
+	items := make([]ScheduleItem, 0, len(raw))
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		item := ScheduleItem{}
+		item.Time, _ = m["time"].(string)
+		item.Name, _ = m["name"].(string)
+		item.Description, _ = m["description"].(string)
+		if item.Name == "" && item.Time == "" {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// eventDateLayouts lists the formats EventDate is stored in: the admin form
+// sends ISO timestamps, while older rows and n8n use plain SQL-like strings.
+var eventDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
+// EventTime parses EventDate. The second return value is false when the date
+// is empty or in an unknown format.
+func (i *Invitation) EventTime() (time.Time, bool) {
+	s := strings.TrimSpace(i.EventDate)
+	if s == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range eventDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// RSVPDeadline parses the ContentRSVPDeadline of Content like EventTime.
+func (i *Invitation) RSVPDeadline() (time.Time, bool) {
+	return (&Invitation{EventDate: i.ContentString(ContentRSVPDeadline)}).EventTime()
+}
+
+// IsExpired reports whether an unpaid invitation is past its trial period,
+// or a paid one past its hosting. Paid invitations without HostingUntil
+// stay online for good.
+func (i *Invitation) IsExpired(now time.Time) bool {
+	if i.IsPaid {
+		return i.HostingUntil != nil && i.HostingUntil.Before(now)
+	}
+	return i.ExpiresAt != nil && i.ExpiresAt.Before(now)
+}
+
+// EndsAt is when the invitation goes offline: the end of the trial, or of
+// the hosting once paid. It is nil when there is no end.
+func (i *Invitation) EndsAt() *time.Time {
+	if i.IsPaid {
+		return i.HostingUntil
+	}
+	return i.ExpiresAt
+}
+
+// Attendance answers of an RSVP.
+const (
+	AttendanceYes   = "yes"
+	AttendanceNo    = "no"
+	AttendanceMaybe = "maybe"
+)
+
 type RSVPResponse struct {
+	ID             int    `json:"id"`
+	InvitationUUID string `json:"invitationUuid"`
+	GuestName      string `json:"guestName"`
+	Attendance     string `json:"attendance"`
+	GuestCount     int    `json:"guestCount"`
+	// Phone is optional, in international format; guests leave it to be
+	// reminded of the event.
+	Phone     string    `json:"phone"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Guest is an entry of an invitation's guest roster: the people the couple
+// plans to invite, as opposed to the RSVP responses that come back.
+type Guest struct {
 	ID             int       `json:"id"`
 	InvitationUUID string    `json:"invitationUuid"`
-	GuestName      string    `json:"guestName"`
-	Attendance     string    `json:"attendance"`
-	GuestCount     int       `json:"guestCount"`
+	Name           string    `json:"name"`
+	Phone          string    `json:"phone"`
+	PartySize      int       `json:"partySize"`
+	Group          string    `json:"group"`
+	Note           string    `json:"note"`
 	CreatedAt      time.Time `json:"createdAt"`
 }
 
+// GuestImportError is a validation problem of one spreadsheet row. Row is
+// the 1-based row number as shown in Excel, header included.
+type GuestImportError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+// GuestImportReport is the outcome of a roster import or its dry run.
+type GuestImportReport struct {
+	DryRun   bool               `json:"dryRun"`
+	Rows     int                `json:"rows"`
+	Valid    int                `json:"valid"`
+	Imported int                `json:"imported"`
+	Mapping  map[string]string  `json:"mapping"`
+	Errors   []GuestImportError `json:"errors"`
+	Guests   []Guest            `json:"guests"`
+}
+
+// InvitationBatchItem is the outcome of one entry of a batch create. Index
+// is the position in the request; Error is empty when it was created.
+type InvitationBatchItem struct {
+	Index     int    `json:"index"`
+	UUID      string `json:"uuid,omitempty"`
+	ShortCode string `json:"shortCode,omitempty"`
+	ShortLink string `json:"shortLink,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// IdempotencyRecord is the stored outcome of a request made with an
+// Idempotency-Key. StatusCode is 0 while the first request is in flight.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+}
+
+// User agent classes of short link clicks.
+const (
+	UAClassMobile  = "mobile"
+	UAClassTablet  = "tablet"
+	UAClassDesktop = "desktop"
+	UAClassBot     = "bot"
+	UAClassOther   = "other"
+)
+
+// Click is one opening of a short link. The visitor is only known by a keyed
+// hash of their IP address; Referrer is the referring host.
+type Click struct {
+	InvitationUUID string
+	ShortCode      string
+	ClickedAt      time.Time
+	VisitorHash    string
+	UAClass        string
+	Referrer       string
+	Country        string
+}
+
+// ClickStats summarizes the clicks of an invitation over [From, To]. Bots
+// are only counted in BotClicks; Devices, Countries and Referrers break the
+// other clicks down, with "" for unknown.
+type ClickStats struct {
+	From           string         `json:"from"`
+	To             string         `json:"to"`
+	Clicks         int            `json:"clicks"`
+	UniqueVisitors int            `json:"uniqueVisitors"`
+	BotClicks      int            `json:"botClicks"`
+	Daily          []DailyClicks  `json:"daily"`
+	Devices        map[string]int `json:"devices"`
+	Countries      map[string]int `json:"countries"`
+	Referrers      map[string]int `json:"referrers"`
+}
+
+// DailyClicks is one UTC day of ClickStats.Daily; Date is YYYY-MM-DD.
+type DailyClicks struct {
+	Date           string `json:"date"`
+	Clicks         int    `json:"clicks"`
+	UniqueVisitors int    `json:"uniqueVisitors"`
+}
+
+// Sections of the invitation page reported by the engagement beacon, in
+// the order guests usually reach them.
+const (
+	EngagementView     = "view"
+	EngagementStory    = "story"
+	EngagementLocation = "location"
+	EngagementRSVPForm = "rsvp_form"
+)
+
+// EngagementEvent is one beacon call of the invitation page.
+type EngagementEvent struct {
+	InvitationUUID string
+	Event          string
+	OccurredAt     time.Time
+	VisitorHash    string
+	UAClass        string
+}
+
+// EngagementFunnel follows guests from opening the invitation to answering
+// it. The steps count unique visitors without bots; Responded is the number
+// of RSVPs, which are not tied to a visitor.
+type EngagementFunnel struct {
+	Opened          int `json:"opened"`
+	ReachedStory    int `json:"reachedStory"`
+	ReachedLocation int `json:"reachedLocation"`
+	ReachedRSVP     int `json:"reachedRsvp"`
+	Responded       int `json:"responded"`
+	Views           int `json:"views"`
+	BotViews        int `json:"botViews"`
+}
+
+// Plan is a package the invitations are sold as. Price is in minor units
+// of Currency; a paid order adds HostingDays to the invitation's hosting.
+type Plan struct {
+	Code        string   `json:"code"`
+	NameRu      string   `json:"nameRu"`
+	NameKk      string   `json:"nameKk"`
+	NameEn      string   `json:"nameEn"`
+	Price       int64    `json:"price"`
+	Currency    string   `json:"currency"`
+	Features    []string `json:"features"`
+	HostingDays int      `json:"hostingDays"`
+	IsActive    bool     `json:"isActive"`
+	SortOrder   int      `json:"sortOrder"`
+}
+
+// Kinds of PromoCode.
+const (
+	PromoPercent = "percent"
+	PromoFixed   = "fixed"
+)
+
+// PromoCode discounts an order: Value percent off, or Value minor units of
+// Currency off for a fixed discount.
+type PromoCode struct {
+	Code     string `json:"code"`
+	Kind     string `json:"kind"`
+	Value    int64  `json:"value"`
+	Currency string `json:"currency,omitempty"`
+	// MaxUses nil is unlimited. UsedCount counts pending and paid orders.
+	MaxUses   *int       `json:"maxUses"`
+	UsedCount int        `json:"usedCount"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	IsActive  bool       `json:"isActive"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Available reports whether the code can still be redeemed at now.
+func (p *PromoCode) Available(now time.Time) bool {
+	return p.IsActive &&
+		(p.ExpiresAt == nil || now.Before(*p.ExpiresAt)) &&
+		(p.MaxUses == nil || p.UsedCount < *p.MaxUses)
+}
+
+// Discount returns how much the code takes off price, never more than the
+// price itself. A fixed discount in another currency takes off nothing.
+func (p *PromoCode) Discount(price int64, currency string) int64 {
+	var d int64
+	switch p.Kind {
+	case PromoPercent:
+		d = price * p.Value / 100
+	case PromoFixed:
+		if p.Currency == currency {
+			d = p.Value
+		}
+	}
+	return min(max(d, 0), price)
+}
+
 type Template struct {
 	ID       int    `json:"id"`
 	Code     string `json:"code"`
@@ -46,24 +366,737 @@ type AdminStats struct {
 	TotalGuests      int `json:"totalGuests"`
 }
 
+// Granularities of BusinessAnalytics.Series.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// BusinessAnalytics charts the business over [From, To]: a series per
+// period of the given granularity, and shares by template and language of
+// the invitations created in the range.
+type BusinessAnalytics struct {
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Granularity string            `json:"granularity"`
+	Totals      AnalyticsPeriod   `json:"totals"`
+	Series      []AnalyticsPeriod `json:"series"`
+	Templates   []AnalyticsShare  `json:"templates"`
+	Languages   []AnalyticsShare  `json:"languages"`
+}
+
+// AnalyticsPeriod counts what happened in a period starting on Period
+// (YYYY-MM-DD; weeks start on Monday). Paid counts the invitations created
+// in the period that have been paid since, so Conversion is the trial to
+// paid rate of that cohort. Guests are those attending.
+type AnalyticsPeriod struct {
+	Period      string  `json:"period,omitempty"`
+	Invitations int     `json:"invitations"`
+	Paid        int     `json:"paid"`
+	Conversion  float64 `json:"conversion"`
+	RSVPs       int     `json:"rsvps"`
+	Guests      int     `json:"guests"`
+}
+
+// AnalyticsShare is the number of invitations with a template_code or lang.
+type AnalyticsShare struct {
+	Key         string  `json:"key"`
+	Name        string  `json:"name,omitempty"`
+	Invitations int     `json:"invitations"`
+	Paid        int     `json:"paid"`
+	Share       float64 `json:"share"`
+}
+
+// Order statuses. A pending order can fail or be cancelled and still be
+// paid later; paid is final.
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderFailed    = "failed"
+	OrderCancelled = "cancelled"
+)
+
+// Order is a payment for an invitation through a payment provider. Amount
+// is in minor units of Currency, e.g. tiyn for KZT.
+type Order struct {
+	ID             string `json:"id"`
+	InvitationUUID string `json:"invitationUuid"`
+	Provider       string `json:"provider"`
+	ProviderRef    string `json:"providerRef"`
+	Amount         int64  `json:"amount"`
+	Currency       string `json:"currency"`
+	Status         string `json:"status"`
+	PaymentURL     string `json:"paymentUrl"`
+	// PlanCode and PromoCode are what the order was priced on: Amount is
+	// ListPrice less Discount. HostingDays is copied from the plan.
+	PlanCode    string     `json:"planCode"`
+	PromoCode   string     `json:"promoCode,omitempty"`
+	ListPrice   int64      `json:"listPrice"`
+	Discount    int64      `json:"discount"`
+	HostingDays int        `json:"hostingDays"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	PaidAt      *time.Time `json:"paidAt"`
+}
+
+// PaymentEvent is a verified webhook call of a payment provider.
+type PaymentEvent struct {
+	Provider string
+	// EventID is unique per provider; redeliveries of an event share it.
+	EventID string
+	OrderID string
+	// Status is one of the Order* statuses but pending.
+	Status string
+	// Amount and Currency are what the provider charged, zero if it
+	// doesn't say.
+	Amount   int64
+	Currency string
+	Payload  []byte
+}
+
 type InvitationWithStats struct {
 	Invitation
-	RSVPCount      int    `json:"rsvpCount"`
-	ApprovedGuests int    `json:"approvedGuests"`
-	TemplateName   string `json:"templateName"`
+	RSVPCount      int              `json:"rsvpCount"`
+	Attending      int              `json:"attending"`
+	Declined       int              `json:"declined"`
+	Maybe          int              `json:"maybe"`
+	ApprovedGuests int              `json:"approvedGuests"`
+	TemplateName   string           `json:"templateName"`
+	Funnel         EngagementFunnel `json:"funnel"`
 }
 
+// ExpiringInvitation is an invitation about to go offline, for the admin
+// to follow up on.
+type ExpiringInvitation struct {
+	UUID         string    `json:"uuid"`
+	ShortCode    string    `json:"shortCode"`
+	PhoneNumber  string    `json:"phoneNumber"`
+	GroomName    string    `json:"groomName"`
+	BrideName    string    `json:"brideName"`
+	TemplateCode string    `json:"templateCode"`
+	PlanCode     string    `json:"planCode"`
+	IsPaid       bool      `json:"isPaid"`
+	EndsAt       time.Time `json:"endsAt"`
+}
+
+// Statuses of a JobRun.
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobRun is one run of a scheduled job.
+type JobRun struct {
+	ID  int64  `json:"id"`
+	Job string `json:"job"`
+	// ScheduledFor is the minute the run was due, or the time it was
+	// started by hand; each job runs once per ScheduledFor.
+	ScheduledFor time.Time  `json:"scheduledFor"`
+	Manual       bool       `json:"manual"`
+	StartedAt    time.Time  `json:"startedAt"`
+	FinishedAt   *time.Time `json:"finishedAt"`
+	Status       string     `json:"status"`
+	// Affected is how many rows the job changed.
+	Affected int    `json:"affected"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Statuses of a QueueJob. A failed job waits as pending until its next
+// attempt and is dead once it has no attempts left.
+const (
+	QueuePending = "pending"
+	QueueRunning = "running"
+	QueueDone    = "done"
+	QueueDead    = "dead"
+)
+
+// QueueJob is a unit of background work in the job queue.
+type QueueJob struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	// RunAt is when the job is due, or due again after a failure.
+	RunAt time.Time `json:"runAt"`
+	// LockedUntil is when a running job is handed to another worker,
+	// should its worker have died.
+	LockedUntil *time.Time `json:"lockedUntil"`
+	LastError   string     `json:"lastError,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	FinishedAt  *time.Time `json:"finishedAt"`
+}
+
+// QueueJobFilter selects jobs for the admin; empty fields match all.
+type QueueJobFilter struct {
+	Status string
+	Kind   string
+	Limit  int
+	Offset int
+}
+
+// Events sent to webhook subscriptions.
+const (
+	WebhookInvitationCreated = "invitation.created"
+	WebhookInvitationPaid    = "invitation.paid"
+	WebhookInvitationExpired = "invitation.expired"
+	WebhookRSVPSubmitted     = "rsvp.submitted"
+)
+
+// WebhookEvents lists the events a subscription can ask for.
+var WebhookEvents = []string{WebhookInvitationCreated, WebhookInvitationPaid, WebhookInvitationExpired, WebhookRSVPSubmitted}
+
+// WebhookSubscription is an endpoint, such as an n8n webhook node, that
+// gets the events it subscribed to, signed with its secret.
+type WebhookSubscription struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// WebhookEvent is an event recorded in the outbox, in the transaction of
+// the change it reports.
+type WebhookEvent struct {
+	ID             int64           `json:"id"`
+	Event          string          `json:"event"`
+	InvitationUUID string          `json:"invitationUuid"`
+	Data           json.RawMessage `json:"data"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// Statuses of a WebhookDelivery. A delivery is pending while it is being
+// retried and failed once the queue gave up on it.
+const (
+	WebhookPending   = "pending"
+	WebhookSucceeded = "succeeded"
+	WebhookFailed    = "failed"
+)
+
+// WebhookDelivery is the sending of one event to one subscription, with
+// the outcome of its last attempt.
+type WebhookDelivery struct {
+	ID             int64  `json:"id"`
+	SubscriptionID int64  `json:"subscriptionId"`
+	EventID        int64  `json:"eventId"`
+	Event          string `json:"event"`
+	Status         string `json:"status"`
+	Attempts       int    `json:"attempts"`
+	// ResponseStatus is the HTTP status of the last answer, if any.
+	ResponseStatus *int       `json:"responseStatus"`
+	ResponseBody   string     `json:"responseBody,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+	// RedeliveryOf is the delivery this one was sent again for.
+	RedeliveryOf *int64 `json:"redeliveryOf"`
+}
+
+// Channels a Notification can go out on.
+const (
+	ChannelWhatsApp = "whatsapp"
+	ChannelSMS      = "sms"
+	ChannelEmail    = "email"
+	// ChannelFake is for local development: messages are only logged.
+	ChannelFake = "fake"
+)
+
+// Statuses of a Notification. A notification is queued while it is being
+// retried and failed once the queue gave up on it.
+const (
+	NotificationQueued = "queued"
+	NotificationSent   = "sent"
+	NotificationFailed = "failed"
+)
+
+// Notification is a message sent to the client about an invitation, as
+// rendered when it was queued, with the outcome of its last attempt.
+type Notification struct {
+	ID             int64  `json:"id"`
+	InvitationUUID string `json:"invitationUuid"`
+	Channel        string `json:"channel"`
+	Template       string `json:"template"`
+	Lang           string `json:"lang"`
+	// Recipient is a phone number for WhatsApp and SMS and an address
+	// for email.
+	Recipient string `json:"recipient"`
+	Subject   string `json:"subject,omitempty"`
+	Body      string `json:"body"`
+	Status    string `json:"status"`
+	// ProviderID is the id the channel gave the message, if any.
+	ProviderID string     `json:"providerId,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Attempts   int        `json:"attempts"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	SentAt     *time.Time `json:"sentAt"`
+}
+
+// Steps of a Conversation besides the questionnaire fields it asks for
+// in turn: confirming the answers, and done once the invitation exists.
+const (
+	ConversationConfirm = "confirm"
+	ConversationDone    = "done"
+)
+
+// Conversation is the state of the WhatsApp onboarding bot with one
+// client phone number.
+type Conversation struct {
+	// Phone is the client's number in international format.
+	Phone string `json:"phone"`
+	// Lang is the language the bot speaks, not the invitation's.
+	Lang string `json:"lang"`
+	// Step is the questionnaire field being asked for, or one of the
+	// Conversation* steps.
+	Step string `json:"step"`
+	// Answers holds the validated answers by field.
+	Answers map[string]string `json:"answers"`
+	// Correcting is set while a single answer is asked again from the
+	// confirmation, which the bot goes back to afterwards.
+	Correcting     bool   `json:"correcting"`
+	InvitationUUID string `json:"invitationUuid,omitempty"`
+	// LastMessageID is the WhatsApp id of the last message handled, so a
+	// redelivered one is ignored.
+	LastMessageID string `json:"lastMessageId"`
+	// Version guards against two messages changing the state at once.
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// What a ReminderPolicy is timed from: the RSVP deadline or the event,
+// both in the event's timezone.
+const (
+	ReminderAnchorDeadline = "deadline"
+	ReminderAnchorEvent    = "event"
+)
+
+// Who a ReminderPolicy reminds.
+const (
+	// ReminderPending: guests of the roster who haven't answered, and
+	// those who answered maybe.
+	ReminderPending = "pending"
+	// ReminderAttending: guests who said they come.
+	ReminderAttending = "attending"
+)
+
+// ReminderPolicy is a reminder sent to an invitation's guests at a time
+// set relative to the RSVP deadline or the event, once per guest.
+type ReminderPolicy struct {
+	ID             int64  `json:"id"`
+	InvitationUUID string `json:"invitationUuid"`
+	Anchor         string `json:"anchor"`
+	// OffsetMinutes moves the reminder from its anchor; negative is
+	// before, -1440 a day before.
+	OffsetMinutes int    `json:"offsetMinutes"`
+	Audience      string `json:"audience"`
+	Enabled       bool   `json:"enabled"`
+	// DueAt is when the reminder goes out, nil while the invitation lacks
+	// the anchor. It is not stored but worked out for the admin.
+	DueAt     *time.Time `json:"dueAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// ReminderDelivery is a reminder of a policy to one phone number.
+type ReminderDelivery struct {
+	PolicyID       int64  `json:"policyId"`
+	InvitationUUID string `json:"invitationUuid"`
+	Phone          string `json:"phone"`
+	// Token is the guest's secret in the opt-out link of the message.
+	Token          string    `json:"-"`
+	NotificationID int64     `json:"notificationId"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// ReminderOptOut is a phone number that gets no more reminders, from any
+// invitation.
+type ReminderOptOut struct {
+	Phone     string    `json:"phone"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ErrShortCodeTaken is returned by InvitationRepository.Create and
+// CreateMany when the short code is already in use.
+var ErrShortCodeTaken = errors.New("short_code_taken")
+
+// Errors of PricingRepository, and of OrderRepository.CreateOrder when
+// the promo code ran out or expired in the meantime.
+var (
+	ErrUnknownPlan          = errors.New("unknown plan")
+	ErrPromoCodeNotFound    = errors.New("promo code not found")
+	ErrPromoCodeExists      = errors.New("promo code already exists")
+	ErrPromoCodeUnavailable = errors.New("promo code is expired or used up")
+)
+
+// ErrOrderNotFound is returned by OrderRepository.GetOrder for an unknown
+// order.
+var ErrOrderNotFound = errors.New("order not found")
+
+// ErrInvitationNotFound is returned by use cases for an admin call on an
+// invitation that can't be loaded.
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+// ErrInvitationPaid and ErrInvitationNotPaid are returned by
+// InvitationRepository.SetExpiresAt and SetHostingUntil: the trial only
+// applies before payment, and hosting only after.
+var (
+	ErrInvitationPaid    = errors.New("invitation is paid")
+	ErrInvitationNotPaid = errors.New("invitation is not paid")
+)
+
+// ErrJobNotFound is returned for a job name the scheduler doesn't know.
+var ErrJobNotFound = errors.New("job not found")
+
+// Errors of QueueRepository.RetryQueueJob: only dead jobs and pending ones
+// waiting to be retried can be retried.
+var (
+	ErrQueueJobNotFound     = errors.New("queue job not found")
+	ErrQueueJobNotRetryable = errors.New("queue job is running or done")
+)
+
+// Errors of WebhookRepository for unknown ids.
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// ErrNotificationNotFound is returned by NotificationRepository for an
+// unknown id.
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// Errors of ConversationRepository: SaveConversation fails with
+// ErrConversationConflict when someone saved the conversation in the
+// meantime.
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrConversationConflict = errors.New("conversation changed concurrently")
+)
+
+// Errors of ReminderRepository for unknown policies and opt-out links.
+var (
+	ErrReminderPolicyNotFound = errors.New("reminder policy not found")
+	ErrReminderTokenNotFound  = errors.New("reminder link not found")
+)
+
+// ErrRSVPNotFound is returned by InvitationRepository.UpdateRSVP and
+// DeleteRSVP when the invitation has no response with that id.
+var ErrRSVPNotFound = errors.New("rsvp not found")
+
 type InvitationRepository interface {
 	GetByUUID(uuid string) (*Invitation, error)
 	GetByShortCode(code string) (*Invitation, error)
 	Create(inv *Invitation) error
+	// CreateMany inserts all invitations in a single transaction.
+	CreateMany(invs []*Invitation) error
+	// ShortCodeExists reports whether an invitation uses code.
+	ShortCodeExists(code string) (bool, error)
 	MarkAsPaid(uuid string) error
+	// SetExpiresAt moves the end of the trial of an unpaid invitation.
+	SetExpiresAt(uuid string, expiresAt time.Time) error
+	// SetHostingUntil moves the end of the hosting of a paid invitation;
+	// nil keeps it online for good.
+	SetHostingUntil(uuid string, hostingUntil *time.Time) error
+	// AddRSVP, UpdateRSVP and DeleteRSVP keep the invitation's RSVP
+	// counters in step in the same transaction.
 	AddRSVP(rsvp *RSVPResponse) error
+	// UpdateRSVP rewrites the name, attendance and guest count of the
+	// response rsvp.ID of rsvp.InvitationUUID.
+	UpdateRSVP(rsvp *RSVPResponse) error
+	DeleteRSVP(invitationUUID string, id int) error
+	GetRSVPs(invitationUUID string) ([]RSVPResponse, error)
+}
+
+type GuestRepository interface {
+	ListGuests(invitationUUID string) ([]Guest, error)
+	// AddGuests inserts all guests in a single transaction.
+	AddGuests(guests []Guest) error
+}
+
+// Sort keys of the admin invitation list.
+const (
+	InvitationSortCreated = "createdAt"
+	InvitationSortEvent   = "eventDate"
+	InvitationSortExpires = "expiresAt"
+	InvitationSortGroom   = "groomName"
+	InvitationSortBride   = "brideName"
+)
+
+// IsInvitationSort reports whether key is one of the InvitationSort* keys.
+func IsInvitationSort(key string) bool {
+	switch key {
+	case InvitationSortCreated, InvitationSortEvent, InvitationSortExpires, InvitationSortGroom, InvitationSortBride:
+		return true
+	}
+	return false
+}
+
+// InvitationFilter narrows, orders and pages the admin invitation list and
+// its exports. Zero values match everything, newest first.
+type InvitationFilter struct {
+	Paid         *bool
+	Expired      *bool
+	TemplateCode string
+	Lang         string
+	// EventFrom and EventTo bound the event day, both included. An
+	// EventDate that cannot be parsed never matches.
+	EventFrom *time.Time
+	EventTo   *time.Time
+	// Search matches a substring of the names, phone number or short code.
+	Search string
+	// Sort is one of the InvitationSort* keys; Desc reverses it.
+	Sort string
+	Desc bool
+	// Limit 0 returns every match.
+	Limit  int
+	Offset int
+}
+
+// InvitationPage is a page of the admin list with the number of matches
+// across all pages.
+type InvitationPage struct {
+	Items  []InvitationWithStats `json:"items"`
+	Total  int                   `json:"total"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
 }
 
 type AdminRepository interface {
 	GetStats() (*AdminStats, error)
-	GetInvitationsList() ([]InvitationWithStats, error)
+	// GetAnalytics aggregates [from, to) by granularity. Series holds only
+	// periods with activity, and the rates and shares are left to compute.
+	GetAnalytics(from, to time.Time, granularity string) (*BusinessAnalytics, error)
+	GetInvitationsList(filter InvitationFilter) (*InvitationPage, error)
+	// EachInvitation calls fn for every matching invitation, in order,
+	// while reading the rows, so exports don't hold the whole list in
+	// memory.
+	EachInvitation(filter InvitationFilter, fn func(*InvitationWithStats) error) error
+	// GetTemplates returns the active templates; IsActive is not read.
 	GetTemplates() ([]Template, error)
 	MarkAsPaid(uuid string) error
+	// ListExpiring returns the invitations whose trial or hosting ends in
+	// [from, to), soonest first.
+	ListExpiring(from, to time.Time) ([]ExpiringInvitation, error)
+	// ReconcileRSVPCounters rebuilds the RSVP counters from the responses
+	// and returns how many invitations were off.
+	ReconcileRSVPCounters() (int, error)
+}
+
+// LifecycleRepository moves invitations between the Lifecycle* states for
+// the scheduled jobs. Each method returns how many invitations it changed.
+type LifecycleRepository interface {
+	// ExpireInvitations marks invitations that went offline before now
+	// as expired, and expired ones back online as active.
+	ExpireInvitations(now time.Time) (int, error)
+	// MarkPostEvent moves active invitations whose event day is before
+	// day to post_event.
+	MarkPostEvent(day time.Time) (int, error)
+	// PurgeExpiredTrials deletes unpaid, expired invitations whose trial
+	// ended before cutoff, that no guest answered and that have no orders.
+	PurgeExpiredTrials(cutoff time.Time) (int, error)
+}
+
+type JobRepository interface {
+	// JobFlags returns the enable flags admins have set, by job name.
+	JobFlags() (map[string]bool, error)
+	SetJobEnabled(job string, enabled bool) error
+	// TryJobLock takes a lock on job shared by all replicas. ok is false
+	// when someone else holds it; otherwise unlock must be called.
+	TryJobLock(job string) (unlock func(), ok bool, err error)
+	// StartJobRun records a run of job due at scheduledFor. It returns
+	// false when that run was already recorded, e.g. by another replica.
+	StartJobRun(job string, scheduledFor time.Time, manual bool) (*JobRun, bool, error)
+	FinishJobRun(run *JobRun) error
+	// ListJobRuns returns the latest runs of job, newest first.
+	ListJobRuns(job string, limit int) ([]JobRun, error)
+	// LastJobRuns returns the latest run of every job that ran.
+	LastJobRuns() (map[string]JobRun, error)
+}
+
+type QueueRepository interface {
+	// Enqueue stores job as pending and fills in its ID.
+	Enqueue(job *QueueJob) error
+	// ClaimQueueJobs hands out up to limit due jobs of the given kinds,
+	// skipping rows other workers hold, and marks them running until
+	// now+lease. Running jobs whose lease ran out are claimed again, or
+	// dead-lettered when they have no attempts left.
+	ClaimQueueJobs(kinds []string, limit int, lease time.Duration) ([]QueueJob, error)
+	// CompleteQueueJob, FailQueueJob, DeadLetterQueueJob and
+	// ReleaseQueueJob finish the claim of job; they do nothing once the
+	// claim was lost to another worker. FailQueueJob makes the job pending
+	// again retryAfter from now, and ReleaseQueueJob hands it back as if
+	// it had not been attempted.
+	CompleteQueueJob(job *QueueJob) error
+	FailQueueJob(job *QueueJob, errMsg string, retryAfter time.Duration) error
+	DeadLetterQueueJob(job *QueueJob, errMsg string) error
+	ReleaseQueueJob(job *QueueJob) error
+	// GetQueueJob returns ErrQueueJobNotFound for an unknown id.
+	GetQueueJob(id int64) (*QueueJob, error)
+	// ListQueueJobs returns the matching jobs, newest first, and how many
+	// match in all.
+	ListQueueJobs(filter QueueJobFilter) ([]QueueJob, int, error)
+	// CountQueueJobs returns how many jobs are in each status.
+	CountQueueJobs() (map[string]int, error)
+	// RetryQueueJob makes a dead or waiting job due now, with its
+	// attempts reset.
+	RetryQueueJob(id int64) (*QueueJob, error)
+	// PurgeQueueJobs deletes done jobs finished before cutoff.
+	PurgeQueueJobs(cutoff time.Time) (int, error)
+}
+
+// WebhookRepository stores subscriptions and relays the outbox. The
+// repositories making the changes write the events: InvitationRepository
+// and AdminRepository (created, paid), OrderRepository (paid),
+// LifecycleRepository (expired) and AddRSVP (rsvp.submitted), only while
+// some active subscription wants them.
+type WebhookRepository interface {
+	ListWebhooks() ([]WebhookSubscription, error)
+	// GetWebhook, UpdateWebhook and DeleteWebhook return
+	// ErrWebhookNotFound for an unknown id.
+	GetWebhook(id int64) (*WebhookSubscription, error)
+	CreateWebhook(sub *WebhookSubscription) error
+	UpdateWebhook(sub *WebhookSubscription) error
+	DeleteWebhook(id int64) error
+	// DispatchWebhookEvents takes up to limit events not yet relayed,
+	// skipping those another replica holds, and creates a delivery for
+	// every active subscription to each. A job like job, with payload
+	// {"deliveryId": id}, is queued for each delivery in the same
+	// transaction. It returns how many events it relayed.
+	DispatchWebhookEvents(limit int, job QueueJob) (int, error)
+	// GetWebhookDelivery returns the delivery with its subscription and
+	// event, or ErrWebhookDeliveryNotFound.
+	GetWebhookDelivery(id int64) (*WebhookDelivery, *WebhookSubscription, *WebhookEvent, error)
+	// RecordWebhookAttempt saves the outcome of an attempt of delivery.
+	RecordWebhookAttempt(delivery *WebhookDelivery) error
+	// ListWebhookDeliveries returns the latest deliveries of a
+	// subscription, newest first.
+	ListWebhookDeliveries(subscriptionID int64, limit int) ([]WebhookDelivery, error)
+	// RedeliverWebhook sends the event of a delivery to its subscription
+	// again as a new delivery, queued like job.
+	RedeliverWebhook(deliveryID int64, job QueueJob) (*WebhookDelivery, error)
+	// PurgeWebhookEvents deletes events relayed before cutoff with their
+	// deliveries.
+	PurgeWebhookEvents(cutoff time.Time) (int, error)
+}
+
+type IdempotencyRepository interface {
+	// Reserve claims key under token for a request with the given hash. It
+	// returns nil when the key was free, or the record already stored under
+	// it. Records created before expiredBefore, and unfinished ones created
+	// before staleBefore, are dropped first.
+	Reserve(key, token, requestHash string, expiredBefore, staleBefore time.Time) (*IdempotencyRecord, error)
+	// Complete and Release only touch the reservation made with token, not
+	// one that took the key over after it went stale.
+	Complete(key, token string, statusCode int, contentType string, body []byte) error
+	Release(key, token string) error
+}
+
+type ClickRepository interface {
+	AddClicks(clicks []Click) error
+	// GetClickStats aggregates clicks in [from, to). Daily holds only days
+	// with clicks.
+	GetClickStats(invitationUUID string, from, to time.Time) (*ClickStats, error)
+}
+
+type EngagementRepository interface {
+	// AddEngagementEvents stores events, skipping those of unknown
+	// invitations.
+	AddEngagementEvents(events []EngagementEvent) error
+	GetFunnel(invitationUUID string) (*EngagementFunnel, error)
+}
+
+type PricingRepository interface {
+	ListPlans(activeOnly bool) ([]Plan, error)
+	// GetPlan returns ErrUnknownPlan for an unknown code.
+	GetPlan(code string) (*Plan, error)
+	// SavePlan creates or replaces the plan with plan.Code.
+	SavePlan(plan *Plan) error
+	ListPromoCodes() ([]PromoCode, error)
+	// GetPromoCode returns ErrPromoCodeNotFound for an unknown code.
+	GetPromoCode(code string) (*PromoCode, error)
+	// CreatePromoCode returns ErrPromoCodeExists when the code is taken.
+	CreatePromoCode(promo *PromoCode) error
+	DeactivatePromoCode(code string) error
+}
+
+type OrderRepository interface {
+	// CreateOrder stores order and redeems its promo code in one
+	// transaction, failing with ErrPromoCodeUnavailable when the code can
+	// no longer be used. Failed and cancelled orders give the use back.
+	CreateOrder(order *Order) error
+	GetOrder(id string) (*Order, error)
+	ListOrders(invitationUUID string) ([]Order, error)
+	// ApplyPaymentEvent records ev and moves its order to ev.Status in one
+	// transaction. A paid order marks the invitation paid on the order's
+	// plan and extends its hosting by the plan's days. An event
+	// seen before changes nothing and is reported with applied false.
+	ApplyPaymentEvent(ev *PaymentEvent) (order *Order, applied bool, err error)
+}
+
+// NotificationRepository keeps the send log of the notifications.
+type NotificationRepository interface {
+	// CreateNotification stores n as queued together with the queue job
+	// that sends it, job.Payload carrying the notification id.
+	CreateNotification(n *Notification, job QueueJob) error
+	// GetNotification returns ErrNotificationNotFound for an unknown id.
+	GetNotification(id int64) (*Notification, error)
+	// RecordNotificationAttempt counts an attempt of n with its outcome:
+	// n.Error, or n.ProviderID once n.Status is NotificationSent.
+	RecordNotificationAttempt(n *Notification) error
+	// ListNotifications returns the send log of an invitation, newest
+	// first.
+	ListNotifications(invitationUUID string) ([]Notification, error)
+}
+
+// ConversationRepository keeps the state of the onboarding bot per phone.
+type ConversationRepository interface {
+	// GetConversation returns ErrConversationNotFound for a phone the bot
+	// hasn't talked to.
+	GetConversation(phone string) (*Conversation, error)
+	// SaveConversation stores c if its Version is still the stored one, 0
+	// for a new conversation, and bumps it; otherwise it fails with
+	// ErrConversationConflict.
+	SaveConversation(c *Conversation) error
+	// ListConversations returns the latest conversations, most recently
+	// active first.
+	ListConversations(limit int) ([]Conversation, error)
+	// DeleteConversation returns ErrConversationNotFound for an unknown
+	// phone.
+	DeleteConversation(phone string) error
+}
+
+// ReminderRepository keeps the reminder policies of invitations, the
+// reminders sent and the opt-outs.
+type ReminderRepository interface {
+	ListReminderPolicies(invitationUUID string) ([]ReminderPolicy, error)
+	CreateReminderPolicy(p *ReminderPolicy) error
+	// UpdateReminderPolicy and DeleteReminderPolicy return
+	// ErrReminderPolicyNotFound when the invitation has no policy p.ID.
+	UpdateReminderPolicy(p *ReminderPolicy) error
+	DeleteReminderPolicy(invitationUUID string, id int64) error
+	// ActiveReminderPolicies returns the enabled policies of the
+	// invitations that are online.
+	ActiveReminderPolicies() ([]ReminderPolicy, error)
+	// RemindedPhones returns the phone numbers policyID was sent to.
+	RemindedPhones(policyID int64) (map[string]bool, error)
+	// CreateReminder records d and queues its message n with job, like
+	// NotificationRepository.CreateNotification, in one transaction. It
+	// returns false, creating nothing, when d.Phone already got the
+	// reminder of d.PolicyID.
+	CreateReminder(d *ReminderDelivery, n *Notification, job QueueJob) (bool, error)
+	// GetReminderByToken returns ErrReminderTokenNotFound for an unknown
+	// token.
+	GetReminderByToken(token string) (*ReminderDelivery, error)
+	// OptedOutPhones returns which of phones opted out.
+	OptedOutPhones(phones []string) (map[string]bool, error)
+	ListReminderOptOuts() ([]ReminderOptOut, error)
+	// AddReminderOptOut does nothing for a phone that already opted out.
+	AddReminderOptOut(phone string) error
+	RemoveReminderOptOut(phone string) error
 }
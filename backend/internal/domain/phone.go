@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidPhone = errors.New("invalid_phone")
+
+// NormalizePhone brings a phone number to E.164 (+77011234567). Numbers are
+// written in many ways in Kazakhstan: "8 701 123 45 67", "+7 (701) 123-45-67",
+// "7011234567"; all of them map to the same +7 number. Other countries must
+// be given with a leading "+".
+func NormalizePhone(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	plus := strings.HasPrefix(s, "+")
+
+	var digits strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case strings.ContainsRune(" -().+\u00a0", r):
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	d := digits.String()
+
+	switch {
+	case plus && len(d) >= 8 && len(d) <= 15 && d[0] != '0':
+		return "+" + d, nil
+	case len(d) == 11 && (d[0] == '8' || d[0] == '7'):
+		return "+7" + d[1:], nil
+	case len(d) == 10 && d[0] == '7':
+		return "+7" + d, nil
+	}
+	return "", ErrInvalidPhone
+}
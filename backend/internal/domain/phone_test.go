@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	for raw, want := range map[string]string{
+		"8 701 123 45 67":    "+77011234567",
+		"+7 (701) 123-45-67": "+77011234567",
+		"87011234567":        "+77011234567",
+		"77011234567":        "+77011234567",
+		"7011234567":         "+77011234567",
+		"+998 90 123 45 67":  "+998901234567",
+	} {
+		got, err := NormalizePhone(raw)
+		assert.NoError(t, err, raw)
+		assert.Equal(t, want, got, raw)
+	}
+
+	for _, raw := range []string{"", "12345", "8 701 123", "call me", "+0 123 456 789", "901234567"} {
+		_, err := NormalizePhone(raw)
+		assert.ErrorIs(t, err, ErrInvalidPhone, raw)
+	}
+}
@@ -0,0 +1,107 @@
+package i18n
+
+import (
+	"fmt"
+	"time"
+)
+
+// Supported invitation languages. Anything else falls back to DefaultLang.
+const (
+	LangRu = "ru"
+	LangKk = "kk"
+	LangEn = "en"
+
+	DefaultLang = LangRu
+)
+
+// Normalize maps an arbitrary lang value to one of the supported languages.
+func Normalize(lang string) string {
+	switch lang {
+	case LangRu, LangKk, LangEn:
+		return lang
+	}
+	return DefaultLang
+}
+
+// OGLocale returns the Open Graph locale for a language.
+func OGLocale(lang string) string {
+	switch Normalize(lang) {
+	case LangKk:
+		return "kk_KZ"
+	case LangEn:
+		return "en_US"
+	}
+	return "ru_RU"
+}
+
+var monthsGenitive = map[string][12]string{
+	LangRu: {"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"},
+	LangKk: {"қаңтар", "ақпан", "наурыз", "сәуір", "мамыр", "маусым", "шілде", "тамыз", "қыркүйек", "қазан", "қараша", "желтоқсан"},
+	LangEn: {"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+}
+
+// FormatDate renders a date the way it is written in invitations:
+// "15 июля 2026", "2026 жылғы 15 шілде", "July 15, 2026".
+func FormatDate(t time.Time, lang string) string {
+	lang = Normalize(lang)
+	month := monthsGenitive[lang][t.Month()-1]
+	switch lang {
+	case LangKk:
+		return fmt.Sprintf("%d жылғы %d %s", t.Year(), t.Day(), month)
+	case LangEn:
+		return fmt.Sprintf("%s %d, %d", month, t.Day(), t.Year())
+	}
+	return fmt.Sprintf("%d %s %d", t.Day(), month, t.Year())
+}
+
+var weekdays = map[string][7]string{
+	LangRu: {"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+	LangKk: {"жексенбі", "дүйсенбі", "сейсенбі", "сәрсенбі", "бейсенбі", "жұма", "сенбі"},
+	LangEn: {"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+}
+
+// FormatWeekday renders the day of the week: "пятница", "жұма", "Friday".
+func FormatWeekday(t time.Time, lang string) string {
+	return weekdays[Normalize(lang)][t.Weekday()]
+}
+
+// FormatTime renders the time of day, or "" when the event has no time set.
+func FormatTime(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return ""
+	}
+	return t.Format("15:04")
+}
+
+// Lookup returns the message for key in lang without any fallback.
+func Lookup(lang, key string) (string, bool) {
+	msg, ok := messages[Normalize(lang)][key]
+	return msg, ok
+}
+
+// T returns the message for key in lang, falling back to DefaultLang and
+// finally to the key itself. Extra args are applied with fmt.Sprintf.
+func T(lang, key string, args ...interface{}) string {
+	msg, ok := messages[Normalize(lang)][key]
+	if !ok {
+		msg, ok = messages[DefaultLang][key]
+	}
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// CoupleNames joins the groom and bride names with the language's conjunction.
+func CoupleNames(groom, bride, lang string) string {
+	switch {
+	case groom == "":
+		return bride
+	case bride == "":
+		return groom
+	}
+	return groom + " " + T(lang, "and") + " " + bride
+}
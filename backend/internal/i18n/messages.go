@@ -0,0 +1,417 @@
+package i18n
+
+// messages holds the server-rendered texts. Invitation page texts mirror
+// frontend/src/locales so SSR output reads the same as the SPA.
+var messages = map[string]map[string]string{
+	LangRu: {
+		"site_title":           "Приглашение на свадьбу | Wedding Invitation",
+		"site_description":     "Элегантные цифровые свадебные приглашения. Создайте свое уникальное приглашение и отслеживайте ответы гостей онлайн.",
+		"invitation_title":     "%s | Приглашение на свадьбу",
+		"invitation_desc":      "%s приглашают вас на свадьбу.",
+		"invitation_desc_at":   "%s приглашают вас на свадьбу %s.",
+		"and":                  "и",
+		"card_label":           "Приглашение на свадьбу",
+		"invite_text":          "Приглашают вас разделить с ними радость",
+		"invite_text_silk":     "Приглашают вас на торжество",
+		"story_title":          "Наша история",
+		"details_title":        "Детали торжества",
+		"details_title_silk":   "Программа праздника",
+		"location_title":       "Место встречи",
+		"location_title_silk":  "Место встречи",
+		"date_label":           "Дата и Время",
+		"location_label":       "Место проведения",
+		"address_label":        "Адрес",
+		"dress_code_title":     "Дресс-код",
+		"dress_code_text":      "Вечерний стиль",
+		"rsvp_title":           "Подтверждение присутствия",
+		"rsvp_text":            "Будем рады видеть вас на нашем празднике!",
+		"rsvp_title_silk":      "Подтверждение",
+		"rsvp_text_silk":       "Пожалуйста, подтвердите ваше участие до 1 августа",
+		"name_label":           "Ваше Имя",
+		"name_placeholder":     "Ваше Имя и Фамилия",
+		"attendance_label":     "Ваше присутствие",
+		"attending_yes":        "С радостью приду!",
+		"attending_no":         "К сожалению, не смогу",
+		"attending_yes_silk":   "Приду с удовольствием",
+		"attending_no_silk":    "Не смогу присутствовать",
+		"guest_count_label":    "Количество гостей",
+		"phone_label":          "Телефон для напоминаний (необязательно)",
+		"submit_btn":           "Отправить ответ",
+		"success_title":        "Спасибо!",
+		"success_text":         "Ваш ответ получен.",
+		"success_title_silk":   "Благодарим за ответ!",
+		"success_text_silk":    "Мы будем очень рады вас видеть.",
+		"rsvp_error":           "Ошибка при отправке RSVP. Пожалуйста, попробуйте позже.",
+		"map_link":             "Посмотреть на карте",
+		"default_story":        "Наша история любви началась с простого взгляда, но переросла в нечто большее. Мы прошли долгий путь вместе и теперь готовы создать нашу семью.",
+		"footer_quote":         "Любовь — это когда счастье другого человека важнее собственного",
+		"footer_copyright":     "2026 — НАВСЕГДА",
+		"schedule_0_time":      "16:00",
+		"schedule_0_name":      "Добро пожаловать",
+		"schedule_0_desc":      "Сбор гостей и легкий фуршет в саду",
+		"schedule_1_time":      "17:00",
+		"schedule_1_name":      "Церемония",
+		"schedule_1_desc":      "Торжественная регистрация брака",
+		"schedule_2_time":      "18:00",
+		"schedule_2_name":      "Ужин",
+		"schedule_2_desc":      "Праздничный банкет и танцы",
+		"rsvp_invalid":         "Пожалуйста, укажите имя и ответ.",
+		"rsvp_invalid_phone":   "Проверьте номер телефона или оставьте поле пустым.",
+		"expired_text":         "Срок действия ссылки истек. Пожалуйста, свяжитесь с отправителем.",
+		"not_found_text":       "Приглашение не найдено.",
+		"home_link":            "На главную",
+		"guest_list_title":     "Список гостей",
+		"guest_list_attending": "Придут",
+		"guest_list_declined":  "Не придут",
+		"guest_list_name":      "Имя",
+		"guest_list_guests":    "Гостей",
+		"guest_list_responded": "Дата ответа",
+		"guest_list_total":     "Всего гостей: %d",
+		"guest_list_responses": "Ответов: %d, придут: %d, не придут: %d",
+		"guest_list_empty":     "Пока нет ответов",
+		"page_of":              "Стр. %d из %d",
+		"card_rsvp_link":       "Подтвердите присутствие:",
+		"guest_list_response":  "Ответ",
+		"export_created":       "Создано",
+		"export_short_code":    "Код",
+		"export_phone":         "Телефон",
+		"export_couple":        "Пара",
+		"export_event_date":    "Дата события",
+		"export_template":      "Шаблон",
+		"export_lang":          "Язык",
+		"export_paid":          "Оплачено",
+		"export_expires":       "Истекает",
+		"export_rsvps":         "Ответов",
+		"export_guests":        "Гостей",
+		"export_yes":           "Да",
+		"export_no":            "Нет",
+
+		// WhatsApp onboarding bot, see usecase.OnboardingUseCase.
+		"bot_greeting":         "Здравствуйте! 👋 Я помогу оформить свадебное приглашение — задам 10 коротких вопросов.\nЧтобы вернуться к предыдущему вопросу, напишите «назад», чтобы начать сначала — «заново».",
+		"bot_restart":          "Начнем сначала.",
+		"bot_q_template":       "1/10. Выберите дизайн — напишите его номер или название:\n%s",
+		"bot_q_lang":           "2/10. На каком языке будет приглашение: русский, казахский или английский?",
+		"bot_q_names":          "3/10. Имена жениха и невесты так, как они должны быть в приглашении, например: Арман и Айгерим.",
+		"bot_q_date":           "4/10. Дата свадьбы (ДД.ММ.ГГГГ), например: 15.08.2026.",
+		"bot_q_time":           "5/10. Время сбора гостей (ЧЧ:ММ), например: 18:00.",
+		"bot_q_venue":          "6/10. Название ресторана или места.",
+		"bot_q_address":        "7/10. Адрес текстом.",
+		"bot_q_map_url":        "8/10. Ссылка на 2GIS или Google Maps. Если ссылки нет, напишите «нет».",
+		"bot_q_dress_code":     "9/10. Дресс-код. Если его нет, напишите «нет».",
+		"bot_q_photo":          "10/10. Пришлите ваше фото для приглашения. Если фото не нужно, напишите «нет».",
+		"bot_err_template":     "Такого дизайна нет в списке. Напишите номер или название из списка.",
+		"bot_err_lang":         "Выберите один из языков: русский, казахский или английский.",
+		"bot_err_names":        "Напишите два имени через «и», например: Арман и Айгерим.",
+		"bot_err_date":         "Не получилось прочитать дату. Напишите ее в формате ДД.ММ.ГГГГ, например: 15.08.2026.",
+		"bot_err_date_range":   "Дата должна быть не раньше сегодняшней и не позже чем через 3 года. Проверьте, пожалуйста.",
+		"bot_err_time":         "Не получилось прочитать время. Напишите его в формате ЧЧ:ММ, например: 18:00.",
+		"bot_err_text":         "Пожалуйста, ответьте текстом.",
+		"bot_err_long":         "Слишком длинный ответ — не больше %d символов, пожалуйста.",
+		"bot_err_link":         "Это не похоже на ссылку. Пришлите ссылку на 2GIS или Google Maps или напишите «нет».",
+		"bot_err_photo":        "Пришлите фото или напишите «нет».",
+		"bot_summary":          "Проверьте, пожалуйста, данные:\n\n%s\n\nЕсли всё верно, ответьте «да». Чтобы исправить пункт, напишите его номер.",
+		"bot_confirm_hint":     "Ответьте «да», чтобы создать приглашение, или напишите номер пункта, который нужно исправить.",
+		"bot_label_template":   "Дизайн",
+		"bot_label_lang":       "Язык",
+		"bot_label_names":      "Имена",
+		"bot_label_date":       "Дата",
+		"bot_label_time":       "Время",
+		"bot_label_venue":      "Место",
+		"bot_label_address":    "Адрес",
+		"bot_label_map_url":    "Карта",
+		"bot_label_dress_code": "Дресс-код",
+		"bot_label_photo":      "Фото",
+		"bot_none":             "—",
+		"bot_photo_attached":   "прикреплено",
+		"bot_lang_ru":          "русский",
+		"bot_lang_kk":          "казахский",
+		"bot_lang_en":          "английский",
+		"bot_ready":            "Ваше приглашение готово! 🥳💍\n\n🔗 %s\n\nПроверьте, пожалуйста, все данные по ссылке. Если нужно что-то поправить — напишите нам.",
+		"bot_done":             "Ваше приглашение: %s\nЧтобы оформить еще одно, напишите «заново».",
+		"bot_failed":           "Не получилось создать приглашение, мы уже разбираемся. Попробуйте ответить «да» чуть позже.",
+
+		// Guest reminders, see usecase.ReminderUseCase.
+		"reminder_today":        "сегодня",
+		"reminder_tomorrow":     "завтра",
+		"reminder_on_date":      "%s",
+		"reminder_stop_title":   "Напоминания о свадьбе",
+		"reminder_stop_text":    "Больше не присылать напоминания на номер %s?",
+		"reminder_stop_button":  "Отписаться",
+		"reminder_stop_done":    "Готово: напоминания на номер %s больше не придут.",
+		"reminder_stop_invalid": "Ссылка недействительна.",
+	},
+	LangKk: {
+		"site_title":           "Үйлену тойына шақыру | Wedding Invitation",
+		"site_description":     "Талғампаз цифрлық үйлену тойы шақырулары. Өз шақыруыңызды жасап, қонақтардың жауаптарын онлайн бақылаңыз.",
+		"invitation_title":     "%s | Үйлену тойына шақыру",
+		"invitation_desc":      "%s сізді үйлену тойына шақырады.",
+		"invitation_desc_at":   "%s сізді үйлену тойына шақырады: %s.",
+		"and":                  "мен",
+		"card_label":           "Үйлену тойына шақыру",
+		"invite_text":          "Сіздерді қуанышымызбен бөлісуге шақырамыз",
+		"invite_text_silk":     "Сіздерді салтанатымызға шақырамыз",
+		"story_title":          "Біздің тарихымыз",
+		"details_title":        "Той егжей-тегжейі",
+		"details_title_silk":   "Той бағдарламасы",
+		"location_title":       "Кездесу орны",
+		"location_title_silk":  "Кездесу орны",
+		"date_label":           "Күні мен уақыты",
+		"location_label":       "Өтетін орны",
+		"address_label":        "Мекен-жайы",
+		"dress_code_title":     "Дресс-код",
+		"dress_code_text":      "Кешкі стиль",
+		"rsvp_title":           "Қатысуды растау",
+		"rsvp_text":            "Сіздерді тойымызда көруге қуаныштымыз!",
+		"rsvp_title_silk":      "Қатысуды растау",
+		"rsvp_text_silk":       "Тойға келетініңізді растауыңызды сұраймыз",
+		"name_label":           "Сіздің атыңыз",
+		"name_placeholder":     "Аты-жөніңіз",
+		"attendance_label":     "Келуіңіз",
+		"attending_yes":        "Қуана келемін!",
+		"attending_no":         "Өкінішке орай, келе алмаймын",
+		"attending_yes_silk":   "Келемін, қуаныштымын",
+		"attending_no_silk":    "Өкінішке орай, келе алмаймын",
+		"guest_count_label":    "Қонақтар саны",
+		"phone_label":          "Еске салу үшін телефон (міндетті емес)",
+		"submit_btn":           "Жауапты жіберу",
+		"success_title":        "Рахмет!",
+		"success_text":         "Жауабыңыз қабылданды.",
+		"success_title_silk":   "Жауабыңызға рахмет!",
+		"success_text_silk":    "Сізді көруге өте қуанышты боламыз.",
+		"rsvp_error":           "RSVP жіберу кезінде қате кетті. Кейінірек қайталап көріңіз.",
+		"map_link":             "Картадан көру",
+		"default_story":        "Біздің махаббат хикаямыз қарапайым көзқарастан басталды, бірақ үлкен сезімге ұласты. Біз бірге ұзақ жолдан өттік және енді өз отбасымызды құруға дайынбыз.",
+		"footer_quote":         "Махаббат — бұл басқа адамның бақыты өзіңдікінен маңыздырақ болған кезде",
+		"footer_copyright":     "2026 — МӘҢГІЛІК",
+		"schedule_0_time":      "16:00",
+		"schedule_0_name":      "Қош келдіңіздер",
+		"schedule_0_desc":      "Қонақтардың жиналуы және бақшадағы жеңіл фуршет",
+		"schedule_1_time":      "17:00",
+		"schedule_1_name":      "Рәсім",
+		"schedule_1_desc":      "Неке қию салтанаты",
+		"schedule_2_time":      "18:00",
+		"schedule_2_name":      "Кешкі ас",
+		"schedule_2_desc":      "Мерекелік банкет және би",
+		"rsvp_invalid":         "Атыңыз бен жауабыңызды көрсетіңіз.",
+		"rsvp_invalid_phone":   "Телефон нөмірін тексеріңіз немесе бос қалдырыңыз.",
+		"expired_text":         "Сілтеменің мерзімі аяқталды. Жіберушімен хабарласыңыз.",
+		"not_found_text":       "Шақыру табылмады.",
+		"home_link":            "Басты бетке",
+		"guest_list_title":     "Қонақтар тізімі",
+		"guest_list_attending": "Келеді",
+		"guest_list_declined":  "Келмейді",
+		"guest_list_name":      "Аты-жөні",
+		"guest_list_guests":    "Қонақтар",
+		"guest_list_responded": "Жауап күні",
+		"guest_list_total":     "Барлық қонақ: %d",
+		"guest_list_responses": "Жауаптар: %d, келеді: %d, келмейді: %d",
+		"guest_list_empty":     "Әзірге жауап жоқ",
+		"page_of":              "%d / %d бет",
+		"card_rsvp_link":       "Қатысуыңызды растаңыз:",
+		"guest_list_response":  "Жауап",
+		"export_created":       "Құрылған",
+		"export_short_code":    "Код",
+		"export_phone":         "Телефон",
+		"export_couple":        "Жұп",
+		"export_event_date":    "Той күні",
+		"export_template":      "Үлгі",
+		"export_lang":          "Тіл",
+		"export_paid":          "Төленген",
+		"export_expires":       "Мерзімі",
+		"export_rsvps":         "Жауаптар",
+		"export_guests":        "Қонақтар",
+		"export_yes":           "Иә",
+		"export_no":            "Жоқ",
+
+		// WhatsApp onboarding bot, see usecase.OnboardingUseCase.
+		"bot_greeting":         "Сәлеметсіз бе! 👋 Үйлену тойына шақыру жасауға көмектесемін — 10 қысқа сұрақ қоямын.\nАлдыңғы сұраққа оралу үшін «артқа», басынан бастау үшін «басынан» деп жазыңыз.",
+		"bot_restart":          "Басынан бастайық.",
+		"bot_q_template":       "1/10. Дизайнды таңдаңыз — нөмірін немесе атауын жазыңыз:\n%s",
+		"bot_q_lang":           "2/10. Шақыру қай тілде болады: қазақ, орыс немесе ағылшын?",
+		"bot_q_names":          "3/10. Күйеу жігіт пен қалыңдықтың есімдерін шақыруда қалай жазылса, солай жазыңыз, мысалы: Арман мен Айгерім.",
+		"bot_q_date":           "4/10. Той күні (КК.АА.ЖЖЖЖ), мысалы: 15.08.2026.",
+		"bot_q_time":           "5/10. Қонақтардың жиналу уақыты (СС:ММ), мысалы: 18:00.",
+		"bot_q_venue":          "6/10. Мейрамхананың немесе орынның атауы.",
+		"bot_q_address":        "7/10. Мекенжайы (мәтінмен).",
+		"bot_q_map_url":        "8/10. 2GIS немесе Google Maps сілтемесі. Сілтеме болмаса, «жоқ» деп жазыңыз.",
+		"bot_q_dress_code":     "9/10. Дресс-код. Болмаса, «жоқ» деп жазыңыз.",
+		"bot_q_photo":          "10/10. Шақыруға арналған суретіңізді жіберіңіз. Сурет керек болмаса, «жоқ» деп жазыңыз.",
+		"bot_err_template":     "Мұндай дизайн тізімде жоқ. Тізімнен нөмірін немесе атауын жазыңыз.",
+		"bot_err_lang":         "Тілдердің бірін таңдаңыз: қазақ, орыс немесе ағылшын.",
+		"bot_err_names":        "Екі есімді «мен» арқылы жазыңыз, мысалы: Арман мен Айгерім.",
+		"bot_err_date":         "Күнді оқи алмадым. КК.АА.ЖЖЖЖ форматында жазыңыз, мысалы: 15.08.2026.",
+		"bot_err_date_range":   "Той күні бүгіннен ерте және 3 жылдан кейін болмауы керек. Тексеріп көріңізші.",
+		"bot_err_time":         "Уақытты оқи алмадым. СС:ММ форматында жазыңыз, мысалы: 18:00.",
+		"bot_err_text":         "Мәтінмен жауап беріңізші.",
+		"bot_err_long":         "Жауап тым ұзын — %d таңбадан аспауы керек.",
+		"bot_err_link":         "Бұл сілтемеге ұқсамайды. 2GIS немесе Google Maps сілтемесін жіберіңіз немесе «жоқ» деп жазыңыз.",
+		"bot_err_photo":        "Сурет жіберіңіз немесе «жоқ» деп жазыңыз.",
+		"bot_summary":          "Деректерді тексеріңізші:\n\n%s\n\nБәрі дұрыс болса, «иә» деп жауап беріңіз. Тармақты түзету үшін оның нөмірін жазыңыз.",
+		"bot_confirm_hint":     "Шақыруды жасау үшін «иә» деп, түзету үшін тармақтың нөмірін жазыңыз.",
+		"bot_label_template":   "Дизайн",
+		"bot_label_lang":       "Тілі",
+		"bot_label_names":      "Есімдер",
+		"bot_label_date":       "Күні",
+		"bot_label_time":       "Уақыты",
+		"bot_label_venue":      "Орны",
+		"bot_label_address":    "Мекенжайы",
+		"bot_label_map_url":    "Карта",
+		"bot_label_dress_code": "Дресс-код",
+		"bot_label_photo":      "Сурет",
+		"bot_none":             "—",
+		"bot_photo_attached":   "тіркелді",
+		"bot_lang_ru":          "орыс",
+		"bot_lang_kk":          "қазақ",
+		"bot_lang_en":          "ағылшын",
+		"bot_ready":            "Шақыруыңыз дайын! 🥳💍\n\n🔗 %s\n\nСілтеме арқылы барлық деректерді тексеріңіз. Бірдеңені түзету керек болса, бізге жазыңыз.",
+		"bot_done":             "Сіздің шақыруыңыз: %s\nТағы біреуін жасау үшін «басынан» деп жазыңыз.",
+		"bot_failed":           "Шақыруды жасау мүмкін болмады, біз қазір тексеріп жатырмыз. Сәл кейінірек қайтадан «иә» деп жауап беріңіз.",
+
+		// Guest reminders, see usecase.ReminderUseCase.
+		"reminder_today":        "бүгін",
+		"reminder_tomorrow":     "ертең",
+		"reminder_on_date":      "%s",
+		"reminder_stop_title":   "Той туралы еске салулар",
+		"reminder_stop_text":    "%s нөміріне еске салулар енді жіберілмесін бе?",
+		"reminder_stop_button":  "Бас тарту",
+		"reminder_stop_done":    "Дайын: %s нөміріне еске салулар енді келмейді.",
+		"reminder_stop_invalid": "Сілтеме жарамсыз.",
+	},
+	LangEn: {
+		"site_title":           "Wedding Invitation",
+		"site_description":     "Elegant digital wedding invitations. Create your own invitation and track guest responses online.",
+		"invitation_title":     "%s | Wedding Invitation",
+		"invitation_desc":      "%s invite you to their wedding.",
+		"invitation_desc_at":   "%s invite you to their wedding on %s.",
+		"and":                  "&",
+		"card_label":           "Wedding Invitation",
+		"invite_text":          "Invite you to share their joy",
+		"invite_text_silk":     "Invite you to the celebration",
+		"story_title":          "Our Story",
+		"details_title":        "Wedding Details",
+		"details_title_silk":   "Wedding Schedule",
+		"location_title":       "Location",
+		"location_title_silk":  "Location",
+		"date_label":           "Date & Time",
+		"location_label":       "Venue",
+		"address_label":        "Address",
+		"dress_code_title":     "Dress Code",
+		"dress_code_text":      "Evening Attire",
+		"rsvp_title":           "RSVP",
+		"rsvp_text":            "We would be delighted to see you at our celebration!",
+		"rsvp_title_silk":      "Confirmation",
+		"rsvp_text_silk":       "Please confirm your attendance",
+		"name_label":           "Your Name",
+		"name_placeholder":     "Your Full Name",
+		"attendance_label":     "Your Attendance",
+		"attending_yes":        "Joyfully accept!",
+		"attending_no":         "Regretfully decline",
+		"attending_yes_silk":   "Will definitely attend",
+		"attending_no_silk":    "Unable to attend",
+		"guest_count_label":    "Number of guests",
+		"phone_label":          "Phone for reminders (optional)",
+		"submit_btn":           "Send RSVP",
+		"success_title":        "Thank you!",
+		"success_text":         "Your response has been received.",
+		"success_title_silk":   "Thank you for the answer!",
+		"success_text_silk":    "We would be very happy to see you.",
+		"rsvp_error":           "Error submitting RSVP. Please try again later.",
+		"map_link":             "View on map",
+		"default_story":        "Our love story began with a simple glance, but grew into something more. We have come a long way together and are now ready to create our family.",
+		"footer_quote":         "Love is when the happiness of another person is more important than your own",
+		"footer_copyright":     "2026 — FOREVER",
+		"schedule_0_time":      "16:00",
+		"schedule_0_name":      "Welcome",
+		"schedule_0_desc":      "Guest arrival and light reception in the garden",
+		"schedule_1_time":      "17:00",
+		"schedule_1_name":      "Ceremony",
+		"schedule_1_desc":      "Solemn marriage registration",
+		"schedule_2_time":      "18:00",
+		"schedule_2_name":      "Dinner",
+		"schedule_2_desc":      "Festive banquet and dancing",
+		"rsvp_invalid":         "Please enter your name and response.",
+		"rsvp_invalid_phone":   "Please check the phone number or leave it empty.",
+		"expired_text":         "This link has expired. Please contact the sender.",
+		"not_found_text":       "Invitation not found.",
+		"home_link":            "Home",
+		"guest_list_title":     "Guest list",
+		"guest_list_attending": "Attending",
+		"guest_list_declined":  "Not attending",
+		"guest_list_name":      "Name",
+		"guest_list_guests":    "Guests",
+		"guest_list_responded": "Responded",
+		"guest_list_total":     "Total guests: %d",
+		"guest_list_responses": "Responses: %d, attending: %d, not attending: %d",
+		"guest_list_empty":     "No responses yet",
+		"page_of":              "Page %d of %d",
+		"card_rsvp_link":       "Please RSVP:",
+		"guest_list_response":  "Response",
+		"export_created":       "Created",
+		"export_short_code":    "Code",
+		"export_phone":         "Phone",
+		"export_couple":        "Couple",
+		"export_event_date":    "Event date",
+		"export_template":      "Template",
+		"export_lang":          "Language",
+		"export_paid":          "Paid",
+		"export_expires":       "Expires",
+		"export_rsvps":         "Responses",
+		"export_guests":        "Guests",
+		"export_yes":           "Yes",
+		"export_no":            "No",
+
+		// WhatsApp onboarding bot, see usecase.OnboardingUseCase.
+		"bot_greeting":         "Hello! 👋 I'll help you set up your wedding invitation with 10 short questions.\nWrite \"back\" to return to the previous question or \"restart\" to start over.",
+		"bot_restart":          "Let's start over.",
+		"bot_q_template":       "1/10. Choose a design — send its number or name:\n%s",
+		"bot_q_lang":           "2/10. Which language should the invitation be in: English, Russian or Kazakh?",
+		"bot_q_names":          "3/10. The names of the groom and the bride as they should appear on the invitation, for example: Arman & Aigerim.",
+		"bot_q_date":           "4/10. Wedding date (DD.MM.YYYY), for example: 15.08.2026.",
+		"bot_q_time":           "5/10. Guest arrival time (HH:MM), for example: 18:00.",
+		"bot_q_venue":          "6/10. Restaurant or venue name.",
+		"bot_q_address":        "7/10. Address (as text).",
+		"bot_q_map_url":        "8/10. A 2GIS or Google Maps link. If you don't have one, write \"no\".",
+		"bot_q_dress_code":     "9/10. Dress code. If there is none, write \"no\".",
+		"bot_q_photo":          "10/10. Send your photo for the invitation. If you don't need one, write \"no\".",
+		"bot_err_template":     "That design isn't on the list. Send its number or name from the list.",
+		"bot_err_lang":         "Choose one of the languages: English, Russian or Kazakh.",
+		"bot_err_names":        "Write both names joined with \"&\", for example: Arman & Aigerim.",
+		"bot_err_date":         "I couldn't read the date. Write it as DD.MM.YYYY, for example: 15.08.2026.",
+		"bot_err_date_range":   "The date must be no earlier than today and within 3 years. Please check it.",
+		"bot_err_time":         "I couldn't read the time. Write it as HH:MM, for example: 18:00.",
+		"bot_err_text":         "Please answer with text.",
+		"bot_err_long":         "That answer is too long — at most %d characters, please.",
+		"bot_err_link":         "That doesn't look like a link. Send a 2GIS or Google Maps link or write \"no\".",
+		"bot_err_photo":        "Send a photo or write \"no\".",
+		"bot_summary":          "Please check the details:\n\n%s\n\nIf everything is correct, reply \"yes\". To change an item, send its number.",
+		"bot_confirm_hint":     "Reply \"yes\" to create the invitation or send the number of the item to change.",
+		"bot_label_template":   "Design",
+		"bot_label_lang":       "Language",
+		"bot_label_names":      "Names",
+		"bot_label_date":       "Date",
+		"bot_label_time":       "Time",
+		"bot_label_venue":      "Venue",
+		"bot_label_address":    "Address",
+		"bot_label_map_url":    "Map",
+		"bot_label_dress_code": "Dress code",
+		"bot_label_photo":      "Photo",
+		"bot_none":             "—",
+		"bot_photo_attached":   "attached",
+		"bot_lang_ru":          "Russian",
+		"bot_lang_kk":          "Kazakh",
+		"bot_lang_en":          "English",
+		"bot_ready":            "Your invitation is ready! 🥳💍\n\n🔗 %s\n\nPlease check all the details at the link. If anything needs fixing, just write to us.",
+		"bot_done":             "Your invitation: %s\nTo make another one, write \"restart\".",
+		"bot_failed":           "We couldn't create the invitation and are looking into it. Please reply \"yes\" again a little later.",
+
+		// Guest reminders, see usecase.ReminderUseCase.
+		"reminder_today":        "today",
+		"reminder_tomorrow":     "tomorrow",
+		"reminder_on_date":      "on %s",
+		"reminder_stop_title":   "Wedding reminders",
+		"reminder_stop_text":    "Stop sending reminders to %s?",
+		"reminder_stop_button":  "Unsubscribe",
+		"reminder_stop_done":    "Done: %s will get no more reminders.",
+		"reminder_stop_invalid": "This link is not valid.",
+	},
+}
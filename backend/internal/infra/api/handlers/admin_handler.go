@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
@@ -11,10 +16,12 @@ import (
 type AdminHandler struct {
 	useCase *usecase.AdminUseCase
 	invUC   *usecase.InvitationUseCase
+	// baseURL is the public origin short links are built on.
+	baseURL string
 }
 
-func NewAdminHandler(u *usecase.AdminUseCase, invUC *usecase.InvitationUseCase) *AdminHandler {
-	return &AdminHandler{useCase: u, invUC: invUC}
+func NewAdminHandler(u *usecase.AdminUseCase, invUC *usecase.InvitationUseCase, baseURL string) *AdminHandler {
+	return &AdminHandler{useCase: u, invUC: invUC, baseURL: baseURL}
 }
 
 func (h *AdminHandler) Login(c *gin.Context) {
@@ -51,13 +58,90 @@ func (h *AdminHandler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetAnalytics charts the business for ?from and ?to (YYYY-MM-DD) per
+// ?granularity: day, week or month.
+func (h *AdminHandler) GetAnalytics(c *gin.Context) {
+	a, err := h.useCase.Analytics(c.Query("from"), c.Query("to"), c.Query("granularity"))
+	if err != nil {
+		var input usecase.InputError
+		if errors.As(err, &input) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, a)
+}
+
+// GetInvitationsList returns a page of invitations: the filters of
+// invitationFilter plus ?limit and ?offset.
 func (h *AdminHandler) GetInvitationsList(c *gin.Context) {
-	list, err := h.useCase.GetInvitations()
+	filter, err := invitationFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		if *dst, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s: %q", name, v)})
+			return
+		}
+	}
+	page, err := h.useCase.GetInvitations(filter)
 	if err != nil {
+		var input usecase.InputError
+		if errors.As(err, &input) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, list)
+	c.JSON(http.StatusOK, page)
+}
+
+// invitationFilter reads the admin list filters from the query string:
+// paid, expired (true/false), template, lang, eventFrom and eventTo
+// (YYYY-MM-DD), q to search and sort, a domain.InvitationSort* key with a
+// leading "-" for descending order.
+func invitationFilter(c *gin.Context) (domain.InvitationFilter, error) {
+	filter := domain.InvitationFilter{
+		TemplateCode: c.Query("template"),
+		Lang:         c.Query("lang"),
+		Search:       c.Query("q"),
+	}
+	filter.Sort, filter.Desc = strings.CutPrefix(c.Query("sort"), "-")
+	if filter.Sort != "" && !domain.IsInvitationSort(filter.Sort) {
+		return filter, fmt.Errorf("invalid sort: %q", c.Query("sort"))
+	}
+	for name, dst := range map[string]**bool{"paid": &filter.Paid, "expired": &filter.Expired} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s filter: %q", name, v)
+		}
+		*dst = &b
+	}
+	for name, dst := range map[string]**time.Time{"eventFrom": &filter.EventFrom, "eventTo": &filter.EventTo} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s filter: %q", name, v)
+		}
+		*dst = &t
+	}
+	return filter, nil
 }
 
 func (h *AdminHandler) GetTemplates(c *gin.Context) {
@@ -77,14 +161,90 @@ func (h *AdminHandler) CreateInvitation(c *gin.Context) {
 	}
 
 	if err := h.invUC.CreateInvitation(&inv); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		createError(c, err)
 		return
 	}
-	// Construct full short link
-	// Ideally, base URL is from config, but we can infer or hardcode based on domain.
-	// User domain is card-go.asia.
-	shortLink := "https://card-go.asia/s/" + inv.ShortCode
-	c.JSON(http.StatusCreated, gin.H{"uuid": inv.UUID, "shortCode": inv.ShortCode, "shortLink": shortLink})
+	c.JSON(http.StatusCreated, gin.H{"uuid": inv.UUID, "shortCode": inv.ShortCode, "shortLink": h.shortLink(inv.ShortCode)})
+}
+
+// CreateInvitations creates up to usecase.MaxBatchInvitations invitations.
+// The default "atomic" mode creates all of them or none; "bestEffort"
+// creates whatever is valid. The response lists one result per entry in
+// request order and is 201 when all were created, 207 when only some were
+// and 422 when none were.
+func (h *AdminHandler) CreateInvitations(c *gin.Context) {
+	var req struct {
+		Mode        string               `json:"mode"`
+		Invitations []*domain.Invitation `json:"invitations"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var bestEffort bool
+	switch req.Mode {
+	case "", "atomic":
+	case "bestEffort":
+		bestEffort = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be atomic or bestEffort"})
+		return
+	}
+
+	results, err := h.invUC.CreateInvitations(req.Invitations, bestEffort)
+	if err != nil {
+		createError(c, err)
+		return
+	}
+	created := 0
+	for i := range results {
+		if results[i].Error == "" {
+			results[i].ShortLink = h.shortLink(results[i].ShortCode)
+			created++
+		}
+	}
+	status := http.StatusCreated
+	switch {
+	case created == 0:
+		status = http.StatusUnprocessableEntity
+	case created < len(results):
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{"created": created, "failed": len(results) - created, "results": results})
+}
+
+func (h *AdminHandler) shortLink(code string) string {
+	return h.baseURL + "/s/" + code
+}
+
+func createError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input), errors.Is(err, domain.ErrUnknownPlan):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrShortCodeTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ShortCodeAvailability tells the admin form whether a vanity short link
+// can be used. Invalid and reserved slugs are answered with available false
+// and the reason, so the form can show it next to the field.
+func (h *AdminHandler) ShortCodeAvailability(c *gin.Context) {
+	slug, ok, err := h.invUC.ShortCodeAvailable(c.Param("code"))
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusOK, gin.H{"shortCode": slug, "available": false, "error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	case !ok:
+		c.JSON(http.StatusOK, gin.H{"shortCode": slug, "available": false, "error": domain.ErrShortCodeTaken.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"shortCode": slug, "available": true, "shortLink": h.shortLink(slug)})
+	}
 }
 
 func (h *AdminHandler) MarkAsPaid(c *gin.Context) {
@@ -100,3 +260,144 @@ func (h *AdminHandler) MarkAsPaid(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// ListRSVPs returns the responses of an invitation in the order they came in.
+func (h *AdminHandler) ListRSVPs(c *gin.Context) {
+	inv, err := h.invUC.FindInvitation(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+		return
+	}
+	list, err := h.invUC.ListRSVPs(inv.UUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// UpdateRSVP replaces the name, attendance, guest count and phone of a
+// response.
+func (h *AdminHandler) UpdateRSVP(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrRSVPNotFound.Error()})
+		return
+	}
+	var req struct {
+		GuestName  string `json:"guestName"`
+		Attendance string `json:"attendance"`
+		GuestCount int    `json:"guestCount"`
+		Phone      string `json:"phone"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.invUC.UpdateRSVP(c.Param("uuid"), id, req.GuestName, req.Attendance, req.GuestCount, req.Phone); err != nil {
+		rsvpError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *AdminHandler) DeleteRSVP(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrRSVPNotFound.Error()})
+		return
+	}
+	if err := h.invUC.DeleteRSVP(c.Param("uuid"), id); err != nil {
+		rsvpError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func rsvpError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrRSVPNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ExtendTrial adds days and hours to the trial of an unpaid invitation.
+func (h *AdminHandler) ExtendTrial(c *gin.Context) {
+	var req struct {
+		Days  int `json:"days"`
+		Hours int `json:"hours"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	by := time.Duration(req.Days)*24*time.Hour + time.Duration(req.Hours)*time.Hour
+	inv, err := h.invUC.ExtendTrial(c.Param("uuid"), by)
+	if err != nil {
+		expiryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, inv)
+}
+
+// RenewTrial starts the trial of an unpaid invitation over.
+func (h *AdminHandler) RenewTrial(c *gin.Context) {
+	inv, err := h.invUC.RenewTrial(c.Param("uuid"))
+	if err != nil {
+		expiryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, inv)
+}
+
+// SetHosting sets the end of the hosting of a paid invitation. Keeping it
+// online for good takes an explicit forever, so an empty body cannot.
+func (h *AdminHandler) SetHosting(c *gin.Context) {
+	var req struct {
+		HostingUntil *time.Time `json:"hostingUntil"`
+		Forever      bool       `json:"forever"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.HostingUntil == nil) != req.Forever {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "either hostingUntil or forever is required"})
+		return
+	}
+	inv, err := h.invUC.SetHostingUntil(c.Param("uuid"), req.HostingUntil)
+	if err != nil {
+		expiryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, inv)
+}
+
+// ExpiringInvitations lists the invitations going offline soon.
+func (h *AdminHandler) ExpiringInvitations(c *gin.Context) {
+	list, err := h.useCase.ExpiringInvitations(c.Query("within"))
+	if err != nil {
+		expiryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func expiryError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvitationPaid), errors.Is(err, domain.ErrInvitationNotPaid):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
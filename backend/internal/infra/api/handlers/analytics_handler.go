@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// maxBeaconSize bounds beacon bodies, which hold a single event name.
+const maxBeaconSize = 1 << 10
+
+type AnalyticsHandler struct {
+	clicks     *usecase.ClickUseCase
+	engagement *usecase.EngagementUseCase
+}
+
+func NewAnalyticsHandler(clicks *usecase.ClickUseCase, engagement *usecase.EngagementUseCase) *AnalyticsHandler {
+	return &AnalyticsHandler{clicks: clicks, engagement: engagement}
+}
+
+// Beacon records {"event": "view"} and the other page sections reached. It
+// is called with navigator.sendBeacon, which posts text/plain, so the body
+// is read as JSON whatever its content type.
+func (h *AnalyticsHandler) Beacon(c *gin.Context) {
+	var req struct {
+		Event string `json:"event"`
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBeaconSize))
+	if err != nil || json.Unmarshal(body, &req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid beacon"})
+		return
+	}
+	if err := h.engagement.Record(c.Param("uuid"), req.Event, c.ClientIP(), c.GetHeader("User-Agent")); err != nil {
+		analyticsError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Engagement reports the view-to-RSVP funnel of an invitation.
+func (h *AnalyticsHandler) Engagement(c *gin.Context) {
+	funnel, err := h.engagement.Funnel(c.Param("uuid"))
+	if err != nil {
+		analyticsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, funnel)
+}
+
+// ClickStats reports short link clicks of an invitation for ?from and ?to
+// (YYYY-MM-DD, inclusive, UTC), the last 30 days by default.
+func (h *AnalyticsHandler) ClickStats(c *gin.Context) {
+	stats, err := h.clicks.Stats(c.Param("uuid"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		analyticsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+func analyticsError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/card"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/export"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/web"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// ExportHandler serves downloadable copies of invitations to admins.
+type ExportHandler struct {
+	invUC   *usecase.InvitationUseCase
+	adminUC *usecase.AdminUseCase
+	pages   *web.PageRenderer
+	cards   *card.Renderer
+	// baseURL is the public origin, for links printed on cards.
+	baseURL string
+}
+
+func NewExportHandler(invUC *usecase.InvitationUseCase, adminUC *usecase.AdminUseCase, pages *web.PageRenderer, cards *card.Renderer, baseURL string) *ExportHandler {
+	return &ExportHandler{invUC: invUC, adminUC: adminUC, pages: pages, cards: cards, baseURL: baseURL}
+}
+
+// StaticSite streams the offline ZIP of an invitation. Expired trials can be
+// exported too: this is the keepsake after hosting ends.
+func (h *ExportHandler) StaticSite(c *gin.Context) {
+	inv, err := h.invUC.FindInvitation(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+export.FileName(inv, ".zip")+`"`)
+	c.Status(http.StatusOK)
+	if err := export.StaticSite(c.Writer, inv, h.pages, h.cards); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// GuestListPDF serves the printable guest list. The report follows the
+// invitation language unless ?lang= asks for another one.
+func (h *ExportHandler) GuestListPDF(c *gin.Context) {
+	inv, err := h.invUC.FindInvitation(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	rsvps, err := h.invUC.ListRSVPs(inv.UUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	lang := c.DefaultQuery("lang", inv.Lang)
+	var buf bytes.Buffer
+	if err := export.GuestListPDF(&buf, inv, rsvps, lang); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.servePDF(c, export.FileName(inv, "-guests.pdf"), buf.Bytes())
+}
+
+// CardPDF serves the printable A5 invitation card.
+func (h *ExportHandler) CardPDF(c *gin.Context) {
+	inv, err := h.invUC.FindInvitation(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	link := ""
+	if inv.ShortCode != "" {
+		link = h.baseURL + "/s/" + inv.ShortCode
+	}
+	var buf bytes.Buffer
+	if err := export.CardPDF(&buf, inv, link); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.servePDF(c, export.FileName(inv, "-card.pdf"), buf.Bytes())
+}
+
+func (h *ExportHandler) servePDF(c *gin.Context, name string, data []byte) {
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+// RSVPs streams all responses of an invitation as CSV or XLSX
+// (?format=csv|xlsx). Headers follow the invitation language unless ?lang=
+// asks for another one.
+func (h *ExportHandler) RSVPs(c *gin.Context) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	inv, err := h.invUC.FindInvitation(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	rsvps, err := h.invUC.ListRSVPs(inv.UUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	lang := i18n.Normalize(c.DefaultQuery("lang", inv.Lang))
+	h.serveTable(c, format, export.FileName(inv, "-rsvps."+string(format)), i18n.T(lang, "guest_list_title"), export.RSVPColumnWidths,
+		func(t export.TableWriter) error {
+			return export.RSVPTable(t, rsvps, lang)
+		})
+}
+
+// Invitations streams the admin invitation list with payment status. It
+// takes the same filters as GET /admin/invitations plus ?format= and
+// ?headerLang= for the column titles (Russian by default).
+func (h *ExportHandler) Invitations(c *gin.Context) {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter, err := invitationFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// lang is already taken by the list filter.
+	lang := i18n.Normalize(c.Query("headerLang"))
+
+	name := "invitations-" + time.Now().Format("20060102") + "." + string(format)
+	h.serveTable(c, format, name, "Invitations", export.InvitationColumnWidths,
+		func(t export.TableWriter) error {
+			return export.InvitationsTable(t, lang, func(fn func(*domain.InvitationWithStats) error) error {
+				return h.adminUC.EachInvitation(filter, fn)
+			})
+		})
+}
+
+// serveTable streams a table to the response. Errors after the first byte
+// can only be logged: the status line has already been sent.
+func (h *ExportHandler) serveTable(c *gin.Context, format export.Format, name, sheet string, widths []float64, write func(export.TableWriter) error) {
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Status(http.StatusOK)
+
+	t, err := export.NewTableWriter(c.Writer, format, sheet, widths...)
+	if err == nil {
+		err = write(t)
+	}
+	if err != nil {
+		_ = c.Error(err)
+	}
+}
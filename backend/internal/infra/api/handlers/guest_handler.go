@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/sheet"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// maxImportSize bounds roster uploads; 300 relatives fit in a few KB.
+const maxImportSize = 5 << 20
+
+type GuestHandler struct {
+	useCase *usecase.GuestUseCase
+}
+
+func NewGuestHandler(u *usecase.GuestUseCase) *GuestHandler {
+	return &GuestHandler{useCase: u}
+}
+
+func (h *GuestHandler) ListGuests(c *gin.Context) {
+	list, err := h.useCase.ListGuests(c.Param("uuid"))
+	if err != nil {
+		guestError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// ImportGuests takes a multipart upload: "file" (CSV or XLSX), an optional
+// "mapping" JSON object such as {"name":"ФИО","phone":"C"} and "dryRun".
+// A dry run always answers 200 with the report; a real import answers 201
+// when every row was added and 422 with the report when nothing was.
+func (h *GuestHandler) ImportGuests(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var mapping map[string]string
+	if m := c.PostForm("mapping"); m != "" {
+		if err := json.Unmarshal([]byte(m), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mapping must be a JSON object of field to column"})
+			return
+		}
+	}
+	dryRun := false
+	if v := c.DefaultPostForm("dryRun", c.Query("dryRun")); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dryRun value"})
+			return
+		}
+	}
+
+	rows, err := sheet.Read(fh.Filename, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.useCase.ImportGuests(c.Param("uuid"), rows, mapping, dryRun)
+	if err != nil {
+		guestError(c, err)
+		return
+	}
+	switch {
+	case dryRun:
+		c.JSON(http.StatusOK, report)
+	case len(report.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, report)
+	default:
+		c.JSON(http.StatusCreated, report)
+	}
+}
+
+func guestError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -9,10 +10,11 @@ import (
 
 type InvitationHandler struct {
 	useCase *usecase.InvitationUseCase
+	clicks  *usecase.ClickUseCase
 }
 
-func NewInvitationHandler(u *usecase.InvitationUseCase) *InvitationHandler {
-	return &InvitationHandler{useCase: u}
+func NewInvitationHandler(u *usecase.InvitationUseCase, clicks *usecase.ClickUseCase) *InvitationHandler {
+	return &InvitationHandler{useCase: u, clicks: clicks}
 }
 
 func (h *InvitationHandler) GetInvitation(c *gin.Context) {
@@ -35,13 +37,19 @@ func (h *InvitationHandler) SubmitRSVP(c *gin.Context) {
 		GuestName  string `json:"guestName" binding:"required"`
 		Attendance string `json:"attendance" binding:"required"`
 		GuestCount int    `json:"guestCount"`
+		Phone      string `json:"phone"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.useCase.SubmitRSVP(id, req.GuestName, req.Attendance, req.GuestCount); err != nil {
+	if err := h.useCase.SubmitRSVP(id, req.GuestName, req.Attendance, req.GuestCount, req.Phone); err != nil {
+		var input usecase.InputError
+		if errors.As(err, &input) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -55,5 +63,10 @@ func (h *InvitationHandler) RedirectShortCode(c *gin.Context) {
 		c.String(http.StatusNotFound, "Short link not found")
 		return
 	}
-	c.Redirect(http.StatusMovedPermanently, "/i/"+uuid)
+	h.clicks.Record(uuid, code, c.ClientIP(), c.GetHeader("User-Agent"), c.GetHeader("Referer"))
+
+	// A temporary redirect keeps links repointable and countable; browsers
+	// may still reuse it for a few minutes.
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Redirect(http.StatusFound, "/i/"+uuid)
 }
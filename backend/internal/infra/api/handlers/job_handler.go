@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+type JobHandler struct {
+	useCase *usecase.SchedulerUseCase
+}
+
+func NewJobHandler(u *usecase.SchedulerUseCase) *JobHandler {
+	return &JobHandler{useCase: u}
+}
+
+// Jobs lists the scheduled jobs with their flags and last runs.
+func (h *JobHandler) Jobs(c *gin.Context) {
+	list, err := h.useCase.Jobs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// SetEnabled turns a job on or off on every replica.
+func (h *JobHandler) SetEnabled(c *gin.Context) {
+	var req struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.useCase.SetJobEnabled(c.Param("name"), *req.Enabled); err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"name": c.Param("name"), "enabled": *req.Enabled})
+}
+
+// Run runs a job now and answers with the finished run, failed or not.
+func (h *JobHandler) Run(c *gin.Context) {
+	run, err := h.useCase.RunJob(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// Runs returns the run history of a job, newest first, up to ?limit.
+func (h *JobHandler) Runs(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid limit: %q", v)})
+			return
+		}
+	}
+	list, err := h.useCase.JobRuns(c.Param("name"), limit)
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func jobError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrJobRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+type NotificationHandler struct {
+	useCase *usecase.NotificationUseCase
+}
+
+func NewNotificationHandler(u *usecase.NotificationUseCase) *NotificationHandler {
+	return &NotificationHandler{useCase: u}
+}
+
+// Templates lists the message templates and the channels they can be
+// sent on.
+func (h *NotificationHandler) Templates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"templates": usecase.NotificationTemplates(), "channels": h.useCase.Channels()})
+}
+
+// Notifications returns the send log of an invitation, newest first.
+func (h *NotificationHandler) Notifications(c *gin.Context) {
+	list, err := h.useCase.Notifications(c.Param("uuid"))
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Preview renders ?template for an invitation in ?lang, its own language
+// by default, without sending it.
+func (h *NotificationHandler) Preview(c *gin.Context) {
+	n, err := h.useCase.Preview(c.Param("uuid"), c.Query("template"), c.Query("lang"))
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, n)
+}
+
+// Send queues a message to the client and answers 202 with its log entry.
+func (h *NotificationHandler) Send(c *gin.Context) {
+	var in usecase.NotificationInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	n, err := h.useCase.Send(c.Param("uuid"), in)
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, n)
+}
+
+func notificationError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
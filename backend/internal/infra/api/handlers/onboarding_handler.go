@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+type OnboardingHandler struct {
+	useCase *usecase.OnboardingUseCase
+}
+
+func NewOnboardingHandler(u *usecase.OnboardingUseCase) *OnboardingHandler {
+	return &OnboardingHandler{useCase: u}
+}
+
+// Verify answers WhatsApp's check of the webhook URL by echoing
+// hub.challenge.
+func (h *OnboardingHandler) Verify(c *gin.Context) {
+	if !h.useCase.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "whatsapp bot is not configured"})
+		return
+	}
+	if !h.useCase.VerifySubscription(c.Query("hub.mode"), c.Query("hub.verify_token")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "invalid verify token"})
+		return
+	}
+	c.String(http.StatusOK, c.Query("hub.challenge"))
+}
+
+// Webhook takes the messages clients send to the business number. Errors
+// other than a bad signature or body answer 500, so WhatsApp delivers the
+// call again.
+func (h *OnboardingHandler) Webhook(c *gin.Context) {
+	if !h.useCase.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "whatsapp bot is not configured"})
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookSize+1))
+	if err != nil || len(body) > maxWebhookSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook body"})
+		return
+	}
+	if err := h.useCase.HandleWebhook(c.Request.Header, body); err != nil {
+		onboardingError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// Conversations lists the bot's conversations, most recently active
+// first.
+func (h *OnboardingHandler) Conversations(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+			return
+		}
+	}
+	list, err := h.useCase.Conversations(limit)
+	if err != nil {
+		onboardingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// ResetConversation forgets the conversation with a phone number.
+func (h *OnboardingHandler) ResetConversation(c *gin.Context) {
+	if err := h.useCase.ResetConversation(c.Param("phone")); err != nil {
+		onboardingError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func onboardingError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrWebhookSignature):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrConversationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/card"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/web"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// PageHandler serves server-rendered invitation pages: the SPA index.html
+// with preview metadata and pre-rendered markup, and a script-free version
+// for crawlers, old phones and browsers without JS.
+type PageHandler struct {
+	useCase   *usecase.InvitationUseCase
+	cards     *card.Renderer
+	pages     *web.PageRenderer
+	indexPath string
+	baseURL   string
+}
+
+func NewPageHandler(u *usecase.InvitationUseCase, cards *card.Renderer, pages *web.PageRenderer, indexPath, baseURL string) *PageHandler {
+	return &PageHandler{useCase: u, cards: cards, pages: pages, indexPath: indexPath, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// InvitationPage serves /i/:uuid.
+func (h *PageHandler) InvitationPage(c *gin.Context) {
+	inv, err := h.useCase.GetInvitation(c.Param("uuid"))
+	h.serveInvitation(c, inv, err)
+}
+
+// ShortLinkPreview answers link-preview bots on /s/:shortCode with the
+// invitation page itself. Bots that do not follow redirects would otherwise
+// only see the redirect. Regular browsers fall through to the redirect.
+func (h *PageHandler) ShortLinkPreview(c *gin.Context) {
+	if !isPreviewBot(c.GetHeader("User-Agent")) {
+		c.Next()
+		return
+	}
+	c.Abort()
+
+	uuid, err := h.useCase.ResolveShortCode(c.Param("shortCode"))
+	if err != nil {
+		h.serveInvitation(c, nil, err)
+		return
+	}
+	inv, err := h.useCase.GetInvitation(uuid)
+	h.serveInvitation(c, inv, err)
+}
+
+// InvitationDocument serves /i/:uuid/html, the complete no-JS page.
+func (h *PageHandler) InvitationDocument(c *gin.Context) {
+	inv, err := h.useCase.GetInvitation(c.Param("uuid"))
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err != nil {
+		expired := err.Error() == "invitation_expired"
+		if expired {
+			c.Status(http.StatusGone)
+		} else {
+			c.Status(http.StatusNotFound)
+		}
+		_ = h.pages.RenderUnavailable(c.Writer, expired, h.baseURL)
+		return
+	}
+
+	c.Status(http.StatusOK)
+	if err := h.pages.RenderDocument(c.Writer, inv, h.pageOptions(c, inv)); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// SubmitRSVPForm accepts the RSVP form of the no-JS page and redirects back
+// to it (post/redirect/get), so a reload does not submit twice.
+func (h *PageHandler) SubmitRSVPForm(c *gin.Context) {
+	id := c.Param("uuid")
+	back := "/i/" + id + "/html"
+	if _, err := h.useCase.GetInvitation(id); err != nil {
+		c.Redirect(http.StatusSeeOther, back)
+		return
+	}
+
+	name := strings.TrimSpace(c.PostForm("guestName"))
+	attendance := c.PostForm("attendance")
+	count, _ := strconv.Atoi(c.PostForm("guestCount"))
+	if count < 1 {
+		count = 1
+	}
+	if name == "" || (attendance != "yes" && attendance != "no") {
+		c.Redirect(http.StatusSeeOther, back+"?rsvp=invalid#rsvp")
+		return
+	}
+
+	if err := h.useCase.SubmitRSVP(id, name, attendance, count, c.PostForm("phone")); err != nil {
+		var input usecase.InputError
+		if errors.As(err, &input) {
+			c.Redirect(http.StatusSeeOther, back+"?rsvp=invalid_phone#rsvp")
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Redirect(http.StatusSeeOther, back+"?rsvp=ok#rsvp")
+}
+
+func (h *PageHandler) pageOptions(c *gin.Context, inv *domain.Invitation) web.PageOptions {
+	status := c.Query("rsvp")
+	if status != "ok" && status != "invalid" && status != "invalid_phone" {
+		status = ""
+	}
+	return web.PageOptions{
+		BaseURL:    h.baseURL,
+		RSVPAction: "/i/" + inv.UUID + "/rsvp",
+		RSVPStatus: status,
+	}
+}
+
+// CardImage serves the 1200x630 PNG preview card of an invitation. Missing
+// and expired invitations get the same 404, so the card doesn't reveal
+// whether a link existed.
+func (h *PageHandler) CardImage(c *gin.Context) {
+	inv, err := h.useCase.GetInvitation(c.Param("uuid"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrInvitationNotFound.Error()})
+		return
+	}
+
+	data, key, err := h.cards.Render(inv)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	etag := `"` + key + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=3600")
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "image/png", data)
+}
+
+func (h *PageHandler) serveInvitation(c *gin.Context, inv *domain.Invitation, err error) {
+	index, readErr := os.ReadFile(h.indexPath)
+	if readErr != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	if err != nil {
+		// Missing and expired links look the same to unfurlers.
+		c.Data(http.StatusNotFound, "text/html; charset=utf-8", web.InjectMeta(index, web.NeutralMeta(h.baseURL)))
+		return
+	}
+
+	page := web.InjectMeta(index, web.InvitationMeta(inv, h.baseURL))
+	frag, fragErr := h.pages.RenderFragment(inv, h.pageOptions(c, inv))
+	if fragErr == nil {
+		page, fragErr = web.InjectApp(page, frag, inv)
+	}
+	if fragErr != nil {
+		// The SPA still works without the pre-rendered markup.
+		_ = c.Error(fragErr)
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// previewBots are User-Agent fragments of the link unfurlers we care about.
+var previewBots = []string{
+	"whatsapp",
+	"telegrambot",
+	"facebookexternalhit",
+	"facebot",
+	"twitterbot",
+	"slackbot",
+	"discordbot",
+	"linkedinbot",
+	"vkshare",
+	"skypeuripreview",
+	"viber",
+}
+
+func isPreviewBot(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, bot := range previewBots {
+		if strings.Contains(ua, bot) {
+			return true
+		}
+	}
+	return false
+}
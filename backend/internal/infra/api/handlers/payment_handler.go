@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// maxWebhookSize bounds payment webhook bodies.
+const maxWebhookSize = 64 << 10
+
+type PaymentHandler struct {
+	useCase *usecase.PaymentUseCase
+}
+
+func NewPaymentHandler(u *usecase.PaymentUseCase) *PaymentHandler {
+	return &PaymentHandler{useCase: u}
+}
+
+// CreateOrder opens an order for an invitation and answers it with the
+// price and the payment URL to send to the customer.
+func (h *PaymentHandler) CreateOrder(c *gin.Context) {
+	var req struct {
+		Provider  string `json:"provider"`
+		Plan      string `json:"plan"`
+		PromoCode string `json:"promoCode"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	order, err := h.useCase.CreateOrder(c.Param("uuid"), req.Provider, req.Plan, req.PromoCode)
+	if err != nil {
+		paymentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+func (h *PaymentHandler) ListOrders(c *gin.Context) {
+	list, err := h.useCase.Orders(c.Param("uuid"))
+	if err != nil {
+		paymentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Webhook takes the notifications of a payment provider. Anything but 200
+// makes providers retry, so redelivered events are answered 200 as well.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookSize+1))
+	if err != nil || len(body) > maxWebhookSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook body"})
+		return
+	}
+	order, err := h.useCase.HandleWebhook(c.Param("provider"), c.Request.Header, body)
+	if err != nil {
+		paymentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"orderId": order.ID, "status": order.Status})
+}
+
+func paymentError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrWebhookSignature):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPromoCodeUnavailable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrPaymentMismatch):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvitationNotFound), errors.Is(err, usecase.ErrUnknownProvider),
+		errors.Is(err, domain.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
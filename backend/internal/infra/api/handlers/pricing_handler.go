@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+type PricingHandler struct {
+	useCase *usecase.PricingUseCase
+}
+
+func NewPricingHandler(u *usecase.PricingUseCase) *PricingHandler {
+	return &PricingHandler{useCase: u}
+}
+
+// PublicPlans lists the plans on sale, for the pricing page.
+func (h *PricingHandler) PublicPlans(c *gin.Context) {
+	h.plans(c, false)
+}
+
+// Plans lists every plan, including those taken off sale.
+func (h *PricingHandler) Plans(c *gin.Context) {
+	h.plans(c, true)
+}
+
+func (h *PricingHandler) plans(c *gin.Context, all bool) {
+	list, err := h.useCase.Plans(all)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// SavePlan creates or replaces the plan named in the path.
+func (h *PricingHandler) SavePlan(c *gin.Context) {
+	var p domain.Plan
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p.Code = c.Param("code")
+	if err := h.useCase.SavePlan(&p); err != nil {
+		pricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *PricingHandler) PromoCodes(c *gin.Context) {
+	list, err := h.useCase.PromoCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *PricingHandler) CreatePromoCode(c *gin.Context) {
+	var p domain.PromoCode
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.useCase.CreatePromoCode(&p); err != nil {
+		pricingError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+func (h *PricingHandler) DeactivatePromoCode(c *gin.Context) {
+	if err := h.useCase.DeactivatePromoCode(c.Param("code")); err != nil {
+		pricingError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func pricingError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPromoCodeExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrPromoCodeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
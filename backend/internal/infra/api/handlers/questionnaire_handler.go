@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+type QuestionnaireHandler struct {
+	useCase *usecase.QuestionnaireUseCase
+}
+
+func NewQuestionnaireHandler(u *usecase.QuestionnaireUseCase) *QuestionnaireHandler {
+	return &QuestionnaireHandler{useCase: u}
+}
+
+type parseQuestionnaireRequest struct {
+	Text        string `json:"text" binding:"required"`
+	PhoneNumber string `json:"phoneNumber"`
+}
+
+// Parse reads a questionnaire a client sent back filled in into a draft
+// invitation. Nothing is stored: the operator checks the draft and creates
+// the invitation with POST /admin/invitations.
+func (h *QuestionnaireHandler) Parse(c *gin.Context) {
+	var req parseQuestionnaireRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	draft, err := h.useCase.Parse(req.Text, req.PhoneNumber)
+	if err != nil {
+		var input usecase.InputError
+		if errors.As(err, &input) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
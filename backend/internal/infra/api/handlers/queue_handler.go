@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+type QueueHandler struct {
+	useCase *usecase.QueueUseCase
+}
+
+func NewQueueHandler(u *usecase.QueueUseCase) *QueueHandler {
+	return &QueueHandler{useCase: u}
+}
+
+// Jobs lists queue jobs, newest first, filtered by ?status and ?kind and
+// paged with ?limit and ?offset.
+func (h *QueueHandler) Jobs(c *gin.Context) {
+	filter := domain.QueueJobFilter{Status: c.Query("status"), Kind: c.Query("kind")}
+	for name, dst := range map[string]*int{"limit": &filter.Limit, "offset": &filter.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s: %q", name, v)})
+				return
+			}
+			*dst = n
+		}
+	}
+	page, err := h.useCase.Jobs(filter)
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// Job returns one queue job with its payload and last error.
+func (h *QueueHandler) Job(c *gin.Context) {
+	id, ok := int64Param(c, "id")
+	if !ok {
+		return
+	}
+	job, err := h.useCase.Job(id)
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// Retry makes a failed job due again with a fresh set of attempts.
+func (h *QueueHandler) Retry(c *gin.Context) {
+	id, ok := int64Param(c, "id")
+	if !ok {
+		return
+	}
+	job, err := h.useCase.Retry(id)
+	if err != nil {
+		queueError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+func queueError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrQueueJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrQueueJobNotRetryable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/web"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// ReminderHandler serves the reminder policies of invitations and the
+// opt-outs to admins, and the stop links of reminders to guests.
+type ReminderHandler struct {
+	useCase *usecase.ReminderUseCase
+	pages   *web.PageRenderer
+	baseURL string
+}
+
+func NewReminderHandler(u *usecase.ReminderUseCase, pages *web.PageRenderer, baseURL string) *ReminderHandler {
+	return &ReminderHandler{useCase: u, pages: pages, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Policies lists the reminder policies of an invitation with when each
+// is due.
+func (h *ReminderHandler) Policies(c *gin.Context) {
+	list, err := h.useCase.Policies(c.Param("uuid"))
+	if err != nil {
+		reminderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ReminderHandler) CreatePolicy(c *gin.Context) {
+	var in usecase.ReminderPolicyInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := h.useCase.CreatePolicy(c.Param("uuid"), in)
+	if err != nil {
+		reminderError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, p)
+}
+
+func (h *ReminderHandler) UpdatePolicy(c *gin.Context) {
+	id, ok := int64Param(c, "id")
+	if !ok {
+		return
+	}
+	var in usecase.ReminderPolicyInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := h.useCase.UpdatePolicy(c.Param("uuid"), id, in)
+	if err != nil {
+		reminderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+func (h *ReminderHandler) DeletePolicy(c *gin.Context) {
+	id, ok := int64Param(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.useCase.DeletePolicy(c.Param("uuid"), id); err != nil {
+		reminderError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ReminderHandler) OptOuts(c *gin.Context) {
+	list, err := h.useCase.OptOuts()
+	if err != nil {
+		reminderError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ReminderHandler) AddOptOut(c *gin.Context) {
+	var req struct {
+		Phone string `json:"phone" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	o, err := h.useCase.AddOptOut(req.Phone)
+	if err != nil {
+		reminderError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, o)
+}
+
+func (h *ReminderHandler) RemoveOptOut(c *gin.Context) {
+	if err := h.useCase.RemoveOptOut(c.Param("phone")); err != nil {
+		reminderError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// StopPage serves /r/stop/:token, which asks the guest to confirm. Only
+// the POST opts out, as messengers fetch links for their previews.
+func (h *ReminderHandler) StopPage(c *gin.Context) {
+	stop, err := h.useCase.StopPage(c.Param("token"))
+	h.serveStop(c, stop, err, web.StopConfirm)
+}
+
+// Stop opts the guest out of reminders.
+func (h *ReminderHandler) Stop(c *gin.Context) {
+	stop, err := h.useCase.Stop(c.Param("token"))
+	h.serveStop(c, stop, err, web.StopDone)
+}
+
+func (h *ReminderHandler) serveStop(c *gin.Context, stop *usecase.ReminderStop, err error, status string) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	switch {
+	case errors.Is(err, domain.ErrReminderTokenNotFound):
+		c.Status(http.StatusNotFound)
+		_ = h.pages.RenderReminderStop(c.Writer, i18n.DefaultLang, "", "", web.StopInvalid, h.baseURL)
+		return
+	case err != nil:
+		c.Status(http.StatusInternalServerError)
+		_ = h.pages.RenderReminderStop(c.Writer, i18n.DefaultLang, "", "", web.StopInvalid, h.baseURL)
+		return
+	}
+	c.Status(http.StatusOK)
+	_ = h.pages.RenderReminderStop(c.Writer, stop.Lang, stop.Phone, "/r/stop/"+c.Param("token"), status, h.baseURL)
+}
+
+func reminderError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrReminderPolicyNotFound), errors.Is(err, domain.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+type WebhookHandler struct {
+	useCase *usecase.WebhookUseCase
+}
+
+func NewWebhookHandler(u *usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{useCase: u}
+}
+
+func (h *WebhookHandler) Webhooks(c *gin.Context) {
+	list, err := h.useCase.Webhooks()
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *WebhookHandler) Webhook(c *gin.Context) {
+	id, ok := int64Param(c, "id")
+	if !ok {
+		return
+	}
+	sub, err := h.useCase.Webhook(id)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// Create adds a subscription and answers with it, secret included.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var in usecase.WebhookInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub, err := h.useCase.CreateWebhook(in)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, ok := int64Param(c, "id")
+	if !ok {
+		return
+	}
+	var in usecase.WebhookInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub, err := h.useCase.UpdateWebhook(id, in)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := int64Param(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.useCase.DeleteWebhook(id); err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries returns the delivery log of a subscription, newest first, up
+// to ?limit.
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	id, ok := int64Param(c, "id")
+	if !ok {
+		return
+	}
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid limit: %q", v)})
+			return
+		}
+	}
+	list, err := h.useCase.Deliveries(id, limit)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Redeliver queues the event of a delivery again and answers 202 with the
+// new delivery.
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := int64Param(c, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := int64Param(c, "delivery")
+	if !ok {
+		return
+	}
+	d, err := h.useCase.Redeliver(id, deliveryID)
+	if err != nil {
+		webhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, d)
+}
+
+// int64Param reads a numeric path parameter, answering 400 when it isn't.
+func int64Param(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s: %q", name, c.Param(name))})
+		return 0, false
+	}
+	return id, true
+}
+
+func webhookError(c *gin.Context, err error) {
+	var input usecase.InputError
+	switch {
+	case errors.As(err, &input):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrWebhookNotFound), errors.Is(err, domain.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
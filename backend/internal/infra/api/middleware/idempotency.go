@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+const (
+	// IdempotencyHeader is the request header clients such as n8n set to
+	// make retries of a mutating call safe.
+	IdempotencyHeader = "Idempotency-Key"
+
+	// maxIdempotentRequest bounds the body read for hashing; it is above
+	// every mutating route's own limit.
+	maxIdempotentRequest = 10 << 20
+	// maxIdempotentResponse bounds what is stored for replay. Larger
+	// responses are sent but not stored, so a retry runs again.
+	maxIdempotentResponse = 1 << 20
+)
+
+// Idempotency replays the stored response when a POST, PUT, PATCH or DELETE
+// carrying an Idempotency-Key header is retried. The key is bound to the
+// method, URL and body: reusing it for a different request is rejected with
+// 422, and a retry arriving while the first call still runs gets 409.
+// Requests without the header pass through untouched.
+func Idempotency(u *usecase.IdempotencyUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || !mutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentRequest+1))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(body) > maxIdempotentRequest {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		rec, token, err := u.Begin(key, requestHash(c.Request, body))
+		var input usecase.InputError
+		switch {
+		case errors.As(err, &input):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, usecase.ErrIdempotencyKeyReused):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, usecase.ErrIdempotencyKeyInUse):
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		case rec != nil:
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(rec.StatusCode, rec.ContentType, rec.Body)
+			c.Abort()
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		finished := false
+		defer func() {
+			// A panicking handler leaves nothing to replay.
+			if !finished {
+				if err := u.Abort(key, token); err != nil {
+					log.Printf("idempotency: release %q: %v", key, err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		finished = true
+		if w.overflow {
+			err = u.Abort(key, token)
+		} else {
+			err = u.Finish(key, token, w.Status(), w.Header().Get("Content-Type"), w.buf.Bytes())
+		}
+		if err != nil {
+			log.Printf("idempotency: store %q: %v", key, err)
+		}
+	}
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestHash fingerprints what the key is bound to. Multipart boundaries
+// are random per attempt, so they are left out.
+func requestHash(r *http.Request, body []byte) string {
+	if mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil &&
+		strings.HasPrefix(mt, "multipart/") && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter passes the response through while keeping a copy.
+type recordingWriter struct {
+	gin.ResponseWriter
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.record(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *recordingWriter) record(b []byte) {
+	if w.overflow {
+		return
+	}
+	if w.buf.Len()+len(b) > maxIdempotentResponse {
+		w.overflow = true
+		w.buf.Reset()
+		return
+	}
+	w.buf.Write(b)
+}
@@ -8,9 +8,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/api/handlers"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/api/middleware"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
 )
 
-func SetupRouter(invHandler *handlers.InvitationHandler, adminHandler *handlers.AdminHandler, jwtSecret []byte, apiKey string, frontendDist string) *gin.Engine {
+func SetupRouter(invHandler *handlers.InvitationHandler, adminHandler *handlers.AdminHandler, pageHandler *handlers.PageHandler, exportHandler *handlers.ExportHandler, guestHandler *handlers.GuestHandler, analyticsHandler *handlers.AnalyticsHandler, paymentHandler *handlers.PaymentHandler, pricingHandler *handlers.PricingHandler, jobHandler *handlers.JobHandler, queueHandler *handlers.QueueHandler, webhookHandler *handlers.WebhookHandler, notificationHandler *handlers.NotificationHandler, onboardingHandler *handlers.OnboardingHandler, questionnaireHandler *handlers.QuestionnaireHandler, reminderHandler *handlers.ReminderHandler, idempotency *usecase.IdempotencyUseCase, jwtSecret []byte, apiKey string, frontendDist string) *gin.Engine {
 	r := gin.Default()
 
 	api := r.Group("/api")
@@ -20,23 +21,88 @@ func SetupRouter(invHandler *handlers.InvitationHandler, adminHandler *handlers.
 		})
 
 		api.GET("/invitations/:uuid", invHandler.GetInvitation)
+		api.GET("/invitations/:uuid/card.png", pageHandler.CardImage)
 		api.POST("/rsvp/:uuid", invHandler.SubmitRSVP)
+		api.POST("/invitations/:uuid/events", analyticsHandler.Beacon)
+		api.POST("/payments/:provider/webhook", paymentHandler.Webhook)
+		api.GET("/whatsapp/webhook", onboardingHandler.Verify)
+		api.POST("/whatsapp/webhook", onboardingHandler.Webhook)
+		api.GET("/plans", pricingHandler.PublicPlans)
 
 		api.POST("/admin/login", adminHandler.Login)
 		api.POST("/admin/logout", adminHandler.Logout)
 
 		admin := api.Group("/admin")
-		admin.Use(middleware.AuthMiddleware(jwtSecret, apiKey))
+		admin.Use(middleware.AuthMiddleware(jwtSecret, apiKey), middleware.Idempotency(idempotency))
 		{
 			admin.GET("/stats", adminHandler.GetStats)
+			admin.GET("/analytics", adminHandler.GetAnalytics)
 			admin.GET("/invitations", adminHandler.GetInvitationsList)
+			admin.GET("/invitations/export", exportHandler.Invitations)
 			admin.POST("/invitations", adminHandler.CreateInvitation)
+			admin.POST("/invitations/batch", adminHandler.CreateInvitations)
+			admin.GET("/invitations/expiring", adminHandler.ExpiringInvitations)
 			admin.POST("/invitations/:uuid/pay", adminHandler.MarkAsPaid)
+			admin.POST("/invitations/:uuid/trial/extend", adminHandler.ExtendTrial)
+			admin.POST("/invitations/:uuid/trial/renew", adminHandler.RenewTrial)
+			admin.PUT("/invitations/:uuid/hosting", adminHandler.SetHosting)
+			admin.GET("/invitations/:uuid/orders", paymentHandler.ListOrders)
+			admin.POST("/invitations/:uuid/orders", paymentHandler.CreateOrder)
+			admin.GET("/invitations/:uuid/export.zip", exportHandler.StaticSite)
+			admin.GET("/invitations/:uuid/guests.pdf", exportHandler.GuestListPDF)
+			admin.GET("/invitations/:uuid/card.pdf", exportHandler.CardPDF)
+			admin.GET("/invitations/:uuid/rsvps", adminHandler.ListRSVPs)
+			admin.GET("/invitations/:uuid/rsvps/export", exportHandler.RSVPs)
+			admin.PUT("/invitations/:uuid/rsvps/:id", adminHandler.UpdateRSVP)
+			admin.DELETE("/invitations/:uuid/rsvps/:id", adminHandler.DeleteRSVP)
+			admin.GET("/invitations/:uuid/guests", guestHandler.ListGuests)
+			admin.POST("/invitations/:uuid/guests/import", guestHandler.ImportGuests)
+			admin.GET("/invitations/:uuid/clicks", analyticsHandler.ClickStats)
+			admin.GET("/invitations/:uuid/engagement", analyticsHandler.Engagement)
+			admin.GET("/invitations/:uuid/notifications", notificationHandler.Notifications)
+			admin.POST("/invitations/:uuid/notifications", notificationHandler.Send)
+			admin.GET("/invitations/:uuid/notifications/preview", notificationHandler.Preview)
+			admin.GET("/notification-templates", notificationHandler.Templates)
+			admin.GET("/invitations/:uuid/reminders", reminderHandler.Policies)
+			admin.POST("/invitations/:uuid/reminders", reminderHandler.CreatePolicy)
+			admin.PUT("/invitations/:uuid/reminders/:id", reminderHandler.UpdatePolicy)
+			admin.DELETE("/invitations/:uuid/reminders/:id", reminderHandler.DeletePolicy)
+			admin.GET("/reminder-opt-outs", reminderHandler.OptOuts)
+			admin.POST("/reminder-opt-outs", reminderHandler.AddOptOut)
+			admin.DELETE("/reminder-opt-outs/:phone", reminderHandler.RemoveOptOut)
+			admin.GET("/conversations", onboardingHandler.Conversations)
+			admin.DELETE("/conversations/:phone", onboardingHandler.ResetConversation)
+			admin.POST("/questionnaires/parse", questionnaireHandler.Parse)
+			admin.GET("/short-codes/:code", adminHandler.ShortCodeAvailability)
 			admin.GET("/templates", adminHandler.GetTemplates)
+			admin.GET("/plans", pricingHandler.Plans)
+			admin.PUT("/plans/:code", pricingHandler.SavePlan)
+			admin.GET("/promo-codes", pricingHandler.PromoCodes)
+			admin.POST("/promo-codes", pricingHandler.CreatePromoCode)
+			admin.DELETE("/promo-codes/:code", pricingHandler.DeactivatePromoCode)
+			admin.GET("/jobs", jobHandler.Jobs)
+			admin.PUT("/jobs/:name", jobHandler.SetEnabled)
+			admin.POST("/jobs/:name/run", jobHandler.Run)
+			admin.GET("/jobs/:name/runs", jobHandler.Runs)
+			admin.GET("/queue/jobs", queueHandler.Jobs)
+			admin.GET("/queue/jobs/:id", queueHandler.Job)
+			admin.POST("/queue/jobs/:id/retry", queueHandler.Retry)
+			admin.GET("/webhooks", webhookHandler.Webhooks)
+			admin.POST("/webhooks", webhookHandler.Create)
+			admin.GET("/webhooks/:id", webhookHandler.Webhook)
+			admin.PUT("/webhooks/:id", webhookHandler.Update)
+			admin.DELETE("/webhooks/:id", webhookHandler.Delete)
+			admin.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries)
+			admin.POST("/webhooks/:id/deliveries/:delivery/redeliver", webhookHandler.Redeliver)
 		}
 	}
 
-	r.GET("/s/:shortCode", invHandler.RedirectShortCode)
+	r.GET("/s/:shortCode", pageHandler.ShortLinkPreview, invHandler.RedirectShortCode)
+	r.GET("/i/:uuid", pageHandler.InvitationPage)
+	r.GET("/i/:uuid/html", pageHandler.InvitationDocument)
+	r.POST("/i/:uuid/rsvp", pageHandler.SubmitRSVPForm)
+	r.GET("/r/stop/:token", reminderHandler.StopPage)
+	r.POST("/r/stop/:token", reminderHandler.Stop)
 
 	// Static Files Frontend
 	rootDir := frontendDist
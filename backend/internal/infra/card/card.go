@@ -0,0 +1,308 @@
+// Package card renders the 1200x630 social preview image of an invitation.
+package card
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/fonts"
+)
+
+// Open Graph's recommended large image size.
+const (
+	Width  = 1200
+	Height = 630
+)
+
+// Key identifies the rendered card of an invitation. It only depends on the
+// fields drawn on the card, so it changes exactly when the image would.
+func Key(inv *domain.Invitation) string {
+	h := sha256.New()
+	for _, s := range []string{inv.TemplateCode, inv.Lang, inv.GroomName, inv.BrideName, inv.EventDate, inv.EventLocation} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Renderer draws invitation cards and keeps the most recent ones in memory.
+type Renderer struct {
+	mu    sync.Mutex
+	cache map[string][]byte
+	order []string
+	limit int
+}
+
+func NewRenderer(limit int) *Renderer {
+	return &Renderer{cache: make(map[string][]byte), limit: limit}
+}
+
+// Render returns the PNG card of inv together with its cache key.
+func (r *Renderer) Render(inv *domain.Invitation) ([]byte, string, error) {
+	key := Key(inv)
+
+	r.mu.Lock()
+	if data, ok := r.cache[key]; ok {
+		r.mu.Unlock()
+		return data, key, nil
+	}
+	r.mu.Unlock()
+
+	data, err := Draw(inv)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.cache[key]; !ok {
+		r.cache[key] = data
+		r.order = append(r.order, key)
+		if len(r.order) > r.limit {
+			delete(r.cache, r.order[0])
+			r.order = r.order[1:]
+		}
+	}
+	return data, key, nil
+}
+
+// theme is the palette of a template.
+type theme struct {
+	top, bottom color.RGBA
+	label       color.RGBA
+	names       color.RGBA
+	text        color.RGBA
+	accent      color.RGBA
+	stars       bool
+	frame       bool
+}
+
+var themes = map[string]theme{
+	"starry-night": {
+		top:    color.RGBA{0x0b, 0x10, 0x26, 0xff},
+		bottom: color.RGBA{0x1c, 0x25, 0x41, 0xff},
+		label:  color.RGBA{0xc9, 0xd1, 0xe8, 0xff},
+		names:  color.RGBA{0xe8, 0xc9, 0x87, 0xff},
+		text:   color.RGBA{0xf1, 0xf1, 0xf1, 0xff},
+		accent: color.RGBA{0xe8, 0xc9, 0x87, 0xff},
+		stars:  true,
+	},
+	"silk-ivory": {
+		top:    color.RGBA{0xfb, 0xf7, 0xef, 0xff},
+		bottom: color.RGBA{0xf1, 0xe7, 0xd6, 0xff},
+		label:  color.RGBA{0x8a, 0x76, 0x5c, 0xff},
+		names:  color.RGBA{0x3d, 0x32, 0x28, 0xff},
+		text:   color.RGBA{0x5a, 0x4b, 0x3c, 0xff},
+		accent: color.RGBA{0xc5, 0xa0, 0x59, 0xff},
+		frame:  true,
+	},
+}
+
+const defaultTheme = "starry-night"
+
+// Draw renders the card of inv as a PNG.
+func Draw(inv *domain.Invitation) ([]byte, error) {
+	th, ok := themes[inv.TemplateCode]
+	if !ok {
+		th = themes[defaultTheme]
+	}
+	lang := i18n.Normalize(inv.Lang)
+
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	gradient(img, th.top, th.bottom)
+	if th.stars {
+		stars(img, Key(inv))
+	}
+	if th.frame {
+		frame(img, th.accent)
+	}
+
+	serif, bold := fonts.Serif(), fonts.SerifBold()
+	const maxWidth = Width - 160
+
+	centered(img, serif, strings.ToUpper(i18n.T(lang, "card_label")), 28, 130, th.label)
+
+	y := drawNames(img, bold, inv.GroomName, inv.BrideName, 275, maxWidth, th.names)
+
+	divider(img, y, th.accent)
+	y += 75
+
+	if t, ok := inv.EventTime(); ok {
+		when := i18n.FormatDate(t, lang)
+		if tm := i18n.FormatTime(t); tm != "" {
+			when += " · " + tm
+		}
+		centered(img, serif, when, 40, y, th.text)
+		y += 60
+	}
+	if inv.EventLocation != "" {
+		centered(img, serif, ellipsize(serif, inv.EventLocation, 34, maxWidth), 34, y, th.text)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawNames draws the couple on one line when it fits at a readable size,
+// otherwise the groom on the first line and "& bride" on the second. It
+// returns the baseline for the content below.
+func drawNames(img *image.RGBA, f *fonts.Font, groom, bride string, y, width float64, c color.Color) float64 {
+	groom, bride = strings.TrimSpace(groom), strings.TrimSpace(bride)
+	if groom == "" || bride == "" {
+		name := groom + bride
+		if name == "" {
+			return y
+		}
+		size := math.Max(fit(f, name, 96, 60, width), 60)
+		centered(img, f, ellipsize(f, name, size, width), size, y, c)
+		return y + 70
+	}
+
+	oneLine := groom + " & " + bride
+	if size := fit(f, oneLine, 96, 60, width); size > 0 {
+		centered(img, f, oneLine, size, y, c)
+		return y + 70
+	}
+
+	second := "& " + bride
+	size := math.Min(fit(f, groom, 80, 40, width), fit(f, second, 80, 40, width))
+	if size == 0 {
+		size = 40
+	}
+	centered(img, f, ellipsize(f, groom, size, width), size, y-40, c)
+	centered(img, f, ellipsize(f, second, size, width), size, y-40+size*1.15, c)
+	return y - 40 + size*1.15 + 60
+}
+
+// fit returns the largest size in [min, max] at which s fits width, or 0.
+func fit(f *fonts.Font, s string, max, min, width float64) float64 {
+	for size := max; size >= min; size -= 4 {
+		if f.Measure(s, size) <= width {
+			return size
+		}
+	}
+	return 0
+}
+
+// ellipsize cuts s so it fits width at size, appending "…" when shortened.
+func ellipsize(f *fonts.Font, s string, size, width float64) string {
+	if f.Measure(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		cut := strings.TrimSpace(string(runes)) + "…"
+		if f.Measure(cut, size) <= width {
+			return cut
+		}
+	}
+	return ""
+}
+
+func centered(img *image.RGBA, f *fonts.Font, s string, size, baseline float64, c color.Color) {
+	x := (Width - f.Measure(s, size)) / 2
+	f.DrawString(img, x, baseline, s, size, c)
+}
+
+func gradient(img *image.RGBA, top, bottom color.RGBA) {
+	for y := 0; y < Height; y++ {
+		t := float64(y) / float64(Height-1)
+		c := color.RGBA{
+			lerp(top.R, bottom.R, t),
+			lerp(top.G, bottom.G, t),
+			lerp(top.B, bottom.B, t),
+			0xff,
+		}
+		row := img.Pix[y*img.Stride : y*img.Stride+Width*4]
+		for x := 0; x < Width; x++ {
+			copy(row[x*4:], []byte{c.R, c.G, c.B, c.A})
+		}
+	}
+}
+
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t + 0.5)
+}
+
+// blend mixes c over the pixel at (x, y) with the given opacity.
+func blend(img *image.RGBA, x, y int, c color.RGBA, alpha float64) {
+	if x < 0 || y < 0 || x >= Width || y >= Height || alpha <= 0 {
+		return
+	}
+	if alpha > 1 {
+		alpha = 1
+	}
+	i := img.PixOffset(x, y)
+	img.Pix[i] = uint8(float64(img.Pix[i])*(1-alpha) + float64(c.R)*alpha)
+	img.Pix[i+1] = uint8(float64(img.Pix[i+1])*(1-alpha) + float64(c.G)*alpha)
+	img.Pix[i+2] = uint8(float64(img.Pix[i+2])*(1-alpha) + float64(c.B)*alpha)
+}
+
+// stars scatters soft dots; the seed keeps one invitation's sky stable.
+func stars(img *image.RGBA, seed string) {
+	var s int64
+	for _, b := range []byte(seed) {
+		s = s*31 + int64(b)
+	}
+	rng := rand.New(rand.NewSource(s))
+	white := color.RGBA{0xff, 0xff, 0xff, 0xff}
+	for i := 0; i < 220; i++ {
+		cx, cy := rng.Float64()*Width, rng.Float64()*Height
+		r := 0.6 + rng.Float64()*1.6
+		bright := 0.35 + rng.Float64()*0.65
+		for y := int(cy - r - 1); y <= int(cy+r+1); y++ {
+			for x := int(cx - r - 1); x <= int(cx+r+1); x++ {
+				d := math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy)
+				blend(img, x, y, white, bright*(1-d/(r+1)))
+			}
+		}
+	}
+}
+
+func frame(img *image.RGBA, c color.RGBA) {
+	rect := func(inset, thickness int, alpha float64) {
+		for t := 0; t < thickness; t++ {
+			x0, y0, x1, y1 := inset+t, inset+t, Width-1-inset-t, Height-1-inset-t
+			for x := x0; x <= x1; x++ {
+				blend(img, x, y0, c, alpha)
+				blend(img, x, y1, c, alpha)
+			}
+			for y := y0 + 1; y < y1; y++ {
+				blend(img, x0, y, c, alpha)
+				blend(img, x1, y, c, alpha)
+			}
+		}
+	}
+	rect(28, 3, 1)
+	rect(40, 1, 0.6)
+}
+
+// divider draws a thin line with a diamond in the middle.
+func divider(img *image.RGBA, y float64, c color.RGBA) {
+	cy := int(y)
+	for x := Width/2 - 160; x <= Width/2+160; x++ {
+		fade := 1 - math.Abs(float64(x-Width/2))/160
+		blend(img, x, cy, c, 0.9*fade+0.1)
+	}
+	const r = 8.0
+	for dy := -r - 1; dy <= r+1; dy++ {
+		for dx := -r - 1; dx <= r+1; dx++ {
+			d := math.Abs(dx) + math.Abs(dy)
+			blend(img, Width/2+int(dx), cy+int(dy), c, r+0.5-d)
+		}
+	}
+}
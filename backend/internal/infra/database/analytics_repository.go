@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+// GetAnalytics runs its four aggregates in one round trip. created_at is a
+// plain TIMESTAMP, so periods are cut in the session time zone, UTC on our
+// servers. The series comes back unordered.
+func (r *PostgresAdminRepository) GetAnalytics(from, to time.Time, granularity string) (*domain.BusinessAnalytics, error) {
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		SELECT to_char(date_trunc($3, created_at), 'YYYY-MM-DD') AS period, COUNT(*), COUNT(*) FILTER (WHERE is_paid)
+		FROM invitations
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY period`, from, to, granularity)
+	batch.Queue(`
+		SELECT to_char(date_trunc($3, created_at), 'YYYY-MM-DD') AS period, COUNT(*),
+		       COALESCE(SUM(guest_count) FILTER (WHERE attendance = 'yes'), 0)
+		FROM rsvp_responses
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY period`, from, to, granularity)
+	batch.Queue(`
+		SELECT i.template_code, COALESCE(t.name_ru, i.template_code), COUNT(*), COUNT(*) FILTER (WHERE i.is_paid)
+		FROM invitations i
+		LEFT JOIN templates t ON i.template_code = t.code
+		WHERE i.created_at >= $1 AND i.created_at < $2
+		GROUP BY i.template_code, t.name_ru
+		ORDER BY COUNT(*) DESC, i.template_code`, from, to)
+	batch.Queue(`
+		SELECT lang, '', COUNT(*), COUNT(*) FILTER (WHERE is_paid)
+		FROM invitations
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY lang
+		ORDER BY COUNT(*) DESC, lang`, from, to)
+
+	br := r.pool.SendBatch(context.Background(), batch)
+	defer br.Close()
+
+	byPeriod := map[string]*domain.AnalyticsPeriod{}
+	period := func(key string) *domain.AnalyticsPeriod {
+		p := byPeriod[key]
+		if p == nil {
+			p = &domain.AnalyticsPeriod{Period: key}
+			byPeriod[key] = p
+		}
+		return p
+	}
+
+	rows, err := br.Query()
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var key string
+		var invitations, paid int
+		if err := rows.Scan(&key, &invitations, &paid); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p := period(key)
+		p.Invitations, p.Paid = invitations, paid
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = br.Query()
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var key string
+		var rsvps, guests int
+		if err := rows.Scan(&key, &rsvps, &guests); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		p := period(key)
+		p.RSVPs, p.Guests = rsvps, guests
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	a := &domain.BusinessAnalytics{Series: make([]domain.AnalyticsPeriod, 0, len(byPeriod))}
+	for _, p := range byPeriod {
+		a.Series = append(a.Series, *p)
+	}
+
+	for _, into := range []*[]domain.AnalyticsShare{&a.Templates, &a.Languages} {
+		rows, err := br.Query()
+		if err != nil {
+			return nil, err
+		}
+		*into = []domain.AnalyticsShare{}
+		for rows.Next() {
+			var s domain.AnalyticsShare
+			if err := rows.Scan(&s.Key, &s.Name, &s.Invitations, &s.Paid); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			*into = append(*into, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresClickRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresClickRepository(pool *pgxpool.Pool) *PostgresClickRepository {
+	return &PostgresClickRepository{pool: pool}
+}
+
+func (r *PostgresClickRepository) AddClicks(clicks []domain.Click) error {
+	_, err := r.pool.CopyFrom(context.Background(),
+		pgx.Identifier{"short_link_clicks"},
+		[]string{"invitation_uuid", "short_code", "clicked_at", "visitor_hash", "ua_class", "referrer", "country"},
+		pgx.CopyFromSlice(len(clicks), func(i int) ([]interface{}, error) {
+			c := clicks[i]
+			return []interface{}{c.InvitationUUID, c.ShortCode, c.ClickedAt, c.VisitorHash, c.UAClass, c.Referrer, c.Country}, nil
+		}))
+	return err
+}
+
+func (r *PostgresClickRepository) GetClickStats(invitationUUID string, from, to time.Time) (*domain.ClickStats, error) {
+	const where = `FROM short_link_clicks WHERE invitation_uuid = $1 AND clicked_at >= $2 AND clicked_at < $3`
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		SELECT COUNT(*) FILTER (WHERE ua_class <> 'bot'),
+		       COUNT(DISTINCT visitor_hash) FILTER (WHERE ua_class <> 'bot'),
+		       COUNT(*) FILTER (WHERE ua_class = 'bot')
+		`+where, invitationUUID, from, to)
+	batch.Queue(`
+		SELECT to_char(clicked_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*), COUNT(DISTINCT visitor_hash)
+		`+where+` AND ua_class <> 'bot'
+		GROUP BY day ORDER BY day`, invitationUUID, from, to)
+	batch.Queue(`
+		SELECT GROUPING(ua_class, country, referrer), COALESCE(ua_class, ''), COALESCE(country, ''), COALESCE(referrer, ''), COUNT(*)
+		`+where+` AND ua_class <> 'bot'
+		GROUP BY GROUPING SETS ((ua_class), (country), (referrer))`, invitationUUID, from, to)
+
+	br := r.pool.SendBatch(context.Background(), batch)
+	defer br.Close()
+
+	stats := &domain.ClickStats{
+		Daily:     []domain.DailyClicks{},
+		Devices:   map[string]int{},
+		Countries: map[string]int{},
+		Referrers: map[string]int{},
+	}
+	if err := br.QueryRow().Scan(&stats.Clicks, &stats.UniqueVisitors, &stats.BotClicks); err != nil {
+		return nil, err
+	}
+
+	rows, err := br.Query()
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var d domain.DailyClicks
+		if err := rows.Scan(&d.Date, &d.Clicks, &d.UniqueVisitors); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats.Daily = append(stats.Daily, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = br.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			grouping                  int
+			device, country, referrer string
+			n                         int
+		)
+		if err := rows.Scan(&grouping, &device, &country, &referrer, &n); err != nil {
+			return nil, err
+		}
+		// GROUPING has a bit set for every column left out of the set:
+		// 0b011 is the ua_class set, 0b101 country and 0b110 referrer.
+		switch grouping {
+		case 3:
+			stats.Devices[device] = n
+		case 5:
+			stats.Countries[country] = n
+		case 6:
+			stats.Referrers[referrer] = n
+		}
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresConversationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresConversationRepository(pool *pgxpool.Pool) *PostgresConversationRepository {
+	return &PostgresConversationRepository{pool: pool}
+}
+
+const conversationColumns = `phone, lang, step, answers, correcting, COALESCE(invitation_uuid::text, ''),
+	last_message_id, version, created_at, updated_at`
+
+func scanConversation(row pgx.Row) (*domain.Conversation, error) {
+	var c domain.Conversation
+	err := row.Scan(&c.Phone, &c.Lang, &c.Step, &c.Answers, &c.Correcting, &c.InvitationUUID,
+		&c.LastMessageID, &c.Version, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *PostgresConversationRepository) GetConversation(phone string) (*domain.Conversation, error) {
+	c, err := scanConversation(r.pool.QueryRow(context.Background(),
+		"SELECT "+conversationColumns+" FROM whatsapp_conversations WHERE phone = $1", phone))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrConversationNotFound
+	}
+	return c, err
+}
+
+// SaveConversation inserts a new conversation, or updates one whose
+// version is unchanged; either way no row comes back on a conflict.
+func (r *PostgresConversationRepository) SaveConversation(c *domain.Conversation) error {
+	answers := c.Answers
+	if answers == nil {
+		answers = map[string]string{}
+	}
+	var row pgx.Row
+	if c.Version == 0 {
+		row = r.pool.QueryRow(context.Background(), `
+			INSERT INTO whatsapp_conversations (phone, lang, step, answers, correcting, invitation_uuid, last_message_id)
+			VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7)
+			ON CONFLICT (phone) DO NOTHING
+			RETURNING `+conversationColumns, c.Phone, c.Lang, c.Step, answers, c.Correcting, c.InvitationUUID, c.LastMessageID)
+	} else {
+		row = r.pool.QueryRow(context.Background(), `
+			UPDATE whatsapp_conversations SET lang = $3, step = $4, answers = $5, correcting = $6,
+			       invitation_uuid = NULLIF($7, '')::uuid, last_message_id = $8,
+			       version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE phone = $1 AND version = $2
+			RETURNING `+conversationColumns, c.Phone, c.Version, c.Lang, c.Step, answers, c.Correcting, c.InvitationUUID, c.LastMessageID)
+	}
+	saved, err := scanConversation(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrConversationConflict
+	}
+	if err != nil {
+		return err
+	}
+	*c = *saved
+	return nil
+}
+
+func (r *PostgresConversationRepository) ListConversations(limit int) ([]domain.Conversation, error) {
+	rows, err := r.pool.Query(context.Background(),
+		"SELECT "+conversationColumns+" FROM whatsapp_conversations ORDER BY updated_at DESC, phone LIMIT $1", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.Conversation{}
+	for rows.Next() {
+		c, err := scanConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *c)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresConversationRepository) DeleteConversation(phone string) error {
+	tag, err := r.pool.Exec(context.Background(), "DELETE FROM whatsapp_conversations WHERE phone = $1", phone)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrConversationNotFound
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+// engagementFunnel aggregates the events of invitation i into the columns
+// of domain.EngagementFunnel, without Responded. It is joined LATERAL by
+// the admin list as well.
+const engagementFunnel = `
+	SELECT COUNT(DISTINCT e.visitor_hash) FILTER (WHERE e.event = 'view' AND e.ua_class <> 'bot') AS opened,
+	       COUNT(DISTINCT e.visitor_hash) FILTER (WHERE e.event = 'story' AND e.ua_class <> 'bot') AS reached_story,
+	       COUNT(DISTINCT e.visitor_hash) FILTER (WHERE e.event = 'location' AND e.ua_class <> 'bot') AS reached_location,
+	       COUNT(DISTINCT e.visitor_hash) FILTER (WHERE e.event = 'rsvp_form' AND e.ua_class <> 'bot') AS reached_rsvp,
+	       COUNT(*) FILTER (WHERE e.event = 'view' AND e.ua_class <> 'bot') AS views,
+	       COUNT(*) FILTER (WHERE e.event = 'view' AND e.ua_class = 'bot') AS bot_views
+	FROM invitation_events e
+	WHERE e.invitation_uuid = i.uuid`
+
+type PostgresEngagementRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresEngagementRepository(pool *pgxpool.Pool) *PostgresEngagementRepository {
+	return &PostgresEngagementRepository{pool: pool}
+}
+
+func (r *PostgresEngagementRepository) AddEngagementEvents(events []domain.EngagementEvent) error {
+	// The beacon is public, so the uuid is only checked here: inserting
+	// through a SELECT skips unknown invitations instead of failing the
+	// whole batch on the foreign key.
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		batch.Queue(`
+			INSERT INTO invitation_events (invitation_uuid, event, occurred_at, visitor_hash, ua_class)
+			SELECT uuid, $2, $3, $4, $5 FROM invitations WHERE uuid = $1
+		`, e.InvitationUUID, e.Event, e.OccurredAt, e.VisitorHash, e.UAClass)
+	}
+	return r.pool.SendBatch(context.Background(), batch).Close()
+}
+
+func (r *PostgresEngagementRepository) GetFunnel(invitationUUID string) (*domain.EngagementFunnel, error) {
+	var f domain.EngagementFunnel
+	err := r.pool.QueryRow(context.Background(), `
+		SELECT f.opened, f.reached_story, f.reached_location, f.reached_rsvp, f.views, f.bot_views,
+		       COALESCE(c.responses, 0)
+		FROM invitations i
+		CROSS JOIN LATERAL (`+engagementFunnel+`) f
+		LEFT JOIN invitation_rsvp_counters c ON c.invitation_uuid = i.uuid
+		WHERE i.uuid = $1
+	`, invitationUUID).Scan(&f.Opened, &f.ReachedStory, &f.ReachedLocation, &f.ReachedRSVP, &f.Views, &f.BotViews, &f.Responded)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresGuestRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresGuestRepository(pool *pgxpool.Pool) *PostgresGuestRepository {
+	return &PostgresGuestRepository{pool: pool}
+}
+
+func (r *PostgresGuestRepository) ListGuests(invitationUUID string) ([]domain.Guest, error) {
+	rows, err := r.pool.Query(context.Background(), `
+		SELECT id, invitation_uuid, name, phone, party_size, group_name, note, created_at
+		FROM guests WHERE invitation_uuid = $1
+		ORDER BY id
+	`, invitationUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.Guest{}
+	for rows.Next() {
+		var g domain.Guest
+		if err := rows.Scan(&g.ID, &g.InvitationUUID, &g.Name, &g.Phone, &g.PartySize, &g.Group, &g.Note, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *PostgresGuestRepository) AddGuests(guests []domain.Guest) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, g := range guests {
+		batch.Queue(`
+			INSERT INTO guests (invitation_uuid, name, phone, party_size, group_name, note)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, g.InvitationUUID, g.Name, g.Phone, g.PartySize, g.Group, g.Note)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
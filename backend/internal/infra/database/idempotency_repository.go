@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresIdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresIdempotencyRepository(pool *pgxpool.Pool) *PostgresIdempotencyRepository {
+	return &PostgresIdempotencyRepository{pool: pool}
+}
+
+func (r *PostgresIdempotencyRepository) Reserve(key, token, requestHash string, expiredBefore, staleBefore time.Time) (*domain.IdempotencyRecord, error) {
+	ctx := context.Background()
+	if _, err := r.pool.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE created_at < $1 OR (status_code = 0 AND created_at < $2)
+	`, expiredBefore, staleBefore); err != nil {
+		return nil, err
+	}
+
+	// The key may be released between the insert and the select, in which
+	// case it is free again and the insert is retried.
+	for {
+		tag, err := r.pool.Exec(ctx, `
+			INSERT INTO idempotency_keys (key, token, request_hash) VALUES ($1, $2, $3)
+			ON CONFLICT (key) DO NOTHING
+		`, key, token, requestHash)
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 1 {
+			return nil, nil
+		}
+
+		var rec domain.IdempotencyRecord
+		err = r.pool.QueryRow(ctx, `
+			SELECT key, request_hash, status_code, content_type, COALESCE(response, ''::bytea), created_at
+			FROM idempotency_keys WHERE key = $1
+		`, key).Scan(&rec.Key, &rec.RequestHash, &rec.StatusCode, &rec.ContentType, &rec.Body, &rec.CreatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &rec, nil
+	}
+}
+
+func (r *PostgresIdempotencyRepository) Complete(key, token string, statusCode int, contentType string, body []byte) error {
+	_, err := r.pool.Exec(context.Background(), `
+		UPDATE idempotency_keys SET status_code = $3, content_type = $4, response = $5
+		WHERE key = $1 AND token = $2 AND status_code = 0
+	`, key, token, statusCode, contentType, body)
+	return err
+}
+
+func (r *PostgresIdempotencyRepository) Release(key, token string) error {
+	_, err := r.pool.Exec(context.Background(),
+		"DELETE FROM idempotency_keys WHERE key = $1 AND token = $2 AND status_code = 0", key, token)
+	return err
+}
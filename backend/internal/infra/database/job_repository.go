@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresJobRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresJobRepository(pool *pgxpool.Pool) *PostgresJobRepository {
+	return &PostgresJobRepository{pool: pool}
+}
+
+const jobRunColumns = `id, job, scheduled_for, manual, started_at, finished_at, status, affected, error`
+
+func scanJobRun(row pgx.Row) (*domain.JobRun, error) {
+	var run domain.JobRun
+	err := row.Scan(&run.ID, &run.Job, &run.ScheduledFor, &run.Manual, &run.StartedAt, &run.FinishedAt,
+		&run.Status, &run.Affected, &run.Error)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *PostgresJobRepository) JobFlags() (map[string]bool, error) {
+	rows, err := r.pool.Query(context.Background(), "SELECT name, enabled FROM scheduled_jobs")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := map[string]bool{}
+	for rows.Next() {
+		var name string
+		var enabled bool
+		if err := rows.Scan(&name, &enabled); err != nil {
+			return nil, err
+		}
+		flags[name] = enabled
+	}
+	return flags, rows.Err()
+}
+
+func (r *PostgresJobRepository) SetJobEnabled(job string, enabled bool) error {
+	_, err := r.pool.Exec(context.Background(), `
+		INSERT INTO scheduled_jobs (name, enabled) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = CURRENT_TIMESTAMP
+	`, job, enabled)
+	return err
+}
+
+// TryJobLock holds a session advisory lock on a connection of its own for
+// as long as the job runs. A connection that fails to unlock is closed
+// rather than handed back to the pool still holding the lock.
+func (r *PostgresJobRepository) TryJobLock(job string) (func(), bool, error) {
+	ctx := context.Background()
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var ok bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", "job:"+job).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+	unlock := func() {
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_unlock(hashtext($1))", "job:"+job); err != nil {
+			log.Printf("jobs: unlocking %s: %v", job, err)
+			conn.Conn().Close(ctx)
+		}
+		conn.Release()
+	}
+	return unlock, true, nil
+}
+
+func (r *PostgresJobRepository) StartJobRun(job string, scheduledFor time.Time, manual bool) (*domain.JobRun, bool, error) {
+	run, err := scanJobRun(r.pool.QueryRow(context.Background(), `
+		INSERT INTO job_runs (job, scheduled_for, manual) VALUES ($1, $2, $3)
+		ON CONFLICT (job, scheduled_for) DO NOTHING
+		RETURNING `+jobRunColumns, job, scheduledFor, manual))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return run, true, nil
+}
+
+func (r *PostgresJobRepository) FinishJobRun(run *domain.JobRun) error {
+	return r.pool.QueryRow(context.Background(), `
+		UPDATE job_runs SET finished_at = CURRENT_TIMESTAMP, status = $2, affected = $3, error = $4
+		WHERE id = $1
+		RETURNING finished_at
+	`, run.ID, run.Status, run.Affected, run.Error).Scan(&run.FinishedAt)
+}
+
+func (r *PostgresJobRepository) ListJobRuns(job string, limit int) ([]domain.JobRun, error) {
+	rows, err := r.pool.Query(context.Background(),
+		"SELECT "+jobRunColumns+" FROM job_runs WHERE job = $1 ORDER BY scheduled_for DESC LIMIT $2", job, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.JobRun{}
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *run)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresJobRepository) LastJobRuns() (map[string]domain.JobRun, error) {
+	rows, err := r.pool.Query(context.Background(),
+		"SELECT DISTINCT ON (job) "+jobRunColumns+" FROM job_runs ORDER BY job, scheduled_for DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	last := map[string]domain.JobRun{}
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		last[run.Job] = *run
+	}
+	return last, rows.Err()
+}
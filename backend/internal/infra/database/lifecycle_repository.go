@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresLifecycleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresLifecycleRepository(pool *pgxpool.Pool) *PostgresLifecycleRepository {
+	return &PostgresLifecycleRepository{pool: pool}
+}
+
+// ExpireInvitations moves both ways in one statement: an invitation that
+// was extended or paid after expiring comes back as active, and the
+// post-event job picks it up again if its day is over. The ones that
+// expire get an invitation.expired outbox row in the same statement.
+func (r *PostgresLifecycleRepository) ExpireInvitations(now time.Time) (int, error) {
+	var n int
+	err := r.pool.QueryRow(context.Background(), `
+		WITH i AS (
+			UPDATE invitations i SET
+			       lifecycle = CASE WHEN i.lifecycle = 'expired' THEN 'active' ELSE 'expired' END,
+			       lifecycle_changed_at = CURRENT_TIMESTAMP
+			WHERE (i.lifecycle <> 'expired' AND `+endsAt+` < $1)
+			   OR (i.lifecycle = 'expired' AND COALESCE(`+endsAt+` >= $1, true))
+			RETURNING i.*
+		), events AS (`+insertWebhookEvent(domain.WebhookInvitationExpired, "", "i", "i.lifecycle = 'expired'")+`
+		)
+		SELECT COUNT(*) FROM i
+	`, now).Scan(&n)
+	return n, err
+}
+
+func (r *PostgresLifecycleRepository) MarkPostEvent(day time.Time) (int, error) {
+	tag, err := r.pool.Exec(context.Background(), `
+		UPDATE invitations SET lifecycle = 'post_event', lifecycle_changed_at = CURRENT_TIMESTAMP
+		WHERE lifecycle = 'active' AND event_on < $1
+	`, day)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// PurgeExpiredTrials keeps trials that guests already answered: those are
+// not abandoned, and their responses are worth a follow-up. It also keeps
+// trials with orders, as a payment may still arrive for one and its order
+// and payment events would cascade away. The other tables cascade.
+func (r *PostgresLifecycleRepository) PurgeExpiredTrials(cutoff time.Time) (int, error) {
+	tag, err := r.pool.Exec(context.Background(), `
+		DELETE FROM invitations
+		WHERE NOT is_paid AND lifecycle = 'expired' AND expires_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM rsvp_responses r WHERE r.invitation_uuid = invitations.uuid)
+		  AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.invitation_uuid = invitations.uuid)
+	`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
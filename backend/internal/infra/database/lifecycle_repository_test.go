@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/migrations"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPool connects to the migrated database at TEST_DATABASE_URL and
+// skips the test when it is not set.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("pgx", dbURL)
+	require.NoError(t, err)
+	defer db.Close()
+	goose.SetBaseFS(migrations.FS)
+	require.NoError(t, goose.SetDialect("postgres"))
+	require.NoError(t, goose.Up(db, "."))
+
+	pool, err := pgxpool.New(context.Background(), dbURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestPurgeExpiredTrials(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	invRepo := NewPostgresInvitationRepository(pool)
+
+	expired := time.Now().Add(-60 * 24 * time.Hour)
+	trial := func() string {
+		inv := &domain.Invitation{UUID: uuid.New().String(), PhoneNumber: "+77011234567", TemplateCode: "starry-night",
+			Lang: "ru", ShortCode: "purge-" + uuid.New().String()[:8], Content: map[string]interface{}{}, ExpiresAt: &expired}
+		require.NoError(t, invRepo.Create(inv))
+		_, err := pool.Exec(ctx, "UPDATE invitations SET lifecycle = 'expired' WHERE uuid = $1", inv.UUID)
+		require.NoError(t, err)
+		return inv.UUID
+	}
+	abandoned, answered, ordered := trial(), trial(), trial()
+	_, err := pool.Exec(ctx, "INSERT INTO rsvp_responses (invitation_uuid, guest_name, attendance) VALUES ($1, 'Dana', 'yes')", answered)
+	require.NoError(t, err)
+	// A payment for this order may still arrive.
+	_, err = pool.Exec(ctx, "INSERT INTO orders (id, invitation_uuid, provider, amount, currency) VALUES ($1, $2, 'hmac', 990000, 'KZT')",
+		uuid.New().String(), ordered)
+	require.NoError(t, err)
+
+	_, err = NewPostgresLifecycleRepository(pool).PurgeExpiredTrials(time.Now().Add(-30 * 24 * time.Hour))
+	require.NoError(t, err)
+
+	exists := func(id string) bool {
+		var ok bool
+		require.NoError(t, pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM invitations WHERE uuid = $1)", id).Scan(&ok))
+		return ok
+	}
+	assert.False(t, exists(abandoned))
+	assert.True(t, exists(answered))
+	assert.True(t, exists(ordered))
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresNotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresNotificationRepository(pool *pgxpool.Pool) *PostgresNotificationRepository {
+	return &PostgresNotificationRepository{pool: pool}
+}
+
+// notificationColumns read a notification off n and its queue job q: a
+// notification the queue gave up on is failed.
+const notificationColumns = `n.id, n.invitation_uuid, n.channel, n.template, n.lang, n.recipient, n.subject, n.body,
+	CASE WHEN n.sent_at IS NOT NULL THEN 'sent' WHEN q.status = 'dead' THEN 'failed' ELSE 'queued' END,
+	n.provider_id, n.error, n.attempts, n.created_at, n.sent_at`
+
+const notificationFrom = `notifications n LEFT JOIN queue_jobs q ON q.id = n.queue_job_id`
+
+func scanNotification(row pgx.Row) (*domain.Notification, error) {
+	var n domain.Notification
+	err := row.Scan(&n.ID, &n.InvitationUUID, &n.Channel, &n.Template, &n.Lang, &n.Recipient, &n.Subject, &n.Body,
+		&n.Status, &n.ProviderID, &n.Error, &n.Attempts, &n.CreatedAt, &n.SentAt)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// CreateNotification draws the notification id first so the queue job can
+// carry it, and writes both rows in one statement.
+func (r *PostgresNotificationRepository) CreateNotification(n *domain.Notification, job domain.QueueJob) error {
+	var id int64
+	err := r.pool.QueryRow(context.Background(), `
+		WITH d AS (
+			SELECT nextval(pg_get_serial_sequence('notifications', 'id')) AS id
+		), q AS (
+			INSERT INTO queue_jobs (kind, payload, max_attempts)
+			SELECT $1::text, jsonb_build_object('notificationId', d.id), $2::int FROM d
+			RETURNING id
+		)
+		INSERT INTO notifications (id, invitation_uuid, channel, template, lang, recipient, subject, body, queue_job_id)
+		SELECT d.id, $3, $4, $5, $6, $7, $8, $9, q.id FROM d, q
+		RETURNING id
+	`, job.Kind, job.MaxAttempts, n.InvitationUUID, n.Channel, n.Template, n.Lang, n.Recipient, n.Subject, n.Body).Scan(&id)
+	if err != nil {
+		return err
+	}
+	created, err := r.GetNotification(id)
+	if err != nil {
+		return err
+	}
+	*n = *created
+	return nil
+}
+
+func (r *PostgresNotificationRepository) GetNotification(id int64) (*domain.Notification, error) {
+	n, err := scanNotification(r.pool.QueryRow(context.Background(),
+		"SELECT "+notificationColumns+" FROM "+notificationFrom+" WHERE n.id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrNotificationNotFound
+	}
+	return n, err
+}
+
+func (r *PostgresNotificationRepository) RecordNotificationAttempt(n *domain.Notification) error {
+	return r.pool.QueryRow(context.Background(), `
+		UPDATE notifications SET attempts = attempts + 1, provider_id = $2, error = $3,
+		       last_attempt_at = CURRENT_TIMESTAMP,
+		       sent_at = CASE WHEN $4 THEN CURRENT_TIMESTAMP ELSE sent_at END
+		WHERE id = $1
+		RETURNING attempts, sent_at
+	`, n.ID, n.ProviderID, n.Error, n.Status == domain.NotificationSent).Scan(&n.Attempts, &n.SentAt)
+}
+
+func (r *PostgresNotificationRepository) ListNotifications(invitationUUID string) ([]domain.Notification, error) {
+	rows, err := r.pool.Query(context.Background(), "SELECT "+notificationColumns+" FROM "+notificationFrom+`
+		WHERE n.invitation_uuid = $1
+		ORDER BY n.created_at DESC, n.id DESC`, invitationUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *n)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresOrderRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresOrderRepository(pool *pgxpool.Pool) *PostgresOrderRepository {
+	return &PostgresOrderRepository{pool: pool}
+}
+
+const orderColumns = `id, invitation_uuid, provider, provider_ref, amount, currency, status, payment_url,
+	COALESCE(plan_code, ''), COALESCE(promo_code, ''), list_price, discount, hosting_days, created_at, paid_at`
+
+func scanOrder(row pgx.Row) (*domain.Order, error) {
+	var o domain.Order
+	err := row.Scan(&o.ID, &o.InvitationUUID, &o.Provider, &o.ProviderRef, &o.Amount, &o.Currency,
+		&o.Status, &o.PaymentURL, &o.PlanCode, &o.PromoCode, &o.ListPrice, &o.Discount, &o.HostingDays,
+		&o.CreatedAt, &o.PaidAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *PostgresOrderRepository) CreateOrder(order *domain.Order) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if order.PromoCode != "" {
+		tag, err := tx.Exec(ctx, `
+			UPDATE promo_codes SET used_count = used_count + 1
+			WHERE code = $1 AND is_active
+			  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			  AND (max_uses IS NULL OR used_count < max_uses)
+		`, order.PromoCode)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrPromoCodeUnavailable
+		}
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO orders (id, invitation_uuid, provider, provider_ref, amount, currency, status, payment_url,
+		                    plan_code, promo_code, list_price, discount, hosting_days, promo_redeemed)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, $12, $13, $10 <> '')
+		RETURNING created_at
+	`, order.ID, order.InvitationUUID, order.Provider, order.ProviderRef, order.Amount, order.Currency,
+		order.Status, order.PaymentURL, order.PlanCode, order.PromoCode, order.ListPrice, order.Discount,
+		order.HostingDays).Scan(&order.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresOrderRepository) GetOrder(id string) (*domain.Order, error) {
+	return scanOrder(r.pool.QueryRow(context.Background(), "SELECT "+orderColumns+" FROM orders WHERE id = $1", id))
+}
+
+func (r *PostgresOrderRepository) ListOrders(invitationUUID string) ([]domain.Order, error) {
+	rows, err := r.pool.Query(context.Background(),
+		"SELECT "+orderColumns+" FROM orders WHERE invitation_uuid = $1 ORDER BY created_at, id", invitationUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.Order{}
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// paidOrderEvent is the invitation.paid outbox row of the invitation i
+// updated for a paid order; renewals are reported too.
+var paidOrderEvent = insertWebhookEvent(domain.WebhookInvitationPaid,
+	"jsonb_build_object('orderId', i.paid_order_id)", "i", "")
+
+// ApplyPaymentEvent claims the event id first: a redelivery that races the
+// original waits on the primary key and then finds it taken. The order row
+// is locked before it moves, so two different events of one order apply
+// one after the other.
+func (r *PostgresOrderRepository) ApplyPaymentEvent(ev *domain.PaymentEvent) (*domain.Order, bool, error) {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO payment_events (provider, event_id, order_id, status, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (provider, event_id) DO NOTHING
+	`, ev.Provider, ev.EventID, ev.OrderID, ev.Status, string(ev.Payload))
+	if err != nil {
+		return nil, false, err
+	}
+	applied := tag.RowsAffected() == 1
+
+	var status string
+	var redeemed bool
+	err = tx.QueryRow(ctx, "SELECT status, promo_redeemed FROM orders WHERE id = $1 FOR UPDATE", ev.OrderID).Scan(&status, &redeemed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, domain.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if applied && status != domain.OrderPaid && status != ev.Status {
+		batch := &pgx.Batch{}
+		batch.Queue(`
+			UPDATE orders SET status = $2, updated_at = CURRENT_TIMESTAMP,
+			       paid_at = CASE WHEN $2 = 'paid' THEN CURRENT_TIMESTAMP END
+			WHERE id = $1
+		`, ev.OrderID, ev.Status)
+		// A failed or cancelled order gives its promo code use back; if it
+		// is paid after all, the use is taken again whatever the limit.
+		switch {
+		case redeemed && ev.Status != domain.OrderPaid:
+			batch.Queue(`
+				UPDATE promo_codes SET used_count = used_count - 1
+				WHERE code = (SELECT promo_code FROM orders WHERE id = $1)
+			`, ev.OrderID)
+			batch.Queue("UPDATE orders SET promo_redeemed = false WHERE id = $1", ev.OrderID)
+		case !redeemed && ev.Status == domain.OrderPaid:
+			batch.Queue(`
+				UPDATE promo_codes SET used_count = used_count + 1
+				WHERE code = (SELECT promo_code FROM orders WHERE id = $1)
+			`, ev.OrderID)
+			batch.Queue("UPDATE orders SET promo_redeemed = promo_code IS NOT NULL WHERE id = $1", ev.OrderID)
+		}
+		if ev.Status == domain.OrderPaid {
+			// Hosting runs on from where it ends, or from now once over.
+			batch.Queue(`
+				WITH i AS (
+					UPDATE invitations i SET is_paid = true, updated_at = CURRENT_TIMESTAMP,
+					       plan_code = COALESCE(o.plan_code, i.plan_code),
+					       hosting_until = CASE WHEN o.hosting_days > 0
+					           THEN GREATEST(i.hosting_until, CURRENT_TIMESTAMP) + o.hosting_days * INTERVAL '1 day'
+					           ELSE i.hosting_until END
+					FROM orders o
+					WHERE o.id = $1 AND i.uuid = o.invitation_uuid
+					RETURNING i.*, o.id AS paid_order_id
+				)`+paidOrderEvent, ev.OrderID)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	order, err := scanOrder(tx.QueryRow(ctx, "SELECT "+orderColumns+" FROM orders WHERE id = $1", ev.OrderID))
+	if err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+	return order, applied, nil
+}
@@ -2,7 +2,13 @@ package database
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
 )
@@ -15,47 +21,132 @@ func NewPostgresInvitationRepository(pool *pgxpool.Pool) *PostgresInvitationRepo
 	return &PostgresInvitationRepository{pool: pool}
 }
 
-func (r *PostgresInvitationRepository) GetByUUID(uuid string) (*domain.Invitation, error) {
+const invitationColumns = `uuid, phone_number, template_code, lang, content, groom_name, bride_name, event_date, event_location, short_code, COALESCE(plan_code, ''), is_paid, expires_at, hosting_until, lifecycle`
+
+func scanInvitation(row pgx.Row) (*domain.Invitation, error) {
 	var i domain.Invitation
-	err := r.pool.QueryRow(context.Background(), `
-		SELECT uuid, phone_number, template_code, lang, content, groom_name, bride_name, event_date, event_location, short_code, is_paid, expires_at
-		FROM invitations WHERE uuid = $1
-	`, uuid).Scan(&i.UUID, &i.PhoneNumber, &i.TemplateCode, &i.Lang, &i.Content, &i.GroomName, &i.BrideName, &i.EventDate, &i.EventLocation, &i.ShortCode, &i.IsPaid, &i.ExpiresAt)
+	err := row.Scan(&i.UUID, &i.PhoneNumber, &i.TemplateCode, &i.Lang, &i.Content, &i.GroomName, &i.BrideName, &i.EventDate, &i.EventLocation, &i.ShortCode, &i.PlanCode, &i.IsPaid, &i.ExpiresAt, &i.HostingUntil, &i.Lifecycle)
 	if err != nil {
 		return nil, err
 	}
 	return &i, nil
 }
 
+func (r *PostgresInvitationRepository) GetByUUID(uuid string) (*domain.Invitation, error) {
+	return scanInvitation(r.pool.QueryRow(context.Background(), "SELECT "+invitationColumns+" FROM invitations WHERE uuid = $1", uuid))
+}
+
 func (r *PostgresInvitationRepository) GetByShortCode(code string) (*domain.Invitation, error) {
-	var i domain.Invitation
-	err := r.pool.QueryRow(context.Background(), `
-		SELECT uuid, phone_number, template_code, lang, content, groom_name, bride_name, event_date, event_location, short_code, is_paid, expires_at
-		FROM invitations WHERE short_code = $1
-	`, code).Scan(&i.UUID, &i.PhoneNumber, &i.TemplateCode, &i.Lang, &i.Content, &i.GroomName, &i.BrideName, &i.EventDate, &i.EventLocation, &i.ShortCode, &i.IsPaid, &i.ExpiresAt)
-	if err != nil {
-		return nil, err
+	return scanInvitation(r.pool.QueryRow(context.Background(), "SELECT "+invitationColumns+" FROM invitations WHERE short_code = $1", code))
+}
+
+const insertInvitation = `
+	INSERT INTO invitations (uuid, phone_number, template_code, lang, content, groom_name, bride_name, event_date, event_location, short_code, is_paid, expires_at, event_on, plan_code)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''))
+`
+
+func insertInvitationArgs(inv *domain.Invitation) []interface{} {
+	// event_on is the parsed day of the free-form event_date, for filtering
+	// and sorting the admin list.
+	var eventOn *time.Time
+	if t, ok := inv.EventTime(); ok {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		eventOn = &day
 	}
-	return &i, nil
+	return []interface{}{inv.UUID, inv.PhoneNumber, inv.TemplateCode, inv.Lang, inv.Content, inv.GroomName, inv.BrideName, inv.EventDate, inv.EventLocation, inv.ShortCode, inv.IsPaid, inv.ExpiresAt, eventOn, inv.PlanCode}
 }
 
+// insertedInvitationEvent is the outbox row of a new invitation, queued
+// after its insert.
+var insertedInvitationEvent = insertWebhookEvent(domain.WebhookInvitationCreated, "", "invitations i", "i.uuid = $1")
+
 func (r *PostgresInvitationRepository) Create(inv *domain.Invitation) error {
-	_, err := r.pool.Exec(context.Background(), `
-		INSERT INTO invitations (uuid, phone_number, template_code, lang, content, groom_name, bride_name, event_date, event_location, short_code, is_paid, expires_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
-	`, inv.UUID, inv.PhoneNumber, inv.TemplateCode, inv.Lang, inv.Content, inv.GroomName, inv.BrideName, inv.EventDate, inv.EventLocation, inv.ShortCode, inv.IsPaid, inv.ExpiresAt)
+	return r.CreateMany([]*domain.Invitation{inv})
+}
+
+func (r *PostgresInvitationRepository) CreateMany(invs []*domain.Invitation) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, inv := range invs {
+		batch.Queue(insertInvitation, insertInvitationArgs(inv)...)
+		batch.Queue(insertedInvitationEvent, inv.UUID)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return insertError(err)
+	}
+	return tx.Commit(ctx)
+}
+
+// insertError reports a clash on the unique short_code as
+// domain.ErrShortCodeTaken so callers can retry or ask for another slug,
+// and a plan_code not in plans as domain.ErrUnknownPlan.
+func insertError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch {
+	case pgErr.Code == "23505" && strings.Contains(pgErr.ConstraintName, "short_code"):
+		return domain.ErrShortCodeTaken
+	case pgErr.Code == "23503" && strings.Contains(pgErr.ConstraintName, "plan_code"):
+		return domain.ErrUnknownPlan
+	}
 	return err
 }
 
+func (r *PostgresInvitationRepository) ShortCodeExists(code string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(context.Background(),
+		"SELECT EXISTS (SELECT 1 FROM invitations WHERE short_code = $1)", code).Scan(&exists)
+	return exists, err
+}
+
+// markAsPaid only touches an unpaid invitation, so that the outbox gets an
+// invitation.paid event for the payment and not for a repeat.
+var markAsPaid = `
+	WITH i AS (UPDATE invitations SET is_paid = true WHERE uuid = $1 AND NOT is_paid RETURNING *)
+` + insertWebhookEvent(domain.WebhookInvitationPaid, "", "i", "")
+
 func (r *PostgresInvitationRepository) MarkAsPaid(uuid string) error {
-	_, err := r.pool.Exec(context.Background(), "UPDATE invitations SET is_paid = true WHERE uuid = $1", uuid)
+	_, err := r.pool.Exec(context.Background(), markAsPaid, uuid)
 	return err
 }
 
-func (r *PostgresInvitationRepository) AddRSVP(rsvp *domain.RSVPResponse) error {
-	_, err := r.pool.Exec(context.Background(),
-		`INSERT INTO rsvp_responses (invitation_uuid, guest_name, attendance, guest_count) VALUES ($1, $2, $3, $4)`,
-		rsvp.InvitationUUID, rsvp.GuestName, rsvp.Attendance, rsvp.GuestCount)
+// SetExpiresAt checks is_paid in the same statement, so a payment landing
+// meanwhile is not undone by a trial extension.
+func (r *PostgresInvitationRepository) SetExpiresAt(uuid string, expiresAt time.Time) error {
+	var paid bool
+	err := r.pool.QueryRow(context.Background(), `
+		UPDATE invitations SET
+		       expires_at = CASE WHEN is_paid THEN expires_at ELSE $2 END,
+		       updated_at = CASE WHEN is_paid THEN updated_at ELSE CURRENT_TIMESTAMP END
+		WHERE uuid = $1
+		RETURNING is_paid
+	`, uuid, expiresAt).Scan(&paid)
+	if err == nil && paid {
+		return domain.ErrInvitationPaid
+	}
+	return err
+}
+
+func (r *PostgresInvitationRepository) SetHostingUntil(uuid string, hostingUntil *time.Time) error {
+	var paid bool
+	err := r.pool.QueryRow(context.Background(), `
+		UPDATE invitations SET
+		       hosting_until = CASE WHEN is_paid THEN $2 ELSE hosting_until END,
+		       updated_at = CASE WHEN is_paid THEN CURRENT_TIMESTAMP ELSE updated_at END
+		WHERE uuid = $1
+		RETURNING is_paid
+	`, uuid, hostingUntil).Scan(&paid)
+	if err == nil && !paid {
+		return domain.ErrInvitationNotPaid
+	}
 	return err
 }
 
@@ -67,54 +158,164 @@ func NewPostgresAdminRepository(pool *pgxpool.Pool) *PostgresAdminRepository {
 	return &PostgresAdminRepository{pool: pool}
 }
 
+// GetStats reads the RSVP totals off the counters rather than the
+// responses, so it costs the same however many guests answer.
 func (r *PostgresAdminRepository) GetStats() (*domain.AdminStats, error) {
 	var s domain.AdminStats
-	if err := r.pool.QueryRow(context.Background(), "SELECT COUNT(*) FROM invitations").Scan(&s.TotalInvitations); err != nil {
+	err := r.pool.QueryRow(context.Background(), `
+		SELECT (SELECT COUNT(*) FROM invitations),
+		       COALESCE(SUM(responses), 0), COALESCE(SUM(guests), 0)
+		FROM invitation_rsvp_counters
+	`).Scan(&s.TotalInvitations, &s.TotalRSVPs, &s.TotalGuests)
+	if err != nil {
 		return nil, err
 	}
-	if err := r.pool.QueryRow(context.Background(), "SELECT COUNT(*) FROM rsvp_responses").Scan(&s.TotalRSVPs); err != nil {
+	return &s, nil
+}
+
+func (r *PostgresAdminRepository) GetInvitationsList(filter domain.InvitationFilter) (*domain.InvitationPage, error) {
+	page := &domain.InvitationPage{Items: []domain.InvitationWithStats{}, Limit: filter.Limit, Offset: filter.Offset}
+	where, args := invitationWhere(filter)
+	if err := r.pool.QueryRow(context.Background(), "SELECT COUNT(*) FROM invitations i "+where, args...).Scan(&page.Total); err != nil {
 		return nil, err
 	}
-	if err := r.pool.QueryRow(context.Background(), "SELECT COALESCE(SUM(guest_count), 0) FROM rsvp_responses WHERE attendance = 'yes'").Scan(&s.TotalGuests); err != nil {
+	if page.Total <= filter.Offset {
+		return page, nil
+	}
+	err := r.EachInvitation(filter, func(i *domain.InvitationWithStats) error {
+		page.Items = append(page.Items, *i)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return &s, nil
+	return page, nil
 }
 
-func (r *PostgresAdminRepository) GetInvitationsList() ([]domain.InvitationWithStats, error) {
+func (r *PostgresAdminRepository) EachInvitation(filter domain.InvitationFilter, fn func(*domain.InvitationWithStats) error) error {
+	where, args := invitationWhere(filter)
+	order := invitationOrder(filter)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		order += " LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+	}
 	rows, err := r.pool.Query(context.Background(), `
 		SELECT 
-            i.uuid, i.phone_number, i.template_code, t.name_ru, i.lang, COALESCE(i.short_code, ''),
-            i.is_paid, i.expires_at,
-            COALESCE((SELECT COUNT(*) FROM rsvp_responses r WHERE r.invitation_uuid = i.uuid), 0) as rsvp_count,
-            COALESCE((SELECT SUM(guest_count) FROM rsvp_responses r WHERE r.invitation_uuid = i.uuid AND r.attendance = 'yes'), 0) as approved_guests
+            i.uuid, i.phone_number, i.template_code, t.name_ru, i.lang, COALESCE(i.short_code, ''), COALESCE(i.plan_code, ''),
+            COALESCE(i.groom_name, ''), COALESCE(i.bride_name, ''), COALESCE(i.event_date, ''),
+            i.is_paid, i.expires_at, i.hosting_until, i.lifecycle, i.created_at,
+            COALESCE(c.responses, 0), COALESCE(c.attending, 0), COALESCE(c.declined, 0), COALESCE(c.maybe, 0),
+            COALESCE(c.guests, 0),
+            f.opened, f.reached_story, f.reached_location, f.reached_rsvp, f.views, f.bot_views
         FROM invitations i
         LEFT JOIN templates t ON i.template_code = t.code
-        ORDER BY i.created_at DESC
-	`)
+        LEFT JOIN invitation_rsvp_counters c ON c.invitation_uuid = i.uuid
+        CROSS JOIN LATERAL (`+engagementFunnel+`) f
+        `+where+`
+        `+order+`
+	`, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	list := []domain.InvitationWithStats{}
 	for rows.Next() {
 		var i domain.InvitationWithStats
 		var templateName *string
-		if err := rows.Scan(&i.UUID, &i.PhoneNumber, &i.TemplateCode, &templateName, &i.Lang, &i.ShortCode, &i.IsPaid, &i.ExpiresAt, &i.RSVPCount, &i.ApprovedGuests); err != nil {
-			return nil, err
+		if err := rows.Scan(&i.UUID, &i.PhoneNumber, &i.TemplateCode, &templateName, &i.Lang, &i.ShortCode, &i.PlanCode,
+			&i.GroomName, &i.BrideName, &i.EventDate,
+			&i.IsPaid, &i.ExpiresAt, &i.HostingUntil, &i.Lifecycle, &i.CreatedAt,
+			&i.RSVPCount, &i.Attending, &i.Declined, &i.Maybe, &i.ApprovedGuests,
+			&i.Funnel.Opened, &i.Funnel.ReachedStory, &i.Funnel.ReachedLocation, &i.Funnel.ReachedRSVP,
+			&i.Funnel.Views, &i.Funnel.BotViews); err != nil {
+			return err
 		}
+		i.Funnel.Responded = i.RSVPCount
 		if templateName != nil {
 			i.TemplateName = *templateName
 		} else {
 			i.TemplateName = i.TemplateCode
 		}
-		list = append(list, i)
+		if err := fn(&i); err != nil {
+			return err
+		}
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return rows.Err()
+}
+
+// invitationWhere builds the WHERE clause of the admin list for filter.
+// endsAt is when an invitation goes offline, as Invitation.EndsAt;
+// migration 19 indexes it.
+const endsAt = "(CASE WHEN i.is_paid THEN i.hosting_until ELSE i.expires_at END)"
+
+func invitationWhere(filter domain.InvitationFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
 	}
-	return list, nil
+
+	if filter.Paid != nil {
+		conds = append(conds, "i.is_paid = "+arg(*filter.Paid))
+	}
+	if filter.Expired != nil {
+		expired := "COALESCE(" + endsAt + " < NOW(), false)"
+		if !*filter.Expired {
+			expired = "NOT " + expired
+		}
+		conds = append(conds, expired)
+	}
+	if filter.TemplateCode != "" {
+		conds = append(conds, "i.template_code = "+arg(filter.TemplateCode))
+	}
+	if filter.Lang != "" {
+		conds = append(conds, "i.lang = "+arg(filter.Lang))
+	}
+	if filter.EventFrom != nil {
+		conds = append(conds, "i.event_on >= "+arg(*filter.EventFrom))
+	}
+	if filter.EventTo != nil {
+		conds = append(conds, "i.event_on <= "+arg(*filter.EventTo))
+	}
+	if filter.Search != "" {
+		conds = append(conds, invitationSearchText+" LIKE "+arg("%"+likeEscaper.Replace(strings.ToLower(filter.Search))+"%"))
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// invitationSearchText is what the admin search looks in. It must match the
+// expression of idx_invitations_search for the trigram index to be used.
+const invitationSearchText = `lower(COALESCE(i.groom_name, '') || ' ' || COALESCE(i.bride_name, '') || ' ' || i.phone_number || ' ' || COALESCE(i.short_code, ''))`
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// invitationSortColumns maps the domain sort keys to columns; anything else
+// sorts by creation time.
+var invitationSortColumns = map[string]string{
+	domain.InvitationSortCreated: "i.created_at",
+	domain.InvitationSortEvent:   "i.event_on",
+	domain.InvitationSortExpires: "i.expires_at",
+	domain.InvitationSortGroom:   "i.groom_name",
+	domain.InvitationSortBride:   "i.bride_name",
+}
+
+// invitationOrder builds the ORDER BY clause of the admin list. Newest
+// first is the default; ties fall back to the id so pages are stable.
+func invitationOrder(filter domain.InvitationFilter) string {
+	column, ok := invitationSortColumns[filter.Sort]
+	if !ok {
+		return "ORDER BY i.created_at DESC, i.id DESC"
+	}
+	dir := "ASC"
+	if filter.Desc {
+		dir = "DESC"
+	}
+	return "ORDER BY " + column + " " + dir + " NULLS LAST, i.id " + dir
 }
 
 func (r *PostgresAdminRepository) GetTemplates() ([]domain.Template, error) {
@@ -139,6 +340,31 @@ func (r *PostgresAdminRepository) GetTemplates() ([]domain.Template, error) {
 }
 
 func (r *PostgresAdminRepository) MarkAsPaid(uuid string) error {
-	_, err := r.pool.Exec(context.Background(), "UPDATE invitations SET is_paid = true WHERE uuid = $1", uuid)
+	_, err := r.pool.Exec(context.Background(), markAsPaid, uuid)
 	return err
 }
+
+func (r *PostgresAdminRepository) ListExpiring(from, to time.Time) ([]domain.ExpiringInvitation, error) {
+	rows, err := r.pool.Query(context.Background(), `
+		SELECT i.uuid, COALESCE(i.short_code, ''), i.phone_number, COALESCE(i.groom_name, ''), COALESCE(i.bride_name, ''), i.template_code,
+		       COALESCE(i.plan_code, ''), i.is_paid, `+endsAt+`
+		FROM invitations i
+		WHERE `+endsAt+` >= $1 AND `+endsAt+` < $2
+		ORDER BY `+endsAt+`, i.uuid
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.ExpiringInvitation{}
+	for rows.Next() {
+		var e domain.ExpiringInvitation
+		if err := rows.Scan(&e.UUID, &e.ShortCode, &e.PhoneNumber, &e.GroomName, &e.BrideName, &e.TemplateCode,
+			&e.PlanCode, &e.IsPaid, &e.EndsAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresPricingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresPricingRepository(pool *pgxpool.Pool) *PostgresPricingRepository {
+	return &PostgresPricingRepository{pool: pool}
+}
+
+const planColumns = `code, name_ru, name_kk, name_en, price, currency, features, hosting_days, is_active, sort_order`
+
+func scanPlan(row pgx.Row) (*domain.Plan, error) {
+	var p domain.Plan
+	err := row.Scan(&p.Code, &p.NameRu, &p.NameKk, &p.NameEn, &p.Price, &p.Currency, &p.Features, &p.HostingDays, &p.IsActive, &p.SortOrder)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUnknownPlan
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PostgresPricingRepository) ListPlans(activeOnly bool) ([]domain.Plan, error) {
+	rows, err := r.pool.Query(context.Background(),
+		"SELECT "+planColumns+" FROM plans WHERE is_active OR NOT $1 ORDER BY sort_order, price, code", activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.Plan{}
+	for rows.Next() {
+		p, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *PostgresPricingRepository) GetPlan(code string) (*domain.Plan, error) {
+	return scanPlan(r.pool.QueryRow(context.Background(), "SELECT "+planColumns+" FROM plans WHERE code = $1", code))
+}
+
+func (r *PostgresPricingRepository) SavePlan(p *domain.Plan) error {
+	_, err := r.pool.Exec(context.Background(), `
+		INSERT INTO plans (code, name_ru, name_kk, name_en, price, currency, features, hosting_days, is_active, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (code) DO UPDATE SET
+			name_ru = EXCLUDED.name_ru, name_kk = EXCLUDED.name_kk, name_en = EXCLUDED.name_en,
+			price = EXCLUDED.price, currency = EXCLUDED.currency, features = EXCLUDED.features,
+			hosting_days = EXCLUDED.hosting_days, is_active = EXCLUDED.is_active, sort_order = EXCLUDED.sort_order,
+			updated_at = CURRENT_TIMESTAMP
+	`, p.Code, p.NameRu, p.NameKk, p.NameEn, p.Price, p.Currency, p.Features, p.HostingDays, p.IsActive, p.SortOrder)
+	return err
+}
+
+const promoCodeColumns = `code, kind, value, COALESCE(currency, ''), max_uses, used_count, expires_at, is_active, created_at`
+
+func scanPromoCode(row pgx.Row) (*domain.PromoCode, error) {
+	var p domain.PromoCode
+	err := row.Scan(&p.Code, &p.Kind, &p.Value, &p.Currency, &p.MaxUses, &p.UsedCount, &p.ExpiresAt, &p.IsActive, &p.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PostgresPricingRepository) ListPromoCodes() ([]domain.PromoCode, error) {
+	rows, err := r.pool.Query(context.Background(), "SELECT "+promoCodeColumns+" FROM promo_codes ORDER BY created_at DESC, code")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.PromoCode{}
+	for rows.Next() {
+		p, err := scanPromoCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *PostgresPricingRepository) GetPromoCode(code string) (*domain.PromoCode, error) {
+	return scanPromoCode(r.pool.QueryRow(context.Background(), "SELECT "+promoCodeColumns+" FROM promo_codes WHERE code = $1", code))
+}
+
+func (r *PostgresPricingRepository) CreatePromoCode(p *domain.PromoCode) error {
+	err := r.pool.QueryRow(context.Background(), `
+		INSERT INTO promo_codes (code, kind, value, currency, max_uses, expires_at, is_active)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+		RETURNING created_at
+	`, p.Code, p.Kind, p.Value, p.Currency, p.MaxUses, p.ExpiresAt, p.IsActive).Scan(&p.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrPromoCodeExists
+	}
+	return err
+}
+
+func (r *PostgresPricingRepository) DeactivatePromoCode(code string) error {
+	tag, err := r.pool.Exec(context.Background(), "UPDATE promo_codes SET is_active = false WHERE code = $1", code)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPromoCodeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresQueueRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresQueueRepository(pool *pgxpool.Pool) *PostgresQueueRepository {
+	return &PostgresQueueRepository{pool: pool}
+}
+
+const queueJobColumns = `id, kind, payload, status, attempts, max_attempts, run_at, locked_until, last_error, created_at, finished_at`
+
+func scanQueueJob(row pgx.Row) (*domain.QueueJob, error) {
+	var job domain.QueueJob
+	err := row.Scan(&job.ID, &job.Kind, &job.Payload, &job.Status, &job.Attempts, &job.MaxAttempts,
+		&job.RunAt, &job.LockedUntil, &job.LastError, &job.CreatedAt, &job.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func collectQueueJobs(rows pgx.Rows) ([]domain.QueueJob, error) {
+	defer rows.Close()
+	list := []domain.QueueJob{}
+	for rows.Next() {
+		job, err := scanQueueJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *job)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresQueueRepository) Enqueue(job *domain.QueueJob) error {
+	runAt := &job.RunAt
+	if job.RunAt.IsZero() {
+		runAt = nil
+	}
+	created, err := scanQueueJob(r.pool.QueryRow(context.Background(), `
+		INSERT INTO queue_jobs (kind, payload, max_attempts, run_at)
+		VALUES ($1, $2, $3, COALESCE($4, CURRENT_TIMESTAMP))
+		RETURNING `+queueJobColumns, job.Kind, job.Payload, job.MaxAttempts, runAt))
+	if err != nil {
+		return err
+	}
+	*job = *created
+	return nil
+}
+
+// ClaimQueueJobs first dead-letters stuck jobs that used up their attempts,
+// so a job that keeps killing its worker stops being handed out. Attempts
+// are counted when a job is claimed for the same reason.
+func (r *PostgresQueueRepository) ClaimQueueJobs(kinds []string, limit int, lease time.Duration) ([]domain.QueueJob, error) {
+	ctx := context.Background()
+	if _, err := r.pool.Exec(ctx, `
+		UPDATE queue_jobs SET status = 'dead', locked_until = NULL, finished_at = CURRENT_TIMESTAMP,
+		       last_error = 'visibility timeout expired on the last attempt'
+		WHERE status = 'running' AND locked_until < CURRENT_TIMESTAMP AND attempts >= max_attempts
+		  AND kind = ANY($1)
+	`, kinds); err != nil {
+		return nil, err
+	}
+	rows, err := r.pool.Query(ctx, `
+		UPDATE queue_jobs SET status = 'running', attempts = attempts + 1,
+		       locked_until = CURRENT_TIMESTAMP + make_interval(secs => $3)
+		WHERE id IN (
+			SELECT id FROM queue_jobs
+			WHERE kind = ANY($1)
+			  AND ((status = 'pending' AND run_at <= CURRENT_TIMESTAMP)
+			    OR (status = 'running' AND locked_until < CURRENT_TIMESTAMP))
+			ORDER BY run_at, id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+queueJobColumns, kinds, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return collectQueueJobs(rows)
+}
+
+// finishQueueJob updates a job only while the caller's claim holds: the
+// job is still running on the attempt it was claimed for.
+func (r *PostgresQueueRepository) finishQueueJob(job *domain.QueueJob, set string, args ...interface{}) error {
+	args = append([]interface{}{job.ID, job.Attempts}, args...)
+	_, err := r.pool.Exec(context.Background(),
+		"UPDATE queue_jobs SET "+set+" WHERE id = $1 AND attempts = $2 AND status = 'running'", args...)
+	return err
+}
+
+func (r *PostgresQueueRepository) CompleteQueueJob(job *domain.QueueJob) error {
+	return r.finishQueueJob(job, "status = 'done', locked_until = NULL, finished_at = CURRENT_TIMESTAMP")
+}
+
+func (r *PostgresQueueRepository) FailQueueJob(job *domain.QueueJob, errMsg string, retryAfter time.Duration) error {
+	return r.finishQueueJob(job, `status = 'pending', locked_until = NULL, last_error = $3,
+		run_at = CURRENT_TIMESTAMP + make_interval(secs => $4)`, errMsg, retryAfter.Seconds())
+}
+
+func (r *PostgresQueueRepository) DeadLetterQueueJob(job *domain.QueueJob, errMsg string) error {
+	return r.finishQueueJob(job, "status = 'dead', locked_until = NULL, last_error = $3, finished_at = CURRENT_TIMESTAMP", errMsg)
+}
+
+func (r *PostgresQueueRepository) ReleaseQueueJob(job *domain.QueueJob) error {
+	return r.finishQueueJob(job, "status = 'pending', locked_until = NULL, attempts = attempts - 1")
+}
+
+func (r *PostgresQueueRepository) GetQueueJob(id int64) (*domain.QueueJob, error) {
+	job, err := scanQueueJob(r.pool.QueryRow(context.Background(),
+		"SELECT "+queueJobColumns+" FROM queue_jobs WHERE id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrQueueJobNotFound
+	}
+	return job, err
+}
+
+func (r *PostgresQueueRepository) ListQueueJobs(filter domain.QueueJobFilter) ([]domain.QueueJob, int, error) {
+	var conds []string
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conds = append(conds, "status = $"+strconv.Itoa(len(args)))
+	}
+	if filter.Kind != "" {
+		args = append(args, filter.Kind)
+		conds = append(conds, "kind = $"+strconv.Itoa(len(args)))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	ctx := context.Background()
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM queue_jobs "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.pool.Query(ctx, "SELECT "+queueJobColumns+" FROM queue_jobs "+where+
+		" ORDER BY created_at DESC, id DESC LIMIT $"+strconv.Itoa(len(args)-1)+" OFFSET $"+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	list, err := collectQueueJobs(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (r *PostgresQueueRepository) CountQueueJobs() (map[string]int, error) {
+	rows, err := r.pool.Query(context.Background(), "SELECT status, COUNT(*) FROM queue_jobs GROUP BY status")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+func (r *PostgresQueueRepository) RetryQueueJob(id int64) (*domain.QueueJob, error) {
+	job, err := scanQueueJob(r.pool.QueryRow(context.Background(), `
+		UPDATE queue_jobs SET status = 'pending', attempts = 0, run_at = CURRENT_TIMESTAMP, finished_at = NULL
+		WHERE id = $1 AND status IN ('dead', 'pending')
+		RETURNING `+queueJobColumns, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		if _, err := r.GetQueueJob(id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrQueueJobNotRetryable
+	}
+	return job, err
+}
+
+func (r *PostgresQueueRepository) PurgeQueueJobs(cutoff time.Time) (int, error) {
+	tag, err := r.pool.Exec(context.Background(),
+		"DELETE FROM queue_jobs WHERE status = 'done' AND finished_at < $1", cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+type PostgresReminderRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresReminderRepository(pool *pgxpool.Pool) *PostgresReminderRepository {
+	return &PostgresReminderRepository{pool: pool}
+}
+
+const reminderPolicyColumns = `id, invitation_uuid, anchor, offset_minutes, audience, enabled, created_at, updated_at`
+
+func scanReminderPolicy(row pgx.Row) (*domain.ReminderPolicy, error) {
+	var p domain.ReminderPolicy
+	err := row.Scan(&p.ID, &p.InvitationUUID, &p.Anchor, &p.OffsetMinutes, &p.Audience, &p.Enabled, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PostgresReminderRepository) queryPolicies(sql string, args ...interface{}) ([]domain.ReminderPolicy, error) {
+	rows, err := r.pool.Query(context.Background(), sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.ReminderPolicy{}
+	for rows.Next() {
+		p, err := scanReminderPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresReminderRepository) ListReminderPolicies(invitationUUID string) ([]domain.ReminderPolicy, error) {
+	return r.queryPolicies("SELECT "+reminderPolicyColumns+" FROM reminder_policies WHERE invitation_uuid = $1 ORDER BY id", invitationUUID)
+}
+
+func (r *PostgresReminderRepository) CreateReminderPolicy(p *domain.ReminderPolicy) error {
+	created, err := scanReminderPolicy(r.pool.QueryRow(context.Background(), `
+		INSERT INTO reminder_policies (invitation_uuid, anchor, offset_minutes, audience, enabled)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+reminderPolicyColumns, p.InvitationUUID, p.Anchor, p.OffsetMinutes, p.Audience, p.Enabled))
+	if err != nil {
+		return err
+	}
+	*p = *created
+	return nil
+}
+
+func (r *PostgresReminderRepository) UpdateReminderPolicy(p *domain.ReminderPolicy) error {
+	updated, err := scanReminderPolicy(r.pool.QueryRow(context.Background(), `
+		UPDATE reminder_policies SET anchor = $3, offset_minutes = $4, audience = $5, enabled = $6,
+		       updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND invitation_uuid = $2
+		RETURNING `+reminderPolicyColumns, p.ID, p.InvitationUUID, p.Anchor, p.OffsetMinutes, p.Audience, p.Enabled))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrReminderPolicyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	*p = *updated
+	return nil
+}
+
+func (r *PostgresReminderRepository) DeleteReminderPolicy(invitationUUID string, id int64) error {
+	tag, err := r.pool.Exec(context.Background(),
+		"DELETE FROM reminder_policies WHERE id = $1 AND invitation_uuid = $2", id, invitationUUID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrReminderPolicyNotFound
+	}
+	return nil
+}
+
+func (r *PostgresReminderRepository) ActiveReminderPolicies() ([]domain.ReminderPolicy, error) {
+	return r.queryPolicies(`
+		SELECT p.id, p.invitation_uuid, p.anchor, p.offset_minutes, p.audience, p.enabled, p.created_at, p.updated_at
+		FROM reminder_policies p JOIN invitations i ON i.uuid = p.invitation_uuid
+		WHERE p.enabled AND i.lifecycle = 'active'
+		ORDER BY p.invitation_uuid, p.id`)
+}
+
+func (r *PostgresReminderRepository) RemindedPhones(policyID int64) (map[string]bool, error) {
+	rows, err := r.pool.Query(context.Background(), "SELECT phone FROM reminder_deliveries WHERE policy_id = $1", policyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	phones := map[string]bool{}
+	for rows.Next() {
+		var phone string
+		if err := rows.Scan(&phone); err != nil {
+			return nil, err
+		}
+		phones[phone] = true
+	}
+	return phones, rows.Err()
+}
+
+// CreateReminder draws the notification id first, like CreateNotification,
+// and only queues the message when the delivery row went in.
+func (r *PostgresReminderRepository) CreateReminder(d *domain.ReminderDelivery, n *domain.Notification, job domain.QueueJob) (bool, error) {
+	err := r.pool.QueryRow(context.Background(), `
+		WITH d AS (
+			SELECT nextval(pg_get_serial_sequence('notifications', 'id')) AS id
+		), r AS (
+			INSERT INTO reminder_deliveries (policy_id, phone, token, notification_id)
+			SELECT $1, $2, $3, d.id FROM d
+			ON CONFLICT (policy_id, phone) DO NOTHING
+			RETURNING notification_id, created_at
+		), q AS (
+			INSERT INTO queue_jobs (kind, payload, max_attempts)
+			SELECT $4::text, jsonb_build_object('notificationId', r.notification_id), $5::int FROM r
+			RETURNING id
+		), n AS (
+			INSERT INTO notifications (id, invitation_uuid, channel, template, lang, recipient, subject, body, queue_job_id)
+			SELECT r.notification_id, $6, $7, $8, $9, $10, $11, $12, q.id FROM r, q
+			RETURNING id
+		)
+		SELECT n.id, r.created_at FROM n, r
+	`, d.PolicyID, d.Phone, d.Token, job.Kind, job.MaxAttempts,
+		n.InvitationUUID, n.Channel, n.Template, n.Lang, n.Recipient, n.Subject, n.Body).Scan(&d.NotificationID, &d.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	n.ID, n.Status, n.CreatedAt = d.NotificationID, domain.NotificationQueued, d.CreatedAt
+	return true, nil
+}
+
+func (r *PostgresReminderRepository) GetReminderByToken(token string) (*domain.ReminderDelivery, error) {
+	var d domain.ReminderDelivery
+	err := r.pool.QueryRow(context.Background(), `
+		SELECT d.policy_id, p.invitation_uuid, d.phone, d.token, d.notification_id, d.created_at
+		FROM reminder_deliveries d JOIN reminder_policies p ON p.id = d.policy_id
+		WHERE d.token = $1
+	`, token).Scan(&d.PolicyID, &d.InvitationUUID, &d.Phone, &d.Token, &d.NotificationID, &d.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrReminderTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *PostgresReminderRepository) OptedOutPhones(phones []string) (map[string]bool, error) {
+	out := map[string]bool{}
+	if len(phones) == 0 {
+		return out, nil
+	}
+	rows, err := r.pool.Query(context.Background(), "SELECT phone FROM reminder_opt_outs WHERE phone = ANY($1)", phones)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var phone string
+		if err := rows.Scan(&phone); err != nil {
+			return nil, err
+		}
+		out[phone] = true
+	}
+	return out, rows.Err()
+}
+
+func (r *PostgresReminderRepository) ListReminderOptOuts() ([]domain.ReminderOptOut, error) {
+	rows, err := r.pool.Query(context.Background(), "SELECT phone, created_at FROM reminder_opt_outs ORDER BY created_at DESC, phone")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.ReminderOptOut{}
+	for rows.Next() {
+		var o domain.ReminderOptOut
+		if err := rows.Scan(&o.Phone, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresReminderRepository) AddReminderOptOut(phone string) error {
+	_, err := r.pool.Exec(context.Background(),
+		"INSERT INTO reminder_opt_outs (phone) VALUES ($1) ON CONFLICT (phone) DO NOTHING", phone)
+	return err
+}
+
+func (r *PostgresReminderRepository) RemoveReminderOptOut(phone string) error {
+	_, err := r.pool.Exec(context.Background(), "DELETE FROM reminder_opt_outs WHERE phone = $1", phone)
+	return err
+}
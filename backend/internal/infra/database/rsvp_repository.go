@@ -0,0 +1,211 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+// rsvpTally is what one response adds to the counters of its invitation.
+type rsvpTally struct {
+	responses, attending, declined, maybe, guests int
+}
+
+func tallyRSVP(attendance string, guestCount int) rsvpTally {
+	t := rsvpTally{responses: 1}
+	switch attendance {
+	case domain.AttendanceYes:
+		t.attending, t.guests = 1, guestCount
+	case domain.AttendanceNo:
+		t.declined = 1
+	case domain.AttendanceMaybe:
+		t.maybe = 1
+	}
+	return t
+}
+
+func (t rsvpTally) minus(o rsvpTally) rsvpTally {
+	return rsvpTally{
+		responses: t.responses - o.responses,
+		attending: t.attending - o.attending,
+		declined:  t.declined - o.declined,
+		maybe:     t.maybe - o.maybe,
+		guests:    t.guests - o.guests,
+	}
+}
+
+// queueCounters adds d to the counters of an invitation. The upsert locks
+// the counter row, so concurrent responses to one invitation add up
+// instead of overwriting each other.
+func queueCounters(batch *pgx.Batch, invitationUUID string, d rsvpTally) {
+	batch.Queue(`
+		INSERT INTO invitation_rsvp_counters AS c (invitation_uuid, responses, attending, declined, maybe, guests)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (invitation_uuid) DO UPDATE SET
+			responses = c.responses + EXCLUDED.responses,
+			attending = c.attending + EXCLUDED.attending,
+			declined = c.declined + EXCLUDED.declined,
+			maybe = c.maybe + EXCLUDED.maybe,
+			guests = c.guests + EXCLUDED.guests,
+			updated_at = CURRENT_TIMESTAMP
+	`, invitationUUID, d.responses, d.attending, d.declined, d.maybe, d.guests)
+}
+
+// submittedRSVPEvent is the rsvp.submitted outbox row of a response,
+// queued right after its insert so currval is its id.
+var submittedRSVPEvent = insertWebhookEvent(domain.WebhookRSVPSubmitted, `jsonb_build_object('rsvp', jsonb_build_object(
+	'id', currval(pg_get_serial_sequence('rsvp_responses', 'id')),
+	'guestName', $2::text, 'attendance', $3::text, 'guestCount', $4::int))`, "invitations i", "i.uuid = $1")
+
+func (r *PostgresInvitationRepository) AddRSVP(rsvp *domain.RSVPResponse) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	batch.Queue(`INSERT INTO rsvp_responses (invitation_uuid, guest_name, attendance, guest_count, phone) VALUES ($1, $2, $3, $4, $5)`,
+		rsvp.InvitationUUID, rsvp.GuestName, rsvp.Attendance, rsvp.GuestCount, rsvp.Phone)
+	batch.Queue(submittedRSVPEvent, rsvp.InvitationUUID, rsvp.GuestName, rsvp.Attendance, rsvp.GuestCount)
+	queueCounters(batch, rsvp.InvitationUUID, tallyRSVP(rsvp.Attendance, rsvp.GuestCount))
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresInvitationRepository) UpdateRSVP(rsvp *domain.RSVPResponse) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var attendance string
+	var guestCount int
+	err = tx.QueryRow(ctx, `
+		SELECT attendance, COALESCE(guest_count, 1) FROM rsvp_responses
+		WHERE id = $1 AND invitation_uuid = $2
+		FOR UPDATE
+	`, rsvp.ID, rsvp.InvitationUUID).Scan(&attendance, &guestCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrRSVPNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	batch.Queue(`UPDATE rsvp_responses SET guest_name = $2, attendance = $3, guest_count = $4, phone = $5 WHERE id = $1`,
+		rsvp.ID, rsvp.GuestName, rsvp.Attendance, rsvp.GuestCount, rsvp.Phone)
+	queueCounters(batch, rsvp.InvitationUUID,
+		tallyRSVP(rsvp.Attendance, rsvp.GuestCount).minus(tallyRSVP(attendance, guestCount)))
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresInvitationRepository) DeleteRSVP(invitationUUID string, id int) error {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var attendance string
+	var guestCount int
+	err = tx.QueryRow(ctx, `
+		DELETE FROM rsvp_responses WHERE id = $1 AND invitation_uuid = $2
+		RETURNING attendance, COALESCE(guest_count, 1)
+	`, id, invitationUUID).Scan(&attendance, &guestCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrRSVPNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	queueCounters(batch, invitationUUID, rsvpTally{}.minus(tallyRSVP(attendance, guestCount)))
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresInvitationRepository) GetRSVPs(invitationUUID string) ([]domain.RSVPResponse, error) {
+	rows, err := r.pool.Query(context.Background(), `
+		SELECT id, invitation_uuid, COALESCE(guest_name, ''), attendance, COALESCE(guest_count, 1), phone, created_at
+		FROM rsvp_responses WHERE invitation_uuid = $1
+		ORDER BY created_at, id
+	`, invitationUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.RSVPResponse{}
+	for rows.Next() {
+		var rsvp domain.RSVPResponse
+		if err := rows.Scan(&rsvp.ID, &rsvp.InvitationUUID, &rsvp.GuestName, &rsvp.Attendance, &rsvp.GuestCount, &rsvp.Phone, &rsvp.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, rsvp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ReconcileRSVPCounters recounts every invitation that has responses or
+// counters and rewrites the counters that differ. Writes to rsvp_responses
+// wait for it, so nothing slips in between the count and the rewrite.
+func (r *PostgresAdminRepository) ReconcileRSVPCounters() (int, error) {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "LOCK TABLE rsvp_responses IN SHARE MODE"); err != nil {
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO invitation_rsvp_counters AS c (invitation_uuid, responses, attending, declined, maybe, guests)
+		SELECT i.uuid,
+		       COUNT(r.id),
+		       COUNT(r.id) FILTER (WHERE r.attendance = 'yes'),
+		       COUNT(r.id) FILTER (WHERE r.attendance = 'no'),
+		       COUNT(r.id) FILTER (WHERE r.attendance = 'maybe'),
+		       COALESCE(SUM(COALESCE(r.guest_count, 1)) FILTER (WHERE r.attendance = 'yes'), 0)
+		FROM invitations i
+		LEFT JOIN rsvp_responses r ON r.invitation_uuid = i.uuid
+		GROUP BY i.uuid
+		HAVING COUNT(r.id) > 0 OR EXISTS (SELECT 1 FROM invitation_rsvp_counters x WHERE x.invitation_uuid = i.uuid)
+		ON CONFLICT (invitation_uuid) DO UPDATE SET
+			responses = EXCLUDED.responses,
+			attending = EXCLUDED.attending,
+			declined = EXCLUDED.declined,
+			maybe = EXCLUDED.maybe,
+			guests = EXCLUDED.guests,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE (c.responses, c.attending, c.declined, c.maybe, c.guests)
+		      IS DISTINCT FROM (EXCLUDED.responses, EXCLUDED.attending, EXCLUDED.declined, EXCLUDED.maybe, EXCLUDED.guests)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,289 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+// invitationEventData is the invitation in the data of webhook events, read
+// off i: the invitations table or a CTE returning its rows.
+const invitationEventData = `jsonb_build_object(
+	'uuid', i.uuid, 'shortCode', i.short_code, 'phoneNumber', i.phone_number,
+	'groomName', i.groom_name, 'brideName', i.bride_name,
+	'eventDate', i.event_date, 'eventLocation', i.event_location,
+	'lang', i.lang, 'templateCode', i.template_code, 'planCode', i.plan_code,
+	'isPaid', i.is_paid, 'expiresAt', i.expires_at, 'hostingUntil', i.hosting_until)`
+
+// insertWebhookEvent is the outbox INSERT of event for every invitation i
+// in from that matches where. extra, if any, is a jsonb expression merged
+// into the data. Nothing is written unless an active subscription wants
+// the event. event is one of the domain constants, never user input.
+func insertWebhookEvent(event, extra, from, where string) string {
+	data := "jsonb_build_object('invitation', " + invitationEventData + ")"
+	if extra != "" {
+		data += " || " + extra
+	}
+	cond := "EXISTS (SELECT 1 FROM webhook_subscriptions s WHERE s.active AND '" + event + "' = ANY(s.events))"
+	if where != "" {
+		cond += " AND " + where
+	}
+	return `
+		INSERT INTO webhook_events (event, invitation_uuid, data)
+		SELECT '` + event + `', i.uuid, ` + data + `
+		FROM ` + from + `
+		WHERE ` + cond
+}
+
+type PostgresWebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresWebhookRepository(pool *pgxpool.Pool) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{pool: pool}
+}
+
+const webhookColumns = `id, url, secret, events, active, description, created_at, updated_at`
+
+func scanWebhook(row pgx.Row) (*domain.WebhookSubscription, error) {
+	var sub domain.WebhookSubscription
+	err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, &sub.Events, &sub.Active, &sub.Description, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// webhookDeliveryColumns read a delivery off d, its event e and its queue
+// job q: a delivery the queue gave up on is failed.
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_id, e.event,
+	CASE WHEN d.delivered_at IS NOT NULL THEN 'succeeded' WHEN q.status = 'dead' THEN 'failed' ELSE 'pending' END,
+	d.attempts, d.response_status, d.response_body, d.error, d.created_at, d.last_attempt_at, d.delivered_at, d.redelivery_of`
+
+const webhookDeliveryFrom = `webhook_deliveries d
+	JOIN webhook_events e ON e.id = d.event_id
+	LEFT JOIN queue_jobs q ON q.id = d.queue_job_id`
+
+func scanWebhookDelivery(row pgx.Row) (*domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.Event, &d.Status, &d.Attempts, &d.ResponseStatus,
+		&d.ResponseBody, &d.Error, &d.CreatedAt, &d.LastAttemptAt, &d.DeliveredAt, &d.RedeliveryOf)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *PostgresWebhookRepository) ListWebhooks() ([]domain.WebhookSubscription, error) {
+	rows, err := r.pool.Query(context.Background(), "SELECT "+webhookColumns+" FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *sub)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresWebhookRepository) GetWebhook(id int64) (*domain.WebhookSubscription, error) {
+	sub, err := scanWebhook(r.pool.QueryRow(context.Background(),
+		"SELECT "+webhookColumns+" FROM webhook_subscriptions WHERE id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWebhookNotFound
+	}
+	return sub, err
+}
+
+func (r *PostgresWebhookRepository) CreateWebhook(sub *domain.WebhookSubscription) error {
+	created, err := scanWebhook(r.pool.QueryRow(context.Background(), `
+		INSERT INTO webhook_subscriptions (url, secret, events, active, description)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+webhookColumns, sub.URL, sub.Secret, sub.Events, sub.Active, sub.Description))
+	if err != nil {
+		return err
+	}
+	*sub = *created
+	return nil
+}
+
+func (r *PostgresWebhookRepository) UpdateWebhook(sub *domain.WebhookSubscription) error {
+	updated, err := scanWebhook(r.pool.QueryRow(context.Background(), `
+		UPDATE webhook_subscriptions SET url = $2, secret = $3, events = $4, active = $5, description = $6,
+		       updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING `+webhookColumns, sub.ID, sub.URL, sub.Secret, sub.Events, sub.Active, sub.Description))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrWebhookNotFound
+	}
+	if err != nil {
+		return err
+	}
+	*sub = *updated
+	return nil
+}
+
+func (r *PostgresWebhookRepository) DeleteWebhook(id int64) error {
+	tag, err := r.pool.Exec(context.Background(), "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// queueWebhookDeliveries creates the deliveries selected by the query in
+// d (columns id, sub, ev and orig) with a queue job each. Delivery ids are
+// drawn first so the jobs can carry them.
+const queueWebhookDeliveries = `
+	q AS (
+		INSERT INTO queue_jobs (kind, payload, max_attempts)
+		SELECT $2::text, jsonb_build_object('deliveryId', d.id), $3::int FROM d
+		RETURNING id, (payload->>'deliveryId')::bigint AS delivery_id
+	)
+	INSERT INTO webhook_deliveries (id, subscription_id, event_id, queue_job_id, redelivery_of)
+	SELECT d.id, d.sub, d.ev, q.id, d.orig FROM d JOIN q ON q.delivery_id = d.id`
+
+func (r *PostgresWebhookRepository) DispatchWebhookEvents(limit int, job domain.QueueJob) (int, error) {
+	ctx := context.Background()
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM webhook_events WHERE dispatched_at IS NULL
+		ORDER BY id LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return 0, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	batch := &pgx.Batch{}
+	batch.Queue(`
+		WITH d AS (
+			SELECT nextval(pg_get_serial_sequence('webhook_deliveries', 'id')) AS id,
+			       s.id AS sub, e.id AS ev, NULL::bigint AS orig
+			FROM webhook_events e
+			JOIN webhook_subscriptions s ON s.active AND e.event = ANY(s.events)
+			WHERE e.id = ANY($1)
+		),`+queueWebhookDeliveries, ids, job.Kind, job.MaxAttempts)
+	batch.Queue("UPDATE webhook_events SET dispatched_at = CURRENT_TIMESTAMP WHERE id = ANY($1)", ids)
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+func (r *PostgresWebhookRepository) getWebhookDelivery(id int64) (*domain.WebhookDelivery, error) {
+	d, err := scanWebhookDelivery(r.pool.QueryRow(context.Background(),
+		"SELECT "+webhookDeliveryColumns+" FROM "+webhookDeliveryFrom+" WHERE d.id = $1", id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+	return d, err
+}
+
+func (r *PostgresWebhookRepository) GetWebhookDelivery(id int64) (*domain.WebhookDelivery, *domain.WebhookSubscription, *domain.WebhookEvent, error) {
+	d, err := r.getWebhookDelivery(id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sub, err := r.GetWebhook(d.SubscriptionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var ev domain.WebhookEvent
+	var invitationUUID *string
+	err = r.pool.QueryRow(context.Background(),
+		"SELECT id, event, invitation_uuid, data, created_at FROM webhook_events WHERE id = $1", d.EventID).
+		Scan(&ev.ID, &ev.Event, &invitationUUID, &ev.Data, &ev.CreatedAt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if invitationUUID != nil {
+		ev.InvitationUUID = *invitationUUID
+	}
+	return d, sub, &ev, nil
+}
+
+func (r *PostgresWebhookRepository) RecordWebhookAttempt(d *domain.WebhookDelivery) error {
+	return r.pool.QueryRow(context.Background(), `
+		UPDATE webhook_deliveries SET attempts = attempts + 1, response_status = $2, response_body = $3, error = $4,
+		       last_attempt_at = CURRENT_TIMESTAMP,
+		       delivered_at = CASE WHEN $5 THEN CURRENT_TIMESTAMP ELSE delivered_at END
+		WHERE id = $1
+		RETURNING attempts, last_attempt_at, delivered_at
+	`, d.ID, d.ResponseStatus, d.ResponseBody, d.Error, d.Status == domain.WebhookSucceeded).
+		Scan(&d.Attempts, &d.LastAttemptAt, &d.DeliveredAt)
+}
+
+func (r *PostgresWebhookRepository) ListWebhookDeliveries(subscriptionID int64, limit int) ([]domain.WebhookDelivery, error) {
+	rows, err := r.pool.Query(context.Background(), "SELECT "+webhookDeliveryColumns+" FROM "+webhookDeliveryFrom+`
+		WHERE d.subscription_id = $1
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $2`, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []domain.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *d)
+	}
+	return list, rows.Err()
+}
+
+func (r *PostgresWebhookRepository) RedeliverWebhook(deliveryID int64, job domain.QueueJob) (*domain.WebhookDelivery, error) {
+	var id int64
+	err := r.pool.QueryRow(context.Background(), `
+		WITH d AS (
+			SELECT nextval(pg_get_serial_sequence('webhook_deliveries', 'id')) AS id,
+			       o.subscription_id AS sub, o.event_id AS ev, o.id AS orig
+			FROM webhook_deliveries o
+			WHERE o.id = $1
+		),`+queueWebhookDeliveries+`
+		RETURNING id`, deliveryID, job.Kind, job.MaxAttempts).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.getWebhookDelivery(id)
+}
+
+func (r *PostgresWebhookRepository) PurgeWebhookEvents(cutoff time.Time) (int, error) {
+	tag, err := r.pool.Exec(context.Background(),
+		"DELETE FROM webhook_events WHERE dispatched_at < $1", cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,132 @@
+package export
+
+import (
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/fonts"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/pdf"
+)
+
+// cardPalette holds print colors of a template. Backgrounds stay white so the
+// card prints well on home printers.
+type cardPalette struct {
+	ink, accent, muted color.RGBA
+	textSuffix         string
+}
+
+var cardPalettes = map[string]cardPalette{
+	"starry-night": {
+		ink:    color.RGBA{0x1c, 0x25, 0x41, 0xff},
+		accent: color.RGBA{0xb8, 0x93, 0x4a, 0xff},
+		muted:  color.RGBA{0x5a, 0x63, 0x80, 0xff},
+	},
+	"silk-ivory": {
+		ink:        color.RGBA{0x3d, 0x32, 0x28, 0xff},
+		accent:     color.RGBA{0xc5, 0xa0, 0x59, 0xff},
+		muted:      color.RGBA{0x8a, 0x76, 0x5c, 0xff},
+		textSuffix: "_silk",
+	},
+}
+
+// CardPDF writes a printable A5 invitation card. link is the public short
+// link printed at the bottom for guests to RSVP; it may be empty.
+func CardPDF(w io.Writer, inv *domain.Invitation, link string) error {
+	pal, ok := cardPalettes[inv.TemplateCode]
+	if !ok {
+		pal = cardPalettes["starry-night"]
+	}
+	lang := i18n.Normalize(inv.Lang)
+	couple := i18n.CoupleNames(inv.GroomName, inv.BrideName, lang)
+
+	doc := pdf.New(i18n.T(lang, "invitation_title", couple))
+	regular := doc.AddFont(fonts.Serif())
+	bold := doc.AddFont(fonts.SerifBold())
+	p := doc.AddPage(pdf.A5)
+	size := p.Size()
+
+	p.StrokeRect(18, 18, size.W-36, size.H-36, 1.5, pal.accent)
+	p.StrokeRect(26, 26, size.W-52, size.H-52, 0.5, pal.accent)
+
+	const inner = 300.0
+	y := 92.0
+	centered := func(f *pdf.Font, s string, fontSize float64, c color.RGBA) {
+		for _, line := range wrap(f, s, fontSize, inner) {
+			p.Text(f, fontSize, (size.W-f.Measure(line, fontSize))/2, y, line, c)
+			y += fontSize * 1.35
+		}
+	}
+
+	centered(regular, strings.ToUpper(i18n.T(lang, "card_label")), 10, pal.muted)
+	y += 30
+
+	// Names: one line when it fits at a readable size, otherwise the groom
+	// and "& bride" on two lines.
+	groom, bride := strings.TrimSpace(inv.GroomName), strings.TrimSpace(inv.BrideName)
+	names := []string{groom + " & " + bride}
+	if groom == "" || bride == "" {
+		names = []string{groom + bride}
+	}
+	nameSize := fitSize(bold, names[0], 30, 22, inner)
+	if nameSize == 0 {
+		names = []string{groom, "& " + bride}
+		nameSize = fitSize(bold, groom, 28, 16, inner)
+		if s := fitSize(bold, names[1], 28, 16, inner); s < nameSize {
+			nameSize = s
+		}
+		if nameSize == 0 {
+			nameSize = 16
+		}
+	}
+	for _, n := range names {
+		centered(bold, ellipsize(bold, n, nameSize, inner), nameSize, pal.ink)
+	}
+	y += 14
+
+	centered(regular, i18n.T(lang, "invite_text"+pal.textSuffix), 12, pal.muted)
+	y += 10
+	p.Line(size.W/2-60, y, size.W/2+60, y, 0.75, pal.accent)
+	y += 40
+
+	if t, ok := inv.EventTime(); ok {
+		centered(bold, i18n.FormatDate(t, lang), 18, pal.ink)
+		if tm := i18n.FormatTime(t); tm != "" {
+			centered(regular, tm, 14, pal.ink)
+		}
+	} else if inv.EventDate != "" {
+		centered(bold, inv.EventDate, 18, pal.ink)
+	}
+	y += 16
+	if inv.EventLocation != "" {
+		centered(bold, inv.EventLocation, 13, pal.ink)
+	}
+	if addr := inv.ContentString(domain.ContentAddress); addr != "" {
+		centered(regular, addr, 11, pal.muted)
+	}
+	if dress := inv.ContentString(domain.ContentDressCode); dress != "" {
+		y += 8
+		centered(regular, i18n.T(lang, "dress_code_title")+": "+dress, 10, pal.muted)
+	}
+
+	if link != "" {
+		y = size.H - 78
+		centered(regular, i18n.T(lang, "card_rsvp_link"), 10, pal.muted)
+		centered(bold, link, 11, pal.ink)
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// fitSize returns the largest size in [min, max] at which s fits width, or 0.
+func fitSize(f *pdf.Font, s string, max, min, width float64) float64 {
+	for size := max; size >= min; size-- {
+		if f.Measure(s, size) <= width {
+			return size
+		}
+	}
+	return 0
+}
@@ -0,0 +1,186 @@
+package export
+
+import (
+	"image/color"
+	"io"
+	"strconv"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/fonts"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/pdf"
+)
+
+var (
+	ink    = color.RGBA{0x22, 0x22, 0x22, 0xff}
+	muted  = color.RGBA{0x6b, 0x6b, 0x6b, 0xff}
+	rule   = color.RGBA{0xc8, 0xc8, 0xc8, 0xff}
+	zebra  = color.RGBA{0xf3, 0xf3, 0xf3, 0xff}
+	margin = 48.0
+)
+
+// guestColumns are the table columns: number, name, guests, response date.
+// The name column takes the remaining width.
+var guestColumns = [4]float64{28, 0, 60, 100}
+
+const rowHeight = 20.0
+
+// GuestListPDF writes the A4 guest list of an invitation for the venue:
+// the total number of confirmed guests and every response with its date,
+// attending parties first.
+func GuestListPDF(w io.Writer, inv *domain.Invitation, rsvps []domain.RSVPResponse, lang string) error {
+	lang = i18n.Normalize(lang)
+	title := i18n.T(lang, "guest_list_title")
+	couple := i18n.CoupleNames(inv.GroomName, inv.BrideName, lang)
+	heading := title + " — " + couple
+
+	doc := pdf.New(heading)
+	l := &listLayout{
+		doc:     doc,
+		regular: doc.AddFont(fonts.Serif()),
+		bold:    doc.AddFont(fonts.SerifBold()),
+	}
+	l.newPage()
+
+	var attending, declined []domain.RSVPResponse
+	total := 0
+	for _, r := range rsvps {
+		if r.Attendance == "yes" {
+			attending = append(attending, r)
+			total += r.GuestCount
+		} else {
+			declined = append(declined, r)
+		}
+	}
+
+	l.text(l.bold, 20, title, ink)
+	l.y += 8
+	l.text(l.bold, 14, couple, ink)
+	if when := eventWhen(inv, lang); when != "" {
+		l.text(l.regular, 11, when, muted)
+	}
+	if inv.EventLocation != "" {
+		l.text(l.regular, 11, inv.EventLocation, muted)
+	}
+	l.y += 14
+	l.text(l.bold, 13, i18n.T(lang, "guest_list_total", total), ink)
+	l.text(l.regular, 11, i18n.T(lang, "guest_list_responses", len(rsvps), len(attending), len(declined)), muted)
+
+	if len(rsvps) == 0 {
+		l.y += 20
+		l.text(l.regular, 12, i18n.T(lang, "guest_list_empty"), muted)
+	}
+	if len(attending) > 0 {
+		l.table(i18n.T(lang, "guest_list_attending"), attending, lang)
+	}
+	if len(declined) > 0 {
+		l.table(i18n.T(lang, "guest_list_declined"), declined, lang)
+	}
+
+	pages := doc.Pages()
+	for i, p := range pages {
+		size := p.Size()
+		footer := i18n.T(lang, "page_of", i+1, len(pages))
+		p.Line(margin, size.H-margin+8, size.W-margin, size.H-margin+8, 0.5, rule)
+		p.Text(l.regular, 9, size.W-margin-l.regular.Measure(footer, 9), size.H-margin+22, footer, muted)
+		p.Text(l.regular, 9, margin, size.H-margin+22, ellipsize(l.regular, heading, 9, size.W/2), muted)
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// listLayout flows lines down the page, starting new pages as needed.
+type listLayout struct {
+	doc     *pdf.Document
+	page    *pdf.Page
+	regular *pdf.Font
+	bold    *pdf.Font
+	y       float64
+}
+
+func (l *listLayout) newPage() {
+	l.page = l.doc.AddPage(pdf.A4)
+	l.y = margin
+}
+
+// ensure starts a new page unless h more points fit above the footer.
+func (l *listLayout) ensure(h float64) {
+	if l.y+h > pdf.A4.H-margin {
+		l.newPage()
+	}
+}
+
+func (l *listLayout) width() float64 { return pdf.A4.W - 2*margin }
+
+func (l *listLayout) text(f *pdf.Font, size float64, s string, c color.RGBA) {
+	for _, line := range wrap(f, s, size, l.width()) {
+		l.ensure(size * 1.4)
+		l.y += size * 1.4
+		l.page.Text(f, size, margin, l.y-size*0.3, line, c)
+	}
+}
+
+func (l *listLayout) table(title string, rows []domain.RSVPResponse, lang string) {
+	l.y += 18
+	l.ensure(16*1.4 + 2*rowHeight)
+	l.text(l.bold, 14, title, ink)
+	l.y += 4
+	l.header(lang)
+
+	for i, r := range rows {
+		if l.y+rowHeight > pdf.A4.H-margin {
+			l.newPage()
+			l.header(lang)
+		}
+		if i%2 == 1 {
+			l.page.FillRect(margin, l.y, l.width(), rowHeight, zebra)
+		}
+		l.row(l.regular, ink, strconv.Itoa(i+1), r.GuestName, strconv.Itoa(r.GuestCount), respondedAt(r))
+	}
+}
+
+func (l *listLayout) header(lang string) {
+	l.row(l.bold, muted, "№", i18n.T(lang, "guest_list_name"), i18n.T(lang, "guest_list_guests"), i18n.T(lang, "guest_list_responded"))
+	l.page.Line(margin, l.y, margin+l.width(), l.y, 0.75, rule)
+}
+
+// row draws one table row at the current position and moves below it.
+func (l *listLayout) row(f *pdf.Font, c color.RGBA, cells ...string) {
+	const size, pad = 10.0, 4.0
+	cols := guestColumns
+	cols[1] = l.width() - cols[0] - cols[2] - cols[3]
+
+	x := margin
+	baseline := l.y + rowHeight/2 + size*0.35
+	for i, cell := range cells {
+		text := ellipsize(f, cell, size, cols[i]-2*pad)
+		tx := x + pad
+		if i == 2 {
+			tx = x + cols[i] - pad - f.Measure(text, size) // counts are right-aligned
+		}
+		l.page.Text(f, size, tx, baseline, text, c)
+		x += cols[i]
+	}
+	l.y += rowHeight
+}
+
+func respondedAt(r domain.RSVPResponse) string {
+	if r.CreatedAt.IsZero() {
+		return ""
+	}
+	return r.CreatedAt.Format("02.01.2006 15:04")
+}
+
+// eventWhen is the localized date and time of the event, or "".
+func eventWhen(inv *domain.Invitation, lang string) string {
+	t, ok := inv.EventTime()
+	if !ok {
+		return inv.EventDate
+	}
+	when := i18n.FormatDate(t, lang)
+	if tm := i18n.FormatTime(t); tm != "" {
+		when += ", " + tm
+	}
+	return when
+}
@@ -0,0 +1,68 @@
+// Package export produces downloadable copies of invitations.
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/card"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/web"
+)
+
+// StaticSite writes a self-contained ZIP of the invitation: the no-JS page,
+// its stylesheet and the preview card. It opens offline from index.html and
+// keeps working after the hosted invitation expires, so the RSVP form is
+// left out.
+func StaticSite(w io.Writer, inv *domain.Invitation, pages *web.PageRenderer, cards *card.Renderer) error {
+	var page bytes.Buffer
+	err := pages.RenderDocument(&page, inv, web.PageOptions{
+		StylesheetHref: "style.css",
+		ImageURL:       "card.png",
+	})
+	if err != nil {
+		return err
+	}
+
+	png, _, err := cards.Render(inv)
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"index.html", page.Bytes()},
+		{"style.css", pages.Stylesheet(inv)},
+		{"card.png", png},
+	}
+
+	zw := zip.NewWriter(w)
+	modified := time.Now()
+	for _, f := range files {
+		method := zip.Deflate
+		if f.name == "card.png" {
+			method = zip.Store // already compressed
+		}
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: method, Modified: modified})
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// FileName is the suggested download name of an invitation export.
+func FileName(inv *domain.Invitation, ext string) string {
+	id := inv.ShortCode
+	if id == "" {
+		id = inv.UUID
+	}
+	return "invitation-" + id + ext
+}
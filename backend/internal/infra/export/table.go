@@ -0,0 +1,106 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/xlsx"
+)
+
+// Format is a spreadsheet export format.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat reads a format name; an empty name means CSV.
+func ParseFormat(s string) (Format, error) {
+	switch Format(s) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	}
+	return "", fmt.Errorf("unsupported format: %q", s)
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// TableWriter writes the rows of a spreadsheet export.
+type TableWriter interface {
+	WriteHeader(names ...string) error
+	// WriteRow accepts strings, ints, time.Time and *time.Time (nil for
+	// an empty cell).
+	WriteRow(values ...interface{}) error
+	Close() error
+}
+
+// NewTableWriter starts a table in the given format. sheet and widths only
+// apply to XLSX.
+func NewTableWriter(w io.Writer, f Format, sheet string, widths ...float64) (TableWriter, error) {
+	if f == FormatXLSX {
+		return xlsx.NewWriter(w, sheet, widths...)
+	}
+	return newCSVTable(w)
+}
+
+// csvTable writes UTF-8 CSV with a byte order mark: without it Excel reads
+// the file in the local ANSI code page and garbles Cyrillic.
+type csvTable struct {
+	w *csv.Writer
+}
+
+func newCSVTable(w io.Writer) (*csvTable, error) {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return nil, err
+	}
+	return &csvTable{w: csv.NewWriter(w)}, nil
+}
+
+func (t *csvTable) WriteHeader(names ...string) error {
+	return t.w.Write(names)
+}
+
+func (t *csvTable) WriteRow(values ...interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case int:
+			record[i] = strconv.Itoa(v)
+		case time.Time:
+			record[i] = csvTime(v)
+		case *time.Time:
+			if v != nil {
+				record[i] = csvTime(*v)
+			}
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return t.w.Write(record)
+}
+
+func (t *csvTable) Close() error {
+	t.w.Flush()
+	return t.w.Error()
+}
+
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+)
+
+// RSVPTable writes every response of an invitation: name, answer, number
+// of guests and when the response came in.
+func RSVPTable(t TableWriter, rsvps []domain.RSVPResponse, lang string) error {
+	err := t.WriteHeader(
+		i18n.T(lang, "guest_list_name"),
+		i18n.T(lang, "guest_list_response"),
+		i18n.T(lang, "guest_list_guests"),
+		i18n.T(lang, "guest_list_responded"),
+	)
+	if err != nil {
+		return err
+	}
+	for _, r := range rsvps {
+		answer := i18n.T(lang, "guest_list_declined")
+		if r.Attendance == "yes" {
+			answer = i18n.T(lang, "guest_list_attending")
+		}
+		if err := t.WriteRow(r.GuestName, answer, r.GuestCount, r.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return t.Close()
+}
+
+// RSVPColumnWidths are the XLSX column widths of RSVPTable.
+var RSVPColumnWidths = []float64{36, 16, 10, 18}
+
+// InvitationsTable writes the admin invitation list with payment status.
+// each feeds the rows, typically straight from the database cursor.
+func InvitationsTable(t TableWriter, lang string, each func(fn func(*domain.InvitationWithStats) error) error) error {
+	err := t.WriteHeader(
+		i18n.T(lang, "export_created"),
+		i18n.T(lang, "export_short_code"),
+		i18n.T(lang, "export_phone"),
+		i18n.T(lang, "export_couple"),
+		i18n.T(lang, "export_event_date"),
+		i18n.T(lang, "export_template"),
+		i18n.T(lang, "export_lang"),
+		i18n.T(lang, "export_paid"),
+		i18n.T(lang, "export_expires"),
+		i18n.T(lang, "export_rsvps"),
+		i18n.T(lang, "export_guests"),
+		"UUID",
+	)
+	if err != nil {
+		return err
+	}
+	err = each(func(inv *domain.InvitationWithStats) error {
+		paid := i18n.T(lang, "export_no")
+		if inv.IsPaid {
+			paid = i18n.T(lang, "export_yes")
+		}
+		return t.WriteRow(
+			inv.CreatedAt,
+			inv.ShortCode,
+			inv.PhoneNumber,
+			i18n.CoupleNames(inv.GroomName, inv.BrideName, lang),
+			inv.EventDate,
+			inv.TemplateName,
+			inv.Lang,
+			paid,
+			inv.ExpiresAt,
+			inv.RSVPCount,
+			inv.ApprovedGuests,
+			inv.UUID,
+		)
+	})
+	if err != nil {
+		return err
+	}
+	return t.Close()
+}
+
+// InvitationColumnWidths are the XLSX column widths of InvitationsTable.
+var InvitationColumnWidths = []float64{18, 12, 16, 32, 18, 16, 6, 10, 18, 10, 10, 38}
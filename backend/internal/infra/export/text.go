@@ -0,0 +1,46 @@
+package export
+
+import (
+	"strings"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/pdf"
+)
+
+// wrap breaks s into lines no wider than width, at spaces where possible.
+func wrap(f *pdf.Font, s string, size, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			next := word
+			if line != "" {
+				next = line + " " + word
+			}
+			if line != "" && f.Measure(next, size) > width {
+				lines = append(lines, line)
+				next = word
+			}
+			line = next
+		}
+		if line != "" {
+			lines = append(lines, ellipsize(f, line, size, width))
+		}
+	}
+	return lines
+}
+
+// ellipsize cuts s so it fits width at size, appending "…" when shortened.
+func ellipsize(f *pdf.Font, s string, size, width float64) string {
+	if f.Measure(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		cut := strings.TrimSpace(string(runes)) + "…"
+		if f.Measure(cut, size) <= width {
+			return cut
+		}
+	}
+	return ""
+}
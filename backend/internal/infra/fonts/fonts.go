@@ -0,0 +1,47 @@
+// Package fonts embeds the typefaces used for server-rendered images and
+// documents. DejaVu covers Latin, Cyrillic and the Kazakh letters
+// (Ә Ғ Қ Ң Ө Ұ Ү Һ І), see data/LICENSE.
+package fonts
+
+import (
+	"embed"
+	"sync"
+)
+
+//go:embed data/*.ttf
+var files embed.FS
+
+var (
+	loadOnce  sync.Once
+	serif     *Font
+	serifBold *Font
+)
+
+func load() {
+	serif = mustParse("data/DejaVuSerif.ttf")
+	serifBold = mustParse("data/DejaVuSerif-Bold.ttf")
+}
+
+func mustParse(name string) *Font {
+	data, err := files.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	f, err := Parse(data)
+	if err != nil {
+		panic(name + ": " + err.Error())
+	}
+	return f
+}
+
+// Serif is DejaVu Serif Book.
+func Serif() *Font {
+	loadOnce.Do(load)
+	return serif
+}
+
+// SerifBold is DejaVu Serif Bold.
+func SerifBold() *Font {
+	loadOnce.Do(load)
+	return serifBold
+}
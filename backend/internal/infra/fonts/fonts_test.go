@@ -0,0 +1,50 @@
+package fonts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoversKazakhAndCyrillic(t *testing.T) {
+	for _, f := range []*Font{Serif(), SerifBold()} {
+		for _, r := range "АаЯяЁёӘәҒғҚқҢңӨөҰұҮүҺһІі&«»—…·" {
+			assert.NotZero(t, f.GlyphIndex(r), "missing glyph for %q in %s", r, f.PostScriptName())
+		}
+	}
+}
+
+func TestContoursAndMeasure(t *testing.T) {
+	f := Serif()
+	assert.Equal(t, "DejaVuSerif", f.PostScriptName())
+
+	// "Й" is a composite glyph (И + breve) in DejaVu.
+	contours, err := f.Contours(f.GlyphIndex('Й'))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, contours)
+
+	assert.Zero(t, f.Measure("", 40))
+	assert.InDelta(t, 2*f.Measure("a", 40), f.Measure("aa", 40), 1e-9)
+	assert.InDelta(t, 2*f.Measure("a", 20), f.Measure("a", 40), 1e-9)
+}
+
+func TestSubsetKeepsGlyphIDs(t *testing.T) {
+	f := Serif()
+	gid := f.GlyphIndex('Й')
+	data, err := f.Subset(map[uint16]bool{gid: true})
+	assert.NoError(t, err)
+	assert.Less(t, len(data), len(f.Data())/4)
+
+	sub, err := Parse(data)
+	if !assert.NoError(t, err) {
+		return
+	}
+	want, _ := f.Contours(gid)
+	got, err := sub.Contours(gid)
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// Unused glyphs are dropped.
+	other, _ := sub.Contours(f.GlyphIndex('Ж'))
+	assert.Empty(t, other)
+}
@@ -0,0 +1,234 @@
+package fonts
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// rasterizer accumulates signed coverage of line segments and resolves it
+// into an anti-aliased alpha mask (the same approach as font-rs).
+type rasterizer struct {
+	w, h int
+	acc  []float64
+}
+
+func newRasterizer(w, h int) *rasterizer {
+	return &rasterizer{w: w, h: h, acc: make([]float64, w*h+2)}
+}
+
+type vec struct{ x, y float64 }
+
+func (r *rasterizer) line(p0, p1 vec) {
+	if p0.y == p1.y {
+		return
+	}
+	dir := 1.0
+	if p0.y > p1.y {
+		dir = -1
+		p0, p1 = p1, p0
+	}
+	dxdy := (p1.x - p0.x) / (p1.y - p0.y)
+	x := p0.x
+	if p0.y < 0 {
+		x -= p0.y * dxdy
+	}
+	yStart := int(math.Max(0, p0.y))
+	yEnd := int(math.Min(float64(r.h), math.Ceil(p1.y)))
+	for y := yStart; y < yEnd; y++ {
+		row := y * r.w
+		dy := math.Min(float64(y+1), p1.y) - math.Max(float64(y), p0.y)
+		xNext := x + dxdy*dy
+		d := dy * dir
+
+		x0, x1 := x, xNext
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+		x0 = clamp(x0, 0, float64(r.w))
+		x1 = clamp(x1, 0, float64(r.w))
+		x0Floor := math.Floor(x0)
+		x0i := int(x0Floor)
+		x1Ceil := math.Ceil(x1)
+		x1i := int(x1Ceil)
+
+		if x1i <= x0i+1 {
+			xmf := 0.5*(x0+x1) - x0Floor
+			r.add(row+x0i, d-d*xmf)
+			r.add(row+x0i+1, d*xmf)
+		} else {
+			s := 1 / (x1 - x0)
+			x0f := x0 - x0Floor
+			a0 := 0.5 * s * (1 - x0f) * (1 - x0f)
+			x1f := x1 - x1Ceil + 1
+			am := 0.5 * s * x1f * x1f
+			r.add(row+x0i, d*a0)
+			if x1i == x0i+2 {
+				r.add(row+x0i+1, d*(1-a0-am))
+			} else {
+				a1 := s * (1.5 - x0f)
+				r.add(row+x0i+1, d*(a1-a0))
+				for xi := x0i + 2; xi < x1i-1; xi++ {
+					r.add(row+xi, d*s)
+				}
+				a2 := a1 + float64(x1i-x0i-3)*s
+				r.add(row+x1i-1, d*(1-a2-am))
+			}
+			r.add(row+x1i, d*am)
+		}
+		x = xNext
+	}
+}
+
+func (r *rasterizer) add(i int, v float64) {
+	if i >= 0 && i < len(r.acc) {
+		r.acc[i] += v
+	}
+}
+
+func (r *rasterizer) mask(origin image.Point) *image.Alpha {
+	m := image.NewAlpha(image.Rect(0, 0, r.w, r.h).Add(origin))
+	sum := 0.0
+	for i := 0; i < r.w*r.h; i++ {
+		sum += r.acc[i]
+		a := math.Min(math.Abs(sum), 1)
+		m.Pix[i] = uint8(a*255 + 0.5)
+	}
+	return m
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+// quadSegments flattens a quadratic Bézier curve into line segments.
+func quadSegments(p0, c, p1 vec, emit func(a, b vec)) {
+	dd := math.Hypot(p0.x-2*c.x+p1.x, p0.y-2*c.y+p1.y)
+	n := int(math.Ceil(math.Sqrt(dd)))
+	if n < 1 {
+		n = 1
+	}
+	if n > 32 {
+		n = 32
+	}
+	prev := p0
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		mt := 1 - t
+		p := vec{
+			mt*mt*p0.x + 2*mt*t*c.x + t*t*p1.x,
+			mt*mt*p0.y + 2*mt*t*c.y + t*t*p1.y,
+		}
+		emit(prev, p)
+		prev = p
+	}
+}
+
+// walkContour emits the line segments of a TrueType contour, expanding the
+// implied on-curve points between consecutive off-curve points.
+func walkContour(pts []vec, on []bool, emit func(a, b vec)) {
+	type node struct {
+		p  vec
+		on bool
+	}
+	n := len(pts)
+	nodes := make([]node, 0, 2*n)
+	for i := 0; i < n; i++ {
+		j := (i + 1) % n
+		nodes = append(nodes, node{pts[i], on[i]})
+		if !on[i] && !on[j] {
+			nodes = append(nodes, node{mid(pts[i], pts[j]), true})
+		}
+	}
+
+	start := 0
+	for start < len(nodes) && !nodes[start].on {
+		start++
+	}
+	if start == len(nodes) {
+		return
+	}
+
+	m := len(nodes)
+	cur := nodes[start].p
+	for k := 1; k <= m; k++ {
+		nd := nodes[(start+k)%m]
+		if nd.on {
+			emit(cur, nd.p)
+			cur = nd.p
+			continue
+		}
+		// After expansion every control point is followed by an on-curve point.
+		next := nodes[(start+k+1)%m].p
+		quadSegments(cur, nd.p, next, emit)
+		cur = next
+		k++
+	}
+}
+
+func mid(a, b vec) vec { return vec{(a.x + b.x) / 2, (a.y + b.y) / 2} }
+
+// Measure returns the advance width of s in pixels at the given size.
+func (f *Font) Measure(s string, size float64) float64 {
+	scale := size / float64(f.unitsPerEm)
+	w := 0
+	for _, r := range s {
+		w += f.Advance(f.GlyphIndex(r))
+	}
+	return float64(w) * scale
+}
+
+// DrawString draws s onto dst with its baseline starting at (x, y) and
+// returns the x position after the last glyph.
+func (f *Font) DrawString(dst draw.Image, x, y float64, s string, size float64, c color.Color) float64 {
+	scale := size / float64(f.unitsPerEm)
+	src := image.NewUniform(c)
+	for _, r := range s {
+		gid := f.GlyphIndex(r)
+		f.drawGlyph(dst, src, gid, x, y, scale)
+		x += float64(f.Advance(gid)) * scale
+	}
+	return x
+}
+
+func (f *Font) drawGlyph(dst draw.Image, src image.Image, gid uint16, x, y, scale float64) {
+	contours, err := f.Contours(gid)
+	if err != nil || len(contours) == 0 {
+		return
+	}
+
+	// Transform to device space (y down) and find the pixel bounds.
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	dev := make([][]vec, len(contours))
+	for ci, contour := range contours {
+		dev[ci] = make([]vec, len(contour))
+		for i, p := range contour {
+			v := vec{x + p.X*scale, y - p.Y*scale}
+			dev[ci][i] = v
+			minX, minY = math.Min(minX, v.x), math.Min(minY, v.y)
+			maxX, maxY = math.Max(maxX, v.x), math.Max(maxY, v.y)
+		}
+	}
+	origin := image.Pt(int(math.Floor(minX)), int(math.Floor(minY)))
+	w := int(math.Ceil(maxX)) - origin.X + 1
+	h := int(math.Ceil(maxY)) - origin.Y + 1
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	r := newRasterizer(w, h)
+	ox, oy := float64(origin.X), float64(origin.Y)
+	for ci, contour := range contours {
+		on := make([]bool, len(contour))
+		local := make([]vec, len(contour))
+		for i, p := range contour {
+			on[i] = p.On
+			local[i] = vec{dev[ci][i].x - ox, dev[ci][i].y - oy}
+		}
+		walkContour(local, on, r.line)
+	}
+	m := r.mask(origin)
+	draw.DrawMask(dst, m.Bounds(), src, image.Point{}, m, m.Bounds().Min, draw.Over)
+}
@@ -0,0 +1,156 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// subsetTables are the tables PDF viewers need from an embedded TrueType
+// font (ISO 32000-1, 9.9), plus cmap so the subset is still a usable font.
+var subsetTables = []string{"head", "hhea", "maxp", "hmtx", "cmap", "loca", "glyf", "cvt ", "fpgm", "prep"}
+
+// Subset returns a font file that only carries the outlines of the given
+// glyphs (and the glyphs they are composed of). Glyph ids are kept, so text
+// encoded against the full font stays valid; unused glyphs become empty.
+func (f *Font) Subset(gids map[uint16]bool) (out []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			out, err = nil, errMalformed
+		}
+	}()
+
+	keep := map[uint16]bool{}
+	var visit func(gid uint16, depth int)
+	visit = func(gid uint16, depth int) {
+		if keep[gid] || depth > maxCompositeDepth {
+			return
+		}
+		keep[gid] = true
+		for _, c := range componentGlyphs(f.glyphData(gid)) {
+			visit(c, depth+1)
+		}
+	}
+	visit(0, 0) // .notdef is always required
+	for gid := range gids {
+		visit(gid, 0)
+	}
+
+	var glyf []byte
+	loca := make([]byte, 4*(f.numGlyphs+1))
+	for i := 0; i < f.numGlyphs; i++ {
+		binary.BigEndian.PutUint32(loca[4*i:], uint32(len(glyf)))
+		if keep[uint16(i)] {
+			glyf = append(glyf, f.glyphData(uint16(i))...)
+			for len(glyf)%4 != 0 {
+				glyf = append(glyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*f.numGlyphs:], uint32(len(glyf)))
+
+	head := append([]byte(nil), f.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)  // checksumAdjustment, set below
+	binary.BigEndian.PutUint16(head[50:], 1) // long loca offsets
+
+	tables := map[string][]byte{"head": head, "loca": loca, "glyf": glyf}
+	for _, tag := range subsetTables {
+		if tables[tag] == nil && f.tables[tag] != nil {
+			tables[tag] = f.tables[tag]
+		}
+	}
+	out = writeFont(tables)
+
+	adj := 0xB1B0AFBA - checksum(out)
+	headOff := int(u32(out, tableRecord(out, "head")+8))
+	binary.BigEndian.PutUint32(out[headOff+8:], adj)
+	return out, nil
+}
+
+// componentGlyphs lists the glyphs a composite glyph refers to.
+func componentGlyphs(g []byte) []uint16 {
+	if len(g) < 10 || i16(g, 0) >= 0 {
+		return nil
+	}
+	var out []uint16
+	off := 10
+	for {
+		flags := u16(g, off)
+		out = append(out, u16(g, off+2))
+		off += 4
+		if flags&compArgsAreWords != 0 {
+			off += 4
+		} else {
+			off += 2
+		}
+		switch {
+		case flags&compHaveScale != 0:
+			off += 2
+		case flags&compHaveXYScale != 0:
+			off += 4
+		case flags&compHaveTwoByTwo != 0:
+			off += 8
+		}
+		if flags&compMoreComps == 0 {
+			return out
+		}
+	}
+}
+
+// writeFont assembles an sfnt file from tables, sorted by tag as the format
+// requires.
+func writeFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	n := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= n {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	out := make([]byte, 12+16*n)
+	binary.BigEndian.PutUint32(out[0:], 0x00010000)
+	binary.BigEndian.PutUint16(out[4:], uint16(n))
+	binary.BigEndian.PutUint16(out[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(out[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(out[10:], uint16(16*n-searchRange))
+
+	for i, tag := range tags {
+		data := tables[tag]
+		rec := 12 + 16*i
+		copy(out[rec:], tag)
+		binary.BigEndian.PutUint32(out[rec+4:], checksum(data))
+		binary.BigEndian.PutUint32(out[rec+8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(out[rec+12:], uint32(len(data)))
+		out = append(out, data...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	return out
+}
+
+func tableRecord(font []byte, tag string) int {
+	n := int(u16(font, 4))
+	for i := 0; i < n; i++ {
+		rec := 12 + 16*i
+		if string(font[rec:rec+4]) == tag {
+			return rec
+		}
+	}
+	return -1
+}
+
+func checksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
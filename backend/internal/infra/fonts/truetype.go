@@ -0,0 +1,413 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Font is a parsed TrueType (glyf-outline) font. Only the tables needed to
+// lay out and draw text are read: no hinting, kerning or shaping.
+type Font struct {
+	data   []byte
+	tables map[string][]byte
+
+	name       string
+	unitsPerEm int
+	ascent     int
+	descent    int
+	bbox       [4]int
+	numGlyphs  int
+	numHMetric int
+	longLoca   bool
+
+	cmap []byte
+	hmtx []byte
+	loca []byte
+	glyf []byte
+
+	cmapFormat uint16
+}
+
+// Point is an outline point in font units. Off-curve points are quadratic
+// Bézier control points.
+type Point struct {
+	X, Y float64
+	On   bool
+}
+
+var errMalformed = errors.New("fonts: malformed font")
+
+func u16(b []byte, off int) uint16 { return binary.BigEndian.Uint16(b[off:]) }
+func u32(b []byte, off int) uint32 { return binary.BigEndian.Uint32(b[off:]) }
+func i16(b []byte, off int) int16  { return int16(binary.BigEndian.Uint16(b[off:])) }
+
+// Parse reads a TrueType font file.
+func Parse(data []byte) (f *Font, err error) {
+	defer func() {
+		// Table offsets come from the file itself; turn out-of-range reads
+		// into an error instead of a panic.
+		if r := recover(); r != nil {
+			f, err = nil, errMalformed
+		}
+	}()
+
+	if len(data) < 12 {
+		return nil, errMalformed
+	}
+	tables := map[string][]byte{}
+	n := int(u16(data, 4))
+	for i := 0; i < n; i++ {
+		rec := 12 + 16*i
+		tag := string(data[rec : rec+4])
+		off, length := int(u32(data, rec+8)), int(u32(data, rec+12))
+		if off+length > len(data) {
+			return nil, errMalformed
+		}
+		tables[tag] = data[off : off+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "cmap", "loca", "glyf"} {
+		if tables[tag] == nil {
+			return nil, fmt.Errorf("fonts: missing %q table", tag)
+		}
+	}
+
+	f = &Font{data: data, tables: tables, hmtx: tables["hmtx"], loca: tables["loca"], glyf: tables["glyf"]}
+
+	head := tables["head"]
+	f.unitsPerEm = int(u16(head, 18))
+	f.bbox = [4]int{int(i16(head, 36)), int(i16(head, 38)), int(i16(head, 40)), int(i16(head, 42))}
+	f.longLoca = i16(head, 50) != 0
+
+	hhea := tables["hhea"]
+	f.ascent = int(i16(hhea, 4))
+	f.descent = int(i16(hhea, 6))
+	f.numHMetric = int(u16(hhea, 34))
+	f.numGlyphs = int(u16(tables["maxp"], 4))
+
+	if err := f.parseCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+	if name, ok := tables["name"]; ok {
+		f.name = parsePostScriptName(name)
+	}
+	return f, nil
+}
+
+// parseCmap picks the best Unicode subtable: full-range format 12 when
+// present, otherwise the BMP format 4.
+func (f *Font) parseCmap(cmap []byte) error {
+	var best []byte
+	var bestFormat uint16
+	n := int(u16(cmap, 2))
+	for i := 0; i < n; i++ {
+		rec := 4 + 8*i
+		platform, encoding := u16(cmap, rec), u16(cmap, rec+2)
+		sub := cmap[u32(cmap, rec+4):]
+		format := u16(sub, 0)
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		if format == 12 || (format == 4 && bestFormat != 12) {
+			best, bestFormat = sub, format
+		}
+	}
+	if best == nil {
+		return errors.New("fonts: no unicode cmap")
+	}
+	f.cmap, f.cmapFormat = best, bestFormat
+	return nil
+}
+
+func parsePostScriptName(name []byte) string {
+	n := int(u16(name, 2))
+	strOff := int(u16(name, 4))
+	for i := 0; i < n; i++ {
+		rec := 6 + 12*i
+		if u16(name, rec+6) != 6 {
+			continue
+		}
+		platform := u16(name, rec)
+		length, off := int(u16(name, rec+8)), int(u16(name, rec+10))
+		raw := name[strOff+off : strOff+off+length]
+		if platform == 1 {
+			return string(raw)
+		}
+		// Windows/Unicode names are UTF-16BE; PostScript names are ASCII.
+		b := make([]byte, 0, length/2)
+		for j := 1; j < len(raw); j += 2 {
+			b = append(b, raw[j])
+		}
+		return string(b)
+	}
+	return ""
+}
+
+// PostScriptName is the font's PostScript name, e.g. "DejaVuSerif".
+func (f *Font) PostScriptName() string { return f.name }
+
+// UnitsPerEm is the size of the em square in font units.
+func (f *Font) UnitsPerEm() int { return f.unitsPerEm }
+
+// Ascent is the typographic ascent in font units (positive).
+func (f *Font) Ascent() int { return f.ascent }
+
+// Descent is the typographic descent in font units (negative).
+func (f *Font) Descent() int { return f.descent }
+
+// BBox is the union of all glyph bounds: xMin, yMin, xMax, yMax.
+func (f *Font) BBox() [4]int { return f.bbox }
+
+// NumGlyphs is the number of glyphs in the font.
+func (f *Font) NumGlyphs() int { return f.numGlyphs }
+
+// Data is the raw font file, for embedding into documents.
+func (f *Font) Data() []byte { return f.data }
+
+// GlyphIndex maps a rune to a glyph id. Zero is the .notdef glyph.
+func (f *Font) GlyphIndex(r rune) uint16 {
+	c := uint32(r)
+	b := f.cmap
+	switch f.cmapFormat {
+	case 12:
+		groups := int(u32(b, 12))
+		lo, hi := 0, groups
+		for lo < hi {
+			m := (lo + hi) / 2
+			g := 16 + 12*m
+			start, end := u32(b, g), u32(b, g+4)
+			switch {
+			case c < start:
+				hi = m
+			case c > end:
+				lo = m + 1
+			default:
+				return uint16(u32(b, g+8) + c - start)
+			}
+		}
+	case 4:
+		if c > 0xFFFF {
+			return 0
+		}
+		segX2 := int(u16(b, 6))
+		for i := 0; i < segX2; i += 2 {
+			end := uint32(u16(b, 14+i))
+			if end < c {
+				continue
+			}
+			start := uint32(u16(b, 16+segX2+i))
+			if start > c {
+				return 0
+			}
+			delta := u16(b, 16+2*segX2+i)
+			rangeOffPos := 16 + 3*segX2 + i
+			rangeOff := int(u16(b, rangeOffPos))
+			if rangeOff == 0 {
+				return uint16(c) + delta
+			}
+			gid := u16(b, rangeOffPos+rangeOff+2*int(c-start))
+			if gid == 0 {
+				return 0
+			}
+			return gid + delta
+		}
+	}
+	return 0
+}
+
+// Advance returns the horizontal advance of a glyph in font units.
+func (f *Font) Advance(gid uint16) int {
+	i := int(gid)
+	if i >= f.numHMetric {
+		i = f.numHMetric - 1
+	}
+	return int(u16(f.hmtx, 4*i))
+}
+
+func (f *Font) glyphData(gid uint16) []byte {
+	i := int(gid)
+	if i >= f.numGlyphs {
+		return nil
+	}
+	var start, end int
+	if f.longLoca {
+		start, end = int(u32(f.loca, 4*i)), int(u32(f.loca, 4*i+4))
+	} else {
+		start, end = 2*int(u16(f.loca, 2*i)), 2*int(u16(f.loca, 2*i+2))
+	}
+	if start >= end || end > len(f.glyf) {
+		return nil
+	}
+	return f.glyf[start:end]
+}
+
+// Contours returns the outline of a glyph in font units, y pointing up.
+func (f *Font) Contours(gid uint16) (contours [][]Point, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			contours, err = nil, errMalformed
+		}
+	}()
+	return f.contours(gid, 0)
+}
+
+// Composite glyphs may nest; real fonts stay well below this depth.
+const maxCompositeDepth = 8
+
+func (f *Font) contours(gid uint16, depth int) ([][]Point, error) {
+	g := f.glyphData(gid)
+	if len(g) < 10 {
+		return nil, nil
+	}
+	n := int(i16(g, 0))
+	if n >= 0 {
+		return simpleContours(g, n), nil
+	}
+	if depth >= maxCompositeDepth {
+		return nil, errMalformed
+	}
+	return f.compositeContours(g, depth)
+}
+
+const (
+	flagOnCurve = 1 << iota
+	flagXShort
+	flagYShort
+	flagRepeat
+	flagXSame
+	flagYSame
+)
+
+func simpleContours(g []byte, n int) [][]Point {
+	ends := make([]int, n)
+	for i := range ends {
+		ends[i] = int(u16(g, 10+2*i))
+	}
+	if n == 0 {
+		return nil
+	}
+	numPoints := ends[n-1] + 1
+	off := 10 + 2*n
+	off += 2 + int(u16(g, off)) // skip instructions
+
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints {
+		fl := g[off]
+		off++
+		flags = append(flags, fl)
+		if fl&flagRepeat != 0 {
+			count := int(g[off])
+			off++
+			for j := 0; j < count; j++ {
+				flags = append(flags, fl)
+			}
+		}
+	}
+
+	pts := make([]Point, numPoints)
+	x := 0
+	for i, fl := range flags {
+		switch {
+		case fl&flagXShort != 0:
+			d := int(g[off])
+			off++
+			if fl&flagXSame == 0 {
+				d = -d
+			}
+			x += d
+		case fl&flagXSame == 0:
+			x += int(i16(g, off))
+			off += 2
+		}
+		pts[i].X = float64(x)
+		pts[i].On = fl&flagOnCurve != 0
+	}
+	y := 0
+	for i, fl := range flags {
+		switch {
+		case fl&flagYShort != 0:
+			d := int(g[off])
+			off++
+			if fl&flagYSame == 0 {
+				d = -d
+			}
+			y += d
+		case fl&flagYSame == 0:
+			y += int(i16(g, off))
+			off += 2
+		}
+		pts[i].Y = float64(y)
+	}
+
+	contours := make([][]Point, 0, n)
+	start := 0
+	for _, end := range ends {
+		contours = append(contours, pts[start:end+1])
+		start = end + 1
+	}
+	return contours
+}
+
+const (
+	compArgsAreWords = 0x0001
+	compArgsAreXY    = 0x0002
+	compHaveScale    = 0x0008
+	compMoreComps    = 0x0020
+	compHaveXYScale  = 0x0040
+	compHaveTwoByTwo = 0x0080
+)
+
+func (f *Font) compositeContours(g []byte, depth int) ([][]Point, error) {
+	var out [][]Point
+	off := 10
+	for {
+		flags := u16(g, off)
+		child := u16(g, off+2)
+		off += 4
+
+		var dx, dy float64
+		if flags&compArgsAreWords != 0 {
+			dx, dy = float64(i16(g, off)), float64(i16(g, off+2))
+			off += 4
+		} else {
+			dx, dy = float64(int8(g[off])), float64(int8(g[off+1]))
+			off += 2
+		}
+		if flags&compArgsAreXY == 0 {
+			// Point-matching anchors are not used by the fonts we ship.
+			dx, dy = 0, 0
+		}
+
+		a, b, c, d := 1.0, 0.0, 0.0, 1.0
+		f2dot14 := func(o int) float64 { return float64(i16(g, o)) / 16384 }
+		switch {
+		case flags&compHaveScale != 0:
+			a = f2dot14(off)
+			d = a
+			off += 2
+		case flags&compHaveXYScale != 0:
+			a, d = f2dot14(off), f2dot14(off+2)
+			off += 4
+		case flags&compHaveTwoByTwo != 0:
+			a, b, c, d = f2dot14(off), f2dot14(off+2), f2dot14(off+4), f2dot14(off+6)
+			off += 8
+		}
+
+		sub, err := f.contours(child, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, contour := range sub {
+			moved := make([]Point, len(contour))
+			for i, p := range contour {
+				moved[i] = Point{X: a*p.X + c*p.Y + dx, Y: b*p.X + d*p.Y + dy, On: p.On}
+			}
+			out = append(out, moved)
+		}
+
+		if flags&compMoreComps == 0 {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// Package geoip looks up the country of an IP address in a local MaxMind DB
+// file, such as GeoLite2-Country.mmdb or a compatible DB-IP database.
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+)
+
+var (
+	ErrFormat = errors.New("geoip: not a MaxMind DB file")
+
+	metadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+)
+
+// dataSectionSeparator is the run of zero bytes between the search tree
+// and the data section.
+const dataSectionSeparator = 16
+
+// DB is an opened database. It is read-only and safe for concurrent use.
+type DB struct {
+	buf        []byte
+	data       []byte
+	nodeCount  uint32
+	recordSize int
+	ipVersion  int
+	ipv4Start  uint32
+}
+
+// Open reads the whole database file into memory; country databases are a
+// few megabytes.
+func Open(path string) (*DB, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(buf)
+}
+
+// New parses a database held in memory.
+func New(buf []byte) (*DB, error) {
+	i := bytes.LastIndex(buf, metadataMarker)
+	if i < 0 {
+		return nil, ErrFormat
+	}
+	meta, _, err := decode(buf[i+len(metadataMarker):], 0)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: metadata: %w", err)
+	}
+	m, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil, ErrFormat
+	}
+	db := &DB{
+		nodeCount:  uint32(toUint(m["node_count"])),
+		recordSize: int(toUint(m["record_size"])),
+		ipVersion:  int(toUint(m["ip_version"])),
+	}
+	switch db.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("geoip: unsupported record size %d", db.recordSize)
+	}
+	treeSize := int(db.nodeCount) * db.recordSize / 4
+	if treeSize+dataSectionSeparator > i {
+		return nil, ErrFormat
+	}
+	db.buf = buf[:treeSize]
+	db.data = buf[treeSize+dataSectionSeparator : i]
+
+	// IPv4 addresses live under ::/96 of an IPv6 tree.
+	if db.ipVersion == 6 {
+		node := uint32(0)
+		for n := 0; n < 96 && node < db.nodeCount; n++ {
+			node = db.record(node, 0)
+		}
+		db.ipv4Start = node
+	}
+	return db, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of ip, or "" when it is not
+// in the database.
+func (db *DB) Country(ip net.IP) string {
+	rec, err := db.Lookup(ip)
+	if err != nil || rec == nil {
+		return ""
+	}
+	for _, key := range []string{"country", "registered_country"} {
+		if c, ok := rec[key].(map[string]interface{}); ok {
+			if code, ok := c["iso_code"].(string); ok {
+				return code
+			}
+		}
+	}
+	return ""
+}
+
+// Lookup returns the record of ip, or nil when there is none.
+func (db *DB) Lookup(ip net.IP) (map[string]interface{}, error) {
+	node, bits := uint32(0), ip.To16()
+	if bits == nil {
+		return nil, fmt.Errorf("geoip: invalid IP %v", ip)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		bits = v4
+		if db.ipVersion == 6 {
+			node = db.ipv4Start
+		}
+	} else if db.ipVersion == 4 {
+		return nil, nil
+	}
+
+	for i := 0; i < len(bits)*8 && node < db.nodeCount; i++ {
+		bit := int(bits[i/8]>>(7-uint(i%8))) & 1
+		node = db.record(node, bit)
+	}
+	if node <= db.nodeCount {
+		return nil, nil
+	}
+	offset := int(node-db.nodeCount) - dataSectionSeparator
+	if offset < 0 || offset >= len(db.data) {
+		return nil, ErrFormat
+	}
+	v, _, err := decode(db.data, offset)
+	if err != nil {
+		return nil, err
+	}
+	rec, _ := v.(map[string]interface{})
+	return rec, nil
+}
+
+// record reads the left (0) or right (1) record of a search tree node.
+func (db *DB) record(node uint32, side int) uint32 {
+	b := db.buf[int(node)*db.recordSize/4:]
+	switch db.recordSize {
+	case 24:
+		b = b[side*3:]
+		return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+	case 28:
+		if side == 0 {
+			return uint32(b[3]&0xf0)<<20 | uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+		}
+		return uint32(b[3]&0x0f)<<24 | uint32(b[4])<<16 | uint32(b[5])<<8 | uint32(b[6])
+	default:
+		return binary.BigEndian.Uint32(b[side*4:])
+	}
+}
+
+// Data section field types.
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// decode reads the value at offset of section and returns it with the
+// offset just past it. Maps decode to map[string]interface{}, arrays to
+// []interface{}, integers to uint64 or int64 and floats to float64.
+func decode(section []byte, offset int) (interface{}, int, error) {
+	if offset >= len(section) {
+		return nil, 0, ErrFormat
+	}
+	ctrl := section[offset]
+	offset++
+	typ := int(ctrl >> 5)
+
+	if typ == typePointer {
+		ss, vvv := int(ctrl>>3)&3, int(ctrl&7)
+		n := ss + 1
+		if offset+n > len(section) {
+			return nil, 0, ErrFormat
+		}
+		p := 0
+		if ss < 3 {
+			p = vvv
+		}
+		for _, c := range section[offset : offset+n] {
+			p = p<<8 | int(c)
+		}
+		p += [...]int{0, 2048, 526336, 0}[ss]
+		v, _, err := decode(section, p)
+		return v, offset + n, err
+	}
+
+	if typ == typeExtended {
+		if offset >= len(section) {
+			return nil, 0, ErrFormat
+		}
+		typ = 7 + int(section[offset])
+		offset++
+	}
+	size := int(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		if offset+n > len(section) {
+			return nil, 0, ErrFormat
+		}
+		extra := 0
+		for _, c := range section[offset : offset+n] {
+			extra = extra<<8 | int(c)
+		}
+		size = [...]int{0, 29, 285, 65821}[n] + extra
+		offset += n
+	}
+
+	switch typ {
+	case typeMap:
+		m := make(map[string]interface{}, size)
+		for i := 0; i < size; i++ {
+			k, next, err := decode(section, offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, 0, ErrFormat
+			}
+			v, next, err := decode(section, next)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[key] = v
+			offset = next
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]interface{}, 0, size)
+		for i := 0; i < size; i++ {
+			v, next, err := decode(section, offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, v)
+			offset = next
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	case typeContainer, typeEndMarker:
+		return nil, offset, nil
+	}
+
+	if offset+size > len(section) {
+		return nil, 0, ErrFormat
+	}
+	b := section[offset : offset+size]
+	offset += size
+	switch typ {
+	case typeString:
+		return string(b), offset, nil
+	case typeBytes:
+		return append([]byte(nil), b...), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, ErrFormat
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, ErrFormat
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), offset, nil
+	case typeUint16, typeUint32, typeUint64, typeUint128:
+		if size > 8 {
+			// Only 128-bit values can be this long; no field we read is.
+			return b, offset, nil
+		}
+		var u uint64
+		for _, c := range b {
+			u = u<<8 | uint64(c)
+		}
+		return u, offset, nil
+	case typeInt32:
+		var u uint32
+		for _, c := range b {
+			u = u<<8 | uint32(c)
+		}
+		if size == 4 {
+			return int64(int32(u)), offset, nil
+		}
+		return int64(u), offset, nil
+	}
+	return nil, 0, fmt.Errorf("geoip: unknown field type %d", typ)
+}
+
+func toUint(v interface{}) uint64 {
+	u, _ := v.(uint64)
+	return u
+}
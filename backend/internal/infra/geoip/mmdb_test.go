@@ -0,0 +1,159 @@
+package geoip
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Minimal MaxMind DB encoder for the tests.
+
+func encString(s string) []byte { return append([]byte{byte(typeString<<5 | len(s))}, s...) }
+
+func encUint(typ int, v uint64, n int) []byte {
+	b := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		b[i], v = byte(v), v>>8
+	}
+	ctrl := []byte{byte(typ<<5 | n)}
+	if typ > 7 {
+		ctrl = []byte{byte(n), byte(typ - 7)}
+	}
+	return append(ctrl, b...)
+}
+
+func encMap(kv ...[]byte) []byte {
+	return append([]byte{byte(typeMap<<5 | len(kv)/2)}, bytes.Join(kv, nil)...)
+}
+
+func encPointer(p int) []byte { return []byte{byte(typePointer<<5 | p>>8), byte(p)} }
+
+type prefix struct {
+	ip   string
+	bits int
+	data int // offset in the data section
+}
+
+// buildDB lays out a search tree holding prefixes over data.
+func buildDB(ipVersion, recordSize int, data []byte, prefixes []prefix) []byte {
+	const empty = -1
+	type rec struct {
+		node int
+		data int
+	}
+	nodes := [][2]rec{{{empty, empty}, {empty, empty}}}
+	for _, p := range prefixes {
+		ip := net.ParseIP(p.ip)
+		addr := ip.To16()
+		bits := p.bits
+		if ipVersion == 4 {
+			addr = ip.To4()
+		} else if v4 := ip.To4(); v4 != nil {
+			addr = append(make([]byte, 12), v4...)
+			bits += 96
+		}
+		node := 0
+		for i := 0; i < bits; i++ {
+			side := int(addr[i/8]>>(7-uint(i%8))) & 1
+			if i == bits-1 {
+				nodes[node][side] = rec{empty, p.data}
+				break
+			}
+			if nodes[node][side].node == empty {
+				nodes = append(nodes, [2]rec{{empty, empty}, {empty, empty}})
+				nodes[node][side] = rec{len(nodes) - 1, empty}
+			}
+			node = nodes[node][side].node
+		}
+	}
+
+	count := len(nodes)
+	var tree []byte
+	for _, n := range nodes {
+		var v [2]uint32
+		for side, r := range n {
+			switch {
+			case r.node != empty:
+				v[side] = uint32(r.node)
+			case r.data != empty:
+				v[side] = uint32(count + dataSectionSeparator + r.data)
+			default:
+				v[side] = uint32(count)
+			}
+		}
+		switch recordSize {
+		case 24:
+			tree = append(tree, byte(v[0]>>16), byte(v[0]>>8), byte(v[0]), byte(v[1]>>16), byte(v[1]>>8), byte(v[1]))
+		case 28:
+			tree = append(tree, byte(v[0]>>16), byte(v[0]>>8), byte(v[0]), byte(v[0]>>24<<4|v[1]>>24), byte(v[1]>>16), byte(v[1]>>8), byte(v[1]))
+		case 32:
+			tree = append(tree, byte(v[0]>>24), byte(v[0]>>16), byte(v[0]>>8), byte(v[0]), byte(v[1]>>24), byte(v[1]>>16), byte(v[1]>>8), byte(v[1]))
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(tree)
+	buf.Write(make([]byte, dataSectionSeparator))
+	buf.Write(data)
+	buf.Write(metadataMarker)
+	buf.Write(encMap(
+		encString("node_count"), encUint(typeUint32, uint64(count), 4),
+		encString("record_size"), encUint(typeUint16, uint64(recordSize), 2),
+		encString("ip_version"), encUint(typeUint16, uint64(ipVersion), 2),
+		encString("database_type"), encString("Test-Country"),
+	))
+	return buf.Bytes()
+}
+
+func testData() ([]byte, int, int) {
+	kz := encMap(encString("country"), encMap(encString("iso_code"), encString("KZ")))
+	ruCountry := encMap(encString("iso_code"), encString("RU"))
+	// The second record reuses a map through a pointer, as real files do.
+	ru := encMap(
+		encString("registered_country"), encPointer(len(kz)),
+		encString("geoname_id"), encUint(typeUint32, 2017370, 4),
+	)
+	data := append(append(append([]byte{}, kz...), ruCountry...), ru...)
+	return data, 0, len(kz) + len(ruCountry)
+}
+
+func TestCountry(t *testing.T) {
+	data, kz, ru := testData()
+	for _, ipVersion := range []int{4, 6} {
+		for _, size := range []int{24, 28, 32} {
+			prefixes := []prefix{{"2.132.0.0", 14, kz}, {"5.3.0.0", 16, ru}}
+			if ipVersion == 6 {
+				prefixes = append(prefixes, prefix{"2a03:32c0::", 32, kz})
+			}
+			db, err := New(buildDB(ipVersion, size, data, prefixes))
+			require.NoError(t, err)
+
+			assert.Equal(t, "KZ", db.Country(net.ParseIP("2.133.10.20")), "v%d/%d", ipVersion, size)
+			assert.Equal(t, "RU", db.Country(net.ParseIP("5.3.200.1")), "v%d/%d", ipVersion, size)
+			assert.Equal(t, "", db.Country(net.ParseIP("8.8.8.8")), "v%d/%d", ipVersion, size)
+			if ipVersion == 6 {
+				assert.Equal(t, "KZ", db.Country(net.ParseIP("2a03:32c0:1::5")))
+			} else {
+				assert.Equal(t, "", db.Country(net.ParseIP("2a03:32c0:1::5")))
+			}
+		}
+	}
+}
+
+func TestLookupRecord(t *testing.T) {
+	data, _, ru := testData()
+	db, err := New(buildDB(4, 24, data, []prefix{{"5.3.0.0", 16, ru}}))
+	require.NoError(t, err)
+
+	rec, err := db.Lookup(net.ParseIP("5.3.1.1"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2017370), rec["geoname_id"])
+}
+
+func TestNewRejectsGarbage(t *testing.T) {
+	_, err := New([]byte("not a database"))
+	assert.ErrorIs(t, err, ErrFormat)
+}
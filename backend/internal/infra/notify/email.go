@@ -0,0 +1,159 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// Email sends plain-text mail through an SMTP server, upgrading the
+// connection with STARTTLS when the server offers it. Rejections (5xx
+// replies) are permanent; anything else is retried.
+type Email struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     *mail.Address
+	// tlsConfig is for tests; nil verifies the server as host.
+	tlsConfig *tls.Config
+	now       func() time.Time
+}
+
+// NewEmail returns the channel for the server at addr, "host:port", with
+// mail from from, "Card Go <hello@card-go.asia>". It logs in when username
+// is set.
+func NewEmail(addr, username, password, from string) (*Email, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("smtp address: %w", err)
+	}
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("smtp sender: %w", err)
+	}
+	return &Email{addr: addr, host: host, username: username, password: password, from: sender, now: time.Now}, nil
+}
+
+func (e *Email) Name() string { return domain.ChannelEmail }
+
+// Send returns the Message-ID of the mail.
+func (e *Email) Send(ctx context.Context, msg usecase.NotificationMessage) (string, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return "", usecase.Permanent(err)
+	}
+	id := e.messageID()
+	body, err := e.compose(id, to, msg)
+	if err != nil {
+		return "", usecase.Permanent(err)
+	}
+	if err := e.deliver(ctx, to.Address, body); err != nil {
+		var reply *textproto.Error
+		if errors.As(err, &reply) && reply.Code >= 500 {
+			return "", usecase.Permanent(err)
+		}
+		return "", err
+	}
+	return id, nil
+}
+
+func (e *Email) deliver(ctx context.Context, to string, body []byte) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return err
+	}
+	// The SMTP client has no context support; bound the whole session.
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(time.Minute)
+	}
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		config := e.tlsConfig
+		if config == nil {
+			config = &tls.Config{ServerName: e.host}
+		}
+		if err := c.StartTLS(config); err != nil {
+			return err
+		}
+	}
+	if e.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func (e *Email) compose(id string, to *mail.Address, msg usecase.NotificationMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", e.now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: %s\r\n", id)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write(bytes.ReplaceAll([]byte(msg.Text), []byte("\n"), []byte("\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (e *Email) messageID() string {
+	b := make([]byte, 16)
+	// crypto/rand does not fail on supported platforms.
+	_, _ = rand.Read(b)
+	domainPart := e.host
+	if at := strings.LastIndexByte(e.from.Address, '@'); at >= 0 {
+		domainPart = e.from.Address[at+1:]
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domainPart + ">"
+}
@@ -0,0 +1,145 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSession is what the fake server was told in one session.
+type smtpSession struct {
+	auth, from string
+	to         []string
+	data       string
+}
+
+// fakeSMTP serves one SMTP session per connection on a local port, without
+// STARTTLS, rejecting recipients at reject.example.
+func fakeSMTP(t *testing.T) (addr string, sessions <-chan smtpSession) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan smtpSession, 4)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, out)
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+func serveSMTP(conn net.Conn, out chan<- smtpSession) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	var s smtpSession
+	tp.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			s.auth = arg
+			tp.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = arg
+			tp.PrintfLine("250 2.1.0 Ok")
+		case "RCPT":
+			if strings.Contains(arg, "reject.example") {
+				tp.PrintfLine("550 5.1.1 No such user")
+				continue
+			}
+			s.to = append(s.to, arg)
+			tp.PrintfLine("250 2.1.5 Ok")
+		case "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := io.ReadAll(tp.DotReader())
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			tp.PrintfLine("250 2.0.0 Ok: queued")
+		case "QUIT":
+			tp.PrintfLine("221 2.0.0 Bye")
+			out <- s
+			return
+		default:
+			tp.PrintfLine("502 5.5.2 Error: command not recognized")
+		}
+	}
+}
+
+func TestEmail_SendsMail(t *testing.T) {
+	addr, sessions := fakeSMTP(t)
+	email, err := NewEmail(addr, "mailer", "pa55", "Card Go <hello@card-go.test>")
+	require.NoError(t, err)
+	email.now = func() time.Time { return time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC) }
+
+	id, err := email.Send(context.Background(), usecase.NotificationMessage{
+		To: "aigerim@example.com", Subject: "Ваше приглашение готово", Text: "Ссылка:\nhttps://card-go.test/s/arman-aigerim"})
+	require.NoError(t, err)
+	assert.Regexp(t, `^<[0-9a-f]{32}@card-go\.test>$`, id)
+
+	s := <-sessions
+	assert.Equal(t, "PLAIN AG1haWxlcgBwYTU1", s.auth)
+	assert.Equal(t, "FROM:<hello@card-go.test>", s.from)
+	assert.Equal(t, []string{"TO:<aigerim@example.com>"}, s.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(s.data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Ваше приглашение готово", subject)
+	assert.Equal(t, id, msg.Header.Get("Message-ID"))
+	assert.Equal(t, "Fri, 01 May 2026 10:00:00 +0000", msg.Header.Get("Date"))
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	require.NoError(t, err)
+	// The dot reader turns CRLF back into LF.
+	assert.Equal(t, "Ссылка:\nhttps://card-go.test/s/arman-aigerim\n", string(body))
+}
+
+func TestEmail_Errors(t *testing.T) {
+	addr, _ := fakeSMTP(t)
+	email, err := NewEmail(addr, "", "", "hello@card-go.test")
+	require.NoError(t, err)
+
+	_, err = email.Send(context.Background(), usecase.NotificationMessage{To: "nobody@reject.example", Text: "hi"})
+	require.Error(t, err)
+	assert.True(t, usecase.IsPermanent(err))
+
+	// Nothing listens on a closed port: retried.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed := ln.Addr().String()
+	ln.Close()
+	down, err := NewEmail(closed, "", "", "hello@card-go.test")
+	require.NoError(t, err)
+	_, err = down.Send(context.Background(), usecase.NotificationMessage{To: "aigerim@example.com", Text: "hi"})
+	require.Error(t, err)
+	assert.False(t, usecase.IsPermanent(err))
+
+	_, err = NewEmail("smtp.example.com", "", "", "hello@card-go.test")
+	assert.Error(t, err)
+}
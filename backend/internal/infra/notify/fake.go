@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// Fake is a channel for local development: messages are written to the log
+// and kept in memory instead of being sent. Never enable it in production.
+type Fake struct {
+	mu   sync.Mutex
+	sent []usecase.NotificationMessage
+}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (f *Fake) Name() string { return domain.ChannelFake }
+
+// Send returns "fake-1", "fake-2" and so on.
+func (f *Fake) Send(_ context.Context, msg usecase.NotificationMessage) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, msg)
+	log.Printf("notify: fake message to %s:\n%s", msg.To, msg.Text)
+	return fmt.Sprintf("fake-%d", len(f.sent)), nil
+}
+
+// Sent returns the messages sent so far, oldest first.
+func (f *Fake) Sent() []usecase.NotificationMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]usecase.NotificationMessage(nil), f.sent...)
+}
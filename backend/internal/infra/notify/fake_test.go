@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake_KeepsMessages(t *testing.T) {
+	f := NewFake()
+	assert.Equal(t, domain.ChannelFake, f.Name())
+
+	id, err := f.Send(context.Background(), usecase.NotificationMessage{To: "+77011234567", Text: "See you tomorrow"})
+	require.NoError(t, err)
+	assert.Equal(t, "fake-1", id)
+	id, err = f.Send(context.Background(), usecase.NotificationMessage{To: "+77017654321", Text: "Please RSVP"})
+	require.NoError(t, err)
+	assert.Equal(t, "fake-2", id)
+
+	sent := f.Sent()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "+77011234567", sent[0].To)
+	assert.Equal(t, "Please RSVP", sent[1].Text)
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/outbound"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// SMS sends text messages through an SMS gateway that takes a JSON POST of
+// {"to", "from", "text"} with a bearer token, which most gateways do
+// directly or through a small relay. An "id" in the answer is kept as the
+// message id.
+type SMS struct {
+	client *http.Client
+	url    string
+	token  string
+	from   string
+}
+
+func NewSMS(client *http.Client, url, token, from string) *SMS {
+	return &SMS{client: client, url: url, token: token, from: from}
+}
+
+func (s *SMS) Name() string { return domain.ChannelSMS }
+
+func (s *SMS) Send(ctx context.Context, msg usecase.NotificationMessage) (string, error) {
+	body, err := json.Marshal(map[string]string{"to": msg.To, "from": s.from, "text": msg.Text})
+	if err != nil {
+		return "", usecase.Permanent(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return "", usecase.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := outbound.StatusError(resp); err != nil {
+		return "", err
+	}
+	// Gateways answer with string and numeric ids alike.
+	var answer struct {
+		ID interface{} `json:"id"`
+	}
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if dec.Decode(&answer) != nil {
+		return "", nil
+	}
+	switch id := answer.ID.(type) {
+	case string:
+		return id, nil
+	case json.Number:
+		return id.String(), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMS_PostsMessage(t *testing.T) {
+	answer := `{"id":"sms-42","status":"queued"}`
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sms-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Write([]byte(answer))
+	}))
+	defer srv.Close()
+	sms := NewSMS(srv.Client(), srv.URL+"/send", "sms-token", "CardGo")
+	msg := usecase.NotificationMessage{To: "+77011234567", Text: "Приглашение готово"}
+
+	id, err := sms.Send(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "sms-42", id)
+	assert.Equal(t, map[string]string{"to": "+77011234567", "from": "CardGo", "text": "Приглашение готово"}, got)
+
+	answer = `{"id":1234567890123}`
+	id, err = sms.Send(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890123", id)
+
+	answer = `OK`
+	id, err = sms.Send(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
+
+func TestSMS_RejectedIsPermanent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid number", http.StatusUnprocessableEntity)
+	}))
+	defer srv.Close()
+
+	_, err := NewSMS(srv.Client(), srv.URL, "", "CardGo").Send(context.Background(), usecase.NotificationMessage{To: "+7", Text: "hi"})
+	require.Error(t, err)
+	assert.True(t, usecase.IsPermanent(err))
+}
@@ -0,0 +1,86 @@
+// Package notify holds the channels notifications go out on.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/outbound"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// DefaultWhatsAppAPI is the WhatsApp Cloud API endpoint.
+const DefaultWhatsAppAPI = "https://graph.facebook.com/v21.0"
+
+// WhatsApp sends text messages through the WhatsApp Cloud API from the
+// business phone number phoneNumberID. WhatsApp only delivers free-form
+// text to clients who wrote to the number in the last 24 hours, which is
+// how our conversations start.
+type WhatsApp struct {
+	client        *http.Client
+	apiURL        string
+	phoneNumberID string
+	token         string
+}
+
+// NewWhatsApp returns the channel; apiURL "" is DefaultWhatsAppAPI.
+func NewWhatsApp(client *http.Client, apiURL, phoneNumberID, token string) *WhatsApp {
+	if apiURL == "" {
+		apiURL = DefaultWhatsAppAPI
+	}
+	return &WhatsApp{client: client, apiURL: strings.TrimSuffix(apiURL, "/"), phoneNumberID: phoneNumberID, token: token}
+}
+
+func (w *WhatsApp) Name() string { return domain.ChannelWhatsApp }
+
+type whatsAppText struct {
+	MessagingProduct string `json:"messaging_product"`
+	RecipientType    string `json:"recipient_type"`
+	To               string `json:"to"`
+	Type             string `json:"type"`
+	Text             struct {
+		PreviewURL bool   `json:"preview_url"`
+		Body       string `json:"body"`
+	} `json:"text"`
+}
+
+// Send returns the WhatsApp message id, "wamid.…".
+func (w *WhatsApp) Send(ctx context.Context, msg usecase.NotificationMessage) (string, error) {
+	payload := whatsAppText{MessagingProduct: "whatsapp", RecipientType: "individual", Type: "text",
+		To: strings.TrimPrefix(msg.To, "+")}
+	payload.Text.PreviewURL = true
+	payload.Text.Body = msg.Text
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", usecase.Permanent(err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.apiURL+"/"+w.phoneNumberID+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", usecase.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+w.token)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := outbound.StatusError(resp); err != nil {
+		return "", err
+	}
+	var answer struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	// The message was accepted; an answer we can't read only loses its id.
+	if json.NewDecoder(resp.Body).Decode(&answer) == nil && len(answer.Messages) > 0 {
+		return answer.Messages[0].ID, nil
+	}
+	return "", nil
+}
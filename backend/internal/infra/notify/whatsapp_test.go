@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhatsApp_SendsTextMessage(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v21.0/1055/messages", r.URL.Path)
+		assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Write([]byte(`{"messaging_product":"whatsapp","contacts":[{"input":"77011234567","wa_id":"77011234567"}],"messages":[{"id":"wamid.HBgL"}]}`))
+	}))
+	defer srv.Close()
+
+	wa := NewWhatsApp(srv.Client(), srv.URL+"/v21.0/", "1055", "secret-token")
+	id, err := wa.Send(context.Background(), usecase.NotificationMessage{To: "+77011234567", Subject: "ignored", Text: "Ваше приглашение готово!"})
+	require.NoError(t, err)
+	assert.Equal(t, "wamid.HBgL", id)
+	assert.Equal(t, map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                "77011234567",
+		"type":              "text",
+		"text":              map[string]interface{}{"preview_url": true, "body": "Ваше приглашение готово!"},
+	}, got)
+}
+
+func TestWhatsApp_Errors(t *testing.T) {
+	status := http.StatusBadRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"error":{"message":"Recipient phone number not in allowed list","code":131030}}`))
+	}))
+	defer srv.Close()
+	wa := NewWhatsApp(srv.Client(), srv.URL, "1055", "secret-token")
+
+	_, err := wa.Send(context.Background(), usecase.NotificationMessage{To: "+77011234567", Text: "hi"})
+	require.Error(t, err)
+	assert.True(t, usecase.IsPermanent(err))
+	assert.Contains(t, err.Error(), "not in allowed list")
+
+	status = http.StatusServiceUnavailable
+	_, err = wa.Send(context.Background(), usecase.NotificationMessage{To: "+77011234567", Text: "hi"})
+	require.Error(t, err)
+	assert.False(t, usecase.IsPermanent(err))
+}
@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// WhatsAppSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+// body of a webhook call, keyed with the Meta app secret.
+const WhatsAppSignatureHeader = "X-Hub-Signature-256"
+
+// WhatsAppWebhook reads the calls the WhatsApp Cloud API makes to our
+// webhook when clients write to the business number.
+type WhatsAppWebhook struct {
+	appSecret []byte
+}
+
+// NewWhatsAppWebhook checks calls against the app secret of the Meta app
+// the number belongs to.
+func NewWhatsAppWebhook(appSecret string) *WhatsAppWebhook {
+	return &WhatsAppWebhook{appSecret: []byte(appSecret)}
+}
+
+type whatsAppNotification struct {
+	Entry []struct {
+		Changes []struct {
+			Field string `json:"field"`
+			Value struct {
+				Messages []whatsAppMessage `json:"messages"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+type whatsAppMessage struct {
+	ID   string `json:"id"`
+	From string `json:"from"`
+	Type string `json:"type"`
+	Text struct {
+		Body string `json:"body"`
+	} `json:"text"`
+	Image struct {
+		ID      string `json:"id"`
+		Caption string `json:"caption"`
+	} `json:"image"`
+	// Button is a quick reply to a template message.
+	Button struct {
+		Text string `json:"text"`
+	} `json:"button"`
+	Interactive struct {
+		ButtonReply struct {
+			Title string `json:"title"`
+		} `json:"button_reply"`
+		ListReply struct {
+			Title string `json:"title"`
+		} `json:"list_reply"`
+	} `json:"interactive"`
+}
+
+// ParseWebhook returns the messages clients sent, in order. Delivery and
+// read statuses come on the same webhook and are skipped.
+func (w *WhatsAppWebhook) ParseWebhook(header http.Header, body []byte) ([]usecase.InboundMessage, error) {
+	sig, ok := strings.CutPrefix(header.Get(WhatsAppSignatureHeader), "sha256=")
+	mac, err := hex.DecodeString(sig)
+	if !ok || err != nil || len(w.appSecret) == 0 || !hmac.Equal(mac, w.sign(body)) {
+		return nil, usecase.ErrWebhookSignature
+	}
+
+	var n whatsAppNotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, usecase.InputError("invalid webhook body")
+	}
+	var out []usecase.InboundMessage
+	for _, entry := range n.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+			for _, m := range change.Value.Messages {
+				msg := usecase.InboundMessage{ID: m.ID, From: m.From, Type: m.Type}
+				switch m.Type {
+				case "text":
+					msg.Text = m.Text.Body
+				case "image":
+					msg.MediaID, msg.Text = m.Image.ID, m.Image.Caption
+				case "button":
+					msg.Text = m.Button.Text
+				case "interactive":
+					msg.Text = m.Interactive.ButtonReply.Title + m.Interactive.ListReply.Title
+				}
+				out = append(out, msg)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (w *WhatsAppWebhook) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, w.appSecret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// SignWhatsApp returns the WhatsAppSignatureHeader value Meta sends with
+// body, for tests and local tools.
+func SignWhatsApp(appSecret string, body []byte) string {
+	return "sha256=" + hex.EncodeToString(NewWhatsAppWebhook(appSecret).sign(body))
+}
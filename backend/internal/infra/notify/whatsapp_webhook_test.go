@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhatsAppWebhook_ParsesMessages(t *testing.T) {
+	body := []byte(`{"object":"whatsapp_business_account","entry":[{"id":"102290129340398","changes":[
+		{"field":"messages","value":{"messaging_product":"whatsapp","metadata":{"phone_number_id":"1055"},
+			"contacts":[{"profile":{"name":"Arman"},"wa_id":"77011234567"}],
+			"messages":[
+				{"from":"77011234567","id":"wamid.1","timestamp":"1780000000","type":"text","text":{"body":"Звездная ночь"}},
+				{"from":"77011234567","id":"wamid.2","timestamp":"1780000001","type":"image","image":{"id":"media-1","mime_type":"image/jpeg","caption":"наше фото"}},
+				{"from":"77011234567","id":"wamid.3","timestamp":"1780000002","type":"interactive","interactive":{"type":"button_reply","button_reply":{"id":"yes","title":"Да"}}}]}},
+		{"field":"messages","value":{"messaging_product":"whatsapp","statuses":[{"id":"wamid.out","status":"delivered"}]}}]}]}`)
+	h := http.Header{}
+	h.Set(WhatsAppSignatureHeader, SignWhatsApp("app-secret", body))
+
+	msgs, err := NewWhatsAppWebhook("app-secret").ParseWebhook(h, body)
+	require.NoError(t, err)
+	assert.Equal(t, []usecase.InboundMessage{
+		{ID: "wamid.1", From: "77011234567", Type: "text", Text: "Звездная ночь"},
+		{ID: "wamid.2", From: "77011234567", Type: "image", Text: "наше фото", MediaID: "media-1"},
+		{ID: "wamid.3", From: "77011234567", Type: "interactive", Text: "Да"},
+	}, msgs)
+}
+
+func TestWhatsAppWebhook_RejectsUnsignedCalls(t *testing.T) {
+	body := []byte(`{"entry":[]}`)
+	for name, sig := range map[string]string{
+		"wrong secret": SignWhatsApp("other", body),
+		"no prefix":    SignWhatsApp("app-secret", body)[len("sha256="):],
+		"missing":      "",
+	} {
+		h := http.Header{}
+		h.Set(WhatsAppSignatureHeader, sig)
+		_, err := NewWhatsAppWebhook("app-secret").ParseWebhook(h, body)
+		assert.ErrorIs(t, err, usecase.ErrWebhookSignature, name)
+	}
+
+	h := http.Header{}
+	h.Set(WhatsAppSignatureHeader, SignWhatsApp("app-secret", []byte("not json")))
+	_, err := NewWhatsAppWebhook("app-secret").ParseWebhook(h, []byte("not json"))
+	var input usecase.InputError
+	assert.ErrorAs(t, err, &input)
+}
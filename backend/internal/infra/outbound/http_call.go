@@ -0,0 +1,61 @@
+// Package outbound makes the HTTP calls queued for other services.
+package outbound
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// NewHTTPCallHandler returns the handler of usecase.HTTPCallKind jobs. A
+// 2xx answer completes the job; timeouts, 408, 429 and 5xx are retried and
+// any other answer kills it.
+func NewHTTPCallHandler(client *http.Client) func(context.Context, usecase.HTTPCall) error {
+	return func(ctx context.Context, call usecase.HTTPCall) error {
+		method := call.Method
+		if method == "" {
+			method = http.MethodPost
+		}
+		var body io.Reader
+		if len(call.Body) > 0 {
+			body = bytes.NewReader(call.Body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, call.URL, body)
+		if err != nil {
+			return usecase.Permanent(err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		for k, v := range call.Headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		return StatusError(resp)
+	}
+}
+
+// StatusError turns an unsuccessful answer into an error carrying the start
+// of its body, permanent unless the call is worth retrying.
+func StatusError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	err := fmt.Errorf("%s %s: %s: %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status, bytes.TrimSpace(snippet))
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return err
+	default:
+		return usecase.Permanent(err)
+	}
+}
@@ -0,0 +1,43 @@
+package outbound
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPCallHandler(t *testing.T) {
+	status := http.StatusOK
+	var gotBody, gotAuth, gotType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody, gotAuth, gotType = string(b), r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	call := NewHTTPCallHandler(srv.Client())
+	job := usecase.HTTPCall{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}, Body: []byte(`{"a":1}`)}
+
+	require.NoError(t, call(context.Background(), job))
+	assert.Equal(t, `{"a":1}`, gotBody)
+	assert.Equal(t, "Bearer x", gotAuth)
+	assert.Equal(t, "application/json", gotType)
+
+	// Retried: no usecase.Permanent wrapper.
+	status = http.StatusServiceUnavailable
+	err := call(context.Background(), job)
+	require.Error(t, err)
+	assert.False(t, usecase.IsPermanent(err))
+
+	status = http.StatusNotFound
+	err = call(context.Background(), job)
+	require.Error(t, err)
+	assert.True(t, usecase.IsPermanent(err))
+}
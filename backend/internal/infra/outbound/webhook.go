@@ -0,0 +1,49 @@
+package outbound
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/payment"
+)
+
+// WebhookSender posts webhook deliveries signed the way the payment
+// webhooks we receive are: payment.TimestampHeader holds the Unix time and
+// payment.SignatureHeader "sha256=" and the hex HMAC-SHA256 of the
+// timestamp, a dot and the body.
+type WebhookSender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewWebhookSender(client *http.Client) *WebhookSender {
+	return &WebhookSender{client: client, now: time.Now}
+}
+
+func (s *WebhookSender) Send(ctx context.Context, url, secret string, header http.Header, body []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	ts := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "card-go-webhooks/1")
+	req.Header.Set(payment.TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(payment.SignatureHeader, "sha256="+hex.EncodeToString(payment.Sign([]byte(secret), ts, body)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return resp.StatusCode, string(response), nil
+}
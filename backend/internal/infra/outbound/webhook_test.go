@@ -0,0 +1,47 @@
+package outbound
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/payment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSender_SignsLikeIncomingWebhooks(t *testing.T) {
+	var got *http.Request
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, gotBody = r, must(io.ReadAll(r.Body))
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("queued"))
+	}))
+	defer srv.Close()
+
+	s := NewWebhookSender(srv.Client())
+	s.now = func() time.Time { return time.Unix(1767225600, 0) }
+	header := http.Header{"X-Webhook-Event": {"rsvp.submitted"}}
+	status, response, err := s.Send(context.Background(), srv.URL, "0123456789abcdef", header, []byte(`{"id":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, "queued", response)
+
+	assert.Equal(t, `{"id":1}`, string(gotBody))
+	assert.Equal(t, "rsvp.submitted", got.Header.Get("X-Webhook-Event"))
+	assert.Equal(t, "1767225600", got.Header.Get(payment.TimestampHeader))
+	want := "sha256=" + hex.EncodeToString(payment.Sign([]byte("0123456789abcdef"), 1767225600, gotBody))
+	assert.Equal(t, want, got.Header.Get(payment.SignatureHeader))
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,46 @@
+package payment
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// FakeProvider is a provider for local development. Nothing is charged and
+// webhooks are not signed: post a Webhook such as
+// {"orderId": "...", "status": "paid"} to /api/payments/fake/webhook to
+// settle an order; the amount may be left out. Never enable it in production.
+type FakeProvider struct {
+	// baseURL is the public origin the fake checkout link points to.
+	baseURL string
+}
+
+func NewFakeProvider(baseURL string) *FakeProvider {
+	return &FakeProvider{baseURL: baseURL}
+}
+
+func (p *FakeProvider) Name() string { return "fake" }
+
+// WebhooksOmitAmount lets fake webhooks settle orders without stating
+// the amount.
+func (p *FakeProvider) WebhooksOmitAmount() bool { return true }
+
+// Checkout links to the invitation itself, as there is nothing to pay.
+func (p *FakeProvider) Checkout(order *domain.Order) (string, string, error) {
+	return "fake_" + order.ID, p.baseURL + "/i/" + order.InvitationUUID, nil
+}
+
+// ParseWebhook accepts any well-formed Webhook. Without an id the event is
+// named after the order and status, so posting it twice is a redelivery.
+func (p *FakeProvider) ParseWebhook(_ http.Header, body []byte) (*domain.PaymentEvent, error) {
+	var w Webhook
+	if err := json.Unmarshal(body, &w); err != nil {
+		return nil, usecase.InputError("invalid webhook body")
+	}
+	if w.ID == "" {
+		w.ID = w.OrderID + ":" + w.Status
+	}
+	return w.event(body), nil
+}
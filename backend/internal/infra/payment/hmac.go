@@ -0,0 +1,113 @@
+// Package payment holds the payment providers of usecase.PaymentUseCase.
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+)
+
+// Headers of a signed webhook call.
+const (
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// DefaultTolerance is how far the timestamp of a webhook call may be from
+// the server clock.
+const DefaultTolerance = 5 * time.Minute
+
+// Webhook is the JSON body of a webhook call of the generic providers.
+type Webhook struct {
+	ID       string `json:"id"`
+	OrderID  string `json:"orderId"`
+	Status   string `json:"status"`
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (w *Webhook) event(body []byte) *domain.PaymentEvent {
+	return &domain.PaymentEvent{
+		EventID:  w.ID,
+		OrderID:  w.OrderID,
+		Status:   w.Status,
+		Amount:   w.Amount,
+		Currency: w.Currency,
+		Payload:  body,
+	}
+}
+
+// HMACProvider is a provider that only needs two things from a payment
+// service: a checkout page reached by URL, and webhooks posting a Webhook
+// signed with a shared secret. TimestampHeader holds the Unix time of the
+// call and SignatureHeader "sha256=" and the hex HMAC-SHA256 of the
+// timestamp, a dot and the body.
+type HMACProvider struct {
+	name   string
+	secret []byte
+	// checkoutURL may contain {order}, {amount} and {currency}.
+	checkoutURL string
+	tolerance   time.Duration
+	now         func() time.Time
+}
+
+func NewHMACProvider(name string, secret []byte, checkoutURL string) *HMACProvider {
+	return &HMACProvider{
+		name:        name,
+		secret:      secret,
+		checkoutURL: checkoutURL,
+		tolerance:   DefaultTolerance,
+		now:         time.Now,
+	}
+}
+
+func (p *HMACProvider) Name() string { return p.name }
+
+// Checkout fills in the checkout URL; the order id is the reference.
+func (p *HMACProvider) Checkout(order *domain.Order) (string, string, error) {
+	link := strings.NewReplacer(
+		"{order}", url.QueryEscape(order.ID),
+		"{amount}", strconv.FormatInt(order.Amount, 10),
+		"{currency}", url.QueryEscape(order.Currency),
+	).Replace(p.checkoutURL)
+	return order.ID, link, nil
+}
+
+func (p *HMACProvider) ParseWebhook(header http.Header, body []byte) (*domain.PaymentEvent, error) {
+	ts, err := strconv.ParseInt(header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return nil, usecase.ErrWebhookSignature
+	}
+	if d := p.now().Sub(time.Unix(ts, 0)); d > p.tolerance || d < -p.tolerance {
+		return nil, usecase.ErrWebhookSignature
+	}
+	sig, ok := strings.CutPrefix(header.Get(SignatureHeader), "sha256=")
+	mac, err := hex.DecodeString(sig)
+	if !ok || err != nil || !hmac.Equal(mac, Sign(p.secret, ts, body)) {
+		return nil, usecase.ErrWebhookSignature
+	}
+
+	var w Webhook
+	if err := json.Unmarshal(body, &w); err != nil {
+		return nil, usecase.InputError("invalid webhook body")
+	}
+	return w.event(body), nil
+}
+
+// Sign returns the HMAC-SHA256 of a webhook call made at the Unix time ts.
+func Sign(secret []byte, ts int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(ts, 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,66 @@
+package payment
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedHeader(secret []byte, ts int64, body []byte) http.Header {
+	h := http.Header{}
+	h.Set(TimestampHeader, strconv.FormatInt(ts, 10))
+	h.Set(SignatureHeader, "sha256="+hex.EncodeToString(Sign(secret, ts, body)))
+	return h
+}
+
+func TestHMACProvider_ParseWebhook(t *testing.T) {
+	secret := []byte("whsec")
+	now := time.Unix(1780000000, 0)
+	p := NewHMACProvider("kaspi", secret, "")
+	p.now = func() time.Time { return now }
+	body := []byte(`{"id":"ev-1","orderId":"o-1","status":"paid","amount":1500000,"currency":"KZT"}`)
+
+	ev, err := p.ParseWebhook(signedHeader(secret, now.Unix(), body), body)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.PaymentEvent{EventID: "ev-1", OrderID: "o-1", Status: "paid", Amount: 1500000, Currency: "KZT", Payload: body}, ev)
+
+	for name, h := range map[string]http.Header{
+		"wrong secret": signedHeader([]byte("other"), now.Unix(), body),
+		"stale":        signedHeader(secret, now.Add(-10*time.Minute).Unix(), body),
+		"unsigned":     {},
+	} {
+		_, err := p.ParseWebhook(h, body)
+		assert.ErrorIs(t, err, usecase.ErrWebhookSignature, name)
+	}
+
+	tampered := []byte(`{"id":"ev-1","orderId":"o-1","status":"paid","amount":1,"currency":"KZT"}`)
+	_, err = p.ParseWebhook(signedHeader(secret, now.Unix(), body), tampered)
+	assert.ErrorIs(t, err, usecase.ErrWebhookSignature)
+
+	garbage := []byte("status=paid")
+	_, err = p.ParseWebhook(signedHeader(secret, now.Unix(), garbage), garbage)
+	var input usecase.InputError
+	assert.ErrorAs(t, err, &input)
+}
+
+func TestHMACProvider_Checkout(t *testing.T) {
+	p := NewHMACProvider("kaspi", nil, "https://pay.test/checkout?order={order}&sum={amount}&cur={currency}")
+	ref, link, err := p.Checkout(&domain.Order{ID: "o-1", Amount: 1500000, Currency: "KZT"})
+	require.NoError(t, err)
+	assert.Equal(t, "o-1", ref)
+	assert.Equal(t, "https://pay.test/checkout?order=o-1&sum=1500000&cur=KZT", link)
+}
+
+func TestFakeProvider_ParseWebhook(t *testing.T) {
+	ev, err := NewFakeProvider("").ParseWebhook(nil, []byte(`{"orderId":"o-1","status":"paid"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "o-1:paid", ev.EventID)
+	assert.True(t, NewFakeProvider("").WebhooksOmitAmount())
+}
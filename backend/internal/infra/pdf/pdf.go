@@ -0,0 +1,303 @@
+// Package pdf writes simple PDF documents: pages with text in embedded
+// TrueType fonts, lines and rectangles. Text is encoded by glyph id, so any
+// script the font covers, Kazakh included, prints without code pages.
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"fmt"
+	"image/color"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/fonts"
+)
+
+// Size is a page size in points (1/72 inch).
+type Size struct{ W, H float64 }
+
+// ISO paper sizes.
+var (
+	A4 = Size{595.28, 841.89}
+	A5 = Size{419.53, 595.28}
+)
+
+// Document is a PDF under construction. Nothing is written until WriteTo.
+type Document struct {
+	title string
+	fonts []*Font
+	pages []*Page
+}
+
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// Font is a TrueType font registered with a document. Only the glyphs that
+// were drawn end up in the file.
+type Font struct {
+	face *fonts.Font
+	name string
+	used map[uint16]rune
+}
+
+// AddFont registers face for use on the document's pages.
+func (d *Document) AddFont(face *fonts.Font) *Font {
+	f := &Font{face: face, name: "F" + strconv.Itoa(len(d.fonts)+1), used: map[uint16]rune{}}
+	d.fonts = append(d.fonts, f)
+	return f
+}
+
+// Measure returns the width of s in points at the given size.
+func (f *Font) Measure(s string, size float64) float64 {
+	return f.face.Measure(s, size)
+}
+
+// Page is a single page. Coordinates are in points with the origin in the
+// top-left corner and y growing downwards, like in the image packages.
+type Page struct {
+	size    Size
+	content bytes.Buffer
+}
+
+// AddPage appends a new page of the given size.
+func (d *Document) AddPage(size Size) *Page {
+	p := &Page{size: size}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages returns the pages added so far, e.g. to stamp page numbers.
+func (d *Document) Pages() []*Page { return d.pages }
+
+func (p *Page) Size() Size { return p.size }
+
+// Text draws s with its baseline starting at (x, y).
+func (p *Page) Text(f *Font, size, x, y float64, s string, c color.RGBA) {
+	var hex strings.Builder
+	for _, r := range s {
+		gid := f.face.GlyphIndex(r)
+		if _, ok := f.used[gid]; !ok {
+			f.used[gid] = r
+		}
+		fmt.Fprintf(&hex, "%04X", gid)
+	}
+	fmt.Fprintf(&p.content, "BT %s /%s %s Tf %s %s Td <%s> Tj ET\n",
+		fill(c), f.name, num(size), num(x), num(p.size.H-y), hex.String())
+}
+
+// Line strokes a straight line.
+func (p *Page) Line(x1, y1, x2, y2, width float64, c color.RGBA) {
+	fmt.Fprintf(&p.content, "%s %s w %s %s m %s %s l S\n",
+		stroke(c), num(width), num(x1), num(p.size.H-y1), num(x2), num(p.size.H-y2))
+}
+
+// FillRect fills the rectangle with its top-left corner at (x, y).
+func (p *Page) FillRect(x, y, w, h float64, c color.RGBA) {
+	fmt.Fprintf(&p.content, "%s %s %s %s %s re f\n", fill(c), num(x), num(p.size.H-y-h), num(w), num(h))
+}
+
+// StrokeRect outlines the rectangle with its top-left corner at (x, y).
+func (p *Page) StrokeRect(x, y, w, h, width float64, c color.RGBA) {
+	fmt.Fprintf(&p.content, "%s %s w %s %s %s %s re S\n",
+		stroke(c), num(width), num(x), num(p.size.H-y-h), num(w), num(h))
+}
+
+func fill(c color.RGBA) string   { return rgb(c) + " rg" }
+func stroke(c color.RGBA) string { return rgb(c) + " RG" }
+
+func rgb(c color.RGBA) string {
+	return num(float64(c.R)/255) + " " + num(float64(c.G)/255) + " " + num(float64(c.B)/255)
+}
+
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-" || s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// writer numbers objects and remembers their offsets for the xref table.
+type writer struct {
+	w       *bufio.Writer
+	n       int64
+	offsets []int64
+	err     error
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(w.w, format, args...)
+	w.n += int64(n)
+	w.err = err
+}
+
+func (w *writer) object(id int, body string) {
+	w.offsets[id] = w.n
+	w.printf("%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes a Flate-compressed stream object.
+func (w *writer) stream(id int, dict string, data []byte) {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(data)
+	zw.Close()
+
+	w.offsets[id] = w.n
+	w.printf("%d 0 obj\n<< %s /Filter /FlateDecode /Length %d >>\nstream\n", id, dict, z.Len())
+	if w.err == nil {
+		n, err := w.w.Write(z.Bytes())
+		w.n += int64(n)
+		w.err = err
+	}
+	w.printf("\nendstream\nendobj\n")
+}
+
+// Objects: 1 catalog, 2 page tree, 3 info, then five per font and two per
+// page.
+const (
+	objCatalog = 1
+	objPages   = 2
+	objInfo    = 3
+	firstObj   = 4
+	objPerFont = 5
+	objPerPage = 2
+)
+
+// WriteTo writes the document.
+func (d *Document) WriteTo(out io.Writer) (int64, error) {
+	fontObj := func(i int) int { return firstObj + objPerFont*i }
+	pageObj := func(i int) int { return firstObj + objPerFont*len(d.fonts) + objPerPage*i }
+	total := pageObj(len(d.pages))
+
+	w := &writer{w: bufio.NewWriter(out), offsets: make([]int64, total)}
+	w.printf("%%PDF-1.7\n%%\xe2\xe3\xcf\xd3\n")
+
+	w.object(objCatalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", objPages))
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageObj(i))
+	}
+	w.object(objPages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	w.object(objInfo, fmt.Sprintf("<< /Title %s /Producer (card-go) >>", textString(d.title)))
+
+	var resources strings.Builder
+	for i, f := range d.fonts {
+		if err := d.writeFont(w, f, fontObj(i)); err != nil {
+			return w.n, err
+		}
+		fmt.Fprintf(&resources, "/%s %d 0 R ", f.name, fontObj(i))
+	}
+
+	for i, p := range d.pages {
+		id := pageObj(i)
+		w.object(id, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			objPages, num(p.size.W), num(p.size.H), resources.String(), id+1))
+		w.stream(id+1, "", p.content.Bytes())
+	}
+
+	xref := w.n
+	w.printf("xref\n0 %d\n0000000000 65535 f \n", total)
+	for _, off := range w.offsets[1:] {
+		w.printf("%010d 00000 n \n", off)
+	}
+	w.printf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", total, objCatalog, objInfo, xref)
+
+	if w.err != nil {
+		return w.n, w.err
+	}
+	return w.n, w.w.Flush()
+}
+
+// writeFont embeds f as a Type0 font with Identity-H encoding: the two-byte
+// codes in the content streams are glyph ids.
+func (d *Document) writeFont(w *writer, f *Font, id int) error {
+	gids := make([]int, 0, len(f.used))
+	keep := make(map[uint16]bool, len(f.used))
+	for gid := range f.used {
+		gids = append(gids, int(gid))
+		keep[gid] = true
+	}
+	sort.Ints(gids)
+
+	data, err := f.face.Subset(keep)
+	if err != nil {
+		return err
+	}
+
+	scale := 1000 / float64(f.face.UnitsPerEm())
+	em := func(v int) string { return num(float64(v) * scale) }
+
+	var widths strings.Builder
+	for _, gid := range gids {
+		fmt.Fprintf(&widths, "%d [%s] ", gid, em(f.face.Advance(uint16(gid))))
+	}
+
+	// Subset fonts are named with a tag unique to the glyph set.
+	sum := sha256.Sum256([]byte(fmt.Sprint(gids)))
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + sum[i]%26
+	}
+	name := string(tag) + "+" + f.face.PostScriptName()
+
+	bbox := f.face.BBox()
+	w.object(id, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		name, id+1, id+4))
+	w.object(id+1, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		name, id+2, widths.String()))
+	w.object(id+2, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%s %s %s %s] /ItalicAngle 0 /Ascent %s /Descent %s /CapHeight %s /StemV 80 /FontFile2 %d 0 R >>",
+		name, em(bbox[0]), em(bbox[1]), em(bbox[2]), em(bbox[3]),
+		em(f.face.Ascent()), em(f.face.Descent()), em(f.face.Ascent()), id+3))
+	w.stream(id+3, fmt.Sprintf("/Length1 %d", len(data)), data)
+	w.stream(id+4, "", toUnicode(gids, f.used))
+	return nil
+}
+
+// toUnicode builds the CMap that lets viewers copy and search the text.
+func toUnicode(gids []int, used map[uint16]rune) []byte {
+	var b bytes.Buffer
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	// A bfchar block holds at most 100 entries.
+	for start := 0; start < len(gids); start += 100 {
+		end := start + 100
+		if end > len(gids) {
+			end = len(gids)
+		}
+		fmt.Fprintf(&b, "%d beginbfchar\n", end-start)
+		for _, gid := range gids[start:end] {
+			fmt.Fprintf(&b, "<%04X> <%s>\n", gid, utf16Hex(string(used[uint16(gid)])))
+		}
+		b.WriteString("endbfchar\n")
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.Bytes()
+}
+
+func utf16Hex(s string) string {
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return b.String()
+}
+
+// textString encodes s as a PDF text string (UTF-16BE with BOM).
+func textString(s string) string {
+	return "<FEFF" + utf16Hex(s) + ">"
+}
@@ -0,0 +1,71 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/fonts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTo(t *testing.T) {
+	doc := New("Қонақтар тізімі")
+	f := doc.AddFont(fonts.Serif())
+	p := doc.AddPage(A5)
+	p.Text(f, 12, 40, 60, "Әйгерім", color.RGBA{A: 0xff})
+	p.Line(40, 70, 200, 70, 1, color.RGBA{A: 0xff})
+	doc.AddPage(A5)
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	data := buf.Bytes()
+
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.7\n")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	assert.Contains(t, string(data), "/Count 2")
+	assert.Contains(t, string(data), "+DejaVuSerif /Encoding /Identity-H")
+
+	// Every xref entry must point at its object.
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(data)
+	require.NotNil(t, m)
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		assert.True(t, bytes.HasPrefix(data[off:], []byte(fmt.Sprintf("%d 0 obj", i+1))), "object %d", i+1)
+	}
+
+	// The text is encoded by glyph id and can be copied back as Unicode.
+	streams := inflateAll(t, data)
+	gid := fmt.Sprintf("%04X", fonts.Serif().GlyphIndex('Ә'))
+	assert.Contains(t, streams, "<"+gid)
+	assert.Contains(t, streams, "<"+gid+"> <04D8>")
+}
+
+func TestTextStringIsUTF16(t *testing.T) {
+	assert.Equal(t, "<FEFF041A0430>", textString("Ка"))
+	assert.Equal(t, "0", num(-0.0001))
+	assert.Equal(t, "12.5", num(12.5))
+}
+
+func inflateAll(t *testing.T, data []byte) string {
+	var out bytes.Buffer
+	for _, loc := range regexp.MustCompile(`stream\n`).FindAllIndex(data, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(data[loc[1]:]))
+		if err != nil {
+			continue
+		}
+		b, _ := io.ReadAll(zr)
+		out.Write(b)
+	}
+	return out.String()
+}
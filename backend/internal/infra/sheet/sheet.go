@@ -0,0 +1,117 @@
+// Package sheet reads tabular uploads: XLSX workbooks and CSV files as
+// Excel and Google Sheets save them.
+package sheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/xlsx"
+)
+
+var ErrUnsupported = errors.New("unsupported_file_type")
+
+// Read parses an uploaded file by its extension (.csv, .txt or .xlsx).
+// rows[i] is the i-th line of the table, 1-based numbering being i+1.
+func Read(name string, data []byte) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx":
+		return xlsx.ReadRows(data)
+	case ".csv", ".txt":
+		return readCSV(data)
+	}
+	return nil, ErrUnsupported
+}
+
+// readCSV accepts UTF-8 with or without BOM and Windows-1251, which Excel
+// uses for "CSV" on Russian-locale Windows. The separator is whatever the
+// header line uses most: Excel picks ";" where "," is the decimal mark.
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		data = decode1251(data)
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = sniffSeparator(data)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var rows [][]string
+	for {
+		rec, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return rows, nil
+			}
+			return nil, err
+		}
+		// Read skips blank lines; keep numbering aligned with the file.
+		line, _ := r.FieldPos(0)
+		for len(rows) < line-1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, rec)
+	}
+}
+
+func sniffSeparator(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+	best, bestCount := ',', 0
+	for _, sep := range []rune{',', ';', '\t'} {
+		count, quoted := 0, false
+		for _, r := range string(line) {
+			switch {
+			case r == '"':
+				quoted = !quoted
+			case r == sep && !quoted:
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = sep, count
+		}
+	}
+	return best
+}
+
+func decode1251(data []byte) []byte {
+	var b strings.Builder
+	b.Grow(len(data) * 2)
+	for _, c := range data {
+		if c < 0x80 {
+			b.WriteByte(c)
+		} else {
+			b.WriteRune(cp1251[c-0x80])
+		}
+	}
+	return []byte(b.String())
+}
+
+// cp1251 maps bytes 0x80-0xFF of Windows-1251 to Unicode.
+var cp1251 = [128]rune{
+	'\u0402', '\u0403', '\u201a', '\u0453', '\u201e', '\u2026', '\u2020', '\u2021',
+	'\u20ac', '\u2030', '\u0409', '\u2039', '\u040a', '\u040c', '\u040b', '\u040f',
+	'\u0452', '\u2018', '\u2019', '\u201c', '\u201d', '\u2022', '\u2013', '\u2014',
+	'\ufffd', '\u2122', '\u0459', '\u203a', '\u045a', '\u045c', '\u045b', '\u045f',
+	'\u00a0', '\u040e', '\u045e', '\u0408', '\u00a4', '\u0490', '\u00a6', '\u00a7',
+	'\u0401', '\u00a9', '\u0404', '\u00ab', '\u00ac', '\u00ad', '\u00ae', '\u0407',
+	'\u00b0', '\u00b1', '\u0406', '\u0456', '\u0491', '\u00b5', '\u00b6', '\u00b7',
+	'\u0451', '\u2116', '\u0454', '\u00bb', '\u0458', '\u0405', '\u0455', '\u0457',
+	'\u0410', '\u0411', '\u0412', '\u0413', '\u0414', '\u0415', '\u0416', '\u0417',
+	'\u0418', '\u0419', '\u041a', '\u041b', '\u041c', '\u041d', '\u041e', '\u041f',
+	'\u0420', '\u0421', '\u0422', '\u0423', '\u0424', '\u0425', '\u0426', '\u0427',
+	'\u0428', '\u0429', '\u042a', '\u042b', '\u042c', '\u042d', '\u042e', '\u042f',
+	'\u0430', '\u0431', '\u0432', '\u0433', '\u0434', '\u0435', '\u0436', '\u0437',
+	'\u0438', '\u0439', '\u043a', '\u043b', '\u043c', '\u043d', '\u043e', '\u043f',
+	'\u0440', '\u0441', '\u0442', '\u0443', '\u0444', '\u0445', '\u0446', '\u0447',
+	'\u0448', '\u0449', '\u044a', '\u044b', '\u044c', '\u044d', '\u044e', '\u044f',
+}
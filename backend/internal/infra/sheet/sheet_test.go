@@ -0,0 +1,31 @@
+package sheet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV(t *testing.T) {
+	rows, err := Read("guests.csv", []byte("\xef\xbb\xbfИмя;Телефон\n\"Ахметов, Ерлан\";8 701 123 45 67\n\nӘсел;\n"))
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Имя", "Телефон"},
+		{"Ахметов, Ерлан", "8 701 123 45 67"},
+		nil,
+		{"Әсел", ""},
+	}, rows)
+}
+
+func TestReadCSV_Windows1251(t *testing.T) {
+	// "Имя,Гость" as Excel saves it on a Russian Windows.
+	rows, err := Read("GUESTS.CSV", []byte{0xc8, 0xec, 0xff, ',', 0xc3, 0xee, 0xf1, 0xf2, 0xfc})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"Имя", "Гость"}}, rows)
+}
+
+func TestReadUnsupported(t *testing.T) {
+	_, err := Read("guests.pdf", nil)
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
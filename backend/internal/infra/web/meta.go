@@ -0,0 +1,128 @@
+package web
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"strings"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/card"
+)
+
+// Meta is the set of head tags used by link previews (WhatsApp, Telegram,
+// Facebook, Twitter) for a single page.
+type Meta struct {
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	ImageWidth  int
+	ImageHeight int
+	Locale      string
+	NoIndex     bool
+}
+
+const defaultImage = "/images/landing_hero_bg.jpg"
+
+// InvitationMeta builds preview metadata for an invitation page.
+// baseURL is the public origin without a trailing slash.
+func InvitationMeta(inv *domain.Invitation, baseURL string) Meta {
+	lang := i18n.Normalize(inv.Lang)
+	couple := i18n.CoupleNames(inv.GroomName, inv.BrideName, lang)
+
+	desc := i18n.T(lang, "invitation_desc", couple)
+	if t, ok := inv.EventTime(); ok {
+		when := i18n.FormatDate(t, lang)
+		if tm := i18n.FormatTime(t); tm != "" {
+			when += ", " + tm
+		}
+		desc = i18n.T(lang, "invitation_desc_at", couple, when)
+	}
+	if inv.EventLocation != "" {
+		desc += " " + inv.EventLocation
+	}
+
+	return Meta{
+		Title:       i18n.T(lang, "invitation_title", couple),
+		Description: desc,
+		URL:         baseURL + "/i/" + inv.UUID,
+		// The version parameter makes messengers refetch the card after edits.
+		Image:       baseURL + "/api/invitations/" + inv.UUID + "/card.png?v=" + card.Key(inv),
+		ImageWidth:  card.Width,
+		ImageHeight: card.Height,
+		Locale:      i18n.OGLocale(lang),
+		NoIndex:     true,
+	}
+}
+
+// NeutralMeta is served for missing or expired invitations. It carries the
+// site-wide texts only, so a preview never reveals whether a link existed.
+func NeutralMeta(baseURL string) Meta {
+	return Meta{
+		Title:       i18n.T(i18n.DefaultLang, "site_title"),
+		Description: i18n.T(i18n.DefaultLang, "site_description"),
+		URL:         baseURL + "/",
+		Image:       baseURL + defaultImage,
+		Locale:      i18n.OGLocale(i18n.DefaultLang),
+		NoIndex:     true,
+	}
+}
+
+var metaTmpl = template.Must(template.New("meta").Parse(`<title>{{.Title}}</title>
+    <meta name="title" content="{{.Title}}" />
+    <meta name="description" content="{{.Description}}" />
+{{- if .NoIndex}}
+    <meta name="robots" content="noindex, nofollow" />
+{{- end}}
+    <meta property="og:type" content="website" />
+    <meta property="og:url" content="{{.URL}}" />
+    <meta property="og:title" content="{{.Title}}" />
+    <meta property="og:description" content="{{.Description}}" />
+    <meta property="og:image" content="{{.Image}}" />
+{{- if .ImageWidth}}
+    <meta property="og:image:type" content="image/png" />
+    <meta property="og:image:width" content="{{.ImageWidth}}" />
+    <meta property="og:image:height" content="{{.ImageHeight}}" />
+{{- end}}
+    <meta property="og:locale" content="{{.Locale}}" />
+    <meta property="twitter:card" content="summary_large_image" />
+    <meta property="twitter:url" content="{{.URL}}" />
+    <meta property="twitter:title" content="{{.Title}}" />
+    <meta property="twitter:description" content="{{.Description}}" />
+    <meta property="twitter:image" content="{{.Image}}" />
+`))
+
+// RenderMeta renders the head tags for m.
+func RenderMeta(m Meta) string {
+	var buf bytes.Buffer
+	// The template only touches string fields, so Execute cannot fail.
+	_ = metaTmpl.Execute(&buf, m)
+	return buf.String()
+}
+
+var (
+	titleRe     = regexp.MustCompile(`(?is)\s*<title>.*?</title>`)
+	previewRe   = regexp.MustCompile(`(?i)\s*<meta\s+(?:name="(?:title|description|robots)"|property="(?:og|twitter):[^"]*")[^>]*>`)
+	headCloseRe = regexp.MustCompile(`(?i)</head>`)
+)
+
+// InjectMeta replaces the title and preview tags of the SPA index.html with
+// the ones from m. Other head content (scripts, styles, icons) is kept.
+func InjectMeta(index []byte, m Meta) []byte {
+	html := titleRe.ReplaceAllString(string(index), "")
+	html = previewRe.ReplaceAllString(html, "")
+
+	loc := headCloseRe.FindStringIndex(html)
+	if loc == nil {
+		return []byte(RenderMeta(m) + html)
+	}
+	var b strings.Builder
+	b.WriteString(html[:loc[0]])
+	b.WriteString("  ")
+	b.WriteString(RenderMeta(m))
+	b.WriteString("  ")
+	b.WriteString(html[loc[0]:])
+	return []byte(b.String())
+}
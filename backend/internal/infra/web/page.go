@@ -0,0 +1,304 @@
+package web
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+)
+
+//go:embed packs
+var packFS embed.FS
+
+// DefaultPack is used for template codes without a pack of their own.
+const DefaultPack = "starry-night"
+
+// PageOptions controls how an invitation page is rendered.
+type PageOptions struct {
+	// BaseURL is the public origin, used for canonical and preview URLs.
+	BaseURL string
+	// RSVPAction is the form target. An empty action hides the RSVP form,
+	// e.g. in offline exports.
+	RSVPAction string
+	// RSVPStatus is "ok" after a successful submission, "invalid" when the
+	// submitted form was incomplete and "invalid_phone" when its phone
+	// number was not one.
+	RSVPStatus string
+	// StylesheetHref links the pack stylesheet instead of inlining it.
+	StylesheetHref string
+	// ImageURL overrides the preview image, e.g. with a file in an export.
+	ImageURL string
+}
+
+// PageView is the data passed to template packs.
+type PageView struct {
+	Lang     string
+	Pack     string
+	UUID     string
+	Meta     template.HTML
+	CSS      template.CSS
+	CSSHref  string
+	Groom    string
+	Bride    string
+	Couple   string
+	Date     string
+	Time     string
+	DateTime string
+	Location string
+	Address  string
+	MapURL   string
+	Dress    string
+	Story    string
+	Schedule []domain.ScheduleItem
+
+	RSVPAction string
+	RSVPStatus string
+
+	Expired bool
+
+	// StopAction, StopPhone and StopStatus fill the opt-out page of guest
+	// reminders, see RenderReminderStop.
+	StopAction string
+	StopPhone  string
+	StopStatus string
+}
+
+// TF returns a localized text with args applied.
+func (v PageView) TF(key string, args ...interface{}) string {
+	return i18n.T(v.Lang, key, args...)
+}
+
+// T returns a localized text in the page language.
+func (v PageView) T(key string) string {
+	return i18n.T(v.Lang, key)
+}
+
+// packTextSuffix names the text variants a pack uses, e.g. "rsvp_title_silk".
+var packTextSuffix = map[string]string{
+	"silk-ivory": "_silk",
+}
+
+// TV returns the pack's variant of a text, falling back to the plain key.
+func (v PageView) TV(key string) string {
+	if suffix, ok := packTextSuffix[v.Pack]; ok {
+		if msg, ok := i18n.Lookup(v.Lang, key+suffix); ok {
+			return msg
+		}
+	}
+	return v.T(key)
+}
+
+// PageRenderer renders invitations with html/template packs, one per
+// templates.code. Each pack lives in packs/<code>/ and provides a "body"
+// template and a style.css.
+type PageRenderer struct {
+	packs map[string]*pack
+}
+
+type pack struct {
+	tmpl *template.Template
+	css  template.CSS
+}
+
+// NewPageRenderer parses all embedded template packs.
+func NewPageRenderer() (*PageRenderer, error) {
+	r := &PageRenderer{packs: map[string]*pack{}}
+	entries, err := fs.ReadDir(packFS, "packs")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		code := e.Name()
+		tmpl, err := template.ParseFS(packFS, "packs/*.html.tmpl", path.Join("packs", code, "*.html.tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("pack %s: %w", code, err)
+		}
+		css, err := packFS.ReadFile(path.Join("packs", code, "style.css"))
+		if err != nil {
+			return nil, fmt.Errorf("pack %s: %w", code, err)
+		}
+		r.packs[code] = &pack{tmpl: tmpl, css: template.CSS(css)}
+	}
+	if r.packs[DefaultPack] == nil {
+		return nil, fmt.Errorf("default pack %q is missing", DefaultPack)
+	}
+	return r, nil
+}
+
+// Stylesheet returns the CSS of the pack used for inv.
+func (r *PageRenderer) Stylesheet(inv *domain.Invitation) []byte {
+	_, p := r.pack(inv.TemplateCode)
+	return []byte(p.css)
+}
+
+// pack returns the pack for a template code, falling back to DefaultPack.
+func (r *PageRenderer) pack(code string) (string, *pack) {
+	if p := r.packs[code]; p != nil {
+		return code, p
+	}
+	return DefaultPack, r.packs[DefaultPack]
+}
+
+// HasPack reports whether code has its own template pack.
+func (r *PageRenderer) HasPack(code string) bool {
+	return r.packs[code] != nil
+}
+
+func (r *PageRenderer) view(inv *domain.Invitation, opts PageOptions) (PageView, *pack) {
+	code, p := r.pack(inv.TemplateCode)
+	lang := i18n.Normalize(inv.Lang)
+
+	meta := InvitationMeta(inv, opts.BaseURL)
+	if opts.ImageURL != "" {
+		meta.Image = opts.ImageURL
+	}
+
+	v := PageView{
+		Lang:       lang,
+		Pack:       code,
+		UUID:       inv.UUID,
+		Meta:       template.HTML(RenderMeta(meta)),
+		CSS:        p.css,
+		CSSHref:    opts.StylesheetHref,
+		Groom:      inv.GroomName,
+		Bride:      inv.BrideName,
+		Couple:     i18n.CoupleNames(inv.GroomName, inv.BrideName, lang),
+		Location:   inv.EventLocation,
+		Address:    inv.ContentString(domain.ContentAddress),
+		MapURL:     safeURL(inv.ContentString(domain.ContentMapURL)),
+		Dress:      inv.ContentString(domain.ContentDressCode),
+		Story:      inv.ContentString(domain.ContentStory),
+		Schedule:   inv.Schedule(),
+		RSVPAction: opts.RSVPAction,
+		RSVPStatus: opts.RSVPStatus,
+	}
+	if t, ok := inv.EventTime(); ok {
+		v.Date = i18n.FormatDate(t, lang)
+		v.Time = i18n.FormatTime(t)
+		v.DateTime = t.Format("2006-01-02T15:04")
+	} else {
+		v.Date = inv.EventDate
+	}
+	if v.Dress == "" {
+		v.Dress = i18n.T(lang, "dress_code_text")
+	}
+	if v.Story == "" {
+		v.Story = i18n.T(lang, "default_story")
+	}
+	if len(v.Schedule) == 0 {
+		for i := 0; i < 3; i++ {
+			v.Schedule = append(v.Schedule, domain.ScheduleItem{
+				Time:        i18n.T(lang, fmt.Sprintf("schedule_%d_time", i)),
+				Name:        i18n.T(lang, fmt.Sprintf("schedule_%d_name", i)),
+				Description: i18n.T(lang, fmt.Sprintf("schedule_%d_desc", i)),
+			})
+		}
+	}
+	return v, p
+}
+
+// RenderDocument writes a complete, script-free HTML document.
+func (r *PageRenderer) RenderDocument(w io.Writer, inv *domain.Invitation, opts PageOptions) error {
+	v, p := r.view(inv, opts)
+	return p.tmpl.ExecuteTemplate(w, "document", v)
+}
+
+// RenderUnavailable writes the script-free page shown for missing or expired
+// invitations. Like NeutralMeta it reveals nothing about the invitation.
+func (r *PageRenderer) RenderUnavailable(w io.Writer, expired bool, baseURL string) error {
+	v := PageView{
+		Lang:    i18n.DefaultLang,
+		Pack:    DefaultPack,
+		Meta:    template.HTML(RenderMeta(NeutralMeta(baseURL))),
+		CSS:     r.packs[DefaultPack].css,
+		Expired: expired,
+	}
+	return r.packs[DefaultPack].tmpl.ExecuteTemplate(w, "unavailable", v)
+}
+
+// Statuses of the reminder opt-out page.
+const (
+	// StopConfirm asks to confirm: link previews fetch the page, so only
+	// the form's POST opts out.
+	StopConfirm = "confirm"
+	StopDone    = "done"
+	StopInvalid = "invalid"
+)
+
+// RenderReminderStop writes the script-free page a guest reaches from the
+// stop link of a reminder: status is StopConfirm, with the form posting
+// to action, StopDone or StopInvalid.
+func (r *PageRenderer) RenderReminderStop(w io.Writer, lang, phone, action, status, baseURL string) error {
+	v := PageView{
+		Lang:       i18n.Normalize(lang),
+		Pack:       DefaultPack,
+		Meta:       template.HTML(RenderMeta(NeutralMeta(baseURL))),
+		CSS:        r.packs[DefaultPack].css,
+		StopAction: action,
+		StopPhone:  phone,
+		StopStatus: status,
+	}
+	return r.packs[DefaultPack].tmpl.ExecuteTemplate(w, "reminder-stop", v)
+}
+
+// Fragment is the server-rendered markup placed into the SPA shell.
+type Fragment struct {
+	Style string
+	Body  string
+}
+
+// RenderFragment renders the invitation body and its stylesheet for
+// embedding into the SPA index.html.
+func (r *PageRenderer) RenderFragment(inv *domain.Invitation, opts PageOptions) (Fragment, error) {
+	v, p := r.view(inv, opts)
+	var body, style bytes.Buffer
+	if err := p.tmpl.ExecuteTemplate(&body, "page", v); err != nil {
+		return Fragment{}, err
+	}
+	if err := p.tmpl.ExecuteTemplate(&style, "style", v); err != nil {
+		return Fragment{}, err
+	}
+	return Fragment{Style: style.String(), Body: body.String()}, nil
+}
+
+// safeURL keeps only http(s) links; anything else is dropped rather than
+// rendered into an href.
+func safeURL(u string) string {
+	l := strings.ToLower(u)
+	if strings.HasPrefix(l, "https://") || strings.HasPrefix(l, "http://") {
+		return u
+	}
+	return ""
+}
+
+// InjectApp places the server-rendered fragment inside the SPA mount point
+// and the invitation JSON next to it, so the SPA can start from the same
+// data without refetching. The SPA replaces the markup when it mounts.
+func InjectApp(index []byte, frag Fragment, state interface{}) ([]byte, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	html := string(index)
+
+	if i := strings.Index(html, `<div id="app"></div>`); i >= 0 {
+		html = html[:i] + `<div id="app">` + frag.Body + `</div>` + "\n    " +
+			`<script id="invitation-data" type="application/json">` + string(data) + `</script>` +
+			html[i+len(`<div id="app"></div>`):]
+	}
+	if loc := headCloseRe.FindStringIndex(html); loc != nil {
+		html = html[:loc[0]] + frag.Style + "\n  " + html[loc[0]:]
+	}
+	return []byte(html), nil
+}
@@ -0,0 +1,290 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxPartSize caps the decompressed size of a workbook part, so a small
+// upload cannot expand into gigabytes.
+const maxPartSize = 64 << 20
+
+// maxRows is Excel's own row limit.
+const maxRows = 1 << 20
+
+var ErrNoSheet = errors.New("xlsx: workbook has no sheets")
+
+// ReadRows returns the cell text of the first worksheet. rows[i] is row
+// i+1 as numbered in Excel; empty rows are nil and short rows are not
+// padded. Numbers come back the way Excel shows them in General format
+// (77011234567, not 7.7011234567E10).
+func ReadRows(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared []string
+	if f := files["xl/sharedStrings.xml"]; f != nil {
+		if shared, err = sharedStrings(f); err != nil {
+			return nil, err
+		}
+	}
+	f := files[sheetPath]
+	if f == nil {
+		return nil, ErrNoSheet
+	}
+	return sheetRows(f, shared)
+}
+
+func openPart(f *zip.File) (io.ReadCloser, *xml.Decoder, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	return rc, xml.NewDecoder(io.LimitReader(rc, maxPartSize)), nil
+}
+
+// firstSheet resolves the part name of the first sheet in the workbook.
+func firstSheet(files map[string]*zip.File) (string, error) {
+	wb, rels := files["xl/workbook.xml"], files["xl/_rels/workbook.xml.rels"]
+	if wb == nil || rels == nil {
+		return "", ErrNoSheet
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodePart(wb, &workbook); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", ErrNoSheet
+	}
+
+	var relationships struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodePart(rels, &relationships); err != nil {
+		return "", err
+	}
+	for _, r := range relationships.Rels {
+		if r.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			return strings.TrimPrefix(r.Target, "/"), nil
+		}
+		return path.Join("xl", r.Target), nil
+	}
+	return "", ErrNoSheet
+}
+
+func decodePart(f *zip.File, v interface{}) error {
+	rc, dec, err := openPart(f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return dec.Decode(v)
+}
+
+// sharedStrings reads the string table. Rich text runs are joined;
+// phonetic hints (rPh) are skipped.
+func sharedStrings(f *zip.File) ([]string, error) {
+	rc, dec, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var out []string
+	var cur strings.Builder
+	inText, skip := false, 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				cur.Reset()
+			case "rPh":
+				skip++
+			case "t":
+				inText = skip == 0
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				out = append(out, cur.String())
+			case "rPh":
+				skip--
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				cur.Write(t)
+			}
+		}
+	}
+}
+
+func sheetRows(f *zip.File, shared []string) ([][]string, error) {
+	rc, dec, err := openPart(f)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var rows [][]string
+	rowNum, col := 0, 0
+	var cellType string
+	var value strings.Builder
+	inValue := false
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				rowNum++
+				if r := attr(t, "r"); r != "" {
+					if n, err := strconv.Atoi(r); err == nil && n >= rowNum {
+						rowNum = n
+					}
+				}
+				if rowNum > maxRows {
+					return nil, errors.New("xlsx: too many rows")
+				}
+				col = -1
+			case "c":
+				col++
+				if ref := attr(t, "r"); ref != "" {
+					if c, ok := columnIndex(ref); ok {
+						col = c
+					}
+				}
+				cellType = attr(t, "t")
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				text, err := cellText(cellType, value.String(), shared)
+				if err != nil {
+					return nil, fmt.Errorf("xlsx: row %d: %w", rowNum, err)
+				}
+				if text == "" {
+					continue
+				}
+				for len(rows) < rowNum {
+					rows = append(rows, nil)
+				}
+				row := rows[rowNum-1]
+				for len(row) <= col {
+					row = append(row, "")
+				}
+				row[col] = text
+				rows[rowNum-1] = row
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
+
+func cellText(typ, v string, shared []string) (string, error) {
+	switch typ {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || i < 0 || i >= len(shared) {
+			return "", errors.New("bad shared string index")
+		}
+		return shared[i], nil
+	case "b":
+		if strings.TrimSpace(v) == "1" {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case "", "n":
+		return formatNumber(v), nil
+	}
+	// inlineStr, str (formula result) and e (error) are kept as written.
+	return v, nil
+}
+
+// formatNumber prints whole numbers without exponent or fraction, the way
+// phone numbers typed into a General cell look in Excel.
+func formatNumber(v string) string {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return v
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatFloat(f, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// columnIndex parses the column of a cell reference: "C12" is 2.
+func columnIndex(ref string) (int, bool) {
+	n := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		n = n*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 || n > 16384 {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Қонақтар: 2026", 20, 10)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteHeader("Аты-жөні", "Саны"))
+	when := time.Date(2026, 7, 15, 18, 0, 0, 0, time.UTC)
+	require.NoError(t, w.WriteRow("Ғалым <&>", 2, when, true, nil, (*time.Time)(nil)))
+	require.NoError(t, w.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(data)
+
+		// Every part must be well-formed XML.
+		dec := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			_, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, f.Name)
+		}
+	}
+
+	assert.Contains(t, parts["xl/workbook.xml"], `name="Қонақтар- 2026"`)
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Аты-жөні</t></is></c>`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">Ғалым &lt;&amp;&gt;</t>`)
+	assert.Contains(t, sheet, `<c r="B2" s="0"><v>2</v></c>`)
+	assert.Contains(t, sheet, `<c r="C2" s="2"><v>46218.75</v></c>`)
+	assert.Contains(t, sheet, `<c r="D2" s="0" t="b"><v>1</v></c>`)
+	assert.NotContains(t, sheet, `r="E2"`)
+}
+
+func TestColumn(t *testing.T) {
+	assert.Equal(t, "A", column(0))
+	assert.Equal(t, "Z", column(25))
+	assert.Equal(t, "AA", column(26))
+	assert.Equal(t, "AZ", column(51))
+	assert.Equal(t, "BA", column(52))
+}
+
+func TestReadRows(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Guests")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteHeader("Аты-жөні", "Телефон"))
+	require.NoError(t, w.WriteRow("Ғалым", 77011234567))
+	require.NoError(t, w.WriteRow())
+	require.NoError(t, w.WriteRow(nil, "Әсел"))
+	require.NoError(t, w.Close())
+
+	rows, err := ReadRows(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Аты-жөні", "Телефон"},
+		{"Ғалым", "77011234567"},
+		nil,
+		{"", "Әсел"},
+	}, rows)
+}
+
+func TestReadRows_SharedStrings(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="A" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId7" Target="/xl/worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>Имя</t></si><si><r><t>Қай</t></r><r><t>рат</t></r><rPh><t>x</t></rPh></si></sst>`,
+		"xl/worksheets/data.xml":     `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>0</v></c></row><row r="3"><c r="B3" t="s"><v>1</v></c><c r="C3"><v>7.7011234567E10</v></c></row></sheetData></worksheet>`,
+	} {
+		f, _ := zw.Create(name)
+		f.Write([]byte(body))
+	}
+	require.NoError(t, zw.Close())
+
+	rows, err := ReadRows(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"Имя"}, nil, {"", "Қайрат", "77011234567"}}, rows)
+}
@@ -2,6 +2,9 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -13,6 +16,7 @@ type AdminUseCase struct {
 	username  string
 	password  string
 	jwtSecret []byte
+	now       func() time.Time
 }
 
 func NewAdminUseCase(repo domain.AdminRepository, user, pass string, secret []byte) *AdminUseCase {
@@ -21,6 +25,7 @@ func NewAdminUseCase(repo domain.AdminRepository, user, pass string, secret []by
 		username:  user,
 		password:  pass,
 		jwtSecret: secret,
+		now:       time.Now,
 	}
 }
 
@@ -41,8 +46,177 @@ func (u *AdminUseCase) GetStats() (*domain.AdminStats, error) {
 	return u.repo.GetStats()
 }
 
-func (u *AdminUseCase) GetInvitations() ([]domain.InvitationWithStats, error) {
-	return u.repo.GetInvitationsList()
+// ReconcileRSVPCounters rebuilds the per-invitation RSVP counters and
+// returns how many invitations had drifted.
+func (u *AdminUseCase) ReconcileRSVPCounters() (int, error) {
+	return u.repo.ReconcileRSVPCounters()
+}
+
+// analyticsRanges are the default and the longest range of Analytics in
+// days, by granularity.
+var analyticsRanges = map[string][2]int{
+	domain.GranularityDay:   {30, 366},
+	domain.GranularityWeek:  {182, 3 * 366},
+	domain.GranularityMonth: {365, 5 * 366},
+}
+
+// Analytics reports the business between the dates from and to
+// (YYYY-MM-DD, UTC) per granularity, "day" by default. The range is widened
+// to whole periods, and periods without activity are filled in so the
+// series can be charted as is.
+func (u *AdminUseCase) Analytics(from, to, granularity string) (*domain.BusinessAnalytics, error) {
+	if granularity == "" {
+		granularity = domain.GranularityDay
+	}
+	limits, ok := analyticsRanges[granularity]
+	if !ok {
+		return nil, InputError(fmt.Sprintf("unsupported granularity: %q", granularity))
+	}
+	start, end, err := dateRange(from, to, u.now(), limits[0], limits[1])
+	if err != nil {
+		return nil, err
+	}
+	start = periodStart(start, granularity)
+	if p := periodStart(end, granularity); p.Before(end) {
+		end = nextPeriod(p, granularity)
+	}
+
+	a, err := u.repo.GetAnalytics(start, end, granularity)
+	if err != nil {
+		return nil, err
+	}
+	a.From, a.To = start.Format(dateLayout), end.AddDate(0, 0, -1).Format(dateLayout)
+	a.Granularity = granularity
+
+	byPeriod := make(map[string]domain.AnalyticsPeriod, len(a.Series))
+	for _, p := range a.Series {
+		byPeriod[p.Period] = p
+	}
+	series := []domain.AnalyticsPeriod{}
+	for day := start; day.Before(end); day = nextPeriod(day, granularity) {
+		key := day.Format(dateLayout)
+		p, ok := byPeriod[key]
+		if !ok {
+			p = domain.AnalyticsPeriod{Period: key}
+		}
+		p.Conversion = ratio(p.Paid, p.Invitations)
+		series = append(series, p)
+
+		a.Totals.Invitations += p.Invitations
+		a.Totals.Paid += p.Paid
+		a.Totals.RSVPs += p.RSVPs
+		a.Totals.Guests += p.Guests
+	}
+	a.Series = series
+	a.Totals.Conversion = ratio(a.Totals.Paid, a.Totals.Invitations)
+
+	for _, shares := range [][]domain.AnalyticsShare{a.Templates, a.Languages} {
+		for i := range shares {
+			shares[i].Share = ratio(shares[i].Invitations, a.Totals.Invitations)
+		}
+	}
+	return a, nil
+}
+
+// periodStart truncates a UTC date to the start of its period, weeks
+// starting on Monday as in Postgres date_trunc.
+func periodStart(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case domain.GranularityWeek:
+		return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	case domain.GranularityMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t
+}
+
+func nextPeriod(t time.Time, granularity string) time.Time {
+	switch granularity {
+	case domain.GranularityWeek:
+		return t.AddDate(0, 0, 7)
+	case domain.GranularityMonth:
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// ratio is part/total rounded to four places, 0 when total is.
+func ratio(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
+
+// Page sizes of the admin invitation list.
+const (
+	DefaultInvitationPageSize = 50
+	MaxInvitationPageSize     = 200
+)
+
+// GetInvitations returns a page of the admin list, DefaultInvitationPageSize
+// invitations unless filter.Limit asks for another size.
+func (u *AdminUseCase) GetInvitations(filter domain.InvitationFilter) (*domain.InvitationPage, error) {
+	if filter.Limit == 0 {
+		filter.Limit = DefaultInvitationPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > MaxInvitationPageSize {
+		return nil, InputError(fmt.Sprintf("limit must be between 1 and %d", MaxInvitationPageSize))
+	}
+	if filter.Offset < 0 {
+		return nil, InputError("offset must not be negative")
+	}
+	if err := prepareFilter(&filter); err != nil {
+		return nil, err
+	}
+	return u.repo.GetInvitationsList(filter)
+}
+
+// EachInvitation streams the filtered invitation list to fn, for exports.
+// Exports are never paged.
+func (u *AdminUseCase) EachInvitation(filter domain.InvitationFilter, fn func(*domain.InvitationWithStats) error) error {
+	filter.Limit, filter.Offset = 0, 0
+	if err := prepareFilter(&filter); err != nil {
+		return err
+	}
+	return u.repo.EachInvitation(filter, fn)
+}
+
+// prepareFilter validates the sort key and normalizes the search: a phone
+// number, however it is typed, is looked up by its last ten digits, which
+// "+7 701 ...", "8701..." and "7701..." have in common.
+func prepareFilter(filter *domain.InvitationFilter) error {
+	if filter.Sort != "" && !domain.IsInvitationSort(filter.Sort) {
+		return InputError(fmt.Sprintf("unsupported sort: %q", filter.Sort))
+	}
+	if filter.EventFrom != nil && filter.EventTo != nil && filter.EventTo.Before(*filter.EventFrom) {
+		return InputError("eventFrom must not be after eventTo")
+	}
+	search := strings.Join(strings.Fields(filter.Search), " ")
+	if digits := phoneDigits(search); len(digits) >= 5 {
+		if len(digits) > 10 {
+			digits = digits[len(digits)-10:]
+		}
+		search = digits
+	}
+	filter.Search = search
+	return nil
+}
+
+// phoneDigits returns the digits of s if it looks like a phone number, and
+// "" otherwise.
+func phoneDigits(s string) string {
+	var digits strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case strings.ContainsRune("+-() ", r):
+		default:
+			return ""
+		}
+	}
+	return digits.String()
 }
 
 func (u *AdminUseCase) GetTemplates() ([]domain.Template, error) {
@@ -52,3 +226,22 @@ func (u *AdminUseCase) GetTemplates() ([]domain.Template, error) {
 func (u *AdminUseCase) MarkAsPaid(uuid string) error {
 	return u.repo.MarkAsPaid(uuid)
 }
+
+// DefaultExpiringWithin is how far ahead ExpiringInvitations looks by
+// default.
+const DefaultExpiringWithin = 72 * time.Hour
+
+// ExpiringInvitations lists the invitations whose trial or paid hosting
+// ends within the given duration ("48h"; DefaultExpiringWithin when
+// empty), soonest first.
+func (u *AdminUseCase) ExpiringInvitations(within string) ([]domain.ExpiringInvitation, error) {
+	d := DefaultExpiringWithin
+	if within != "" {
+		var err error
+		if d, err = time.ParseDuration(within); err != nil || d <= 0 || d > maxTrial {
+			return nil, InputError(fmt.Sprintf("within must be a positive duration of at most %d days, e.g. 48h", maxTrial/(24*time.Hour)))
+		}
+	}
+	now := u.now()
+	return u.repo.ListExpiring(now, now.Add(d))
+}
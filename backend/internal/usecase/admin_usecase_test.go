@@ -3,6 +3,7 @@ package usecase
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
 	"github.com/stretchr/testify/assert"
@@ -44,12 +45,65 @@ func TestGetInvitations(t *testing.T) {
 	mockRepo := new(MockAdminRepository)
 	uc := NewAdminUseCase(mockRepo, "admin", "password", []byte("s"))
 
-	expectedList := []domain.InvitationWithStats{{Invitation: domain.Invitation{ID: 1}}}
-	mockRepo.On("GetInvitationsList").Return(expectedList, nil)
+	expectedPage := &domain.InvitationPage{Items: []domain.InvitationWithStats{{Invitation: domain.Invitation{ID: 1}}}, Total: 1, Limit: DefaultInvitationPageSize}
+	paid := true
+	filter := domain.InvitationFilter{Paid: &paid, Lang: "kk"}
+	paged := filter
+	paged.Limit = DefaultInvitationPageSize
+	mockRepo.On("GetInvitationsList", paged).Return(expectedPage, nil)
 
-	list, err := uc.GetInvitations()
+	page, err := uc.GetInvitations(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPage, page)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetInvitations_NormalizesSearch(t *testing.T) {
+	cases := map[string]string{
+		"  Arman   Aigerim ": "Arman Aigerim",
+		"+7 (701) 123-45-67": "7011234567",
+		"8 701 123 45 67":    "7011234567",
+		"701 12":             "70112",
+		"2026":               "2026",
+		"AbCd12":             "AbCd12",
+	}
+	for search, want := range cases {
+		mockRepo := new(MockAdminRepository)
+		uc := NewAdminUseCase(mockRepo, "admin", "password", []byte("s"))
+		mockRepo.On("GetInvitationsList", domain.InvitationFilter{Search: want, Limit: 20, Offset: 40}).
+			Return(&domain.InvitationPage{}, nil)
+
+		_, err := uc.GetInvitations(domain.InvitationFilter{Search: search, Limit: 20, Offset: 40})
+		assert.NoError(t, err, search)
+		mockRepo.AssertExpectations(t)
+	}
+}
+
+func TestGetInvitations_RejectsBadPaging(t *testing.T) {
+	uc := NewAdminUseCase(new(MockAdminRepository), "admin", "password", []byte("s"))
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	var input InputError
+
+	for _, filter := range []domain.InvitationFilter{
+		{Limit: MaxInvitationPageSize + 1},
+		{Limit: -1},
+		{Offset: -5},
+		{Sort: "phoneNumber"},
+		{EventFrom: &from, EventTo: &to},
+	} {
+		_, err := uc.GetInvitations(filter)
+		assert.True(t, errors.As(err, &input), "%+v", filter)
+	}
+}
+
+func TestEachInvitation_IsNeverPaged(t *testing.T) {
+	mockRepo := new(MockAdminRepository)
+	uc := NewAdminUseCase(mockRepo, "admin", "password", []byte("s"))
+	mockRepo.On("EachInvitation", domain.InvitationFilter{Sort: domain.InvitationSortEvent}).Return(nil, nil)
+
+	err := uc.EachInvitation(domain.InvitationFilter{Sort: domain.InvitationSortEvent, Limit: 10, Offset: 10}, func(*domain.InvitationWithStats) error { return nil })
 	assert.NoError(t, err)
-	assert.Equal(t, expectedList, list)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -74,3 +128,85 @@ func TestGetTemplates(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, templates)
 }
+
+func TestAnalytics_WeeklyAlignsAndFills(t *testing.T) {
+	mockRepo := new(MockAdminRepository)
+	uc := NewAdminUseCase(mockRepo, "admin", "password", []byte("s"))
+
+	// 2026-03-04 is a Wednesday and 2026-03-17 a Tuesday.
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 23, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetAnalytics", start, end, "week").Return(&domain.BusinessAnalytics{
+		Series: []domain.AnalyticsPeriod{
+			{Period: "2026-03-16", Invitations: 1, RSVPs: 7, Guests: 12},
+			{Period: "2026-03-02", Invitations: 4, Paid: 1, RSVPs: 2, Guests: 3},
+		},
+		Templates: []domain.AnalyticsShare{{Key: "starry-night", Invitations: 4}, {Key: "silk-ivory", Invitations: 1}},
+		Languages: []domain.AnalyticsShare{{Key: "kk", Invitations: 5, Paid: 1}},
+	}, nil)
+
+	a, err := uc.Analytics("2026-03-04", "2026-03-17", "week")
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-03-02", a.From)
+	assert.Equal(t, "2026-03-22", a.To)
+	assert.Equal(t, "week", a.Granularity)
+	assert.Equal(t, []domain.AnalyticsPeriod{
+		{Period: "2026-03-02", Invitations: 4, Paid: 1, Conversion: 0.25, RSVPs: 2, Guests: 3},
+		{Period: "2026-03-09"},
+		{Period: "2026-03-16", Invitations: 1, RSVPs: 7, Guests: 12},
+	}, a.Series)
+	assert.Equal(t, domain.AnalyticsPeriod{Invitations: 5, Paid: 1, Conversion: 0.2, RSVPs: 9, Guests: 15}, a.Totals)
+	assert.Equal(t, 0.8, a.Templates[0].Share)
+	assert.Equal(t, 1.0, a.Languages[0].Share)
+}
+
+func TestAnalytics_MonthlyDefaultRange(t *testing.T) {
+	mockRepo := new(MockAdminRepository)
+	uc := NewAdminUseCase(mockRepo, "admin", "password", []byte("s"))
+	uc.now = func() time.Time { return time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC) }
+
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetAnalytics", start, end, "month").Return(&domain.BusinessAnalytics{}, nil)
+
+	a, err := uc.Analytics("", "", "month")
+	assert.NoError(t, err)
+	assert.Len(t, a.Series, 13)
+	assert.Equal(t, "2026-03-01", a.Series[12].Period)
+	assert.Equal(t, "2026-03-31", a.To)
+}
+
+func TestAnalytics_RejectsBadInput(t *testing.T) {
+	uc := NewAdminUseCase(new(MockAdminRepository), "admin", "password", []byte("s"))
+	var input InputError
+
+	_, err := uc.Analytics("", "", "year")
+	assert.True(t, errors.As(err, &input))
+	_, err = uc.Analytics("2020-01-01", "2026-01-01", "day")
+	assert.True(t, errors.As(err, &input))
+	_, err = uc.Analytics("2026-01-01", "2026-13-01", "")
+	assert.True(t, errors.As(err, &input))
+}
+
+func TestExpiringInvitations(t *testing.T) {
+	mockRepo := new(MockAdminRepository)
+	uc := NewAdminUseCase(mockRepo, "admin", "password", []byte("s"))
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	mockRepo.On("ListExpiring", now, now.Add(DefaultExpiringWithin)).Return([]domain.ExpiringInvitation{{UUID: "uuid-1"}}, nil)
+	mockRepo.On("ListExpiring", now, now.Add(6*time.Hour)).Return([]domain.ExpiringInvitation{}, nil)
+
+	list, err := uc.ExpiringInvitations("")
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	list, err = uc.ExpiringInvitations("6h")
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	var input InputError
+	for _, within := range []string{"soon", "-1h", "10000h"} {
+		_, err = uc.ExpiringInvitations(within)
+		assert.True(t, errors.As(err, &input), within)
+	}
+}
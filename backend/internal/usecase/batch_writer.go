@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	batchQueueSize     = 4096
+	batchSize          = 200
+	batchFlushInterval = 2 * time.Second
+)
+
+// batchWriter moves analytics writes off the request path: push never
+// blocks, and Run stores what was pushed in batches.
+type batchWriter[T any] struct {
+	name    string
+	queue   chan T
+	dropped atomic.Int64
+	write   func([]T) error
+}
+
+func newBatchWriter[T any](name string, write func([]T) error) *batchWriter[T] {
+	return &batchWriter[T]{name: name, queue: make(chan T, batchQueueSize), write: write}
+}
+
+// push queues v. When the writer falls behind v is dropped rather than
+// slowing the request down.
+func (w *batchWriter[T]) push(v T) {
+	select {
+	case w.queue <- v:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+// Run writes queued values in batches until ctx is done, then writes what
+// is still queued and returns.
+func (w *batchWriter[T]) Run(ctx context.Context) {
+	ticker := time.NewTicker(batchFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]T, 0, batchSize)
+	for {
+		select {
+		case v := <-w.queue:
+			batch = append(batch, v)
+			if len(batch) == batchSize {
+				batch = w.flush(batch)
+			}
+		case <-ticker.C:
+			batch = w.flush(batch)
+		case <-ctx.Done():
+			for {
+				select {
+				case v := <-w.queue:
+					batch = append(batch, v)
+				default:
+					w.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush stores batch and returns it emptied. A failed write is logged and
+// dropped: analytics are not worth blocking the queue for.
+func (w *batchWriter[T]) flush(batch []T) []T {
+	if n := w.dropped.Swap(0); n > 0 {
+		log.Printf("%s: queue full, dropped %d", w.name, n)
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := w.write(batch); err != nil {
+		log.Printf("%s: failed to store %d: %v", w.name, len(batch), err)
+	}
+	return batch[:0]
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+const (
+	// DefaultClickStatsDays is the range of ClickStats without dates.
+	DefaultClickStatsDays = 30
+	maxClickStatsDays     = 366
+	maxReferrerLength     = 255
+)
+
+// CountryLookup maps an IP address to an ISO country code, "" if unknown.
+type CountryLookup interface {
+	Country(ip net.IP) string
+}
+
+// pendingClick is a click waiting for the writer, with the address kept in
+// memory only until its country is looked up.
+type pendingClick struct {
+	click domain.Click
+	ip    net.IP
+}
+
+// ClickUseCase records short link clicks off the request path and reports
+// them per invitation.
+type ClickUseCase struct {
+	repo    domain.ClickRepository
+	invRepo domain.InvitationRepository
+	geo     CountryLookup
+	secret  []byte
+	writer  *batchWriter[pendingClick]
+	now     func() time.Time
+}
+
+// NewClickUseCase builds the use case. geo may be nil when no GeoIP database
+// is configured; secret keys the visitor hashes.
+func NewClickUseCase(repo domain.ClickRepository, invRepo domain.InvitationRepository, geo CountryLookup, secret []byte) *ClickUseCase {
+	u := &ClickUseCase{
+		repo:    repo,
+		invRepo: invRepo,
+		geo:     geo,
+		secret:  secret,
+		now:     time.Now,
+	}
+	u.writer = newBatchWriter("clicks", u.store)
+	return u
+}
+
+// Record queues a click without blocking.
+func (u *ClickUseCase) Record(invitationUUID, shortCode, ip, userAgent, referrer string) {
+	u.writer.push(pendingClick{
+		click: domain.Click{
+			InvitationUUID: invitationUUID,
+			ShortCode:      shortCode,
+			ClickedAt:      u.now(),
+			VisitorHash:    visitorHash(u.secret, ip),
+			UAClass:        ClassifyUserAgent(userAgent),
+			Referrer:       referrerHost(referrer),
+		},
+		ip: net.ParseIP(ip),
+	})
+}
+
+// Run writes queued clicks until ctx is done.
+func (u *ClickUseCase) Run(ctx context.Context) {
+	u.writer.Run(ctx)
+}
+
+func (u *ClickUseCase) store(batch []pendingClick) error {
+	clicks := make([]domain.Click, len(batch))
+	for i, p := range batch {
+		clicks[i] = p.click
+		if u.geo != nil && p.ip != nil {
+			clicks[i].Country = u.geo.Country(p.ip)
+		}
+	}
+	return u.repo.AddClicks(clicks)
+}
+
+// Stats reports clicks of an invitation between the dates from and to
+// (YYYY-MM-DD, both included, UTC). Empty dates default to the last
+// DefaultClickStatsDays days.
+func (u *ClickUseCase) Stats(invitationUUID, from, to string) (*domain.ClickStats, error) {
+	if _, err := u.invRepo.GetByUUID(invitationUUID); err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	start, end, err := dateRange(from, to, u.now(), DefaultClickStatsDays, maxClickStatsDays)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := u.repo.GetClickStats(invitationUUID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	stats.From, stats.To = start.Format(dateLayout), end.AddDate(0, 0, -1).Format(dateLayout)
+
+	// Fill in the days without clicks so the series can be charted as is.
+	byDate := make(map[string]domain.DailyClicks, len(stats.Daily))
+	for _, d := range stats.Daily {
+		byDate[d.Date] = d
+	}
+	daily := make([]domain.DailyClicks, 0, int(end.Sub(start).Hours()/24))
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(dateLayout)
+		d, ok := byDate[date]
+		if !ok {
+			d = domain.DailyClicks{Date: date}
+		}
+		daily = append(daily, d)
+	}
+	stats.Daily = daily
+	return stats, nil
+}
+
+const dateLayout = "2006-01-02"
+
+// dateRange parses an inclusive range of UTC dates into [start, end). An
+// empty to means today and an empty from the defaultDays up to to.
+func dateRange(from, to string, now time.Time, defaultDays, maxDays int) (time.Time, time.Time, error) {
+	today := now.UTC().Truncate(24 * time.Hour)
+	end := today
+	if to != "" {
+		t, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, InputError("to must be a date in YYYY-MM-DD format")
+		}
+		end = t
+	}
+	start := end.AddDate(0, 0, 1-defaultDays)
+	if from != "" {
+		t, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, InputError("from must be a date in YYYY-MM-DD format")
+		}
+		start = t
+	}
+	end = end.AddDate(0, 0, 1)
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, InputError("from must not be after to")
+	}
+	if end.Sub(start) > time.Duration(maxDays)*24*time.Hour {
+		return time.Time{}, time.Time{}, InputError(fmt.Sprintf("the range is limited to %d days", maxDays))
+	}
+	return start, end, nil
+}
+
+// visitorHash identifies a visitor without keeping the address: a keyed
+// hash cannot be reversed by enumerating the IPv4 space.
+func visitorHash(secret []byte, ip string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// referrerHost keeps only the host of a Referer header; paths can carry
+// personal data.
+func referrerHost(referrer string) string {
+	if referrer == "" {
+		return ""
+	}
+	ref, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(ref.Hostname()), "www.")
+	if len(host) > maxReferrerLength {
+		host = host[:maxReferrerLength]
+	}
+	return host
+}
+
+// botUserAgents are fragments of crawlers, link unfurlers and HTTP
+// libraries, which should not count as guests opening the invitation.
+var botUserAgents = []string{
+	"bot", "crawl", "spider", "slurp", "preview", "headless", "lighthouse",
+	"facebookexternalhit", "whatsapp", "viber", "vkshare", "skypeuripreview",
+	"curl", "wget", "python-", "go-http-client", "okhttp", "java/", "axios", "node-fetch",
+}
+
+// ClassifyUserAgent puts a User-Agent into one of the domain.UAClass* buckets.
+func ClassifyUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return domain.UAClassOther
+	}
+	for _, bot := range botUserAgents {
+		if strings.Contains(ua, bot) {
+			return domain.UAClassBot
+		}
+	}
+	switch {
+	case strings.Contains(ua, "ipad"), strings.Contains(ua, "tablet"),
+		strings.Contains(ua, "android") && !strings.Contains(ua, "mobile"):
+		return domain.UAClassTablet
+	case strings.Contains(ua, "mobi"), strings.Contains(ua, "iphone"), strings.Contains(ua, "android"):
+		return domain.UAClassMobile
+	case strings.Contains(ua, "windows"), strings.Contains(ua, "macintosh"),
+		strings.Contains(ua, "x11"), strings.Contains(ua, "linux"), strings.Contains(ua, "cros"):
+		return domain.UAClassDesktop
+	}
+	return domain.UAClassOther
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeCountries map[string]string
+
+func (f fakeCountries) Country(ip net.IP) string { return f[ip.String()] }
+
+func newClickUseCase() (*ClickUseCase, *MockClickRepository, *MockInvitationRepository) {
+	repo := new(MockClickRepository)
+	invRepo := new(MockInvitationRepository)
+	uc := NewClickUseCase(repo, invRepo, fakeCountries{"2.133.10.20": "KZ"}, []byte("secret"))
+	uc.now = func() time.Time { return time.Date(2026, 3, 10, 15, 4, 5, 0, time.UTC) }
+	return uc, repo, invRepo
+}
+
+func TestClassifyUserAgent(t *testing.T) {
+	cases := map[string]string{
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148":        domain.UAClassMobile,
+		"Mozilla/5.0 (Linux; Android 14; SM-S918B) Chrome/120.0 Mobile Safari/537.36": domain.UAClassMobile,
+		"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X)":                               domain.UAClassTablet,
+		"Mozilla/5.0 (Linux; Android 13; SM-X700) Chrome/120.0 Safari/537.36":         domain.UAClassTablet,
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0":                      domain.UAClassDesktop,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0)":                                domain.UAClassDesktop,
+		"WhatsApp/2.23.20.0 A":          domain.UAClassBot,
+		"TelegramBot (like TwitterBot)": domain.UAClassBot,
+		"facebookexternalhit/1.1":       domain.UAClassBot,
+		"curl/8.4.0":                    domain.UAClassBot,
+		"":                              domain.UAClassOther,
+		"SomethingElse/1.0":             domain.UAClassOther,
+	}
+	for ua, want := range cases {
+		assert.Equal(t, want, ClassifyUserAgent(ua), ua)
+	}
+}
+
+func TestReferrerHost(t *testing.T) {
+	assert.Equal(t, "instagram.com", referrerHost("https://www.Instagram.com/p/secret?igsh=1"))
+	assert.Equal(t, "l.facebook.com", referrerHost("https://l.facebook.com/l.php?u=x"))
+	assert.Equal(t, "", referrerHost(""))
+	assert.Equal(t, "", referrerHost("::not a url"))
+}
+
+func TestRecord_RunWritesQueuedClicks(t *testing.T) {
+	uc, repo, _ := newClickUseCase()
+	var stored []domain.Click
+	repo.On("AddClicks", mock.Anything).Run(func(args mock.Arguments) {
+		stored = append(stored, args.Get(0).([]domain.Click)...)
+	}).Return(nil)
+
+	uc.Record("uuid", "abc123", "2.133.10.20", "Mozilla/5.0 (iPhone) Mobile", "https://www.instagram.com/stories/x")
+	uc.Record("uuid", "abc123", "8.8.8.8", "WhatsApp/2.23", "")
+	uc.Record("uuid", "abc123", "2.133.10.20", "Mozilla/5.0 (Windows NT 10.0)", "")
+
+	// Cancelling before Run starts still drains the queue.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	uc.Run(ctx)
+
+	if assert.Len(t, stored, 3) {
+		assert.Equal(t, "uuid", stored[0].InvitationUUID)
+		assert.Equal(t, "abc123", stored[0].ShortCode)
+		assert.Equal(t, domain.UAClassMobile, stored[0].UAClass)
+		assert.Equal(t, "instagram.com", stored[0].Referrer)
+		assert.Equal(t, "KZ", stored[0].Country)
+		assert.Len(t, stored[0].VisitorHash, 32)
+
+		assert.Equal(t, domain.UAClassBot, stored[1].UAClass)
+		assert.Equal(t, "", stored[1].Country)
+		assert.NotEqual(t, stored[0].VisitorHash, stored[1].VisitorHash)
+
+		// The same address hashes to the same visitor.
+		assert.Equal(t, stored[0].VisitorHash, stored[2].VisitorHash)
+	}
+}
+
+func TestRecord_DropsWhenQueueFull(t *testing.T) {
+	uc, _, _ := newClickUseCase()
+	for i := 0; i < batchQueueSize+5; i++ {
+		uc.Record("uuid", "abc123", "8.8.8.8", "", "")
+	}
+	assert.Equal(t, int64(5), uc.writer.dropped.Load())
+}
+
+func TestRun_KeepsGoingAfterWriteError(t *testing.T) {
+	uc, repo, _ := newClickUseCase()
+	repo.On("AddClicks", mock.Anything).Return(errors.New("db down"))
+
+	uc.Record("uuid", "abc123", "8.8.8.8", "", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	uc.Run(ctx)
+	repo.AssertNumberOfCalls(t, "AddClicks", 1)
+}
+
+func TestStats_DefaultRangeIsZeroFilled(t *testing.T) {
+	uc, repo, invRepo := newClickUseCase()
+	invRepo.On("GetByUUID", "uuid").Return(&domain.Invitation{UUID: "uuid"}, nil)
+	from := time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)
+	repo.On("GetClickStats", "uuid", from, to).Return(&domain.ClickStats{
+		Clicks:         4,
+		UniqueVisitors: 2,
+		Daily:          []domain.DailyClicks{{Date: "2026-03-09", Clicks: 4, UniqueVisitors: 2}},
+	}, nil)
+
+	stats, err := uc.Stats("uuid", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-02-09", stats.From)
+	assert.Equal(t, "2026-03-10", stats.To)
+	assert.Len(t, stats.Daily, DefaultClickStatsDays)
+	assert.Equal(t, domain.DailyClicks{Date: "2026-02-09"}, stats.Daily[0])
+	assert.Equal(t, domain.DailyClicks{Date: "2026-03-09", Clicks: 4, UniqueVisitors: 2}, stats.Daily[28])
+	assert.Equal(t, domain.DailyClicks{Date: "2026-03-10"}, stats.Daily[29])
+}
+
+func TestStats_ExplicitRange(t *testing.T) {
+	uc, repo, invRepo := newClickUseCase()
+	invRepo.On("GetByUUID", "uuid").Return(&domain.Invitation{UUID: "uuid"}, nil)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)
+	repo.On("GetClickStats", "uuid", from, to).Return(&domain.ClickStats{}, nil)
+
+	stats, err := uc.Stats("uuid", "2026-01-01", "2026-01-03")
+	assert.NoError(t, err)
+	assert.Len(t, stats.Daily, 3)
+	assert.Equal(t, "2026-01-03", stats.Daily[2].Date)
+}
+
+func TestStats_RejectsBadRanges(t *testing.T) {
+	uc, _, invRepo := newClickUseCase()
+	invRepo.On("GetByUUID", "uuid").Return(&domain.Invitation{UUID: "uuid"}, nil)
+
+	for _, r := range [][2]string{
+		{"yesterday", ""},
+		{"", "2026-13-01"},
+		{"2026-03-05", "2026-03-01"},
+		{"2024-01-01", "2026-01-01"},
+	} {
+		_, err := uc.Stats("uuid", r[0], r[1])
+		var input InputError
+		assert.True(t, errors.As(err, &input), "%v: %v", r, err)
+	}
+}
+
+func TestStats_InvitationNotFound(t *testing.T) {
+	uc, _, invRepo := newClickUseCase()
+	invRepo.On("GetByUUID", "missing").Return(nil, errors.New("no rows in result set"))
+
+	_, err := uc.Stats("missing", "", "")
+	assert.ErrorIs(t, err, domain.ErrInvitationNotFound)
+}
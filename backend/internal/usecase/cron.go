@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronFields are the bounds of the five fields of a cron expression:
+// minute, hour, day of month, month and day of week (0 is Sunday, and so
+// is 7).
+var cronFields = [5]struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// CronSchedule is a parsed five-field cron expression such as
+// "*/15 * * * *". Fields take *, numbers, ranges (1-5), lists (1,15) and
+// steps (*/10, 0-30/5); @hourly, @daily, @weekly and @monthly are
+// shorthands. As in cron, a day matches when either the day of month or the
+// day of week does, if both are restricted.
+type CronSchedule struct {
+	expr   string
+	fields [5]uint64
+	// anyDom and anyDow record whether day of month and day of week start
+	// with *, which leaves the day to the other field.
+	anyDom, anyDow bool
+}
+
+// ParseCron parses a cron expression.
+func ParseCron(expr string) (*CronSchedule, error) {
+	spec := strings.TrimSpace(expr)
+	if m, ok := cronMacros[spec]; ok {
+		spec = m
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields", expr)
+	}
+	s := &CronSchedule{expr: expr, anyDom: strings.HasPrefix(parts[2], "*"), anyDow: strings.HasPrefix(parts[4], "*")}
+	for i, part := range parts {
+		bits, err := parseCronField(part, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %s: %w", expr, cronFields[i].name, err)
+		}
+		s.fields[i] = bits
+	}
+	// Sunday may be written 0 or 7.
+	if s.fields[4]&(1<<7) != 0 {
+		s.fields[4] |= 1
+	}
+	return s, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, step := item, 1
+		if r, st, ok := strings.Cut(item, "/"); ok {
+			n, err := strconv.Atoi(st)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", st)
+			}
+			rng, step = r, n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value %q", from)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q", to)
+				}
+			} else if step > 1 {
+				// "5/15" runs from 5 to the end, as in cron.
+				hi = max
+			}
+			if lo < min || hi > max || lo > hi {
+				return 0, fmt.Errorf("%q is out of %d-%d", rng, min, max)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (s *CronSchedule) String() string { return s.expr }
+
+// Matches reports whether the schedule fires in the minute of t.
+func (s *CronSchedule) Matches(t time.Time) bool {
+	if !has(s.fields[0], t.Minute()) || !has(s.fields[1], t.Hour()) || !has(s.fields[3], int(t.Month())) {
+		return false
+	}
+	dom, dow := has(s.fields[2], t.Day()), has(s.fields[4], int(t.Weekday()))
+	if s.anyDom || s.anyDow {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// Next returns the first minute after t the schedule fires in, or the zero
+// time if there is none within five years (e.g. "0 0 31 2 *").
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	for end := next.AddDate(5, 0, 0); next.Before(end); next = next.Add(time.Minute) {
+		if s.Matches(next) {
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+func has(bits uint64, v int) bool { return bits&(1<<v) != 0 }
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", s)
+		require.NoError(t, err)
+		return tm
+	}
+
+	every5, err := ParseCron("*/5 * * * *")
+	require.NoError(t, err)
+	assert.True(t, every5.Matches(at("2026-05-01 10:05")))
+	assert.False(t, every5.Matches(at("2026-05-01 10:06")))
+	assert.Equal(t, at("2026-05-01 10:10"), every5.Next(at("2026-05-01 10:05")))
+
+	weekdays, err := ParseCron("30 9 * * 1-5")
+	require.NoError(t, err)
+	assert.True(t, weekdays.Matches(at("2026-05-01 09:30")))  // Friday
+	assert.False(t, weekdays.Matches(at("2026-05-02 09:30"))) // Saturday
+	assert.Equal(t, at("2026-05-04 09:30"), weekdays.Next(at("2026-05-01 09:30")))
+
+	// Restricted day of month and day of week match either.
+	either, err := ParseCron("0 0 13 * 5")
+	require.NoError(t, err)
+	assert.True(t, either.Matches(at("2026-05-13 00:00")))
+	assert.True(t, either.Matches(at("2026-05-08 00:00")))
+	assert.False(t, either.Matches(at("2026-05-09 00:00")))
+
+	sunday, err := ParseCron("@weekly")
+	require.NoError(t, err)
+	assert.True(t, sunday.Matches(at("2026-05-03 00:00")))
+	seven, err := ParseCron("0 0 * * 7")
+	require.NoError(t, err)
+	assert.True(t, seven.Matches(at("2026-05-03 00:00")))
+
+	list, err := ParseCron("0,30 8-10/2 1 1,6 *")
+	require.NoError(t, err)
+	assert.True(t, list.Matches(at("2026-06-01 10:30")))
+	assert.False(t, list.Matches(at("2026-06-01 09:30")))
+
+	for _, bad := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@yearly"} {
+		_, err := ParseCron(bad)
+		assert.Error(t, err, bad)
+	}
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+// engagementEvents are the events the beacon accepts.
+var engagementEvents = map[string]bool{
+	domain.EngagementView:     true,
+	domain.EngagementStory:    true,
+	domain.EngagementLocation: true,
+	domain.EngagementRSVPForm: true,
+}
+
+// EngagementUseCase records what guests do on the invitation page and
+// turns it into a view-to-RSVP funnel.
+type EngagementUseCase struct {
+	repo    domain.EngagementRepository
+	invRepo domain.InvitationRepository
+	secret  []byte
+	writer  *batchWriter[domain.EngagementEvent]
+	now     func() time.Time
+}
+
+// NewEngagementUseCase builds the use case. secret keys the visitor hashes
+// and should be the one of ClickUseCase, so both count visitors alike.
+func NewEngagementUseCase(repo domain.EngagementRepository, invRepo domain.InvitationRepository, secret []byte) *EngagementUseCase {
+	return &EngagementUseCase{
+		repo:    repo,
+		invRepo: invRepo,
+		secret:  secret,
+		writer:  newBatchWriter("engagement events", repo.AddEngagementEvents),
+		now:     time.Now,
+	}
+}
+
+// Record validates a beacon call and queues it without blocking. The
+// invitation itself is not looked up: events of unknown invitations are
+// skipped when stored.
+func (u *EngagementUseCase) Record(invitationUUID, event, ip, userAgent string) error {
+	if _, err := uuid.Parse(invitationUUID); err != nil {
+		return InputError(fmt.Sprintf("invalid uuid: %q", invitationUUID))
+	}
+	if !engagementEvents[event] {
+		return InputError(fmt.Sprintf("unknown event: %q", event))
+	}
+	u.writer.push(domain.EngagementEvent{
+		InvitationUUID: invitationUUID,
+		Event:          event,
+		OccurredAt:     u.now(),
+		VisitorHash:    visitorHash(u.secret, ip),
+		UAClass:        ClassifyUserAgent(userAgent),
+	})
+	return nil
+}
+
+// Run writes queued events until ctx is done.
+func (u *EngagementUseCase) Run(ctx context.Context) {
+	u.writer.Run(ctx)
+}
+
+// Funnel reports the engagement of an invitation over its whole life.
+func (u *EngagementUseCase) Funnel(invitationUUID string) (*domain.EngagementFunnel, error) {
+	if _, err := u.invRepo.GetByUUID(invitationUUID); err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	return u.repo.GetFunnel(invitationUUID)
+}
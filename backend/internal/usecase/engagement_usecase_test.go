@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const engagementUUID = "0b9d6a1e-3c1f-4a8e-9a53-6f1c2b7d8e90"
+
+func TestEngagementRecord_QueuesValidEvents(t *testing.T) {
+	repo := new(MockEngagementRepository)
+	uc := NewEngagementUseCase(repo, new(MockInvitationRepository), []byte("secret"))
+	var stored []domain.EngagementEvent
+	repo.On("AddEngagementEvents", mock.Anything).Run(func(args mock.Arguments) {
+		stored = append(stored, args.Get(0).([]domain.EngagementEvent)...)
+	}).Return(nil)
+
+	assert.NoError(t, uc.Record(engagementUUID, domain.EngagementView, "2.133.10.20", "Mozilla/5.0 (iPhone) Mobile"))
+	assert.NoError(t, uc.Record(engagementUUID, domain.EngagementRSVPForm, "2.133.10.20", "Mozilla/5.0 (iPhone) Mobile"))
+	assert.NoError(t, uc.Record(engagementUUID, domain.EngagementView, "8.8.8.8", "HeadlessChrome/120.0"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	uc.Run(ctx)
+
+	if assert.Len(t, stored, 3) {
+		assert.Equal(t, engagementUUID, stored[0].InvitationUUID)
+		assert.Equal(t, domain.UAClassMobile, stored[0].UAClass)
+		assert.Equal(t, domain.EngagementRSVPForm, stored[1].Event)
+		assert.Equal(t, stored[0].VisitorHash, stored[1].VisitorHash)
+		assert.Equal(t, domain.UAClassBot, stored[2].UAClass)
+	}
+}
+
+func TestEngagementRecord_MatchesClickVisitors(t *testing.T) {
+	secret := []byte("secret")
+	clicks := NewClickUseCase(new(MockClickRepository), new(MockInvitationRepository), nil, secret)
+	clicks.Record(engagementUUID, "abc123", "2.133.10.20", "", "")
+	engagement := NewEngagementUseCase(new(MockEngagementRepository), new(MockInvitationRepository), secret)
+	assert.NoError(t, engagement.Record(engagementUUID, domain.EngagementView, "2.133.10.20", ""))
+
+	click := <-clicks.writer.queue
+	event := <-engagement.writer.queue
+	assert.Equal(t, click.click.VisitorHash, event.VisitorHash)
+}
+
+func TestEngagementRecord_RejectsBadInput(t *testing.T) {
+	uc := NewEngagementUseCase(new(MockEngagementRepository), new(MockInvitationRepository), []byte("secret"))
+	var input InputError
+
+	err := uc.Record("not-a-uuid", domain.EngagementView, "8.8.8.8", "")
+	assert.True(t, errors.As(err, &input))
+	err = uc.Record(engagementUUID, "gallery", "8.8.8.8", "")
+	assert.True(t, errors.As(err, &input))
+	assert.Empty(t, uc.writer.queue)
+}
+
+func TestEngagementFunnel(t *testing.T) {
+	repo := new(MockEngagementRepository)
+	invRepo := new(MockInvitationRepository)
+	uc := NewEngagementUseCase(repo, invRepo, []byte("secret"))
+	invRepo.On("GetByUUID", "uuid").Return(&domain.Invitation{UUID: "uuid"}, nil)
+	invRepo.On("GetByUUID", "missing").Return(nil, errors.New("no rows in result set"))
+	repo.On("GetFunnel", "uuid").Return(&domain.EngagementFunnel{Opened: 10, ReachedRSVP: 6, Responded: 4}, nil)
+
+	funnel, err := uc.Funnel("uuid")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, funnel.Opened)
+
+	_, err = uc.Funnel("missing")
+	assert.ErrorIs(t, err, domain.ErrInvitationNotFound)
+}
@@ -0,0 +1,7 @@
+package usecase
+
+// InputError is an error caused by the request rather than by the server,
+// e.g. an unusable upload or a missing field. Handlers answer it with 400.
+type InputError string
+
+func (e InputError) Error() string { return string(e) }
@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+// MaxGuestImportRows bounds a single roster import.
+const MaxGuestImportRows = 2000
+
+// Roster fields a spreadsheet column can be mapped to.
+const (
+	GuestFieldName      = "name"
+	GuestFieldPhone     = "phone"
+	GuestFieldPartySize = "partySize"
+	GuestFieldGroup     = "group"
+	GuestFieldNote      = "note"
+)
+
+var guestFields = []string{GuestFieldName, GuestFieldPhone, GuestFieldPartySize, GuestFieldGroup, GuestFieldNote}
+
+// guestHeaderAliases recognizes the usual column titles when no mapping is
+// given. Titles are compared lower-cased and trimmed.
+var guestHeaderAliases = map[string][]string{
+	GuestFieldName:      {"name", "guest", "full name", "имя", "фио", "гость", "аты", "аты-жөні", "қонақ"},
+	GuestFieldPhone:     {"phone", "phone number", "mobile", "whatsapp", "телефон", "тел", "тел.", "номер", "номер телефона"},
+	GuestFieldPartySize: {"party size", "guests", "count", "кол-во", "количество", "гости", "гостей", "человек", "саны", "адам"},
+	GuestFieldGroup:     {"group", "side", "группа", "сторона", "топ", "тарап"},
+	GuestFieldNote:      {"note", "notes", "comment", "примечание", "комментарий", "ескерту"},
+}
+
+const maxPartySize = 20
+
+type GuestUseCase struct {
+	repo    domain.GuestRepository
+	invRepo domain.InvitationRepository
+}
+
+func NewGuestUseCase(repo domain.GuestRepository, invRepo domain.InvitationRepository) *GuestUseCase {
+	return &GuestUseCase{repo: repo, invRepo: invRepo}
+}
+
+func (u *GuestUseCase) ListGuests(invUUID string) ([]domain.Guest, error) {
+	if _, err := u.invRepo.GetByUUID(invUUID); err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	return u.repo.ListGuests(invUUID)
+}
+
+// ImportGuests validates spreadsheet rows against the roster of an
+// invitation. rows[0] is the header. mapping assigns roster fields to
+// columns, by header title or column letter; unmapped fields are detected
+// from the header. Nothing is written on a dry run or when any row is
+// invalid; otherwise all guests are added in one transaction.
+func (u *GuestUseCase) ImportGuests(invUUID string, rows [][]string, mapping map[string]string, dryRun bool) (*domain.GuestImportReport, error) {
+	if _, err := u.invRepo.GetByUUID(invUUID); err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	if len(rows) < 2 {
+		return nil, InputError("the file has no guest rows")
+	}
+	if len(rows)-1 > MaxGuestImportRows {
+		return nil, InputError(fmt.Sprintf("too many rows: at most %d guests per import", MaxGuestImportRows))
+	}
+
+	columns, err := resolveGuestColumns(rows[0], mapping)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := u.repo.ListGuests(invUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.GuestImportReport{
+		DryRun:  dryRun,
+		Mapping: map[string]string{},
+		Errors:  []domain.GuestImportError{},
+		Guests:  []domain.Guest{},
+	}
+	for field, col := range columns {
+		report.Mapping[field] = columnTitle(rows[0], col)
+	}
+
+	seen := newGuestIndex()
+	for _, g := range existing {
+		seen.add(g, 0)
+	}
+
+	for i, row := range rows[1:] {
+		line := i + 2 // 1-based, after the header
+		cell := func(field string) string {
+			col, ok := columns[field]
+			if !ok || col >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[col])
+		}
+		if blankRow(row) {
+			continue
+		}
+		report.Rows++
+
+		g := domain.Guest{
+			InvitationUUID: invUUID,
+			Name:           strings.Join(strings.Fields(cell(GuestFieldName)), " "),
+			Group:          cell(GuestFieldGroup),
+			Note:           cell(GuestFieldNote),
+			PartySize:      1,
+		}
+		rowErrors := len(report.Errors)
+		fail := func(field, msg string) {
+			report.Errors = append(report.Errors, domain.GuestImportError{Row: line, Field: field, Error: msg})
+		}
+
+		if g.Name == "" {
+			fail(GuestFieldName, "name_required")
+		}
+		if raw := cell(GuestFieldPhone); raw != "" {
+			phone, err := domain.NormalizePhone(raw)
+			if err != nil {
+				fail(GuestFieldPhone, err.Error())
+			}
+			g.Phone = phone
+		}
+		if raw := cell(GuestFieldPartySize); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > maxPartySize {
+				fail(GuestFieldPartySize, "invalid_party_size")
+			}
+			g.PartySize = n
+		}
+
+		if len(report.Errors) == rowErrors {
+			if dup, ok := seen.find(g); ok {
+				if dup == 0 {
+					fail(seen.field(g), "duplicate_existing")
+				} else {
+					fail(seen.field(g), fmt.Sprintf("duplicate_of_row_%d", dup))
+				}
+			}
+		}
+		if len(report.Errors) > rowErrors {
+			continue
+		}
+		seen.add(g, line)
+		report.Guests = append(report.Guests, g)
+	}
+	report.Valid = len(report.Guests)
+
+	if dryRun || len(report.Errors) > 0 || len(report.Guests) == 0 {
+		return report, nil
+	}
+	if err := u.repo.AddGuests(report.Guests); err != nil {
+		return nil, err
+	}
+	report.Imported = len(report.Guests)
+	return report, nil
+}
+
+// resolveGuestColumns maps roster fields to column indexes.
+func resolveGuestColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	columns := map[string]int{}
+	for field, ref := range mapping {
+		if !isGuestField(field) {
+			return nil, InputError(fmt.Sprintf("unknown field in mapping: %q", field))
+		}
+		if strings.TrimSpace(ref) == "" {
+			continue
+		}
+		col, ok := findColumn(header, ref)
+		if !ok {
+			return nil, InputError(fmt.Sprintf("column %q for %s not found", ref, field))
+		}
+		columns[field] = col
+	}
+
+	taken := map[int]bool{}
+	for _, col := range columns {
+		taken[col] = true
+	}
+	for _, field := range guestFields {
+		if _, ok := columns[field]; ok {
+			continue
+		}
+		for col, title := range header {
+			if !taken[col] && isAlias(field, title) {
+				columns[field] = col
+				taken[col] = true
+				break
+			}
+		}
+	}
+
+	if _, ok := columns[GuestFieldName]; !ok {
+		return nil, InputError("name column not found: map it explicitly")
+	}
+	return columns, nil
+}
+
+func isGuestField(field string) bool {
+	for _, f := range guestFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isAlias(field, title string) bool {
+	title = strings.ToLower(strings.TrimSpace(title))
+	for _, a := range guestHeaderAliases[field] {
+		if title == a {
+			return true
+		}
+	}
+	return false
+}
+
+// findColumn locates ref by header title (case-insensitive) or, failing
+// that, as a column letter such as "C".
+func findColumn(header []string, ref string) (int, bool) {
+	ref = strings.TrimSpace(ref)
+	for col, title := range header {
+		if strings.EqualFold(strings.TrimSpace(title), ref) {
+			return col, true
+		}
+	}
+	if len(ref) > 3 {
+		return 0, false
+	}
+	col := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			return 0, false
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1, true
+}
+
+func columnTitle(header []string, col int) string {
+	if col < len(header) && strings.TrimSpace(header[col]) != "" {
+		return strings.TrimSpace(header[col])
+	}
+	name := ""
+	for n := col + 1; n > 0; n = (n - 1) / 26 {
+		name = string(rune('A'+(n-1)%26)) + name
+	}
+	return name
+}
+
+func blankRow(row []string) bool {
+	for _, c := range row {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// guestIndex finds duplicates: the same phone, or the same name when the
+// guest has no phone. Values are the rows guests came from, 0 for guests
+// already on the roster.
+type guestIndex struct {
+	phones map[string]int
+	names  map[string]int
+}
+
+func newGuestIndex() *guestIndex {
+	return &guestIndex{phones: map[string]int{}, names: map[string]int{}}
+}
+
+func (x *guestIndex) add(g domain.Guest, row int) {
+	if g.Phone != "" {
+		x.phones[g.Phone] = row
+	} else {
+		x.names[strings.ToLower(g.Name)] = row
+	}
+}
+
+func (x *guestIndex) find(g domain.Guest) (int, bool) {
+	if g.Phone != "" {
+		row, ok := x.phones[g.Phone]
+		return row, ok
+	}
+	row, ok := x.names[strings.ToLower(g.Name)]
+	return row, ok
+}
+
+func (x *guestIndex) field(g domain.Guest) string {
+	if g.Phone != "" {
+		return GuestFieldPhone
+	}
+	return GuestFieldName
+}
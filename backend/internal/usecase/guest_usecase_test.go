@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newGuestUseCase() (*GuestUseCase, *MockGuestRepository, *MockInvitationRepository) {
+	repo := new(MockGuestRepository)
+	invRepo := new(MockInvitationRepository)
+	invRepo.On("GetByUUID", "uuid").Return(&domain.Invitation{UUID: "uuid"}, nil)
+	return NewGuestUseCase(repo, invRepo), repo, invRepo
+}
+
+var rosterRows = [][]string{
+	{"ФИО", "Телефон", "Кол-во", "Сторона"},
+	{"Асқар  Бекұлы", "8 701 123 45 67", "2", "Жених"},
+	{"Гүлнар", "", "", "Невеста"},
+	nil,
+	{"Дина", "+7 (701) 123-45-67", "1", ""},
+	{"", "87770000000", "x", ""},
+	{"гүлнар", "", "1", ""},
+	{"Марат", "8 705 000 00 00", "1", ""},
+}
+
+func TestImportGuests_DryRunReportsRowErrors(t *testing.T) {
+	uc, repo, _ := newGuestUseCase()
+	repo.On("ListGuests", "uuid").Return([]domain.Guest{{Name: "Марат", Phone: "+77050000000"}}, nil)
+
+	report, err := uc.ImportGuests("uuid", rosterRows, nil, true)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"name": "ФИО", "phone": "Телефон", "partySize": "Кол-во", "group": "Сторона"}, report.Mapping)
+	assert.Equal(t, 6, report.Rows)
+	assert.Equal(t, 2, report.Valid)
+	assert.Equal(t, []domain.GuestImportError{
+		{Row: 5, Field: "phone", Error: "duplicate_of_row_2"},
+		{Row: 6, Field: "name", Error: "name_required"},
+		{Row: 6, Field: "partySize", Error: "invalid_party_size"},
+		{Row: 7, Field: "name", Error: "duplicate_of_row_3"},
+		{Row: 8, Field: "phone", Error: "duplicate_existing"},
+	}, report.Errors)
+	assert.Equal(t, domain.Guest{InvitationUUID: "uuid", Name: "Асқар Бекұлы", Phone: "+77011234567", PartySize: 2, Group: "Жених"}, report.Guests[0])
+	repo.AssertNotCalled(t, "AddGuests", mock.Anything)
+}
+
+func TestImportGuests_CommitsValidFile(t *testing.T) {
+	uc, repo, _ := newGuestUseCase()
+	repo.On("ListGuests", "uuid").Return([]domain.Guest{}, nil)
+	repo.On("AddGuests", mock.MatchedBy(func(g []domain.Guest) bool { return len(g) == 2 })).Return(nil)
+
+	rows := [][]string{
+		{"Guest", "Mobile", "Seats"},
+		{"Aruzhan", "7011234567", "3"},
+		{"Timur", "", ""},
+	}
+	report, err := uc.ImportGuests("uuid", rows, map[string]string{"partySize": "C"}, false)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 2, report.Imported)
+	assert.Equal(t, 3, report.Guests[0].PartySize)
+	assert.Equal(t, "+77011234567", report.Guests[0].Phone)
+	repo.AssertExpectations(t)
+}
+
+func TestImportGuests_NothingWrittenOnErrors(t *testing.T) {
+	uc, repo, _ := newGuestUseCase()
+	repo.On("ListGuests", "uuid").Return([]domain.Guest{}, nil)
+
+	report, err := uc.ImportGuests("uuid", rosterRows, nil, false)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, report.Errors)
+	assert.Zero(t, report.Imported)
+	repo.AssertNotCalled(t, "AddGuests", mock.Anything)
+}
+
+func TestImportGuests_BadInput(t *testing.T) {
+	uc, repo, invRepo := newGuestUseCase()
+	repo.On("ListGuests", "uuid").Return([]domain.Guest{}, nil)
+	invRepo.On("GetByUUID", "missing").Return(nil, errors.New("no rows"))
+
+	_, err := uc.ImportGuests("missing", rosterRows, nil, true)
+	assert.ErrorIs(t, err, domain.ErrInvitationNotFound)
+
+	var input InputError
+	_, err = uc.ImportGuests("uuid", [][]string{{"A", "B"}, {"x", "y"}}, nil, true)
+	assert.ErrorAs(t, err, &input)
+
+	_, err = uc.ImportGuests("uuid", rosterRows, map[string]string{"email": "B"}, true)
+	assert.ErrorAs(t, err, &input)
+
+	_, err = uc.ImportGuests("uuid", rosterRows, map[string]string{"phone": "Почта"}, true)
+	assert.ErrorAs(t, err, &input)
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+const (
+	// IdempotencyKeyTTL is how long a stored response is replayed. n8n
+	// retries within minutes; a day also covers a rerun of a failed flow.
+	IdempotencyKeyTTL = 24 * time.Hour
+	// idempotencyLockTimeout frees keys whose first request never finished,
+	// e.g. because the process was restarted mid-request. It is well above
+	// a batch of MaxBatchInvitations; a request still running after it has
+	// lost its key and neither stores nor releases it.
+	idempotencyLockTimeout  = 10 * time.Minute
+	maxIdempotencyKeyLength = 255
+)
+
+var (
+	ErrIdempotencyKeyReused = errors.New("idempotency_key_reused")
+	ErrIdempotencyKeyInUse  = errors.New("idempotency_key_in_use")
+)
+
+type IdempotencyUseCase struct {
+	repo     domain.IdempotencyRepository
+	now      func() time.Time
+	newToken func() string
+}
+
+func NewIdempotencyUseCase(repo domain.IdempotencyRepository) *IdempotencyUseCase {
+	return &IdempotencyUseCase{repo: repo, now: time.Now, newToken: func() string { return uuid.New().String() }}
+}
+
+// Begin claims key for a request whose method, target and body hash to
+// requestHash. When the request should run it returns the token of the
+// reservation, which Finish and Abort take; otherwise the stored response
+// to replay. A key seen with a different request fails with
+// ErrIdempotencyKeyReused, and one whose first request is still running
+// with ErrIdempotencyKeyInUse.
+func (u *IdempotencyUseCase) Begin(key, requestHash string) (*domain.IdempotencyRecord, string, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, "", InputError("Idempotency-Key is too long")
+	}
+	for _, r := range key {
+		if r < 0x21 || r > 0x7e {
+			return nil, "", InputError("Idempotency-Key must be printable ASCII")
+		}
+	}
+
+	now, token := u.now(), u.newToken()
+	rec, err := u.repo.Reserve(key, token, requestHash, now.Add(-IdempotencyKeyTTL), now.Add(-idempotencyLockTimeout))
+	switch {
+	case err != nil:
+		return nil, "", err
+	case rec == nil:
+		return nil, token, nil
+	case rec.RequestHash != requestHash:
+		return nil, "", ErrIdempotencyKeyReused
+	case rec.StatusCode == 0:
+		return nil, "", ErrIdempotencyKeyInUse
+	}
+	return rec, "", nil
+}
+
+// Finish stores the response of a request started with Begin. Server
+// errors are not stored, so the client's retry runs the request again.
+func (u *IdempotencyUseCase) Finish(key, token string, statusCode int, contentType string, body []byte) error {
+	if statusCode >= 500 {
+		return u.repo.Release(key, token)
+	}
+	return u.repo.Complete(key, token, statusCode, contentType, body)
+}
+
+// Abort frees key without storing a response.
+func (u *IdempotencyUseCase) Abort(key, token string) error {
+	return u.repo.Release(key, token)
+}
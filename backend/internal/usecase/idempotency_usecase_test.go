@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotencyBegin(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	uc := NewIdempotencyUseCase(repo)
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	uc.newToken = func() string { return "token-1" }
+
+	repo.On("Reserve", "fresh", "token-1", "h1", now.Add(-IdempotencyKeyTTL), now.Add(-idempotencyLockTimeout)).Return(nil, nil)
+	rec, token, err := uc.Begin("fresh", "h1")
+	assert.NoError(t, err)
+	assert.Nil(t, rec)
+	assert.Equal(t, "token-1", token)
+
+	stored := &domain.IdempotencyRecord{Key: "done", RequestHash: "h1", StatusCode: 201, Body: []byte(`{}`)}
+	repo.On("Reserve", "done", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(stored, nil)
+	rec, token, err = uc.Begin("done", "h1")
+	assert.NoError(t, err)
+	assert.Equal(t, stored, rec)
+	assert.Empty(t, token)
+
+	_, _, err = uc.Begin("done", "h2")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	repo.On("Reserve", "running", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&domain.IdempotencyRecord{Key: "running", RequestHash: "h1"}, nil)
+	_, _, err = uc.Begin("running", "h1")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInUse)
+
+	var input InputError
+	_, _, err = uc.Begin("with space", "h1")
+	assert.ErrorAs(t, err, &input)
+	_, _, err = uc.Begin(strings.Repeat("k", 256), "h1")
+	assert.ErrorAs(t, err, &input)
+}
+
+func TestIdempotencyFinish(t *testing.T) {
+	repo := new(MockIdempotencyRepository)
+	uc := NewIdempotencyUseCase(repo)
+	repo.On("Complete", "k1", "t1", 400, "application/json", []byte(`{"error":"x"}`)).Return(nil)
+	repo.On("Release", "k2", "t2").Return(nil)
+
+	assert.NoError(t, uc.Finish("k1", "t1", 400, "application/json", []byte(`{"error":"x"}`)))
+	assert.NoError(t, uc.Finish("k2", "t2", 503, "application/json", nil))
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "Complete", "k2", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
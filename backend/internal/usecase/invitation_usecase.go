@@ -2,9 +2,8 @@ package usecase
 
 import (
 	"errors"
-
-	"crypto/sha256"
-	"encoding/base64"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,11 +11,14 @@ import (
 )
 
 type InvitationUseCase struct {
-	repo domain.InvitationRepository
+	repo  domain.InvitationRepository
+	codes ShortCodeGenerator
+	trial TrialPolicy
+	now   func() time.Time
 }
 
-func NewInvitationUseCase(repo domain.InvitationRepository) *InvitationUseCase {
-	return &InvitationUseCase{repo: repo}
+func NewInvitationUseCase(repo domain.InvitationRepository, codes ShortCodeGenerator, trial TrialPolicy) *InvitationUseCase {
+	return &InvitationUseCase{repo: repo, codes: codes, trial: trial, now: time.Now}
 }
 
 func (u *InvitationUseCase) GetInvitation(uuidStr string) (*domain.Invitation, error) {
@@ -29,47 +31,370 @@ func (u *InvitationUseCase) GetInvitation(uuidStr string) (*domain.Invitation, e
 	}
 
 	// Check if expired and unpaid
-	if !inv.IsPaid && inv.ExpiresAt != nil && inv.ExpiresAt.Before(time.Now()) {
+	if inv.IsExpired(u.now()) {
 		return nil, errors.New("invitation_expired")
 	}
 
 	return inv, nil
 }
 
+// FindInvitation loads an invitation without the trial-expiry check, for
+// admin tools such as exports.
+func (u *InvitationUseCase) FindInvitation(uuidStr string) (*domain.Invitation, error) {
+	if uuidStr == "" {
+		return nil, errors.New("uuid is required")
+	}
+	return u.repo.GetByUUID(uuidStr)
+}
+
+// ListRSVPs returns the responses of an invitation in the order they came in.
+func (u *InvitationUseCase) ListRSVPs(uuidStr string) ([]domain.RSVPResponse, error) {
+	return u.repo.GetRSVPs(uuidStr)
+}
+
 func (u *InvitationUseCase) MarkAsPaid(uuid string) error {
 	return u.repo.MarkAsPaid(uuid)
 }
 
-func (u *InvitationUseCase) SubmitRSVP(invUUID string, name string, attendance string, count int) error {
+// ExtendTrial gives an unpaid invitation more time, counted from the end of
+// its trial or from now if that has passed, e.g. for a client paying late.
+func (u *InvitationUseCase) ExtendTrial(uuidStr string, by time.Duration) (*domain.Invitation, error) {
+	if by <= 0 || by > maxTrial {
+		return nil, InputError(fmt.Sprintf("extension must be positive and at most %d days", maxTrial/(24*time.Hour)))
+	}
+	inv, err := u.unpaid(uuidStr)
+	if err != nil {
+		return nil, err
+	}
+	from := u.now()
+	if inv.ExpiresAt != nil && inv.ExpiresAt.After(from) {
+		from = *inv.ExpiresAt
+	}
+	return u.setExpiresAt(inv, from.Add(by))
+}
+
+// RenewTrial starts the trial of an unpaid invitation over, with the trial
+// length of its template.
+func (u *InvitationUseCase) RenewTrial(uuidStr string) (*domain.Invitation, error) {
+	inv, err := u.unpaid(uuidStr)
+	if err != nil {
+		return nil, err
+	}
+	return u.setExpiresAt(inv, u.now().Add(u.trial.For(inv.TemplateCode)))
+}
+
+func (u *InvitationUseCase) unpaid(uuidStr string) (*domain.Invitation, error) {
+	inv, err := u.FindInvitation(uuidStr)
+	if err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	if inv.IsPaid {
+		return nil, domain.ErrInvitationPaid
+	}
+	return inv, nil
+}
+
+func (u *InvitationUseCase) setExpiresAt(inv *domain.Invitation, at time.Time) (*domain.Invitation, error) {
+	at = at.Truncate(time.Second)
+	if err := u.repo.SetExpiresAt(inv.UUID, at); err != nil {
+		return nil, err
+	}
+	inv.ExpiresAt = &at
+	return inv, nil
+}
+
+// SetHostingUntil moves the end of the hosting of a paid invitation; nil
+// keeps it online for good. A date in the past takes it offline.
+func (u *InvitationUseCase) SetHostingUntil(uuidStr string, until *time.Time) (*domain.Invitation, error) {
+	inv, err := u.FindInvitation(uuidStr)
+	if err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	if !inv.IsPaid {
+		return nil, domain.ErrInvitationNotPaid
+	}
+	if err := u.repo.SetHostingUntil(inv.UUID, until); err != nil {
+		return nil, err
+	}
+	inv.HostingUntil = until
+	return inv, nil
+}
+
+// SubmitRSVP records a guest's answer. phone is optional.
+func (u *InvitationUseCase) SubmitRSVP(invUUID string, name string, attendance string, count int, phone string) error {
 	if invUUID == "" || name == "" || attendance == "" {
 		return errors.New("missing required fields for RSVP")
 	}
+	number, err := rsvpPhone(phone)
+	if err != nil {
+		return err
+	}
 	rsvp := &domain.RSVPResponse{
 		InvitationUUID: invUUID,
 		GuestName:      name,
 		Attendance:     attendance,
 		GuestCount:     count,
+		Phone:          number,
 	}
 	return u.repo.AddRSVP(rsvp)
 }
 
+// rsvpPhone normalizes the optional phone number of an RSVP.
+func rsvpPhone(phone string) (string, error) {
+	if strings.TrimSpace(phone) == "" {
+		return "", nil
+	}
+	return normalizePhone(phone)
+}
+
+// UpdateRSVP corrects a response on behalf of the couple, e.g. a guest who
+// changed their mind by phone. phone is the guest's, optional.
+func (u *InvitationUseCase) UpdateRSVP(invUUID string, id int, name string, attendance string, count int, phone string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return InputError("guestName is required")
+	}
+	switch attendance {
+	case domain.AttendanceYes, domain.AttendanceNo, domain.AttendanceMaybe:
+	default:
+		return InputError(fmt.Sprintf("invalid attendance: %q", attendance))
+	}
+	if count < 1 {
+		return InputError("guestCount must be at least 1")
+	}
+	number, err := rsvpPhone(phone)
+	if err != nil {
+		return err
+	}
+	return u.repo.UpdateRSVP(&domain.RSVPResponse{
+		ID:             id,
+		InvitationUUID: invUUID,
+		GuestName:      name,
+		Attendance:     attendance,
+		GuestCount:     count,
+		Phone:          number,
+	})
+}
+
+// DeleteRSVP removes a response, e.g. a duplicate or a test.
+func (u *InvitationUseCase) DeleteRSVP(invUUID string, id int) error {
+	return u.repo.DeleteRSVP(invUUID, id)
+}
+
+// CreateInvitation validates and stores a new invitation. A ShortCode set by
+// the caller is a vanity slug: it is normalized and fails with
+// domain.ErrShortCodeTaken when in use. Otherwise a random code is
+// allocated, retrying on collisions.
 func (u *InvitationUseCase) CreateInvitation(inv *domain.Invitation) error {
+	vanity := inv.ShortCode != ""
+	if err := u.prepareInvitation(inv); err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		err := u.repo.Create(inv)
+		if !errors.Is(err, domain.ErrShortCodeTaken) || vanity || attempt == maxShortCodeAttempts {
+			return err
+		}
+		inv.ShortCode = u.codes.Generate()
+	}
+}
+
+// MaxBatchInvitations caps CreateInvitations so one request stays well
+// inside a single transaction and the HTTP timeout.
+const MaxBatchInvitations = 100
+
+// CreateInvitations creates several invitations at once. Every entry is
+// validated first. By default the batch is all or nothing: if any entry is
+// invalid nothing is written, and the valid entries are reported as
+// "batch_rejected"; a database error is returned as is. With bestEffort each
+// valid entry is inserted on its own and failures are reported per entry.
+func (u *InvitationUseCase) CreateInvitations(invs []*domain.Invitation, bestEffort bool) ([]domain.InvitationBatchItem, error) {
+	if len(invs) == 0 {
+		return nil, InputError("invitations are required")
+	}
+	if len(invs) > MaxBatchInvitations {
+		return nil, InputError(fmt.Sprintf("too many invitations: at most %d per batch", MaxBatchInvitations))
+	}
+
+	if bestEffort {
+		results := make([]domain.InvitationBatchItem, len(invs))
+		seen := map[string]int{}
+		for i, inv := range invs {
+			results[i].Index = i
+			if inv == nil {
+				results[i].Error = "invitation is required"
+				continue
+			}
+			if j, ok := seen[inv.UUID]; ok && inv.UUID != "" {
+				results[i].Error = fmt.Sprintf("duplicate uuid of item %d", j)
+				continue
+			}
+			seen[inv.UUID] = i
+			if err := u.CreateInvitation(inv); err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			results[i].UUID, results[i].ShortCode = inv.UUID, inv.ShortCode
+		}
+		return results, nil
+	}
+
+	results := make([]domain.InvitationBatchItem, len(invs))
+	generated := make([]bool, len(invs))
+	seen := map[string]int{}
+	failed := false
+	for i, inv := range invs {
+		results[i].Index = i
+		if err := u.prepareBatchItem(inv, i, seen, &generated[i]); err != nil {
+			results[i].Error = err.Error()
+			failed = true
+		}
+	}
+	if failed {
+		for i := range results {
+			if results[i].Error == "" {
+				results[i].Error = "batch_rejected"
+			}
+		}
+		return results, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := u.repo.CreateMany(invs)
+		if err == nil {
+			break
+		}
+		// A vanity slug checked above may have been taken meanwhile;
+		// only random codes are worth another try.
+		if !errors.Is(err, domain.ErrShortCodeTaken) || attempt == maxShortCodeAttempts {
+			return nil, err
+		}
+		for i, inv := range invs {
+			if generated[i] {
+				inv.ShortCode = u.uniqueCode(seen, i)
+			}
+		}
+	}
+	for i, inv := range invs {
+		results[i].UUID, results[i].ShortCode = inv.UUID, inv.ShortCode
+	}
+	return results, nil
+}
+
+// prepareBatchItem validates entry i of an atomic batch. The batch shares
+// one transaction, so anything that would fail it as a whole -- a repeated
+// uuid or slug, or a slug already in use -- is reported on the entry.
+func (u *InvitationUseCase) prepareBatchItem(inv *domain.Invitation, i int, seen map[string]int, generated *bool) error {
+	if inv == nil {
+		return errors.New("invitation is required")
+	}
+	*generated = inv.ShortCode == ""
+	if err := u.prepareInvitation(inv); err != nil {
+		return err
+	}
+	if j, ok := seen["uuid:"+inv.UUID]; ok {
+		return fmt.Errorf("duplicate uuid of item %d", j)
+	}
+	seen["uuid:"+inv.UUID] = i
+	if *generated {
+		if _, ok := seen["code:"+inv.ShortCode]; ok {
+			inv.ShortCode = u.uniqueCode(seen, i)
+		}
+		seen["code:"+inv.ShortCode] = i
+		return nil
+	}
+	if j, ok := seen["code:"+inv.ShortCode]; ok {
+		return fmt.Errorf("duplicate shortCode of item %d", j)
+	}
+	seen["code:"+inv.ShortCode] = i
+	taken, err := u.repo.ShortCodeExists(inv.ShortCode)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return domain.ErrShortCodeTaken
+	}
+	return nil
+}
+
+// uniqueCode generates a code not yet used within the batch and records it
+// for entry i.
+func (u *InvitationUseCase) uniqueCode(seen map[string]int, i int) string {
+	for {
+		code := u.codes.Generate()
+		if _, ok := seen["code:"+code]; !ok {
+			seen["code:"+code] = i
+			return code
+		}
+	}
+}
+
+// ShortCodeAvailable normalizes a requested vanity slug and reports whether
+// it can be used. Invalid or reserved slugs come back as an InputError.
+func (u *InvitationUseCase) ShortCodeAvailable(raw string) (string, bool, error) {
+	slug, err := NormalizeSlug(raw)
+	if err != nil {
+		return slug, false, err
+	}
+	taken, err := u.repo.ShortCodeExists(slug)
+	if err != nil {
+		return slug, false, err
+	}
+	return slug, !taken, nil
+}
+
+// supportedLangs are the languages invitations and their pages exist in.
+var supportedLangs = map[string]bool{"ru": true, "kk": true, "en": true}
+
+// prepareInvitation validates a new invitation and fills in the defaults:
+// identifiers, language, trial expiry and empty content. It is shared by
+// single and batch creation so both accept exactly the same input.
+func (u *InvitationUseCase) prepareInvitation(inv *domain.Invitation) error {
+	inv.PhoneNumber = strings.TrimSpace(inv.PhoneNumber)
+	if inv.PhoneNumber == "" {
+		return InputError("phoneNumber is required")
+	}
+	if inv.TemplateCode == "" {
+		return InputError("templateCode is required")
+	}
+	if inv.Lang == "" {
+		inv.Lang = "ru"
+	}
+	if !supportedLangs[inv.Lang] {
+		return InputError(fmt.Sprintf("unsupported lang: %q", inv.Lang))
+	}
+	inv.PlanCode = strings.ToLower(strings.TrimSpace(inv.PlanCode))
+	inv.Lifecycle = domain.LifecycleActive
+	if inv.EventDate != "" {
+		if _, ok := inv.EventTime(); !ok {
+			return InputError(fmt.Sprintf("invalid eventDate: %q", inv.EventDate))
+		}
+	}
+
 	if inv.UUID == "" {
 		inv.UUID = uuid.New().String()
+	} else if _, err := uuid.Parse(inv.UUID); err != nil {
+		return InputError(fmt.Sprintf("invalid uuid: %q", inv.UUID))
 	}
 	if inv.ShortCode == "" {
-		inv.ShortCode = generateShortCode(inv.UUID)
+		inv.ShortCode = u.codes.Generate()
+	} else {
+		slug, err := NormalizeSlug(inv.ShortCode)
+		if err != nil {
+			return err
+		}
+		inv.ShortCode = slug
 	}
-	// Default: Expire in 1 hour if not paid
+	// Unpaid invitations stay online for the trial of their template.
 	if inv.ExpiresAt == nil {
-		exp := time.Now().Add(1 * time.Hour)
+		exp := u.now().Add(u.trial.For(inv.TemplateCode))
 		inv.ExpiresAt = &exp
 	}
 	// Initialize Content if nil to prevent DB violation (NOT NULL)
 	if inv.Content == nil {
 		inv.Content = make(map[string]interface{})
 	}
-	return u.repo.Create(inv)
+	return nil
 }
 
 func (u *InvitationUseCase) ResolveShortCode(code string) (string, error) {
@@ -79,22 +404,3 @@ func (u *InvitationUseCase) ResolveShortCode(code string) (string, error) {
 	}
 	return inv.UUID, nil
 }
-
-// Private helper to generate short code (normally a separate domain service)
-// Private helper to generate short code (normally a separate domain service)
-func generateShortCode(seedUUID string) string {
-	// Combine UUID and current nanosecond timestamp for uniqueness
-	data := seedUUID + time.Now().String()
-	hash := sha256.Sum256([]byte(data))
-
-	// Encode hash to make it URL-safe string
-	encoded := base64.RawURLEncoding.EncodeToString(hash[:])
-
-	// Take first 6 characters.
-	// In a real high-load system, you'd check for collisions in DB,
-	// but for this scale, 6 chars of SHA256 is effectively unique.
-	if len(encoded) > 6 {
-		return encoded[:6]
-	}
-	return encoded
-}
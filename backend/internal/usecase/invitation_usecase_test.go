@@ -1,16 +1,19 @@
 package usecase
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetInvitation(t *testing.T) {
 	mockRepo := new(MockInvitationRepository)
-	uc := NewInvitationUseCase(mockRepo)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
 
 	testUUID := "test-uuid"
 	expectedInv := &domain.Invitation{UUID: testUUID, PhoneNumber: "123"}
@@ -26,12 +29,324 @@ func TestGetInvitation(t *testing.T) {
 
 func TestSubmitRSVP(t *testing.T) {
 	mockRepo := new(MockInvitationRepository)
-	uc := NewInvitationUseCase(mockRepo)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
 
-	mockRepo.On("AddRSVP", mock.Anything).Return(nil)
+	mockRepo.On("AddRSVP", &domain.RSVPResponse{InvitationUUID: "uuid", GuestName: "Ivan", Attendance: "yes", GuestCount: 2}).Return(nil).Once()
+	mockRepo.On("AddRSVP", &domain.RSVPResponse{InvitationUUID: "uuid", GuestName: "Ivan", Attendance: "yes", GuestCount: 2, Phone: "+77011234567"}).Return(nil).Once()
 
-	err := uc.SubmitRSVP("uuid", "Ivan", "yes", 2)
+	err := uc.SubmitRSVP("uuid", "Ivan", "yes", 2, "")
+	assert.NoError(t, err)
+	assert.NoError(t, uc.SubmitRSVP("uuid", "Ivan", "yes", 2, "8 (701) 123-45-67"))
+
+	var input InputError
+	assert.ErrorAs(t, uc.SubmitRSVP("uuid", "Ivan", "yes", 2, "call me"), &input)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateRSVP(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+
+	for _, c := range []struct {
+		name, attendance string
+		count            int
+	}{
+		{" ", "yes", 1},
+		{"Ivan", "perhaps", 1},
+		{"Ivan", "yes", 0},
+	} {
+		var input InputError
+		assert.ErrorAs(t, uc.UpdateRSVP("uuid", 7, c.name, c.attendance, c.count, ""), &input, c)
+	}
+
+	mockRepo.On("UpdateRSVP", &domain.RSVPResponse{ID: 7, InvitationUUID: "uuid", GuestName: "Ivan", Attendance: "maybe", GuestCount: 2}).Return(nil)
+	assert.NoError(t, uc.UpdateRSVP("uuid", 7, " Ivan ", "maybe", 2, ""))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateInvitation_Validation(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
 
+	for _, inv := range []*domain.Invitation{
+		{TemplateCode: "starry-night"},
+		{PhoneNumber: "+77011234567"},
+		{PhoneNumber: "+77011234567", TemplateCode: "starry-night", Lang: "de"},
+		{PhoneNumber: "+77011234567", TemplateCode: "starry-night", EventDate: "next summer"},
+		{PhoneNumber: "+77011234567", TemplateCode: "starry-night", UUID: "not-a-uuid"},
+	} {
+		var input InputError
+		assert.ErrorAs(t, uc.CreateInvitation(inv), &input)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	mockRepo.On("Create", mock.Anything).Return(nil)
+	inv := &domain.Invitation{PhoneNumber: " +77011234567 ", TemplateCode: "starry-night", EventDate: "2026-07-15T18:00"}
+	assert.NoError(t, uc.CreateInvitation(inv))
+	assert.Equal(t, "+77011234567", inv.PhoneNumber)
+	assert.Equal(t, "ru", inv.Lang)
+	assert.NotEmpty(t, inv.UUID)
+	assert.Len(t, inv.ShortCode, 6)
+	assert.NotNil(t, inv.ExpiresAt)
+}
+
+func batchInvitations() []*domain.Invitation {
+	return []*domain.Invitation{
+		{PhoneNumber: "+77011234567", TemplateCode: "starry-night"},
+		{PhoneNumber: "", TemplateCode: "silk-ivory"},
+		{PhoneNumber: "+77021234567", TemplateCode: "silk-ivory", Lang: "kk"},
+	}
+}
+
+func TestCreateInvitations_AtomicRejectsInvalidBatch(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+
+	results, err := uc.CreateInvitations(batchInvitations(), false)
 	assert.NoError(t, err)
+	assert.Equal(t, []domain.InvitationBatchItem{
+		{Index: 0, Error: "batch_rejected"},
+		{Index: 1, Error: "phoneNumber is required"},
+		{Index: 2, Error: "batch_rejected"},
+	}, results)
+	mockRepo.AssertNotCalled(t, "CreateMany", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestCreateInvitations_AtomicCreatesInOneCall(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+	mockRepo.On("CreateMany", mock.MatchedBy(func(invs []*domain.Invitation) bool { return len(invs) == 2 })).Return(nil)
+
+	invs := batchInvitations()
+	invs[1].PhoneNumber = "+77031234567"
+	invs = invs[1:]
+	results, err := uc.CreateInvitations(invs, false)
+	assert.NoError(t, err)
+	for i, r := range results {
+		assert.Empty(t, r.Error)
+		assert.Equal(t, invs[i].UUID, r.UUID)
+		assert.Equal(t, invs[i].ShortCode, r.ShortCode)
+	}
+	mockRepo.AssertExpectations(t)
+
+	failing := new(MockInvitationRepository)
+	failing.On("CreateMany", mock.Anything).Return(errors.New("conflict"))
+	_, err = NewInvitationUseCase(failing, ShortCodeGenerator{}, TrialPolicy{}).CreateInvitations(batchInvitations()[:1], false)
+	assert.EqualError(t, err, "conflict")
+}
+
+func TestCreateInvitations_BestEffort(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+	mockRepo.On("Create", mock.MatchedBy(func(inv *domain.Invitation) bool { return inv.Lang == "ru" })).Return(nil)
+	mockRepo.On("Create", mock.MatchedBy(func(inv *domain.Invitation) bool { return inv.Lang == "kk" })).Return(errors.New("db is down"))
+
+	results, err := uc.CreateInvitations(batchInvitations(), true)
+	assert.NoError(t, err)
+	assert.Empty(t, results[0].Error)
+	assert.NotEmpty(t, results[0].UUID)
+	assert.Equal(t, "phoneNumber is required", results[1].Error)
+	assert.Equal(t, domain.InvitationBatchItem{Index: 2, Error: "db is down"}, results[2])
+	mockRepo.AssertNotCalled(t, "CreateMany", mock.Anything)
+}
+
+func TestCreateInvitations_Limits(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+	mockRepo.On("Create", mock.Anything).Return(nil)
+	var input InputError
+
+	_, err := uc.CreateInvitations(nil, false)
+	assert.ErrorAs(t, err, &input)
+
+	_, err = uc.CreateInvitations(make([]*domain.Invitation, MaxBatchInvitations+1), false)
+	assert.ErrorAs(t, err, &input)
+
+	id := "7f1c1c2e-3c6a-4c37-9d7b-0d1b8e1e2a10"
+	results, err := uc.CreateInvitations([]*domain.Invitation{
+		{UUID: id, PhoneNumber: "1", TemplateCode: "starry-night"},
+		{UUID: id, PhoneNumber: "2", TemplateCode: "starry-night"},
+		nil,
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "duplicate uuid of item 0", results[1].Error)
+	assert.Equal(t, "invitation is required", results[2].Error)
+}
+
+func TestCreateInvitation_RetriesShortCodeCollision(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+	var codes []string
+	record := func(args mock.Arguments) { codes = append(codes, args.Get(0).(*domain.Invitation).ShortCode) }
+	mockRepo.On("Create", mock.Anything).Run(record).Return(domain.ErrShortCodeTaken).Twice()
+	mockRepo.On("Create", mock.Anything).Run(record).Return(nil).Once()
+
+	inv := &domain.Invitation{PhoneNumber: "+77011234567", TemplateCode: "starry-night"}
+	assert.NoError(t, uc.CreateInvitation(inv))
+	assert.Len(t, codes, 3)
+	assert.NotEqual(t, codes[0], codes[2])
+	assert.Equal(t, codes[2], inv.ShortCode)
+
+	always := new(MockInvitationRepository)
+	always.On("Create", mock.Anything).Return(domain.ErrShortCodeTaken)
+	err := NewInvitationUseCase(always, ShortCodeGenerator{}, TrialPolicy{}).CreateInvitation(&domain.Invitation{PhoneNumber: "1", TemplateCode: "starry-night"})
+	assert.ErrorIs(t, err, domain.ErrShortCodeTaken)
+	always.AssertNumberOfCalls(t, "Create", maxShortCodeAttempts)
+}
+
+func TestCreateInvitation_VanitySlug(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+	mockRepo.On("Create", mock.MatchedBy(func(inv *domain.Invitation) bool { return inv.ShortCode == "arman-aigerim" })).
+		Return(domain.ErrShortCodeTaken).Once()
+
+	inv := &domain.Invitation{PhoneNumber: "1", TemplateCode: "starry-night", ShortCode: "Arman-Aigerim"}
+	assert.ErrorIs(t, uc.CreateInvitation(inv), domain.ErrShortCodeTaken)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+
+	var input InputError
+	assert.ErrorAs(t, uc.CreateInvitation(&domain.Invitation{PhoneNumber: "1", TemplateCode: "starry-night", ShortCode: "assets"}), &input)
+}
+
+func TestCreateInvitations_AtomicShortCodes(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+	mockRepo.On("ShortCodeExists", "arman-aigerim").Return(true, nil)
+	mockRepo.On("ShortCodeExists", "dana-timur").Return(false, nil)
+
+	results, err := uc.CreateInvitations([]*domain.Invitation{
+		{PhoneNumber: "1", TemplateCode: "starry-night", ShortCode: "arman-aigerim"},
+		{PhoneNumber: "2", TemplateCode: "starry-night", ShortCode: "dana-timur"},
+		{PhoneNumber: "3", TemplateCode: "starry-night", ShortCode: "Dana-Timur"},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "short_code_taken", results[0].Error)
+	assert.Equal(t, "batch_rejected", results[1].Error)
+	assert.Equal(t, "duplicate shortCode of item 1", results[2].Error)
+	mockRepo.AssertNotCalled(t, "CreateMany", mock.Anything)
+
+	// A collision of a random code on insert regenerates only random codes.
+	var calls [][]string
+	mockRepo.On("CreateMany", mock.Anything).Run(func(args mock.Arguments) {
+		var codes []string
+		for _, inv := range args.Get(0).([]*domain.Invitation) {
+			codes = append(codes, inv.ShortCode)
+		}
+		calls = append(calls, codes)
+	}).Return(domain.ErrShortCodeTaken).Once()
+	mockRepo.On("CreateMany", mock.Anything).Return(nil).Once()
+
+	results, err = uc.CreateInvitations([]*domain.Invitation{
+		{PhoneNumber: "1", TemplateCode: "starry-night"},
+		{PhoneNumber: "2", TemplateCode: "starry-night", ShortCode: "dana-timur"},
+	}, false)
+	assert.NoError(t, err)
+	assert.NotEqual(t, calls[0][0], results[0].ShortCode)
+	assert.Equal(t, "dana-timur", results[1].ShortCode)
+}
+
+func TestShortCodeAvailable(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+	mockRepo.On("ShortCodeExists", "arman-aigerim").Return(true, nil)
+	mockRepo.On("ShortCodeExists", "dana-timur").Return(false, nil)
+
+	slug, ok, err := uc.ShortCodeAvailable("Arman-Aigerim")
+	assert.NoError(t, err)
+	assert.Equal(t, "arman-aigerim", slug)
+	assert.False(t, ok)
+
+	_, ok, err = uc.ShortCodeAvailable("dana-timur")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, _, err = uc.ShortCodeAvailable("admin")
+	var input InputError
+	assert.ErrorAs(t, err, &input)
+	mockRepo.AssertNotCalled(t, "ShortCodeExists", "admin")
+}
+
+func TestCreateInvitation_TrialByTemplate(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{Default: 24 * time.Hour, Templates: map[string]time.Duration{"silk-ivory": 72 * time.Hour}})
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+	mockRepo.On("Create", mock.Anything).Return(nil)
+
+	inv := &domain.Invitation{PhoneNumber: "1", TemplateCode: "silk-ivory"}
+	require.NoError(t, uc.CreateInvitation(inv))
+	assert.Equal(t, now.Add(72*time.Hour), *inv.ExpiresAt)
+
+	inv = &domain.Invitation{PhoneNumber: "1", TemplateCode: "starry-night"}
+	require.NoError(t, uc.CreateInvitation(inv))
+	assert.Equal(t, now.Add(24*time.Hour), *inv.ExpiresAt)
+}
+
+func TestExtendTrial(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	later, lapsed := now.Add(2*time.Hour), now.Add(-48*time.Hour)
+	mockRepo.On("GetByUUID", "running").Return(&domain.Invitation{UUID: "running", ExpiresAt: &later}, nil)
+	mockRepo.On("GetByUUID", "lapsed").Return(&domain.Invitation{UUID: "lapsed", ExpiresAt: &lapsed}, nil)
+	mockRepo.On("GetByUUID", "paid").Return(&domain.Invitation{UUID: "paid", IsPaid: true}, nil)
+	mockRepo.On("GetByUUID", "missing").Return(nil, errors.New("no rows in result set"))
+	mockRepo.On("SetExpiresAt", "running", now.Add(26*time.Hour)).Return(nil)
+	mockRepo.On("SetExpiresAt", "lapsed", now.Add(24*time.Hour)).Return(nil)
+
+	inv, err := uc.ExtendTrial("running", 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(26*time.Hour), *inv.ExpiresAt)
+
+	// A trial that is over is extended from now.
+	inv, err = uc.ExtendTrial("lapsed", 24*time.Hour)
+	require.NoError(t, err)
+	assert.False(t, inv.IsExpired(now))
+
+	_, err = uc.ExtendTrial("paid", time.Hour)
+	assert.ErrorIs(t, err, domain.ErrInvitationPaid)
+	_, err = uc.ExtendTrial("missing", time.Hour)
+	assert.ErrorIs(t, err, domain.ErrInvitationNotFound)
+	var input InputError
+	_, err = uc.ExtendTrial("running", 0)
+	assert.ErrorAs(t, err, &input)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRenewTrial(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{Templates: map[string]time.Duration{"silk-ivory": 48 * time.Hour}})
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	uc.now = func() time.Time { return now }
+
+	lapsed := now.Add(-time.Hour)
+	mockRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1", TemplateCode: "silk-ivory", ExpiresAt: &lapsed}, nil)
+	mockRepo.On("SetExpiresAt", "uuid-1", now.Add(48*time.Hour)).Return(nil)
+
+	inv, err := uc.RenewTrial("uuid-1")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(48*time.Hour), *inv.ExpiresAt)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSetHostingUntil(t *testing.T) {
+	mockRepo := new(MockInvitationRepository)
+	uc := NewInvitationUseCase(mockRepo, ShortCodeGenerator{}, TrialPolicy{})
+
+	until := time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetByUUID", "paid").Return(&domain.Invitation{UUID: "paid", IsPaid: true}, nil)
+	mockRepo.On("GetByUUID", "trial").Return(&domain.Invitation{UUID: "trial"}, nil)
+	mockRepo.On("SetHostingUntil", "paid", &until).Return(nil)
+
+	inv, err := uc.SetHostingUntil("paid", &until)
+	require.NoError(t, err)
+	assert.Equal(t, &until, inv.HostingUntil)
+	assert.True(t, inv.IsExpired(until.Add(time.Second)))
+
+	_, err = uc.SetHostingUntil("trial", &until)
+	assert.ErrorIs(t, err, domain.ErrInvitationNotPaid)
 	mockRepo.AssertExpectations(t)
 }
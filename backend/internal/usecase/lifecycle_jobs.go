@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+// DefaultTrialRetention is how long an abandoned trial is kept after it
+// expires before purge-expired-trials deletes it.
+const DefaultTrialRetention = 30 * 24 * time.Hour
+
+// LifecycleJobs are the scheduled jobs that move invitations through their
+// lifecycle. Purging is off until an admin turns it on, since it deletes.
+func LifecycleJobs(repo domain.LifecycleRepository, retention time.Duration) []ScheduledJob {
+	if retention <= 0 {
+		retention = DefaultTrialRetention
+	}
+	return []ScheduledJob{
+		{
+			Name:        "expire-invitations",
+			Description: "Mark invitations whose trial or hosting ended as expired, and extended ones as active again",
+			Schedule:    "*/5 * * * *",
+			Enabled:     true,
+			Run: func(context.Context) (int, error) {
+				return repo.ExpireInvitations(time.Now())
+			},
+		},
+		{
+			Name:        "post-event",
+			Description: "Move invitations to post_event the day after the wedding",
+			Schedule:    "10 * * * *",
+			Enabled:     true,
+			Run: func(context.Context) (int, error) {
+				// A day of slack covers events in any timezone.
+				return repo.MarkPostEvent(time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour))
+			},
+		},
+		{
+			Name:        "purge-expired-trials",
+			Description: "Delete unpaid trials that expired over the retention period ago and that no guest answered",
+			Schedule:    "30 3 * * *",
+			Run: func(context.Context) (int, error) {
+				return repo.PurgeExpiredTrials(time.Now().Add(-retention))
+			},
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"time"
+
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
 	"github.com/stretchr/testify/mock"
 )
@@ -30,16 +32,54 @@ func (m *MockInvitationRepository) Create(inv *domain.Invitation) error {
 	return args.Error(0)
 }
 
+func (m *MockInvitationRepository) CreateMany(invs []*domain.Invitation) error {
+	args := m.Called(invs)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) ShortCodeExists(code string) (bool, error) {
+	args := m.Called(code)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockInvitationRepository) AddRSVP(rsvp *domain.RSVPResponse) error {
 	args := m.Called(rsvp)
 	return args.Error(0)
 }
 
+func (m *MockInvitationRepository) UpdateRSVP(rsvp *domain.RSVPResponse) error {
+	args := m.Called(rsvp)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) DeleteRSVP(invitationUUID string, id int) error {
+	args := m.Called(invitationUUID, id)
+	return args.Error(0)
+}
+
 func (m *MockInvitationRepository) MarkAsPaid(uuid string) error {
 	args := m.Called(uuid)
 	return args.Error(0)
 }
 
+func (m *MockInvitationRepository) SetExpiresAt(uuid string, expiresAt time.Time) error {
+	args := m.Called(uuid, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) SetHostingUntil(uuid string, hostingUntil *time.Time) error {
+	args := m.Called(uuid, hostingUntil)
+	return args.Error(0)
+}
+
+func (m *MockInvitationRepository) GetRSVPs(invitationUUID string) ([]domain.RSVPResponse, error) {
+	args := m.Called(invitationUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.RSVPResponse), args.Error(1)
+}
+
 type MockAdminRepository struct {
 	mock.Mock
 }
@@ -52,12 +92,33 @@ func (m *MockAdminRepository) GetStats() (*domain.AdminStats, error) {
 	return args.Get(0).(*domain.AdminStats), args.Error(1)
 }
 
-func (m *MockAdminRepository) GetInvitationsList() ([]domain.InvitationWithStats, error) {
-	args := m.Called()
+func (m *MockAdminRepository) GetAnalytics(from, to time.Time, granularity string) (*domain.BusinessAnalytics, error) {
+	args := m.Called(from, to, granularity)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]domain.InvitationWithStats), args.Error(1)
+	return args.Get(0).(*domain.BusinessAnalytics), args.Error(1)
+}
+
+func (m *MockAdminRepository) GetInvitationsList(filter domain.InvitationFilter) (*domain.InvitationPage, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.InvitationPage), args.Error(1)
+}
+
+// EachInvitation feeds the list given to Return to fn.
+func (m *MockAdminRepository) EachInvitation(filter domain.InvitationFilter, fn func(*domain.InvitationWithStats) error) error {
+	args := m.Called(filter)
+	if list, ok := args.Get(0).([]domain.InvitationWithStats); ok {
+		for i := range list {
+			if err := fn(&list[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
 }
 
 func (m *MockAdminRepository) GetTemplates() ([]domain.Template, error) {
@@ -72,3 +133,519 @@ func (m *MockAdminRepository) MarkAsPaid(uuid string) error {
 	args := m.Called(uuid)
 	return args.Error(0)
 }
+
+func (m *MockAdminRepository) ListExpiring(from, to time.Time) ([]domain.ExpiringInvitation, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ExpiringInvitation), args.Error(1)
+}
+
+func (m *MockAdminRepository) ReconcileRSVPCounters() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+type MockGuestRepository struct {
+	mock.Mock
+}
+
+func (m *MockGuestRepository) ListGuests(invitationUUID string) ([]domain.Guest, error) {
+	args := m.Called(invitationUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Guest), args.Error(1)
+}
+
+func (m *MockGuestRepository) AddGuests(guests []domain.Guest) error {
+	args := m.Called(guests)
+	return args.Error(0)
+}
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Reserve(key, token, requestHash string, expiredBefore, staleBefore time.Time) (*domain.IdempotencyRecord, error) {
+	args := m.Called(key, token, requestHash, expiredBefore, staleBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Complete(key, token string, statusCode int, contentType string, body []byte) error {
+	args := m.Called(key, token, statusCode, contentType, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Release(key, token string) error {
+	args := m.Called(key, token)
+	return args.Error(0)
+}
+
+type MockClickRepository struct {
+	mock.Mock
+}
+
+func (m *MockClickRepository) AddClicks(clicks []domain.Click) error {
+	args := m.Called(clicks)
+	return args.Error(0)
+}
+
+func (m *MockClickRepository) GetClickStats(invitationUUID string, from, to time.Time) (*domain.ClickStats, error) {
+	args := m.Called(invitationUUID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ClickStats), args.Error(1)
+}
+
+type MockEngagementRepository struct {
+	mock.Mock
+}
+
+func (m *MockEngagementRepository) AddEngagementEvents(events []domain.EngagementEvent) error {
+	args := m.Called(events)
+	return args.Error(0)
+}
+
+func (m *MockEngagementRepository) GetFunnel(invitationUUID string) (*domain.EngagementFunnel, error) {
+	args := m.Called(invitationUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.EngagementFunnel), args.Error(1)
+}
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) CreateOrder(order *domain.Order) error {
+	args := m.Called(order)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) GetOrder(id string) (*domain.Order, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) ListOrders(invitationUUID string) ([]domain.Order, error) {
+	args := m.Called(invitationUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) ApplyPaymentEvent(ev *domain.PaymentEvent) (*domain.Order, bool, error) {
+	args := m.Called(ev)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*domain.Order), args.Bool(1), args.Error(2)
+}
+
+type MockPricingRepository struct {
+	mock.Mock
+}
+
+func (m *MockPricingRepository) ListPlans(activeOnly bool) ([]domain.Plan, error) {
+	args := m.Called(activeOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Plan), args.Error(1)
+}
+
+func (m *MockPricingRepository) GetPlan(code string) (*domain.Plan, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Plan), args.Error(1)
+}
+
+func (m *MockPricingRepository) SavePlan(plan *domain.Plan) error {
+	args := m.Called(plan)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) ListPromoCodes() ([]domain.PromoCode, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.PromoCode), args.Error(1)
+}
+
+func (m *MockPricingRepository) GetPromoCode(code string) (*domain.PromoCode, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PromoCode), args.Error(1)
+}
+
+func (m *MockPricingRepository) CreatePromoCode(promo *domain.PromoCode) error {
+	args := m.Called(promo)
+	return args.Error(0)
+}
+
+func (m *MockPricingRepository) DeactivatePromoCode(code string) error {
+	args := m.Called(code)
+	return args.Error(0)
+}
+
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) JobFlags() (map[string]bool, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockJobRepository) SetJobEnabled(job string, enabled bool) error {
+	args := m.Called(job, enabled)
+	return args.Error(0)
+}
+
+// TryJobLock returns the ok and error given to Return, and an unlock that
+// does nothing.
+func (m *MockJobRepository) TryJobLock(job string) (func(), bool, error) {
+	args := m.Called(job)
+	return func() {}, args.Bool(0), args.Error(1)
+}
+
+func (m *MockJobRepository) StartJobRun(job string, scheduledFor time.Time, manual bool) (*domain.JobRun, bool, error) {
+	args := m.Called(job, scheduledFor, manual)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*domain.JobRun), args.Bool(1), args.Error(2)
+}
+
+func (m *MockJobRepository) FinishJobRun(run *domain.JobRun) error {
+	args := m.Called(run)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) ListJobRuns(job string, limit int) ([]domain.JobRun, error) {
+	args := m.Called(job, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.JobRun), args.Error(1)
+}
+
+func (m *MockJobRepository) LastJobRuns() (map[string]domain.JobRun, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]domain.JobRun), args.Error(1)
+}
+
+type MockLifecycleRepository struct {
+	mock.Mock
+}
+
+func (m *MockLifecycleRepository) ExpireInvitations(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLifecycleRepository) MarkPostEvent(day time.Time) (int, error) {
+	args := m.Called(day)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockLifecycleRepository) PurgeExpiredTrials(cutoff time.Time) (int, error) {
+	args := m.Called(cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+type MockQueueRepository struct {
+	mock.Mock
+}
+
+func (m *MockQueueRepository) Enqueue(job *domain.QueueJob) error {
+	return m.Called(job).Error(0)
+}
+
+func (m *MockQueueRepository) ClaimQueueJobs(kinds []string, limit int, lease time.Duration) ([]domain.QueueJob, error) {
+	args := m.Called(kinds, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.QueueJob), args.Error(1)
+}
+
+func (m *MockQueueRepository) CompleteQueueJob(job *domain.QueueJob) error {
+	return m.Called(job).Error(0)
+}
+
+func (m *MockQueueRepository) FailQueueJob(job *domain.QueueJob, errMsg string, retryAfter time.Duration) error {
+	return m.Called(job, errMsg, retryAfter).Error(0)
+}
+
+func (m *MockQueueRepository) DeadLetterQueueJob(job *domain.QueueJob, errMsg string) error {
+	return m.Called(job, errMsg).Error(0)
+}
+
+func (m *MockQueueRepository) ReleaseQueueJob(job *domain.QueueJob) error {
+	return m.Called(job).Error(0)
+}
+
+func (m *MockQueueRepository) GetQueueJob(id int64) (*domain.QueueJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.QueueJob), args.Error(1)
+}
+
+func (m *MockQueueRepository) ListQueueJobs(filter domain.QueueJobFilter) ([]domain.QueueJob, int, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]domain.QueueJob), args.Int(1), args.Error(2)
+}
+
+func (m *MockQueueRepository) CountQueueJobs() (map[string]int, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockQueueRepository) RetryQueueJob(id int64) (*domain.QueueJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.QueueJob), args.Error(1)
+}
+
+func (m *MockQueueRepository) PurgeQueueJobs(cutoff time.Time) (int, error) {
+	args := m.Called(cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) ListWebhooks() ([]domain.WebhookSubscription, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhook(id int64) (*domain.WebhookSubscription, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CreateWebhook(sub *domain.WebhookSubscription) error {
+	return m.Called(sub).Error(0)
+}
+
+func (m *MockWebhookRepository) UpdateWebhook(sub *domain.WebhookSubscription) error {
+	return m.Called(sub).Error(0)
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(id int64) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockWebhookRepository) DispatchWebhookEvents(limit int, job domain.QueueJob) (int, error) {
+	args := m.Called(limit, job)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) GetWebhookDelivery(id int64) (*domain.WebhookDelivery, *domain.WebhookSubscription, *domain.WebhookEvent, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, nil, nil, args.Error(3)
+	}
+	sub, _ := args.Get(1).(*domain.WebhookSubscription)
+	ev, _ := args.Get(2).(*domain.WebhookEvent)
+	return args.Get(0).(*domain.WebhookDelivery), sub, ev, args.Error(3)
+}
+
+func (m *MockWebhookRepository) RecordWebhookAttempt(delivery *domain.WebhookDelivery) error {
+	return m.Called(delivery).Error(0)
+}
+
+func (m *MockWebhookRepository) ListWebhookDeliveries(subscriptionID int64, limit int) ([]domain.WebhookDelivery, error) {
+	args := m.Called(subscriptionID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RedeliverWebhook(deliveryID int64, job domain.QueueJob) (*domain.WebhookDelivery, error) {
+	args := m.Called(deliveryID, job)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) PurgeWebhookEvents(cutoff time.Time) (int, error) {
+	args := m.Called(cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) CreateNotification(n *domain.Notification, job domain.QueueJob) error {
+	return m.Called(n, job).Error(0)
+}
+
+func (m *MockNotificationRepository) GetNotification(id int64) (*domain.Notification, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) RecordNotificationAttempt(n *domain.Notification) error {
+	return m.Called(n).Error(0)
+}
+
+func (m *MockNotificationRepository) ListNotifications(invitationUUID string) ([]domain.Notification, error) {
+	args := m.Called(invitationUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Notification), args.Error(1)
+}
+
+type MockConversationRepository struct {
+	mock.Mock
+}
+
+func (m *MockConversationRepository) GetConversation(phone string) (*domain.Conversation, error) {
+	args := m.Called(phone)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) SaveConversation(c *domain.Conversation) error {
+	return m.Called(c).Error(0)
+}
+
+func (m *MockConversationRepository) ListConversations(limit int) ([]domain.Conversation, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Conversation), args.Error(1)
+}
+
+func (m *MockConversationRepository) DeleteConversation(phone string) error {
+	return m.Called(phone).Error(0)
+}
+
+type MockReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderRepository) ListReminderPolicies(invitationUUID string) ([]domain.ReminderPolicy, error) {
+	args := m.Called(invitationUUID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ReminderPolicy), args.Error(1)
+}
+
+func (m *MockReminderRepository) CreateReminderPolicy(p *domain.ReminderPolicy) error {
+	return m.Called(p).Error(0)
+}
+
+func (m *MockReminderRepository) UpdateReminderPolicy(p *domain.ReminderPolicy) error {
+	return m.Called(p).Error(0)
+}
+
+func (m *MockReminderRepository) DeleteReminderPolicy(invitationUUID string, id int64) error {
+	return m.Called(invitationUUID, id).Error(0)
+}
+
+func (m *MockReminderRepository) ActiveReminderPolicies() ([]domain.ReminderPolicy, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ReminderPolicy), args.Error(1)
+}
+
+func (m *MockReminderRepository) RemindedPhones(policyID int64) (map[string]bool, error) {
+	args := m.Called(policyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockReminderRepository) CreateReminder(d *domain.ReminderDelivery, n *domain.Notification, job domain.QueueJob) (bool, error) {
+	args := m.Called(d, n, job)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReminderRepository) GetReminderByToken(token string) (*domain.ReminderDelivery, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReminderDelivery), args.Error(1)
+}
+
+func (m *MockReminderRepository) OptedOutPhones(phones []string) (map[string]bool, error) {
+	args := m.Called(phones)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockReminderRepository) ListReminderOptOuts() ([]domain.ReminderOptOut, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ReminderOptOut), args.Error(1)
+}
+
+func (m *MockReminderRepository) AddReminderOptOut(phone string) error {
+	return m.Called(phone).Error(0)
+}
+
+func (m *MockReminderRepository) RemoveReminderOptOut(phone string) error {
+	return m.Called(phone).Error(0)
+}
@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+)
+
+// Message templates for the client, sent in the invitation's language
+// unless the admin picks another.
+const (
+	// NotificationPaymentReceived thanks for the payment and asks to pick
+	// a design from the attached screenshots.
+	NotificationPaymentReceived = "payment_received"
+	// NotificationQuestionnaire asks for the "Данные для приглашения"
+	// questionnaire.
+	NotificationQuestionnaire = "questionnaire"
+	// NotificationInvitationReady sends the finished invitation's links.
+	NotificationInvitationReady = "invitation_ready"
+)
+
+// NotificationTemplate describes a message template for the admin.
+type NotificationTemplate struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Langs       []string `json:"langs"`
+}
+
+// NotificationData is what message templates are rendered from.
+type NotificationData struct {
+	GroomName string
+	BrideName string
+	// Couple is both names joined in the message language.
+	Couple string
+	// Link is the invitation page, ShortLink its short link or Link when
+	// the invitation has no short code.
+	Link      string
+	ShortLink string
+	// Date and Time are the event day and time in the message language,
+	// "" when unknown.
+	Date     string
+	Time     string
+	Location string
+}
+
+type messageTemplate struct {
+	subject, text string
+}
+
+var notificationTemplateList = []struct {
+	name, description string
+	langs             map[string]messageTemplate
+}{
+	{NotificationPaymentReceived, "After payment: thanks and asks to choose a design", map[string]messageTemplate{
+		i18n.LangRu: {"Оплата получена", `Здравствуйте! 🎉 Оплату получили, спасибо!
+
+Ниже я прикрепил(а) варианты дизайнов, которые мы можем использовать для вашего приглашения.
+
+🖼 Посмотрите фото ниже 👇
+Пожалуйста, выберите стиль, который вам больше всего нравится, и напишите его номер или название (например, "Звездная ночь").`},
+		i18n.LangKk: {"Төлем қабылданды", `Сәлеметсіз бе! 🎉 Төлем қабылданды, рахмет!
+
+Төменде шақыруыңызға қолдануға болатын дизайн нұсқаларын жібердім.
+
+🖼 Төмендегі суреттерді қараңыз 👇
+Өзіңізге ұнаған стильді таңдап, оның нөмірін немесе атауын жазыңыз (мысалы, "Жұлдызды түн").`},
+		i18n.LangEn: {"Payment received", `Hello! 🎉 We have received your payment, thank you!
+
+Below are the designs we can use for your invitation.
+
+🖼 Please see the pictures below 👇
+Choose the style you like best and send us its number or name (for example, "Starry Night").`},
+	}},
+	{NotificationQuestionnaire, "Asks for the invitation details questionnaire", map[string]messageTemplate{
+		i18n.LangRu: {"Данные для приглашения", `Отлично! С дизайном определились. ✨
+Теперь нужны данные для приглашения. Скопируйте список ниже, заполните его и пришлите ответным сообщением:
+
+📋 Данные для приглашения:
+
+1. Выбранный дизайн: (название или скрин)
+2. Язык приглашения: (Русский / Казахский / Английский)
+3. Имена: (Жених и Невеста, как должно быть написано)
+4. Дата свадьбы: (ДД.ММ.ГГГГ)
+5. Время сбора гостей: (ЧЧ:ММ)
+6. Название ресторана/места:
+7. Адрес (текстом):
+8. Ссылка на 2GIS/Google Maps (желательно):
+9. Дресс-код (если есть):
+10. Ваше фото (если шаблон предусматривает фото, прикрепите его отдельно)
+
+⏳ Как только пришлете данные, мы подготовим ссылку в течение нескольких часов.`},
+		i18n.LangKk: {"Шақыруға арналған мәліметтер", `Керемет! Дизайн таңдалды. ✨
+Енді шақыруға мәліметтер керек. Төмендегі тізімді көшіріп, толтырып, жауап ретінде жіберіңіз:
+
+📋 Шақыруға арналған мәліметтер:
+
+1. Таңдалған дизайн: (атауы немесе скриншот)
+2. Шақыру тілі: (Орысша / Қазақша / Ағылшынша)
+3. Есімдер: (Күйеу жігіт пен Қалыңдық, қалай жазылуы керек)
+4. Той күні: (КК.АА.ЖЖЖЖ)
+5. Қонақтардың жиналу уақыты: (СС:ММ)
+6. Мейрамхананың/орынның атауы:
+7. Мекенжайы (мәтінмен):
+8. 2GIS/Google Maps сілтемесі (қалаулы):
+9. Дресс-код (болса):
+10. Сіздің суретіңіз (егер үлгіде сурет болса, бөлек жіберіңіз)
+
+⏳ Мәліметтерді жібергеннен кейін бірнеше сағат ішінде сілтемені дайындаймыз.`},
+		i18n.LangEn: {"Invitation details", `Great, the design is settled! ✨
+Now we need the details for your invitation. Please copy the list below, fill it in and send it back:
+
+📋 Invitation details:
+
+1. Design: (name or screenshot)
+2. Invitation language: (Russian / Kazakh / English)
+3. Names: (Groom and Bride, as they should be written)
+4. Wedding date: (DD.MM.YYYY)
+5. Guest arrival time: (HH:MM)
+6. Restaurant/venue name:
+7. Address (as text):
+8. 2GIS/Google Maps link (preferably):
+9. Dress code (if any):
+10. Your photo (if the design has one, send it separately)
+
+⏳ Once you send the details, we will prepare the link within a few hours.`},
+	}},
+	{NotificationInvitationReady, "The finished invitation with its links", map[string]messageTemplate{
+		i18n.LangRu: {"Ваше приглашение готово", `Ваше приглашение готово! 🥳💍
+{{if .Couple}}
+{{.Couple}}{{if .Date}}, {{.Date}}{{if .Time}} в {{.Time}}{{end}}{{end}}{{if .Location}}, {{.Location}}{{end}}
+{{end}}
+Оно доступно по ссылке:
+🔗 {{.ShortLink}}
+
+Что можно сделать:
+✅ Перейдите по ссылке и проверьте все данные.
+✅ Нажмите "С радостью приду!", чтобы проверить подтверждение присутствия.
+✅ Отправьте ссылку гостям в WhatsApp или Telegram.
+
+Если нужно что-то поправить — пишите, мы на связи!`},
+		i18n.LangKk: {"Шақыруыңыз дайын", `Шақыруыңыз дайын! 🥳💍
+{{if .Couple}}
+{{.Couple}}{{if .Date}}, {{.Date}}{{if .Time}}, {{.Time}}{{end}}{{end}}{{if .Location}}, {{.Location}}{{end}}
+{{end}}
+Сілтеме:
+🔗 {{.ShortLink}}
+
+Не істеуге болады:
+✅ Сілтемені ашып, барлық мәліметтерді тексеріңіз.
+✅ Қатысуды растауды тексеру үшін "Қуана келемін!" батырмасын басып көріңіз.
+✅ Сілтемені қонақтарға WhatsApp немесе Telegram арқылы жіберіңіз.
+
+Бірдеңе түзету керек болса — жазыңыз, біз байланыстамыз!`},
+		i18n.LangEn: {"Your invitation is ready", `Your invitation is ready! 🥳💍
+{{if .Couple}}
+{{.Couple}}{{if .Date}}, {{.Date}}{{if .Time}} at {{.Time}}{{end}}{{end}}{{if .Location}}, {{.Location}}{{end}}
+{{end}}
+Here is the link:
+🔗 {{.ShortLink}}
+
+What to do next:
+✅ Open the link and check the details.
+✅ Press "Joyfully accept!" to try the RSVP.
+✅ Send the link to your guests on WhatsApp or Telegram.
+
+If anything needs changing, just write to us!`},
+	}},
+}
+
+type parsedTemplate struct {
+	subject, text *template.Template
+}
+
+// notificationTemplates holds the parsed templates by name and language.
+var notificationTemplates = func() map[string]map[string]parsedTemplate {
+	parsed := map[string]map[string]parsedTemplate{}
+	for _, t := range notificationTemplateList {
+		parsed[t.name] = map[string]parsedTemplate{}
+		for lang, m := range t.langs {
+			name := t.name + "." + lang
+			parsed[t.name][lang] = parsedTemplate{
+				subject: template.Must(template.New(name + ".subject").Option("missingkey=error").Parse(m.subject)),
+				text:    template.Must(template.New(name).Option("missingkey=error").Parse(m.text)),
+			}
+		}
+	}
+	return parsed
+}()
+
+// NotificationTemplates lists the message templates.
+func NotificationTemplates() []NotificationTemplate {
+	list := make([]NotificationTemplate, 0, len(notificationTemplateList))
+	for _, t := range notificationTemplateList {
+		var langs []string
+		for _, lang := range []string{i18n.LangRu, i18n.LangKk, i18n.LangEn} {
+			if _, ok := t.langs[lang]; ok {
+				langs = append(langs, lang)
+			}
+		}
+		list = append(list, NotificationTemplate{Name: t.name, Description: t.description, Langs: langs})
+	}
+	return list
+}
+
+// newNotificationData collects the template data of inv in lang; links
+// point to baseURL.
+func newNotificationData(inv *domain.Invitation, lang, baseURL string) NotificationData {
+	d := NotificationData{
+		GroomName: inv.GroomName,
+		BrideName: inv.BrideName,
+		Couple:    i18n.CoupleNames(inv.GroomName, inv.BrideName, lang),
+		Link:      baseURL + "/i/" + inv.UUID,
+		Location:  inv.EventLocation,
+	}
+	d.ShortLink = d.Link
+	if inv.ShortCode != "" {
+		d.ShortLink = baseURL + "/s/" + inv.ShortCode
+	}
+	if t, ok := inv.EventTime(); ok {
+		d.Date, d.Time = i18n.FormatDate(t, lang), i18n.FormatTime(t)
+	}
+	return d
+}
+
+// renderNotification renders the template name in lang for inv.
+func renderNotification(name, lang string, inv *domain.Invitation, baseURL string) (subject, text string, err error) {
+	byLang, ok := notificationTemplates[name]
+	if !ok {
+		names := make([]string, 0, len(notificationTemplateList))
+		for _, t := range notificationTemplateList {
+			names = append(names, t.name)
+		}
+		return "", "", InputError(fmt.Sprintf("unknown template %q, expected one of %s", name, strings.Join(names, ", ")))
+	}
+	tmpl, ok := byLang[lang]
+	if !ok {
+		return "", "", InputError(fmt.Sprintf("template %q has no %q version", name, lang))
+	}
+	data := newNotificationData(inv, lang, baseURL)
+	var buf bytes.Buffer
+	if err := tmpl.subject.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	subject = buf.String()
+	buf.Reset()
+	if err := tmpl.text.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	return subject, buf.String(), nil
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+)
+
+// NotificationSendKind is the queue job kind that makes one attempt of
+// sending a notification; the queue retries it with backoff.
+const NotificationSendKind = "notification.send"
+
+// NotificationMessage is a rendered message for one recipient.
+type NotificationMessage struct {
+	// To is a phone number in international format, "+77011234567", for
+	// WhatsApp and SMS, and an address for email.
+	To      string
+	Subject string
+	Text    string
+}
+
+// NotificationChannel delivers messages over WhatsApp, SMS or email. Send
+// returns the id the provider gave the message, if any; errors wrapped
+// with Permanent are not retried.
+type NotificationChannel interface {
+	// Name is one of the domain.Channel* names.
+	Name() string
+	Send(ctx context.Context, msg NotificationMessage) (providerID string, err error)
+}
+
+// NotificationInput is a message as the admin asks for it. To defaults to
+// the invitation's phone number, except for email, and Lang to the
+// invitation's language.
+type NotificationInput struct {
+	Template string `json:"template" binding:"required"`
+	Channel  string `json:"channel" binding:"required"`
+	To       string `json:"to"`
+	Lang     string `json:"lang"`
+}
+
+type notificationJob struct {
+	NotificationID int64 `json:"notificationId"`
+}
+
+// NotificationUseCase renders message templates for invitations and sends
+// them through the configured channels, keeping a send log per
+// invitation. Messages are sent by the queue, which retries them.
+type NotificationUseCase struct {
+	repo     domain.NotificationRepository
+	invRepo  domain.InvitationRepository
+	queue    *QueueUseCase
+	channels map[string]NotificationChannel
+	baseURL  string
+}
+
+// NewNotificationUseCase registers the send handler on queue. Links in the
+// messages point to baseURL.
+func NewNotificationUseCase(repo domain.NotificationRepository, invRepo domain.InvitationRepository, queue *QueueUseCase, baseURL string, channels ...NotificationChannel) *NotificationUseCase {
+	u := &NotificationUseCase{repo: repo, invRepo: invRepo, queue: queue, channels: map[string]NotificationChannel{}, baseURL: baseURL}
+	for _, ch := range channels {
+		u.channels[ch.Name()] = ch
+	}
+	HandleQueue(queue, NotificationSendKind, u.send)
+	return u
+}
+
+// Channels returns the names of the configured channels.
+func (u *NotificationUseCase) Channels() []string {
+	names := make([]string, 0, len(u.channels))
+	for name := range u.channels {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Preview renders a template for the invitation uuid without sending it;
+// lang "" is the invitation's language.
+func (u *NotificationUseCase) Preview(uuid, template, lang string) (*domain.Notification, error) {
+	inv, err := u.invRepo.GetByUUID(uuid)
+	if err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	return u.render(inv, template, lang)
+}
+
+func (u *NotificationUseCase) render(inv *domain.Invitation, template, lang string) (*domain.Notification, error) {
+	if lang == "" {
+		lang = i18n.Normalize(inv.Lang)
+	}
+	subject, text, err := renderNotification(template, lang, inv, u.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Notification{InvitationUUID: inv.UUID, Template: template, Lang: lang, Subject: subject, Body: text}, nil
+}
+
+// Send renders a template for the invitation uuid and queues it on a
+// channel. The message is logged as rendered now, whenever it goes out.
+func (u *NotificationUseCase) Send(uuid string, in NotificationInput) (*domain.Notification, error) {
+	if len(u.channels) == 0 {
+		return nil, InputError("no notification channel is configured")
+	}
+	if _, ok := u.channels[in.Channel]; !ok {
+		return nil, InputError(fmt.Sprintf("channel %q is not configured, expected one of %s", in.Channel, strings.Join(u.Channels(), ", ")))
+	}
+	inv, err := u.invRepo.GetByUUID(uuid)
+	if err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	n, err := u.render(inv, in.Template, in.Lang)
+	if err != nil {
+		return nil, err
+	}
+	n.Channel = in.Channel
+	if n.Recipient, err = notificationRecipient(in.Channel, in.To, inv.PhoneNumber); err != nil {
+		return nil, err
+	}
+	if err := u.repo.CreateNotification(n, u.SendJob()); err != nil {
+		return nil, err
+	}
+	u.Queued()
+	return n, nil
+}
+
+// SendJob is the queue job that sends a notification, for use cases that
+// store notifications themselves.
+func (u *NotificationUseCase) SendJob() domain.QueueJob {
+	return domain.QueueJob{Kind: NotificationSendKind, MaxAttempts: u.queue.opts.MaxAttempts}
+}
+
+// Queued wakes the queue for notifications just stored with SendJob.
+func (u *NotificationUseCase) Queued() {
+	u.queue.notify()
+}
+
+// notificationRecipient checks the recipient of a message on channel: an
+// address for email, a phone number, phone by default, otherwise.
+func notificationRecipient(channel, to, phone string) (string, error) {
+	if channel == domain.ChannelEmail {
+		addr, err := mail.ParseAddress(strings.TrimSpace(to))
+		if err != nil {
+			return "", InputError("to must be an email address")
+		}
+		return addr.Address, nil
+	}
+	if strings.TrimSpace(to) == "" {
+		to = phone
+	}
+	return normalizePhone(to)
+}
+
+// normalizePhone is domain.NormalizePhone for numbers the admin or a
+// client typed in, with ErrInvalidPhone as an InputError.
+func normalizePhone(phone string) (string, error) {
+	number, err := domain.NormalizePhone(phone)
+	if errors.Is(err, domain.ErrInvalidPhone) {
+		return "", InputError(fmt.Sprintf("%q is not a phone number", phone))
+	}
+	return number, err
+}
+
+// send makes one attempt of a queued notification and records it. Any
+// failure is retried by the queue, except for what the channel calls
+// permanent or a channel no longer configured.
+func (u *NotificationUseCase) send(ctx context.Context, job notificationJob) error {
+	n, err := u.repo.GetNotification(job.NotificationID)
+	if errors.Is(err, domain.ErrNotificationNotFound) {
+		return Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+	if n.SentAt != nil {
+		// A worker that died after sending; the recipient has it.
+		return nil
+	}
+
+	n.ProviderID, n.Error = "", ""
+	ch, ok := u.channels[n.Channel]
+	if !ok {
+		n.Error = fmt.Sprintf("channel %s is not configured", n.Channel)
+		if err := u.repo.RecordNotificationAttempt(n); err != nil {
+			return err
+		}
+		return Permanent(errors.New(n.Error))
+	}
+
+	n.ProviderID, err = ch.Send(ctx, NotificationMessage{To: n.Recipient, Subject: n.Subject, Text: n.Body})
+	if err != nil {
+		n.Error = err.Error()
+	} else {
+		n.Status = domain.NotificationSent
+	}
+	if err := u.repo.RecordNotificationAttempt(n); err != nil {
+		log.Printf("notifications: recording notification %d: %v", n.ID, err)
+	}
+	return err
+}
+
+// Notifications returns the send log of the invitation uuid, newest first.
+func (u *NotificationUseCase) Notifications(uuid string) ([]domain.Notification, error) {
+	if _, err := u.invRepo.GetByUUID(uuid); err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	return u.repo.ListNotifications(uuid)
+}
@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChannel struct {
+	name string
+	id   string
+	err  error
+	sent []NotificationMessage
+}
+
+func (c *fakeChannel) Name() string { return c.name }
+
+func (c *fakeChannel) Send(_ context.Context, msg NotificationMessage) (string, error) {
+	c.sent = append(c.sent, msg)
+	return c.id, c.err
+}
+
+var notifyInvitation = &domain.Invitation{UUID: "inv-1", PhoneNumber: "8 (701) 123-45-67", Lang: "kk",
+	GroomName: "Арман", BrideName: "Айгерім", EventDate: "2026-08-15T18:00", EventLocation: "Rixos", ShortCode: "arman-aigerim"}
+
+func newNotificationUseCase(repo *MockNotificationRepository, invRepo *MockInvitationRepository, channels ...NotificationChannel) *NotificationUseCase {
+	return NewNotificationUseCase(repo, invRepo, NewQueueUseCase(new(MockQueueRepository), QueueOptions{MaxAttempts: 6}), "https://card-go.test", channels...)
+}
+
+func TestNotification_TemplatesRenderInEveryLanguage(t *testing.T) {
+	for _, tmpl := range NotificationTemplates() {
+		assert.Equal(t, []string{"ru", "kk", "en"}, tmpl.Langs, tmpl.Name)
+		for _, lang := range tmpl.Langs {
+			subject, text, err := renderNotification(tmpl.Name, lang, notifyInvitation, "https://card-go.test")
+			require.NoError(t, err, tmpl.Name+"."+lang)
+			assert.NotEmpty(t, subject)
+			assert.NotContains(t, text, "<no value>")
+		}
+	}
+
+	_, text, err := renderNotification(NotificationInvitationReady, "ru", notifyInvitation, "https://card-go.test")
+	require.NoError(t, err)
+	assert.Contains(t, text, "Арман и Айгерім, 15 августа 2026 в 18:00, Rixos")
+	assert.Contains(t, text, "https://card-go.test/s/arman-aigerim")
+
+	noCode := *notifyInvitation
+	noCode.ShortCode, noCode.EventDate = "", ""
+	_, text, err = renderNotification(NotificationInvitationReady, "en", &noCode, "https://card-go.test")
+	require.NoError(t, err)
+	assert.Contains(t, text, "Арман & Айгерім, Rixos\n")
+	assert.Contains(t, text, "https://card-go.test/i/inv-1")
+
+	var input InputError
+	_, _, err = renderNotification("reminder", "ru", notifyInvitation, "")
+	assert.ErrorAs(t, err, &input)
+	_, _, err = renderNotification(NotificationQuestionnaire, "de", notifyInvitation, "")
+	assert.ErrorAs(t, err, &input)
+}
+
+func TestNotification_SendQueuesRenderedMessage(t *testing.T) {
+	repo, invRepo := new(MockNotificationRepository), new(MockInvitationRepository)
+	u := newNotificationUseCase(repo, invRepo, &fakeChannel{name: domain.ChannelWhatsApp}, &fakeChannel{name: domain.ChannelEmail})
+	invRepo.On("GetByUUID", "inv-1").Return(notifyInvitation, nil)
+	repo.On("CreateNotification", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Channel == domain.ChannelWhatsApp && n.Recipient == "+77011234567" && n.Lang == "kk" &&
+			n.Template == NotificationInvitationReady && strings.Contains(n.Body, "Шақыруыңыз дайын")
+	}), domain.QueueJob{Kind: NotificationSendKind, MaxAttempts: 6}).Return(nil)
+
+	n, err := u.Send("inv-1", NotificationInput{Template: NotificationInvitationReady, Channel: domain.ChannelWhatsApp})
+	require.NoError(t, err)
+	assert.Equal(t, "Шақыруыңыз дайын", n.Subject)
+	repo.AssertExpectations(t)
+
+	var input InputError
+	for _, in := range []NotificationInput{
+		{Template: NotificationInvitationReady, Channel: domain.ChannelSMS},
+		{Template: NotificationInvitationReady, Channel: domain.ChannelEmail},
+		{Template: NotificationInvitationReady, Channel: domain.ChannelWhatsApp, To: "call me"},
+		{Template: "reminder", Channel: domain.ChannelWhatsApp},
+	} {
+		_, err := u.Send("inv-1", in)
+		assert.ErrorAs(t, err, &input, in)
+	}
+}
+
+func TestNotification_SendWithoutChannels(t *testing.T) {
+	u := newNotificationUseCase(new(MockNotificationRepository), new(MockInvitationRepository))
+	var input InputError
+	_, err := u.Send("inv-1", NotificationInput{Template: NotificationQuestionnaire, Channel: domain.ChannelWhatsApp})
+	assert.ErrorAs(t, err, &input)
+}
+
+func TestNotification_SendJobRecordsOutcome(t *testing.T) {
+	queued := func() *domain.Notification {
+		return &domain.Notification{ID: 5, Channel: domain.ChannelSMS, Recipient: "+77011234567", Body: "hi", Status: domain.NotificationQueued}
+	}
+
+	repo := new(MockNotificationRepository)
+	sms := &fakeChannel{name: domain.ChannelSMS, id: "msg-1"}
+	u := newNotificationUseCase(repo, nil, sms)
+	repo.On("GetNotification", int64(5)).Return(queued(), nil).Once()
+	repo.On("RecordNotificationAttempt", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Status == domain.NotificationSent && n.ProviderID == "msg-1" && n.Error == ""
+	})).Return(nil).Once()
+	require.NoError(t, u.send(context.Background(), notificationJob{NotificationID: 5}))
+	assert.Equal(t, []NotificationMessage{{To: "+77011234567", Text: "hi"}}, sms.sent)
+
+	sms.id, sms.err = "", errors.New("gateway timeout")
+	repo.On("GetNotification", int64(5)).Return(queued(), nil).Once()
+	repo.On("RecordNotificationAttempt", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Status == domain.NotificationQueued && n.Error == "gateway timeout"
+	})).Return(nil).Once()
+	err := u.send(context.Background(), notificationJob{NotificationID: 5})
+	require.Error(t, err)
+	assert.False(t, IsPermanent(err))
+	repo.AssertExpectations(t)
+}
+
+func TestNotification_SendJobGivesUp(t *testing.T) {
+	repo := new(MockNotificationRepository)
+	u := newNotificationUseCase(repo, nil, &fakeChannel{name: domain.ChannelSMS})
+	sentAt := time.Now()
+	repo.On("GetNotification", int64(1)).Return(nil, domain.ErrNotificationNotFound)
+	repo.On("GetNotification", int64(2)).Return(&domain.Notification{ID: 2, Channel: domain.ChannelEmail}, nil)
+	repo.On("GetNotification", int64(3)).Return(&domain.Notification{ID: 3, Channel: domain.ChannelSMS, SentAt: &sentAt}, nil)
+	repo.On("RecordNotificationAttempt", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.ID == 2 && n.Error == "channel email is not configured"
+	})).Return(nil)
+
+	assert.True(t, IsPermanent(u.send(context.Background(), notificationJob{NotificationID: 1})))
+	assert.True(t, IsPermanent(u.send(context.Background(), notificationJob{NotificationID: 2})))
+	assert.NoError(t, u.send(context.Background(), notificationJob{NotificationID: 3}))
+	repo.AssertExpectations(t)
+}
+
+func TestNotificationRecipient(t *testing.T) {
+	for in, want := range map[string]string{
+		"+7 701 123 45 67":  "+77011234567",
+		"8 (701) 123-45-67": "+77011234567",
+		"7011234567":        "+77011234567",
+		"+44 20 7946 0958":  "+442079460958",
+	} {
+		got, err := notificationRecipient(domain.ChannelSMS, in, "")
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	var input InputError
+	for _, in := range []string{"", "701-12", "+0 123 456 789", "447946095812", "tel:77011234567"} {
+		_, err := notificationRecipient(domain.ChannelWhatsApp, in, "")
+		assert.ErrorAs(t, err, &input, in)
+	}
+	got, err := notificationRecipient(domain.ChannelWhatsApp, "", "8 701 123 45 67")
+	require.NoError(t, err)
+	assert.Equal(t, "+77011234567", got)
+}
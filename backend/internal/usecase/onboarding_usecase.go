@@ -0,0 +1,538 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+)
+
+// WhatsAppReplyKind is the queue job kind that sends one reply of the
+// onboarding bot.
+const WhatsAppReplyKind = "whatsapp.reply"
+
+const (
+	// DefaultConversationsLimit and maxConversationsLimit bound the
+	// conversations listed for the admin.
+	DefaultConversationsLimit = 50
+	maxConversationsLimit     = 500
+
+	// maxConversationAttempts is how often a message is handled again
+	// when another one changed the conversation in the meantime.
+	maxConversationAttempts = 3
+	maxAnswerLength         = 255
+	maxNameLength           = 100
+	// maxEventYears is how far ahead a wedding may be.
+	maxEventYears = 3
+)
+
+// Answers of the names field, which holds two.
+const (
+	answerGroomName = "groomName"
+	answerBrideName = "brideName"
+)
+
+// InboundMessage is a message a client sent to the business WhatsApp
+// number.
+type InboundMessage struct {
+	// ID is WhatsApp's message id, "wamid.…".
+	ID string
+	// From is the sender's number, digits only.
+	From string
+	// Type is "text", "image" or another WhatsApp message type.
+	Type string
+	// Text is the text, the caption of an image or the title of a button
+	// pressed.
+	Text string
+	// MediaID is the id of an image, for downloading it from WhatsApp.
+	MediaID string
+}
+
+// WhatsAppInbound reads the webhook calls WhatsApp makes for messages to
+// the business number.
+type WhatsAppInbound interface {
+	// ParseWebhook verifies a webhook call and returns the messages it
+	// carries. It fails with ErrWebhookSignature when the call is not
+	// authentic and with an InputError when it can't be read.
+	ParseWebhook(header http.Header, body []byte) ([]InboundMessage, error)
+}
+
+type whatsAppReply struct {
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+// The commands are compared with the answer's words joined by spaces.
+var (
+	yesCommands = map[string]bool{
+		"да": true, "верно": true, "все верно": true, "подтверждаю": true, "ок": true,
+		"иә": true, "ия": true, "иа": true, "дұрыс": true, "бәрі дұрыс": true,
+		"yes": true, "y": true, "ok": true, "okay": true, "correct": true,
+	}
+	backCommands    = map[string]bool{"назад": true, "артқа": true, "back": true}
+	restartCommands = map[string]bool{
+		"заново": true, "сначала": true, "начать заново": true, "басынан": true,
+		"restart": true, "start": true, "start over": true,
+	}
+)
+
+// OnboardingUseCase is the WhatsApp bot that replaces the n8n workflow:
+// it asks a client who writes to the business number for the fields of
+// the questionnaire one at a time, checks every answer, shows them all
+// for confirmation, letting the client correct any, and then creates the
+// invitation and replies with its link. The state of each conversation is
+// kept per phone number, so it survives restarts and is shared between
+// replicas.
+type OnboardingUseCase struct {
+	repo        domain.ConversationRepository
+	invitations *InvitationUseCase
+	adminRepo   domain.AdminRepository
+	queue       *QueueUseCase
+	whatsapp    NotificationChannel
+	inbound     WhatsAppInbound
+	verifyToken string
+	baseURL     string
+	now         func() time.Time
+}
+
+// NewOnboardingUseCase registers the reply handler on queue. The bot is
+// off unless both whatsapp, which sends the replies, and inbound are set.
+// verifyToken is the token WhatsApp's webhook subscription is set up
+// with; links point to baseURL.
+func NewOnboardingUseCase(repo domain.ConversationRepository, invitations *InvitationUseCase, adminRepo domain.AdminRepository,
+	queue *QueueUseCase, whatsapp NotificationChannel, inbound WhatsAppInbound, verifyToken, baseURL string) *OnboardingUseCase {
+	u := &OnboardingUseCase{repo: repo, invitations: invitations, adminRepo: adminRepo, queue: queue, whatsapp: whatsapp,
+		inbound: inbound, verifyToken: verifyToken, baseURL: baseURL, now: time.Now}
+	HandleQueue(queue, WhatsAppReplyKind, u.sendReply)
+	return u
+}
+
+// Enabled reports whether the bot is configured.
+func (u *OnboardingUseCase) Enabled() bool {
+	return u.whatsapp != nil && u.inbound != nil
+}
+
+// VerifySubscription answers WhatsApp's check of the webhook URL: mode
+// "subscribe" with our verify token.
+func (u *OnboardingUseCase) VerifySubscription(mode, token string) bool {
+	return u.verifyToken != "" && mode == "subscribe" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(u.verifyToken)) == 1
+}
+
+// HandleWebhook handles the messages of a webhook call in order. On an
+// error WhatsApp delivers the call again; the messages already handled
+// are then skipped.
+func (u *OnboardingUseCase) HandleWebhook(header http.Header, body []byte) error {
+	msgs, err := u.inbound.ParseWebhook(header, body)
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		if err := u.receive(msg); err != nil {
+			return fmt.Errorf("message %s: %w", msg.ID, err)
+		}
+	}
+	return nil
+}
+
+func (u *OnboardingUseCase) receive(msg InboundMessage) error {
+	// WhatsApp sends the sender's number in international format without
+	// the "+", foreign numbers included.
+	phone, err := domain.NormalizePhone("+" + strings.TrimPrefix(msg.From, "+"))
+	if err != nil {
+		log.Printf("onboarding: ignoring message %s from %q", msg.ID, msg.From)
+		return nil
+	}
+	for attempt := 1; ; attempt++ {
+		c, err := u.repo.GetConversation(phone)
+		if errors.Is(err, domain.ErrConversationNotFound) {
+			c = &domain.Conversation{Phone: phone, Lang: detectLang(msg.Text)}
+		} else if err != nil {
+			return err
+		}
+		if msg.ID != "" && msg.ID == c.LastMessageID {
+			return nil
+		}
+
+		reply, create, err := u.advance(c, msg)
+		if err != nil {
+			return err
+		}
+		// The invitation is created before the conversation is saved as
+		// done, so a done conversation always has one. Should the save
+		// fail, the redelivered confirmation finds the invitation.
+		if create {
+			reply = u.createInvitation(c)
+		}
+		c.LastMessageID = msg.ID
+		err = u.repo.SaveConversation(c)
+		if errors.Is(err, domain.ErrConversationConflict) && attempt < maxConversationAttempts {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = u.queue.Enqueue(WhatsAppReplyKind, whatsAppReply{To: phone, Text: reply})
+		return err
+	}
+}
+
+// advance applies a message to the conversation and returns the reply.
+// create is set when the client confirmed the answers; the invitation is
+// then created before the conversation is saved as done.
+func (u *OnboardingUseCase) advance(c *domain.Conversation, msg InboundMessage) (reply string, create bool, err error) {
+	command := strings.Join(normalizeWords(msg.Text), " ")
+	switch {
+	case c.Step == "":
+		restartConversation(c)
+		reply, err = u.prompt(c)
+		return u.t(c, "bot_greeting") + "\n\n" + reply, false, err
+	case restartCommands[command]:
+		restartConversation(c)
+		reply, err = u.prompt(c)
+		return u.t(c, "bot_restart") + "\n\n" + reply, false, err
+	case c.Step == domain.ConversationDone:
+		return u.t(c, "bot_done", u.link(c.InvitationUUID)), false, nil
+	case backCommands[command]:
+		stepBack(c)
+		reply, err = u.prompt(c)
+		return reply, false, err
+	case c.Step == domain.ConversationConfirm:
+		if yesCommands[command] {
+			c.Step, c.InvitationUUID = domain.ConversationDone, confirmedInvitationUUID(c.Phone, msg.ID)
+			return "", true, nil
+		}
+		if n, err := strconv.Atoi(command); err == nil && n >= 1 && n <= len(QuestionnaireFields) {
+			c.Step, c.Correcting = QuestionnaireFields[n-1], true
+			reply, err = u.prompt(c)
+			return reply, false, err
+		}
+		return u.t(c, "bot_confirm_hint"), false, nil
+	}
+
+	problem, err := u.answer(c, msg)
+	if err != nil || problem != "" {
+		return problem, false, err
+	}
+	stepForward(c)
+	reply, err = u.prompt(c)
+	return reply, false, err
+}
+
+func restartConversation(c *domain.Conversation) {
+	c.Step, c.Answers, c.Correcting, c.InvitationUUID = QuestionnaireFields[0], map[string]string{}, false, ""
+}
+
+// stepForward moves to the next field, or back to the confirmation after
+// a correction or the last field.
+func stepForward(c *domain.Conversation) {
+	i := fieldIndex(c.Step)
+	if c.Correcting || i+1 == len(QuestionnaireFields) {
+		c.Step, c.Correcting = domain.ConversationConfirm, false
+		return
+	}
+	c.Step = QuestionnaireFields[i+1]
+}
+
+// stepBack moves to the previous field; from a correction back to the
+// confirmation, and from the confirmation to the last field.
+func stepBack(c *domain.Conversation) {
+	switch i := fieldIndex(c.Step); {
+	case c.Correcting:
+		c.Step, c.Correcting = domain.ConversationConfirm, false
+	case c.Step == domain.ConversationConfirm:
+		c.Step = QuestionnaireFields[len(QuestionnaireFields)-1]
+	case i > 0:
+		c.Step = QuestionnaireFields[i-1]
+	}
+}
+
+func fieldIndex(field string) int {
+	for i, f := range QuestionnaireFields {
+		if f == field {
+			return i
+		}
+	}
+	return -1
+}
+
+// answer checks the answer to the field being asked for and stores it. It
+// returns what is wrong with the answer, for the client, or "".
+func (u *OnboardingUseCase) answer(c *domain.Conversation, msg InboundMessage) (string, error) {
+	if c.Answers == nil {
+		c.Answers = map[string]string{}
+	}
+	text := strings.TrimSpace(msg.Text)
+	if c.Step == FieldPhoto {
+		switch {
+		case msg.MediaID != "":
+			c.Answers[FieldPhoto] = msg.MediaID
+		case text != "" && IsSkipAnswer(text):
+			c.Answers[FieldPhoto] = ""
+		default:
+			return u.t(c, "bot_err_photo"), nil
+		}
+		return "", nil
+	}
+	if text == "" {
+		return u.t(c, "bot_err_text"), nil
+	}
+	if utf8.RuneCountInString(text) > maxAnswerLength {
+		return u.t(c, "bot_err_long", maxAnswerLength), nil
+	}
+
+	value := text
+	switch c.Step {
+	case FieldTemplate:
+		templates, err := u.templates()
+		if err != nil {
+			return "", err
+		}
+		code, ok := MatchTemplate(templates, text)
+		if !ok {
+			return u.t(c, "bot_err_template"), nil
+		}
+		value = code
+	case FieldLang:
+		lang, ok := ParseLang(text)
+		if !ok {
+			return u.t(c, "bot_err_lang"), nil
+		}
+		value = lang
+	case FieldNames:
+		groom, bride, ok := SplitCoupleNames(text)
+		if !ok {
+			return u.t(c, "bot_err_names"), nil
+		}
+		if utf8.RuneCountInString(groom) > maxNameLength || utf8.RuneCountInString(bride) > maxNameLength {
+			return u.t(c, "bot_err_long", maxNameLength), nil
+		}
+		c.Answers[answerGroomName], c.Answers[answerBrideName] = groom, bride
+		return "", nil
+	case FieldDate:
+		day, ok := ParseEventDay(text, u.now())
+		if !ok {
+			return u.t(c, "bot_err_date"), nil
+		}
+		now := u.now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		if day.Before(today) || day.After(today.AddDate(maxEventYears, 0, 0)) {
+			return u.t(c, "bot_err_date_range"), nil
+		}
+		value = day.Format(time.DateOnly)
+	case FieldTime:
+		clock, ok := ParseEventClock(text)
+		if !ok {
+			return u.t(c, "bot_err_time"), nil
+		}
+		value = clock
+	case FieldMapURL:
+		if IsSkipAnswer(text) {
+			value = ""
+			break
+		}
+		link, ok := FindLink(text)
+		if !ok {
+			return u.t(c, "bot_err_link"), nil
+		}
+		value = link
+	case FieldDressCode:
+		if IsSkipAnswer(text) {
+			value = ""
+		}
+	}
+	c.Answers[c.Step] = value
+	return "", nil
+}
+
+// prompt is the question for the current step, or the answers to confirm.
+func (u *OnboardingUseCase) prompt(c *domain.Conversation) (string, error) {
+	switch c.Step {
+	case domain.ConversationConfirm:
+		return u.summary(c)
+	case FieldTemplate:
+		templates, err := u.templates()
+		if err != nil {
+			return "", err
+		}
+		lines := make([]string, len(templates))
+		for i, t := range templates {
+			lines[i] = fmt.Sprintf("%d. %s", i+1, TemplateName(t, c.Lang))
+		}
+		return u.t(c, "bot_q_template", strings.Join(lines, "\n")), nil
+	}
+	return u.t(c, "bot_q_"+botFieldKey(c.Step)), nil
+}
+
+func (u *OnboardingUseCase) summary(c *domain.Conversation) (string, error) {
+	templates, err := u.templates()
+	if err != nil {
+		return "", err
+	}
+	a := c.Answers
+	lines := make([]string, len(QuestionnaireFields))
+	for i, field := range QuestionnaireFields {
+		value := a[field]
+		switch field {
+		case FieldTemplate:
+			for _, t := range templates {
+				if t.Code == value {
+					value = TemplateName(t, c.Lang)
+				}
+			}
+		case FieldLang:
+			value = u.t(c, "bot_lang_"+value)
+		case FieldNames:
+			value = i18n.CoupleNames(a[answerGroomName], a[answerBrideName], c.Lang)
+		case FieldDate:
+			if day, err := time.Parse(time.DateOnly, value); err == nil {
+				value = i18n.FormatDate(day, c.Lang)
+			}
+		case FieldPhoto:
+			if value != "" {
+				value = u.t(c, "bot_photo_attached")
+			}
+		}
+		if value == "" {
+			value = u.t(c, "bot_none")
+		}
+		lines[i] = fmt.Sprintf("%d. %s: %s", i+1, u.t(c, "bot_label_"+botFieldKey(field)), value)
+	}
+	return u.t(c, "bot_summary", strings.Join(lines, "\n")), nil
+}
+
+// onboardingNamespace names the invitations the bot creates.
+var onboardingNamespace = uuid.MustParse("bf908290-31f2-4dcb-9f3e-e14ca0a1efb2")
+
+// confirmedInvitationUUID is the uuid of the invitation a confirmation
+// creates. It follows from the phone and the message, so every delivery
+// of the confirmation creates the same invitation.
+func confirmedInvitationUUID(phone, msgID string) string {
+	if msgID == "" {
+		return uuid.New().String()
+	}
+	return uuid.NewSHA1(onboardingNamespace, []byte(phone+"/"+msgID)).String()
+}
+
+// createInvitation creates the invitation of a confirmed conversation and
+// returns the reply. An invitation an earlier delivery of the
+// confirmation created is taken as is. If creating fails the conversation
+// goes back to the confirmation, so the client can confirm again.
+func (u *OnboardingUseCase) createInvitation(c *domain.Conversation) string {
+	a := c.Answers
+	content := map[string]interface{}{}
+	for key, field := range map[string]string{
+		domain.ContentAddress: FieldAddress, domain.ContentMapURL: FieldMapURL,
+		domain.ContentDressCode: FieldDressCode, domain.ContentPhotoMediaID: FieldPhoto,
+	} {
+		if a[field] != "" {
+			content[key] = a[field]
+		}
+	}
+	inv := &domain.Invitation{
+		UUID:          c.InvitationUUID,
+		PhoneNumber:   c.Phone,
+		TemplateCode:  a[FieldTemplate],
+		Lang:          a[FieldLang],
+		GroomName:     a[answerGroomName],
+		BrideName:     a[answerBrideName],
+		EventDate:     a[FieldDate] + "T" + a[FieldTime],
+		EventLocation: a[FieldVenue],
+		Content:       content,
+	}
+	if err := u.invitations.CreateInvitation(inv); err != nil {
+		existing, findErr := u.invitations.FindInvitation(c.InvitationUUID)
+		if findErr != nil || existing.PhoneNumber != c.Phone {
+			log.Printf("onboarding: creating the invitation of %s: %v", c.Phone, err)
+			c.Step, c.InvitationUUID = domain.ConversationConfirm, ""
+			return u.t(c, "bot_failed")
+		}
+		inv = existing
+	}
+	return u.t(c, "bot_ready", newNotificationData(inv, inv.Lang, u.baseURL).ShortLink)
+}
+
+// link is the short link of a created invitation.
+func (u *OnboardingUseCase) link(uuid string) string {
+	inv, err := u.invitations.FindInvitation(uuid)
+	if err != nil {
+		return u.baseURL + "/i/" + uuid
+	}
+	return newNotificationData(inv, inv.Lang, u.baseURL).ShortLink
+}
+
+// templates are the active designs, numbered as the bot lists them.
+func (u *OnboardingUseCase) templates() ([]domain.Template, error) {
+	return activeTemplates(u.adminRepo)
+}
+
+func (u *OnboardingUseCase) t(c *domain.Conversation, key string, args ...interface{}) string {
+	return i18n.T(c.Lang, key, args...)
+}
+
+// botFieldKey is the part of a field's message keys after "bot_q_" and
+// "bot_label_".
+func botFieldKey(field string) string {
+	switch field {
+	case FieldMapURL:
+		return "map_url"
+	case FieldDressCode:
+		return "dress_code"
+	}
+	return field
+}
+
+// detectLang guesses the language a client writes in from their first
+// message: Kazakh letters, other Cyrillic or Latin.
+func detectLang(text string) string {
+	switch {
+	case strings.ContainsAny(text, "әғқңөұүһіӘҒҚҢӨҰҮҺІ"):
+		return i18n.LangKk
+	case strings.IndexFunc(text, func(r rune) bool { return unicode.Is(unicode.Cyrillic, r) }) >= 0:
+		return i18n.LangRu
+	case strings.IndexFunc(text, func(r rune) bool { return unicode.Is(unicode.Latin, r) }) >= 0:
+		return i18n.LangEn
+	}
+	return i18n.DefaultLang
+}
+
+func (u *OnboardingUseCase) sendReply(ctx context.Context, r whatsAppReply) error {
+	if u.whatsapp == nil {
+		return Permanent(errors.New("whatsapp is not configured"))
+	}
+	_, err := u.whatsapp.Send(ctx, NotificationMessage{To: r.To, Text: r.Text})
+	return err
+}
+
+// Conversations lists the bot's conversations for the admin, most
+// recently active first; limit 0 means DefaultConversationsLimit.
+func (u *OnboardingUseCase) Conversations(limit int) ([]domain.Conversation, error) {
+	if limit == 0 {
+		limit = DefaultConversationsLimit
+	}
+	if limit < 0 || limit > maxConversationsLimit {
+		return nil, InputError(fmt.Sprintf("limit must be between 1 and %d", maxConversationsLimit))
+	}
+	return u.repo.ListConversations(limit)
+}
+
+// ResetConversation forgets the conversation with phone, so the bot
+// greets the client anew, e.g. after the admin took it over.
+func (u *OnboardingUseCase) ResetConversation(phone string) error {
+	number, err := normalizePhone(phone)
+	if err != nil {
+		return err
+	}
+	return u.repo.DeleteConversation(number)
+}
@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memConversations keeps one conversation the way the database does,
+// version guard included; conflicts makes that many saves fail first.
+type memConversations struct {
+	domain.ConversationRepository
+	stored    *domain.Conversation
+	conflicts int
+}
+
+func (r *memConversations) GetConversation(string) (*domain.Conversation, error) {
+	if r.stored == nil {
+		return nil, domain.ErrConversationNotFound
+	}
+	c := *r.stored
+	c.Answers = maps.Clone(r.stored.Answers)
+	return &c, nil
+}
+
+func (r *memConversations) SaveConversation(c *domain.Conversation) error {
+	stored := 0
+	if r.stored != nil {
+		stored = r.stored.Version
+	}
+	if r.conflicts > 0 || c.Version != stored {
+		r.conflicts--
+		return domain.ErrConversationConflict
+	}
+	c.Version++
+	saved := *c
+	saved.Answers = maps.Clone(c.Answers)
+	r.stored = &saved
+	return nil
+}
+
+// scriptedInbound hands the use case the messages it is given as the
+// body of a webhook call.
+type scriptedInbound struct{}
+
+func (scriptedInbound) ParseWebhook(_ http.Header, body []byte) ([]InboundMessage, error) {
+	var msgs []InboundMessage
+	return msgs, json.Unmarshal(body, &msgs)
+}
+
+type onboardingTest struct {
+	t       *testing.T
+	u       *OnboardingUseCase
+	repo    *memConversations
+	invRepo *MockInvitationRepository
+	replies []string
+	n       int
+}
+
+func newOnboardingTest(t *testing.T) *onboardingTest {
+	ot := &onboardingTest{t: t, repo: &memConversations{}, invRepo: new(MockInvitationRepository)}
+	adminRepo, queueRepo := new(MockAdminRepository), new(MockQueueRepository)
+	adminRepo.On("GetTemplates").Return([]domain.Template{
+		// As GetTemplates returns them: active only, IsActive not read.
+		{Code: "starry-night", NameRu: "Звездная ночь", NameKk: "Жұлдызды түн", NameEn: "Starry Night"},
+		{Code: "silk-ivory", NameRu: "Шелк и слоновая кость", NameKk: "Жібек пен Піл сүйегі", NameEn: "Silk & Ivory"},
+	}, nil)
+	queueRepo.On("Enqueue", mock.Anything).Run(func(args mock.Arguments) {
+		job := args.Get(0).(*domain.QueueJob)
+		var reply whatsAppReply
+		require.NoError(t, json.Unmarshal(job.Payload, &reply))
+		assert.Equal(t, "+77011234567", reply.To)
+		ot.replies = append(ot.replies, reply.Text)
+	}).Return(nil)
+	invitations := NewInvitationUseCase(ot.invRepo, ShortCodeGenerator{}, TrialPolicy{})
+	ot.u = NewOnboardingUseCase(ot.repo, invitations, adminRepo, NewQueueUseCase(queueRepo, QueueOptions{}),
+		&fakeChannel{name: domain.ChannelWhatsApp}, scriptedInbound{}, "verify-me", "https://card-go.test")
+	ot.u.now = func() time.Time { return time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC) }
+	return ot
+}
+
+// send delivers a message from the client and returns the bot's reply.
+func (ot *onboardingTest) send(msg InboundMessage) string {
+	ot.t.Helper()
+	ot.n++
+	if msg.ID == "" {
+		msg.ID = fmt.Sprintf("wamid.%d", ot.n)
+	}
+	msg.From = "77011234567"
+	body, _ := json.Marshal([]InboundMessage{msg})
+	before := len(ot.replies)
+	require.NoError(ot.t, ot.u.HandleWebhook(nil, body))
+	require.Len(ot.t, ot.replies, before+1)
+	return ot.replies[before]
+}
+
+func (ot *onboardingTest) say(text string) string {
+	ot.t.Helper()
+	return ot.send(InboundMessage{Type: "text", Text: text})
+}
+
+func TestOnboarding_CollectsQuestionnaireAndCreatesInvitation(t *testing.T) {
+	ot := newOnboardingTest(t)
+
+	reply := ot.say("Здравствуйте!")
+	assert.Contains(t, reply, "Здравствуйте! 👋")
+	assert.Contains(t, reply, "1/10. Выберите дизайн")
+	assert.Contains(t, reply, "1. Шелк и слоновая кость\n2. Звездная ночь")
+	assert.NotContains(t, reply, "Старый")
+
+	assert.Contains(t, ot.say("Розовый"), "Такого дизайна нет")
+	assert.Contains(t, ot.say("2"), "2/10.")
+	assert.Contains(t, ot.say("на казахском"), "3/10.")
+	assert.Contains(t, ot.say("Арман"), "Напишите два имени")
+	assert.Contains(t, ot.say("Арман и Айгерим"), "4/10.")
+	assert.Contains(t, ot.say("01.01.2026"), "не раньше сегодняшней")
+	assert.Contains(t, ot.say("на выходных"), "Не получилось прочитать дату")
+	assert.Contains(t, ot.say("15 августа 2026"), "5/10.")
+	assert.Contains(t, ot.say("18.00"), "6/10.")
+	assert.Contains(t, ot.say("Rixos"), "7/10.")
+	assert.Contains(t, ot.say("пр. Достык, 1"), "8/10.")
+	assert.Contains(t, ot.say("2gis Rixos"), "не похоже на ссылку")
+	assert.Contains(t, ot.say("вот https://2gis.kz/almaty/firm/1"), "9/10.")
+	assert.Contains(t, ot.say("нет"), "10/10.")
+	assert.Contains(t, ot.say("сейчас найду"), "Пришлите фото")
+
+	summary := ot.send(InboundMessage{Type: "image", MediaID: "media-1"})
+	assert.Contains(t, summary, "1. Дизайн: Звездная ночь\n2. Язык: казахский\n3. Имена: Арман и Айгерим\n"+
+		"4. Дата: 15 августа 2026\n5. Время: 18:00\n6. Место: Rixos\n7. Адрес: пр. Достык, 1\n"+
+		"8. Карта: https://2gis.kz/almaty/firm/1\n9. Дресс-код: —\n10. Фото: прикреплено")
+
+	// A correction goes back to the summary.
+	assert.Contains(t, ot.say("4"), "4/10.")
+	assert.Contains(t, ot.say("16.08.2026"), "4. Дата: 16 августа 2026")
+	assert.Contains(t, ot.say("может быть"), "Ответьте «да»")
+	assert.Contains(t, ot.say("назад"), "10/10.")
+	assert.Contains(t, ot.say("-"), "10. Фото: —")
+
+	ot.invRepo.On("Create", mock.MatchedBy(func(inv *domain.Invitation) bool {
+		return inv.UUID == confirmedInvitationUUID("+77011234567", fmt.Sprintf("wamid.%d", ot.n)) && inv.PhoneNumber == "+77011234567" &&
+			inv.TemplateCode == "starry-night" && inv.Lang == "kk" && inv.GroomName == "Арман" && inv.BrideName == "Айгерим" &&
+			inv.EventDate == "2026-08-16T18:00" && inv.EventLocation == "Rixos" &&
+			inv.Content[domain.ContentAddress] == "пр. Достык, 1" && inv.Content[domain.ContentMapURL] == "https://2gis.kz/almaty/firm/1" &&
+			len(inv.Content) == 2
+	})).Return(nil).Once()
+	reply = ot.say("Да")
+	assert.Contains(t, reply, "Ваше приглашение готово!")
+	assert.Regexp(t, `https://card-go\.test/s/\w+`, reply)
+	assert.Equal(t, domain.ConversationDone, ot.repo.stored.Step)
+	ot.invRepo.AssertExpectations(t)
+
+	ot.invRepo.On("GetByUUID", ot.repo.stored.InvitationUUID).Return(&domain.Invitation{UUID: ot.repo.stored.InvitationUUID, ShortCode: "arman-aigerim"}, nil)
+	assert.Contains(t, ot.say("Спасибо!"), "Ваше приглашение: https://card-go.test/s/arman-aigerim")
+
+	reply = ot.say("заново")
+	assert.Contains(t, reply, "Начнем сначала.\n\n1/10.")
+	assert.Empty(t, ot.repo.stored.Answers)
+	assert.Empty(t, ot.repo.stored.InvitationUUID)
+}
+
+func TestOnboarding_SpeaksTheClientsLanguage(t *testing.T) {
+	ot := newOnboardingTest(t)
+	assert.Contains(t, ot.say("Сәлеметсіз бе"), "1/10. Дизайнды таңдаңыз")
+	assert.Contains(t, ot.say("Жұлдызды түн"), "2/10. Шақыру қай тілде")
+
+	ot = newOnboardingTest(t)
+	reply := ot.say("Hi there")
+	assert.Contains(t, reply, "1. Silk & Ivory\n2. Starry Night")
+	assert.Contains(t, ot.say("back"), "1/10. Choose a design")
+}
+
+func TestOnboarding_FailedCreationReopensConfirmation(t *testing.T) {
+	ot := newOnboardingTest(t)
+	ot.repo.stored = &domain.Conversation{Phone: "+77011234567", Lang: "en", Step: domain.ConversationConfirm, Version: 4,
+		Answers: map[string]string{FieldTemplate: "silk-ivory", FieldLang: "en", answerGroomName: "Arman", answerBrideName: "Aigerim",
+			FieldDate: "2026-08-15", FieldTime: "18:00", FieldVenue: "Rixos", FieldAddress: "Dostyk 1", FieldPhoto: "media-1"}}
+	ot.invRepo.On("Create", mock.Anything).Return(errors.New("connection reset")).Once()
+	ot.invRepo.On("GetByUUID", mock.Anything).Return(nil, errors.New("no rows in result set")).Once()
+
+	assert.Contains(t, ot.say("yes"), "We couldn't create the invitation")
+	assert.Equal(t, domain.ConversationConfirm, ot.repo.stored.Step)
+	assert.Empty(t, ot.repo.stored.InvitationUUID)
+
+	ot.invRepo.On("Create", mock.MatchedBy(func(inv *domain.Invitation) bool {
+		return inv.Content[domain.ContentPhotoMediaID] == "media-1"
+	})).Return(nil).Once()
+	assert.Contains(t, ot.say("ok"), "Your invitation is ready!")
+}
+
+func TestOnboarding_RedeliveredConfirmationFindsInvitation(t *testing.T) {
+	ot := newOnboardingTest(t)
+	ot.repo.stored = &domain.Conversation{Phone: "+77011234567", Lang: "en", Step: domain.ConversationConfirm, Version: 4,
+		Answers: map[string]string{FieldTemplate: "silk-ivory", FieldLang: "en", answerGroomName: "Arman", answerBrideName: "Aigerim",
+			FieldDate: "2026-08-15", FieldTime: "18:00", FieldVenue: "Rixos"}}
+	id := confirmedInvitationUUID("+77011234567", "wamid.yes")
+	ot.invRepo.On("Create", mock.MatchedBy(func(inv *domain.Invitation) bool { return inv.UUID == id })).Return(nil).Once()
+	ot.invRepo.On("Create", mock.Anything).Return(errors.New("duplicate key value violates unique constraint"))
+	ot.invRepo.On("GetByUUID", id).Return(&domain.Invitation{UUID: id, PhoneNumber: "+77011234567", ShortCode: "arman-aigerim"}, nil)
+
+	// The invitation is created, but the conversation can't be saved.
+	ot.repo.conflicts = maxConversationAttempts
+	body, _ := json.Marshal([]InboundMessage{{ID: "wamid.yes", From: "77011234567", Type: "text", Text: "yes"}})
+	require.Error(t, ot.u.HandleWebhook(nil, body))
+	assert.Equal(t, domain.ConversationConfirm, ot.repo.stored.Step)
+	assert.Empty(t, ot.replies)
+
+	// WhatsApp delivers the confirmation again: the invitation exists.
+	require.NoError(t, ot.u.HandleWebhook(nil, body))
+	assert.Equal(t, domain.ConversationDone, ot.repo.stored.Step)
+	assert.Equal(t, id, ot.repo.stored.InvitationUUID)
+	require.Len(t, ot.replies, 1)
+	assert.Contains(t, ot.replies[0], "https://card-go.test/s/arman-aigerim")
+}
+
+func TestOnboarding_RedeliveryAndConflicts(t *testing.T) {
+	ot := newOnboardingTest(t)
+	ot.send(InboundMessage{ID: "wamid.1", Type: "text", Text: "Привет"})
+
+	// WhatsApp delivering the same message again gets no second reply.
+	body, _ := json.Marshal([]InboundMessage{{ID: "wamid.1", From: "77011234567", Type: "text", Text: "Привет"}})
+	require.NoError(t, ot.u.HandleWebhook(nil, body))
+	assert.Len(t, ot.replies, 1)
+
+	// Another message saved in the meantime: this one is applied on top.
+	ot.repo.conflicts = 1
+	assert.Contains(t, ot.say("1"), "2/10.")
+	assert.Equal(t, "silk-ivory", ot.repo.stored.Answers[FieldTemplate])
+
+	ot.repo.conflicts = maxConversationAttempts
+	body, _ = json.Marshal([]InboundMessage{{ID: "wamid.x", From: "77011234567", Type: "text", Text: "русский"}})
+	assert.ErrorIs(t, ot.u.HandleWebhook(nil, body), domain.ErrConversationConflict)
+}
+
+func TestOnboarding_Admin(t *testing.T) {
+	repo := new(MockConversationRepository)
+	u := NewOnboardingUseCase(repo, nil, nil, NewQueueUseCase(new(MockQueueRepository), QueueOptions{}), nil, nil, "", "")
+	assert.False(t, u.Enabled())
+	assert.False(t, u.VerifySubscription("subscribe", ""))
+
+	repo.On("ListConversations", DefaultConversationsLimit).Return([]domain.Conversation{}, nil)
+	repo.On("DeleteConversation", "+77011234567").Return(nil)
+	_, err := u.Conversations(0)
+	require.NoError(t, err)
+	require.NoError(t, u.ResetConversation("8 701 123 45 67"))
+
+	var input InputError
+	_, err = u.Conversations(501)
+	assert.ErrorAs(t, err, &input)
+	assert.ErrorAs(t, u.ResetConversation("nobody"), &input)
+	repo.AssertExpectations(t)
+}
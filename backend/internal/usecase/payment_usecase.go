@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+var (
+	// ErrUnknownProvider is returned for a webhook of a provider that is
+	// not configured.
+	ErrUnknownProvider = errors.New("unknown payment provider")
+	// ErrWebhookSignature is returned by PaymentProvider.ParseWebhook when
+	// the call is not signed by the provider.
+	ErrWebhookSignature = errors.New("invalid webhook signature")
+	// ErrPaymentMismatch is returned when a provider reports a payment
+	// that differs from the order.
+	ErrPaymentMismatch = errors.New("payment does not match the order")
+)
+
+// PaymentProvider is a payment service customers pay orders through.
+type PaymentProvider interface {
+	// Name identifies the provider in stored orders and webhook URLs.
+	Name() string
+	// Checkout registers order with the provider and returns the
+	// provider's reference for it and the URL where the customer pays.
+	Checkout(order *domain.Order) (ref, paymentURL string, err error)
+	// ParseWebhook verifies a webhook call and returns the event it
+	// carries. It fails with ErrWebhookSignature when the call is not
+	// authentic and with an InputError when it can't be read.
+	ParseWebhook(header http.Header, body []byte) (*domain.PaymentEvent, error)
+}
+
+// amountlessProvider is implemented by providers whose webhooks don't
+// state the amount paid, which is only the fake provider for local
+// development. Paid events of every other provider must match the order.
+type amountlessProvider interface {
+	WebhooksOmitAmount() bool
+}
+
+func omitsAmount(p PaymentProvider) bool {
+	a, ok := p.(amountlessProvider)
+	return ok && a.WebhooksOmitAmount()
+}
+
+// DefaultCurrency is used for plans and fixed discounts created without
+// one.
+const DefaultCurrency = "KZT"
+
+// PromoProvider is the provider of orders a promo code pays in full. They
+// never reach a payment service and are paid when created.
+const PromoProvider = "promo"
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// PaymentUseCase takes payments for invitations: it prices orders from
+// plans and promo codes, opens them with a provider and marks invitations
+// paid when the provider reports success.
+type PaymentUseCase struct {
+	repo      domain.OrderRepository
+	pricing   domain.PricingRepository
+	invRepo   domain.InvitationRepository
+	providers map[string]PaymentProvider
+	now       func() time.Time
+}
+
+func NewPaymentUseCase(repo domain.OrderRepository, pricing domain.PricingRepository, invRepo domain.InvitationRepository, providers ...PaymentProvider) *PaymentUseCase {
+	u := &PaymentUseCase{repo: repo, pricing: pricing, invRepo: invRepo, providers: map[string]PaymentProvider{}, now: time.Now}
+	for _, p := range providers {
+		u.providers[p.Name()] = p
+	}
+	return u
+}
+
+// CreateOrder opens an order for an invitation with the named provider,
+// which may be left out when only one is configured. The price is the one
+// of planCode, or of the invitation's plan when empty, less promoCode if
+// given. Paying for a paid invitation renews its hosting. Nothing is
+// stored when the provider refuses the order.
+func (u *PaymentUseCase) CreateOrder(invUUID, provider, planCode, promoCode string) (*domain.Order, error) {
+	if provider == "" && len(u.providers) == 1 {
+		for name := range u.providers {
+			provider = name
+		}
+	}
+	p, ok := u.providers[provider]
+	if !ok {
+		return nil, InputError(fmt.Sprintf("unknown provider: %q", provider))
+	}
+	inv, err := u.invRepo.GetByUUID(invUUID)
+	if err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+
+	order, err := u.price(inv, planCode, promoCode)
+	if err != nil {
+		return nil, err
+	}
+	order.ID = uuid.New().String()
+	order.InvitationUUID = inv.UUID
+	order.Status = domain.OrderPending
+
+	if order.Amount == 0 {
+		return u.settleFree(order)
+	}
+	order.Provider = provider
+	if order.ProviderRef, order.PaymentURL, err = p.Checkout(order); err != nil {
+		return nil, fmt.Errorf("%s checkout: %w", provider, err)
+	}
+	if err := u.repo.CreateOrder(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// price fills in what an order of the plan costs with the promo code.
+func (u *PaymentUseCase) price(inv *domain.Invitation, planCode, promoCode string) (*domain.Order, error) {
+	if planCode == "" {
+		planCode = inv.PlanCode
+	}
+	if planCode == "" {
+		return nil, InputError("plan is required")
+	}
+	plan, err := u.pricing.GetPlan(planCode)
+	if errors.Is(err, domain.ErrUnknownPlan) {
+		return nil, InputError(fmt.Sprintf("unknown plan: %q", planCode))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !plan.IsActive {
+		return nil, InputError(fmt.Sprintf("plan %q is not on sale", planCode))
+	}
+	order := &domain.Order{
+		PlanCode:    plan.Code,
+		ListPrice:   plan.Price,
+		Amount:      plan.Price,
+		Currency:    plan.Currency,
+		HostingDays: plan.HostingDays,
+	}
+
+	if promoCode = NormalizePromoCode(promoCode); promoCode != "" {
+		promo, err := u.pricing.GetPromoCode(promoCode)
+		if errors.Is(err, domain.ErrPromoCodeNotFound) {
+			return nil, InputError(fmt.Sprintf("unknown promo code: %q", promoCode))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !promo.Available(u.now()) {
+			return nil, domain.ErrPromoCodeUnavailable
+		}
+		if promo.Kind == domain.PromoFixed && promo.Currency != plan.Currency {
+			return nil, InputError(fmt.Sprintf("promo code %q is in %s, the plan in %s", promoCode, promo.Currency, plan.Currency))
+		}
+		order.PromoCode = promo.Code
+		order.Discount = promo.Discount(plan.Price, plan.Currency)
+		order.Amount -= order.Discount
+	}
+	return order, nil
+}
+
+// settleFree stores an order with nothing to pay and pays it at once.
+func (u *PaymentUseCase) settleFree(order *domain.Order) (*domain.Order, error) {
+	order.Provider = PromoProvider
+	if err := u.repo.CreateOrder(order); err != nil {
+		return nil, err
+	}
+	paid, _, err := u.repo.ApplyPaymentEvent(&domain.PaymentEvent{
+		Provider: PromoProvider,
+		EventID:  order.ID,
+		OrderID:  order.ID,
+		Status:   domain.OrderPaid,
+		Currency: order.Currency,
+	})
+	return paid, err
+}
+
+// Orders lists the orders of an invitation, oldest first.
+func (u *PaymentUseCase) Orders(invUUID string) ([]domain.Order, error) {
+	if _, err := u.invRepo.GetByUUID(invUUID); err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	return u.repo.ListOrders(invUUID)
+}
+
+// HandleWebhook verifies and applies a webhook call of the named provider.
+// Redelivered events are accepted again without effect, so the provider
+// stops retrying. A payment must name the order's amount and currency.
+func (u *PaymentUseCase) HandleWebhook(provider string, header http.Header, body []byte) (*domain.Order, error) {
+	p, ok := u.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	ev, err := p.ParseWebhook(header, body)
+	if err != nil {
+		return nil, err
+	}
+	ev.Provider = provider
+	switch ev.Status {
+	case domain.OrderPaid, domain.OrderFailed, domain.OrderCancelled:
+	default:
+		return nil, InputError(fmt.Sprintf("unsupported status: %q", ev.Status))
+	}
+	if ev.EventID == "" || ev.OrderID == "" {
+		return nil, InputError("event and order ids are required")
+	}
+	if _, err := uuid.Parse(ev.OrderID); err != nil {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	order, err := u.repo.GetOrder(ev.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Provider != provider {
+		return nil, domain.ErrOrderNotFound
+	}
+	if ev.Status == domain.OrderPaid && !omitsAmount(p) &&
+		(ev.Amount != order.Amount || ev.Currency != order.Currency) {
+		return nil, ErrPaymentMismatch
+	}
+	order, _, err = u.repo.ApplyPaymentEvent(ev)
+	return order, err
+}
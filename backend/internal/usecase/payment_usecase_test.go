@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const orderID = "5f0c3a52-8d1e-4b8e-9c4a-2f6d7e8a9b10"
+
+// stubProvider checks out every order and returns event for every webhook.
+type stubProvider struct {
+	event      *domain.PaymentEvent
+	err        error
+	omitAmount bool
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) WebhooksOmitAmount() bool { return p.omitAmount }
+
+func (p *stubProvider) Checkout(order *domain.Order) (string, string, error) {
+	return "ref-" + order.ID, "https://pay.test/" + order.ID, p.err
+}
+
+func (p *stubProvider) ParseWebhook(http.Header, []byte) (*domain.PaymentEvent, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	ev := *p.event
+	return &ev, nil
+}
+
+var testPlans = map[string]*domain.Plan{
+	"basic":   {Code: "basic", Price: 990000, Currency: "KZT", HostingDays: 90, IsActive: true},
+	"premium": {Code: "premium", Price: 1990000, Currency: "KZT", HostingDays: 365, IsActive: true},
+	"legacy":  {Code: "legacy", Price: 500000, Currency: "KZT"},
+}
+
+func newPricingMock() *MockPricingRepository {
+	pricing := new(MockPricingRepository)
+	for code, p := range testPlans {
+		pricing.On("GetPlan", code).Return(p, nil)
+	}
+	pricing.On("GetPlan", mock.Anything).Return(nil, domain.ErrUnknownPlan)
+	return pricing
+}
+
+func TestCreateOrder(t *testing.T) {
+	repo := new(MockOrderRepository)
+	invRepo := new(MockInvitationRepository)
+	uc := NewPaymentUseCase(repo, newPricingMock(), invRepo, &stubProvider{})
+
+	invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1", PlanCode: "premium"}, nil)
+	repo.On("CreateOrder", mock.Anything).Return(nil)
+
+	order, err := uc.CreateOrder("uuid-1", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "stub", order.Provider)
+	assert.Equal(t, "premium", order.PlanCode)
+	assert.Equal(t, int64(1990000), order.Amount)
+	assert.Equal(t, "KZT", order.Currency)
+	assert.Equal(t, 365, order.HostingDays)
+	assert.Equal(t, domain.OrderPending, order.Status)
+	assert.Equal(t, "ref-"+order.ID, order.ProviderRef)
+	assert.Equal(t, "https://pay.test/"+order.ID, order.PaymentURL)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateOrder_PromoCode(t *testing.T) {
+	repo := new(MockOrderRepository)
+	invRepo := new(MockInvitationRepository)
+	pricing := newPricingMock()
+	uc := NewPaymentUseCase(repo, pricing, invRepo, &stubProvider{})
+
+	invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	pricing.On("GetPromoCode", "SPRING20").Return(&domain.PromoCode{Code: "SPRING20", Kind: domain.PromoPercent, Value: 20, IsActive: true}, nil)
+	pricing.On("GetPromoCode", "MINUS5K").Return(&domain.PromoCode{Code: "MINUS5K", Kind: domain.PromoFixed, Value: 500000, Currency: "KZT", IsActive: true}, nil)
+	repo.On("CreateOrder", mock.Anything).Return(nil)
+
+	order, err := uc.CreateOrder("uuid-1", "stub", "basic", " spring20 ")
+	require.NoError(t, err)
+	assert.Equal(t, "SPRING20", order.PromoCode)
+	assert.Equal(t, int64(990000), order.ListPrice)
+	assert.Equal(t, int64(198000), order.Discount)
+	assert.Equal(t, int64(792000), order.Amount)
+
+	order, err = uc.CreateOrder("uuid-1", "stub", "premium", "MINUS5K")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1490000), order.Amount)
+}
+
+func TestCreateOrder_FreeWithPromoCode(t *testing.T) {
+	repo := new(MockOrderRepository)
+	invRepo := new(MockInvitationRepository)
+	pricing := newPricingMock()
+	uc := NewPaymentUseCase(repo, pricing, invRepo, &stubProvider{err: errors.New("must not be called")})
+
+	invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	pricing.On("GetPromoCode", "FRIENDS").Return(&domain.PromoCode{Code: "FRIENDS", Kind: domain.PromoPercent, Value: 100, IsActive: true}, nil)
+	repo.On("CreateOrder", mock.MatchedBy(func(o *domain.Order) bool {
+		return o.Provider == PromoProvider && o.Amount == 0
+	})).Return(nil)
+	repo.On("ApplyPaymentEvent", mock.MatchedBy(func(ev *domain.PaymentEvent) bool {
+		return ev.Provider == PromoProvider && ev.Status == domain.OrderPaid
+	})).Return(&domain.Order{Status: domain.OrderPaid}, true, nil)
+
+	order, err := uc.CreateOrder("uuid-1", "stub", "basic", "FRIENDS")
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderPaid, order.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateOrder_Rejects(t *testing.T) {
+	repo := new(MockOrderRepository)
+	invRepo := new(MockInvitationRepository)
+	pricing := newPricingMock()
+	uc := NewPaymentUseCase(repo, pricing, invRepo, &stubProvider{})
+
+	past := time.Now().Add(-time.Hour)
+	used := 3
+	invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	invRepo.On("GetByUUID", "missing").Return(nil, errors.New("no rows in result set"))
+	pricing.On("GetPromoCode", "OLD").Return(&domain.PromoCode{Code: "OLD", Kind: domain.PromoPercent, Value: 10, IsActive: true, ExpiresAt: &past}, nil)
+	pricing.On("GetPromoCode", "GONE").Return(&domain.PromoCode{Code: "GONE", Kind: domain.PromoPercent, Value: 10, IsActive: true, MaxUses: &used, UsedCount: 3}, nil)
+	pricing.On("GetPromoCode", "USD").Return(&domain.PromoCode{Code: "USD", Kind: domain.PromoFixed, Value: 1000, Currency: "USD", IsActive: true}, nil)
+	pricing.On("GetPromoCode", mock.Anything).Return(nil, domain.ErrPromoCodeNotFound)
+
+	for _, c := range []struct{ provider, plan, promo string }{
+		{"kaspi", "basic", ""},
+		{"stub", "", ""},
+		{"stub", "gold", ""},
+		{"stub", "legacy", ""},
+		{"stub", "basic", "NOPE"},
+		{"stub", "basic", "USD"},
+	} {
+		_, err := uc.CreateOrder("uuid-1", c.provider, c.plan, c.promo)
+		var input InputError
+		assert.ErrorAs(t, err, &input, c)
+	}
+	for _, code := range []string{"OLD", "GONE"} {
+		_, err := uc.CreateOrder("uuid-1", "stub", "basic", code)
+		assert.ErrorIs(t, err, domain.ErrPromoCodeUnavailable, code)
+	}
+
+	_, err := uc.CreateOrder("missing", "stub", "basic", "")
+	assert.ErrorIs(t, err, domain.ErrInvitationNotFound)
+	repo.AssertNotCalled(t, "CreateOrder", mock.Anything)
+}
+
+func TestCreateOrder_CheckoutFails(t *testing.T) {
+	repo := new(MockOrderRepository)
+	invRepo := new(MockInvitationRepository)
+	uc := NewPaymentUseCase(repo, newPricingMock(), invRepo, &stubProvider{err: errors.New("timeout")})
+
+	invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+
+	_, err := uc.CreateOrder("uuid-1", "stub", "basic", "")
+	assert.EqualError(t, err, "stub checkout: timeout")
+	repo.AssertNotCalled(t, "CreateOrder", mock.Anything)
+}
+
+func TestHandleWebhook(t *testing.T) {
+	repo := new(MockOrderRepository)
+	provider := &stubProvider{event: &domain.PaymentEvent{EventID: "ev-1", OrderID: orderID, Status: domain.OrderPaid, Amount: 1500000, Currency: "KZT"}}
+	uc := NewPaymentUseCase(repo, new(MockPricingRepository), new(MockInvitationRepository), provider)
+
+	pending := &domain.Order{ID: orderID, Provider: "stub", Amount: 1500000, Currency: "KZT", Status: domain.OrderPending}
+	paid := *pending
+	paid.Status = domain.OrderPaid
+	repo.On("GetOrder", orderID).Return(pending, nil)
+	repo.On("ApplyPaymentEvent", mock.MatchedBy(func(ev *domain.PaymentEvent) bool {
+		return ev.Provider == "stub" && ev.EventID == "ev-1"
+	})).Return(&paid, true, nil)
+
+	order, err := uc.HandleWebhook("stub", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderPaid, order.Status)
+
+	_, err = uc.HandleWebhook("kaspi", nil, nil)
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
+
+func TestHandleWebhook_ProviderWithoutAmounts(t *testing.T) {
+	repo := new(MockOrderRepository)
+	provider := &stubProvider{omitAmount: true, event: &domain.PaymentEvent{EventID: "ev-1", OrderID: orderID, Status: domain.OrderPaid}}
+	uc := NewPaymentUseCase(repo, new(MockPricingRepository), new(MockInvitationRepository), provider)
+	repo.On("GetOrder", orderID).Return(&domain.Order{ID: orderID, Provider: "stub", Amount: 1500000, Currency: "KZT"}, nil)
+	repo.On("ApplyPaymentEvent", mock.Anything).Return(&domain.Order{ID: orderID, Status: domain.OrderPaid}, true, nil)
+
+	order, err := uc.HandleWebhook("stub", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, domain.OrderPaid, order.Status)
+}
+
+func TestHandleWebhook_Rejects(t *testing.T) {
+	repo := new(MockOrderRepository)
+	provider := &stubProvider{}
+	uc := NewPaymentUseCase(repo, new(MockPricingRepository), new(MockInvitationRepository), provider)
+	repo.On("GetOrder", orderID).Return(&domain.Order{ID: orderID, Provider: "stub", Amount: 1500000, Currency: "KZT"}, nil)
+
+	for _, ev := range []domain.PaymentEvent{
+		{Amount: 100, Currency: "KZT"},
+		{Amount: 1500000, Currency: "USD"},
+		{Currency: "KZT"},
+		{Amount: 1500000},
+		{},
+	} {
+		ev.EventID, ev.OrderID, ev.Status = "ev-1", orderID, domain.OrderPaid
+		provider.event = &ev
+		_, err := uc.HandleWebhook("stub", nil, nil)
+		assert.ErrorIs(t, err, ErrPaymentMismatch, "%+v", ev)
+	}
+
+	provider.event = &domain.PaymentEvent{EventID: "ev-1", OrderID: orderID, Status: "refunded"}
+	_, err := uc.HandleWebhook("stub", nil, nil)
+	var input InputError
+	assert.ErrorAs(t, err, &input)
+
+	provider.event = &domain.PaymentEvent{EventID: "ev-1", OrderID: "not-an-order", Status: domain.OrderPaid}
+	_, err = uc.HandleWebhook("stub", nil, nil)
+	assert.ErrorIs(t, err, domain.ErrOrderNotFound)
+
+	provider.event, provider.err = nil, ErrWebhookSignature
+	_, err = uc.HandleWebhook("stub", nil, nil)
+	assert.ErrorIs(t, err, ErrWebhookSignature)
+
+	repo.AssertNotCalled(t, "ApplyPaymentEvent", mock.Anything)
+}
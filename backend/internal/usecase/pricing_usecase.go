@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+var (
+	planCodePattern  = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,49}$`)
+	promoCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{2,49}$`)
+)
+
+// NormalizePromoCode puts a promo code the way it is stored: upper case,
+// without surrounding spaces, so customers can type it either way.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// PricingUseCase manages the plans invitations are sold as and the promo
+// codes that discount them.
+type PricingUseCase struct {
+	repo domain.PricingRepository
+	now  func() time.Time
+}
+
+func NewPricingUseCase(repo domain.PricingRepository) *PricingUseCase {
+	return &PricingUseCase{repo: repo, now: time.Now}
+}
+
+// Plans lists the plans, only those on sale unless all is set.
+func (u *PricingUseCase) Plans(all bool) ([]domain.Plan, error) {
+	return u.repo.ListPlans(!all)
+}
+
+// SavePlan creates or replaces a plan. The currency defaults to KZT.
+func (u *PricingUseCase) SavePlan(p *domain.Plan) error {
+	p.Code = strings.ToLower(strings.TrimSpace(p.Code))
+	if !planCodePattern.MatchString(p.Code) {
+		return InputError(fmt.Sprintf("invalid plan code: %q", p.Code))
+	}
+	if strings.TrimSpace(p.NameRu) == "" {
+		return InputError("nameRu is required")
+	}
+	if p.Price < 0 {
+		return InputError("price cannot be negative")
+	}
+	if p.HostingDays < 0 {
+		return InputError("hostingDays cannot be negative")
+	}
+	if p.Currency == "" {
+		p.Currency = DefaultCurrency
+	}
+	if !currencyPattern.MatchString(p.Currency) {
+		return InputError(fmt.Sprintf("invalid currency: %q", p.Currency))
+	}
+	features := make([]string, 0, len(p.Features))
+	for _, f := range p.Features {
+		if f = strings.TrimSpace(f); f != "" {
+			features = append(features, f)
+		}
+	}
+	p.Features = features
+	return u.repo.SavePlan(p)
+}
+
+func (u *PricingUseCase) PromoCodes() ([]domain.PromoCode, error) {
+	return u.repo.ListPromoCodes()
+}
+
+// CreatePromoCode validates and stores a new, active promo code. A percent
+// code takes 1 to 100 percent off; a fixed one takes Value minor units of
+// Currency (KZT by default) off.
+func (u *PricingUseCase) CreatePromoCode(p *domain.PromoCode) error {
+	p.Code = NormalizePromoCode(p.Code)
+	if !promoCodePattern.MatchString(p.Code) {
+		return InputError("code must be 3 to 50 latin letters, digits, hyphens or underscores")
+	}
+	switch p.Kind {
+	case domain.PromoPercent:
+		if p.Value < 1 || p.Value > 100 {
+			return InputError("a percent discount must be 1 to 100")
+		}
+		p.Currency = ""
+	case domain.PromoFixed:
+		if p.Value <= 0 {
+			return InputError("a fixed discount must be positive")
+		}
+		if p.Currency == "" {
+			p.Currency = DefaultCurrency
+		}
+		if !currencyPattern.MatchString(p.Currency) {
+			return InputError(fmt.Sprintf("invalid currency: %q", p.Currency))
+		}
+	default:
+		return InputError("kind must be percent or fixed")
+	}
+	if p.MaxUses != nil && *p.MaxUses < 1 {
+		return InputError("maxUses must be at least 1")
+	}
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(u.now()) {
+		return InputError("expiresAt must be in the future")
+	}
+	p.UsedCount = 0
+	p.IsActive = true
+	return u.repo.CreatePromoCode(p)
+}
+
+// DeactivatePromoCode stops a code from being redeemed. Orders already
+// priced with it keep their discount.
+func (u *PricingUseCase) DeactivatePromoCode(code string) error {
+	return u.repo.DeactivatePromoCode(NormalizePromoCode(code))
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromoCode_Discount(t *testing.T) {
+	percent := domain.PromoCode{Kind: domain.PromoPercent, Value: 15}
+	assert.Equal(t, int64(148500), percent.Discount(990000, "KZT"))
+
+	fixed := domain.PromoCode{Kind: domain.PromoFixed, Value: 500000, Currency: "KZT"}
+	assert.Equal(t, int64(500000), fixed.Discount(990000, "KZT"))
+	assert.Equal(t, int64(300000), fixed.Discount(300000, "KZT"))
+	assert.Equal(t, int64(0), fixed.Discount(990000, "USD"))
+}
+
+func TestSavePlan(t *testing.T) {
+	repo := new(MockPricingRepository)
+	uc := NewPricingUseCase(repo)
+	repo.On("SavePlan", mock.Anything).Return(nil)
+
+	p := &domain.Plan{Code: " Premium ", NameRu: "Премиум", Price: 1990000, Features: []string{" story ", ""}, HostingDays: 365}
+	require.NoError(t, uc.SavePlan(p))
+	assert.Equal(t, "premium", p.Code)
+	assert.Equal(t, "KZT", p.Currency)
+	assert.Equal(t, []string{"story"}, p.Features)
+
+	for _, bad := range []*domain.Plan{
+		{Code: "", NameRu: "x"},
+		{Code: "gold", NameRu: ""},
+		{Code: "gold", NameRu: "x", Price: -1},
+		{Code: "gold", NameRu: "x", Currency: "tenge"},
+	} {
+		var input InputError
+		assert.ErrorAs(t, uc.SavePlan(bad), &input, bad)
+	}
+}
+
+func TestCreatePromoCode(t *testing.T) {
+	repo := new(MockPricingRepository)
+	uc := NewPricingUseCase(repo)
+	uc.now = func() time.Time { return time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC) }
+	repo.On("CreatePromoCode", mock.Anything).Return(nil)
+
+	p := &domain.PromoCode{Code: "spring-20", Kind: domain.PromoFixed, Value: 200000}
+	require.NoError(t, uc.CreatePromoCode(p))
+	assert.Equal(t, "SPRING-20", p.Code)
+	assert.Equal(t, "KZT", p.Currency)
+	assert.True(t, p.IsActive)
+
+	zero := 0
+	past := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	for _, bad := range []*domain.PromoCode{
+		{Code: "X", Kind: domain.PromoPercent, Value: 10},
+		{Code: "HALF", Kind: domain.PromoPercent, Value: 101},
+		{Code: "HALF", Kind: "gift", Value: 10},
+		{Code: "HALF", Kind: domain.PromoFixed, Value: 0},
+		{Code: "HALF", Kind: domain.PromoPercent, Value: 50, MaxUses: &zero},
+		{Code: "HALF", Kind: domain.PromoPercent, Value: 50, ExpiresAt: &past},
+	} {
+		var input InputError
+		assert.ErrorAs(t, uc.CreatePromoCode(bad), &input, bad)
+	}
+}
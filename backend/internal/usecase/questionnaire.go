@@ -0,0 +1,353 @@
+package usecase
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+)
+
+// Fields of the "Данные для приглашения" questionnaire the client fills
+// in, in its order.
+const (
+	FieldTemplate  = "template"
+	FieldLang      = "lang"
+	FieldNames     = "names"
+	FieldDate      = "date"
+	FieldTime      = "time"
+	FieldVenue     = "venue"
+	FieldAddress   = "address"
+	FieldMapURL    = "mapUrl"
+	FieldDressCode = "dressCode"
+	FieldPhoto     = "photo"
+)
+
+// QuestionnaireFields lists the questionnaire fields in order; field n of
+// the questionnaire is QuestionnaireFields[n-1].
+var QuestionnaireFields = []string{
+	FieldTemplate, FieldLang, FieldNames, FieldDate, FieldTime,
+	FieldVenue, FieldAddress, FieldMapURL, FieldDressCode, FieldPhoto,
+}
+
+// normalizeWords lowercases s, folds ё into е and returns its runs of
+// letters and digits.
+func normalizeWords(s string) []string {
+	s = strings.NewReplacer("ё", "е", "Ё", "е").Replace(strings.ToLower(s))
+	return strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// connectorWords join two names or words in a template name; they are
+// dropped when matching names.
+var connectorWords = map[string]bool{"и": true, "and": true, "пен": true, "мен": true, "және": true}
+
+// matchKey is s reduced for loose comparison: "Silk & Ivory", "silk-ivory"
+// and "Silk and ivory" all give "silkivory".
+func matchKey(s string) string {
+	var b strings.Builder
+	for _, w := range normalizeWords(s) {
+		if !connectorWords[w] {
+			b.WriteString(w)
+		}
+	}
+	return b.String()
+}
+
+// activeTemplates returns the designs clients choose from, numbered as the
+// bot lists them. GetTemplates only returns active templates.
+func activeTemplates(repo domain.AdminRepository) ([]domain.Template, error) {
+	templates, err := repo.GetTemplates()
+	if err != nil {
+		return nil, err
+	}
+	SortTemplates(templates)
+	return templates, nil
+}
+
+// SortTemplates orders templates the way the bot numbers them.
+func SortTemplates(templates []domain.Template) {
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Code < templates[j].Code })
+}
+
+// TemplateName returns the name of t in lang, falling back to the Russian
+// name and the code.
+func TemplateName(t domain.Template, lang string) string {
+	name := map[string]string{i18n.LangRu: t.NameRu, i18n.LangKk: t.NameKk, i18n.LangEn: t.NameEn}[i18n.Normalize(lang)]
+	if name == "" {
+		name = t.NameRu
+	}
+	if name == "" {
+		name = t.Code
+	}
+	return name
+}
+
+var choiceNumber = regexp.MustCompile(`^\s*(?:№|#)?\s*(\d{1,2})\s*[.)]?\s*$`)
+
+// MatchTemplate finds the template an answer names: its number in
+// templates, its code, or its name in any language, also inside a longer
+// answer like "Дизайн «Звездная ночь»".
+func MatchTemplate(templates []domain.Template, answer string) (string, bool) {
+	code, _, ok := matchTemplate(templates, answer)
+	return code, ok
+}
+
+// matchTemplate is MatchTemplate; exact is unset when the name was only
+// found inside the answer.
+func matchTemplate(templates []domain.Template, answer string) (code string, exact, ok bool) {
+	if m := choiceNumber.FindStringSubmatch(answer); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n >= 1 && n <= len(templates) {
+			return templates[n-1].Code, true, true
+		}
+		return "", false, false
+	}
+	key := matchKey(answer)
+	if key == "" {
+		return "", false, false
+	}
+	for _, t := range templates {
+		for _, name := range []string{t.Code, t.NameRu, t.NameKk, t.NameEn} {
+			if k := matchKey(name); k != "" && k == key {
+				return t.Code, true, true
+			}
+		}
+	}
+	for _, t := range templates {
+		for _, name := range []string{t.Code, t.NameRu, t.NameKk, t.NameEn} {
+			if k := matchKey(name); k != "" && strings.Contains(key, k) {
+				if code != "" && code != t.Code {
+					return "", false, false
+				}
+				code = t.Code
+			}
+		}
+	}
+	return code, false, code != ""
+}
+
+// langPrefixes maps the start of a language's name, as clients write it
+// in Russian, Kazakh or English, to its code.
+var langPrefixes = []struct{ prefix, lang string }{
+	{"рус", i18n.LangRu}, {"орыс", i18n.LangRu}, {"russ", i18n.LangRu},
+	{"каз", i18n.LangKk}, {"қаз", i18n.LangKk}, {"kaz", i18n.LangKk}, {"qaz", i18n.LangKk},
+	{"англ", i18n.LangEn}, {"ағыл", i18n.LangEn}, {"агыл", i18n.LangEn}, {"eng", i18n.LangEn},
+}
+
+// ParseLang reads an invitation language: a code, "ru", "kk", "kz" or
+// "en", or its name, "Русский", "Қазақша", "English".
+func ParseLang(answer string) (string, bool) {
+	words := normalizeWords(answer)
+	if len(words) == 0 {
+		return "", false
+	}
+	var found string
+	for _, w := range words {
+		lang := ""
+		switch w {
+		case "ru", "kk", "en":
+			lang = w
+		case "kz":
+			lang = i18n.LangKk
+		default:
+			for _, p := range langPrefixes {
+				if strings.HasPrefix(w, p.prefix) {
+					lang = p.lang
+					break
+				}
+			}
+		}
+		if lang == "" {
+			continue
+		}
+		if found != "" && found != lang {
+			// "Русский и казахский": not one language.
+			return "", false
+		}
+		found = lang
+	}
+	return found, found != ""
+}
+
+var nameSeparator = regexp.MustCompile(`(?i)\s+(?:и|and|және|мен|пен)\s+|\s*[&+/,;\n]\s*|\s+[-–—]\s+`)
+
+// SplitCoupleNames reads the names of the groom and the bride, in that
+// order: "Арман и Айгерим", "Arman & Aigerim", "Арман, Айгерім".
+func SplitCoupleNames(answer string) (groom, bride string, ok bool) {
+	var names []string
+	for _, part := range nameSeparator.Split(strings.TrimSpace(answer), -1) {
+		if part = strings.TrimSpace(part); part != "" {
+			names = append(names, part)
+		}
+	}
+	if len(names) != 2 {
+		return "", "", false
+	}
+	return names[0], names[1], true
+}
+
+// monthStems are the starts of month names in Russian, Kazakh (also
+// typed without Kazakh letters) and English. September is "сент", as
+// "сенбі" is Saturday in Kazakh.
+var monthStems = []struct {
+	stem  string
+	month time.Month
+}{
+	{"янв", 1}, {"фев", 2}, {"мар", 3}, {"апр", 4}, {"май", 5}, {"мая", 5}, {"июн", 6},
+	{"июл", 7}, {"авг", 8}, {"сент", 9}, {"окт", 10}, {"ноя", 11}, {"дек", 12},
+	{"қаң", 1}, {"қан", 1}, {"кан", 1}, {"ақп", 2}, {"акп", 2}, {"нау", 3}, {"сәу", 4}, {"сау", 4},
+	{"мам", 5}, {"мау", 6}, {"шіл", 7}, {"шил", 7}, {"там", 8}, {"қыр", 9}, {"кыр", 9},
+	{"қаз", 10}, {"каз", 10}, {"қар", 11}, {"кар", 11}, {"жел", 12},
+	{"jan", 1}, {"feb", 2}, {"mar", 3}, {"apr", 4}, {"may", 5}, {"jun", 6},
+	{"jul", 7}, {"aug", 8}, {"sep", 9}, {"oct", 10}, {"nov", 11}, {"dec", 12},
+}
+
+func parseMonth(word string) (time.Month, bool) {
+	for _, s := range monthStems {
+		if strings.HasPrefix(word, s.stem) {
+			return s.month, true
+		}
+	}
+	return 0, false
+}
+
+// splitDigits splits the words of s further where digits meet letters,
+// so "2026г" and "15-го" give their number.
+func splitDigits(s string) []string {
+	var out []string
+	for _, w := range normalizeWords(s) {
+		start := 0
+		runes := []rune(w)
+		for i := 1; i <= len(runes); i++ {
+			if i == len(runes) || unicode.IsDigit(runes[i]) != unicode.IsDigit(runes[i-1]) {
+				out = append(out, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	return out
+}
+
+// ParseEventDay reads the day of the wedding: "15.08.2026", "15/08/26",
+// "2026-08-15", "15 августа 2026", "2026 жылғы 15 тамыз", "August 15,
+// 2026". Without a year it is the next such day from now. Anything after
+// the date, such as a time, is ignored.
+func ParseEventDay(answer string, now time.Time) (time.Time, bool) {
+	var nums []string
+	var month time.Month
+	for _, w := range splitDigits(answer) {
+		if _, err := strconv.Atoi(w); err == nil {
+			nums = append(nums, w)
+			continue
+		}
+		if month == 0 {
+			if m, ok := parseMonth(w); ok {
+				month = m
+			}
+		}
+	}
+
+	day, year := 0, 0
+	switch {
+	case month != 0 && len(nums) >= 1:
+		// The year is the four-digit number, if any.
+		if len(nums) >= 2 && len(nums[0]) == 4 {
+			year, day = atoi(nums[0]), atoi(nums[1])
+		} else {
+			day = atoi(nums[0])
+			if len(nums) >= 2 && len(nums[1]) >= 2 {
+				year = atoi(nums[1])
+			}
+		}
+	case month == 0 && len(nums) >= 3 && len(nums[0]) == 4:
+		year, month, day = atoi(nums[0]), time.Month(atoi(nums[1])), atoi(nums[2])
+	case month == 0 && len(nums) >= 3 && (len(nums[2]) == 2 || len(nums[2]) == 4):
+		day, month, year = atoi(nums[0]), time.Month(atoi(nums[1])), atoi(nums[2])
+	case month == 0 && len(nums) == 2:
+		day, month = atoi(nums[0]), time.Month(atoi(nums[1]))
+	default:
+		return time.Time{}, false
+	}
+	if year > 0 && year < 100 {
+		year += 2000
+	}
+	inferred := year == 0
+	if inferred {
+		year = now.Year()
+	}
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day || t.Month() != month || year < 2000 || year > 2100 {
+		return time.Time{}, false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if inferred && t.Before(today) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t, true
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+var clockTime = regexp.MustCompile(`(?i)^(\d{1,2})(?:\s*[:.\-hч]\s*(\d{2})|(\d{2}))?\s*(?:ч|час|часов|сағат|h|hrs|am|pm)?\.?$`)
+
+// ParseEventClock reads the time guests gather, "18:00", "18.30", "1830",
+// "18 ч", "6:30 pm", as "15:04".
+func ParseEventClock(answer string) (string, bool) {
+	s := strings.TrimSpace(strings.ToLower(answer))
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "в "), "at ")
+	m := clockTime.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	hour, minute := atoi(m[1]), atoi(m[2]+m[3])
+	if strings.HasSuffix(s, "pm") && hour < 12 {
+		hour += 12
+	}
+	if strings.HasSuffix(s, "am") && hour == 12 {
+		hour = 0
+	}
+	if hour > 23 || minute > 59 {
+		return "", false
+	}
+	return time.Date(0, 1, 1, hour, minute, 0, 0, time.UTC).Format("15:04"), true
+}
+
+var urlPattern = regexp.MustCompile(`https?://\S+`)
+
+// FindLink returns the first http or https link in an answer, such as a
+// 2GIS or Google Maps link pasted with some words around it.
+func FindLink(answer string) (string, bool) {
+	raw := strings.TrimRight(urlPattern.FindString(answer), ".,;:!?)»\"'")
+	if raw == "" {
+		return "", false
+	}
+	link, err := url.Parse(raw)
+	if err != nil || link.Host == "" {
+		return "", false
+	}
+	return link.String(), true
+}
+
+var skipAnswers = map[string]bool{
+	"нет": true, "нету": true, "не нужно": true, "не надо": true, "пропустить": true, "без": true,
+	"жоқ": true, "жок": true, "керек емес": true, "өткізу": true,
+	"no": true, "none": true, "skip": true, "n a": true,
+}
+
+// IsSkipAnswer reports whether the answer to an optional field says
+// there is nothing: "-", "нет", "жоқ", "no".
+func IsSkipAnswer(answer string) bool {
+	s := strings.TrimSpace(answer)
+	if s == "" || strings.Trim(s, "-–—. ") == "" {
+		return true
+	}
+	return skipAnswers[strings.Join(normalizeWords(s), " ")]
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+var questionnaireTemplates = []domain.Template{
+	{Code: "silk-ivory", NameRu: "Шелк и слоновая кость", NameKk: "Жібек пен Піл сүйегі", NameEn: "Silk & Ivory"},
+	{Code: "starry-night", NameRu: "Звездная ночь", NameKk: "Жұлдызды түн", NameEn: "Starry Night"},
+}
+
+func TestMatchTemplate(t *testing.T) {
+	for answer, want := range map[string]string{
+		"2":             "starry-night",
+		"№1":            "silk-ivory",
+		"Звёздная ночь": "starry-night",
+		"дизайн «Звездная ночь»": "starry-night",
+		"silk and ivory":          "silk-ivory",
+		"Жібек пен піл сүйегі":    "silk-ivory",
+		"starry-night":            "starry-night",
+		"Мне нравится Silk&Ivory": "silk-ivory",
+	} {
+		got, ok := MatchTemplate(questionnaireTemplates, answer)
+		assert.True(t, ok, answer)
+		assert.Equal(t, want, got, answer)
+	}
+	for _, answer := range []string{"3", "0", "красивый", "Звездная ночь или Silk & Ivory", ""} {
+		_, ok := MatchTemplate(questionnaireTemplates, answer)
+		assert.False(t, ok, answer)
+	}
+}
+
+func TestParseLang(t *testing.T) {
+	for answer, want := range map[string]string{
+		"Русский": "ru", "на русском": "ru", "орыс тілі": "ru", "RU": "ru",
+		"Қазақша": "kk", "казахский": "kk", "kz": "kk", "Kazakh": "kk",
+		"English": "en", "английский": "en", "ағылшын": "en",
+	} {
+		got, ok := ParseLang(answer)
+		assert.True(t, ok, answer)
+		assert.Equal(t, want, got, answer)
+	}
+	for _, answer := range []string{"", "немецкий", "русский и казахский"} {
+		_, ok := ParseLang(answer)
+		assert.False(t, ok, answer)
+	}
+}
+
+func TestSplitCoupleNames(t *testing.T) {
+	for _, answer := range []string{"Арман и Айгерим", "Арман & Айгерим", "Арман, Айгерим", "Арман мен Айгерим", "Арман\nАйгерим", "Арман + Айгерим"} {
+		groom, bride, ok := SplitCoupleNames(answer)
+		assert.True(t, ok, answer)
+		assert.Equal(t, []string{"Арман", "Айгерим"}, []string{groom, bride}, answer)
+	}
+	for _, answer := range []string{"Арман", "Арман, Айгерим и Данияр", ""} {
+		_, _, ok := SplitCoupleNames(answer)
+		assert.False(t, ok, answer)
+	}
+}
+
+func TestParseEventDay(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	for answer, want := range map[string]string{
+		"15.08.2026":           "2026-08-15",
+		"15/08/26":             "2026-08-15",
+		"2026-08-15":           "2026-08-15",
+		"15.08.2026 18:00":     "2026-08-15",
+		"15 августа 2026 г.":   "2026-08-15",
+		"15 августа 2026г":     "2026-08-15",
+		"2026 жылғы 15 тамыз":  "2026-08-15",
+		"August 15, 2026":      "2026-08-15",
+		"15 августа":           "2026-08-15",
+		"15.08":                "2026-08-15",
+		"10 сентября, сенбі":   "2026-09-10",
+		"20.03":                "2027-03-20",
+		"1 қаңтар 2027 жыл":    "2027-01-01",
+		"3 декабря 2026 (чт)":  "2026-12-03",
+		"12 October 2026":      "2026-10-12",
+		"5 мая 2026 года":      "2026-05-05",
+		"5-го мая 2026":        "2026-05-05",
+		"дата: 29.02.2028":     "2028-02-29",
+		"Той күні: 7.9.2026 ж": "2026-09-07",
+	} {
+		got, ok := ParseEventDay(answer, now)
+		if assert.True(t, ok, answer) {
+			assert.Equal(t, want, got.Format(time.DateOnly), answer)
+		}
+	}
+	for _, answer := range []string{"", "завтра", "31.02.2026", "15.13.2026", "в августе", "29.02.2027"} {
+		_, ok := ParseEventDay(answer, now)
+		assert.False(t, ok, answer)
+	}
+}
+
+func TestParseEventClock(t *testing.T) {
+	for answer, want := range map[string]string{
+		"18:00": "18:00", "18.30": "18:30", "1830": "18:30", "18": "18:00", "18ч": "18:00",
+		"в 18:00": "18:00", "18 ч.": "18:00", "6:30 pm": "18:30", "9 AM": "09:00", "18-00": "18:00",
+	} {
+		got, ok := ParseEventClock(answer)
+		assert.True(t, ok, answer)
+		assert.Equal(t, want, got, answer)
+	}
+	for _, answer := range []string{"", "вечером", "25:00", "18:75", "18:00 - 23:00"} {
+		_, ok := ParseEventClock(answer)
+		assert.False(t, ok, answer)
+	}
+}
+
+func TestFindLinkAndSkip(t *testing.T) {
+	link, ok := FindLink("Вот: https://2gis.kz/almaty/firm/70000001 (вход со двора)")
+	assert.True(t, ok)
+	assert.Equal(t, "https://2gis.kz/almaty/firm/70000001", link)
+	_, ok = FindLink("2gis Rixos")
+	assert.False(t, ok)
+
+	for _, answer := range []string{"-", "нет", "Нет.", "жоқ", "No", "не нужно", "—"} {
+		assert.True(t, IsSkipAnswer(answer), answer)
+	}
+	assert.False(t, IsSkipAnswer("Black tie"))
+}
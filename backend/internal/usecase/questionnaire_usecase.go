@@ -0,0 +1,531 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+// MaxQuestionnaireLength bounds the pasted questionnaire, in characters.
+const MaxQuestionnaireLength = 10000
+
+// How sure the parser is of a field of a pasted questionnaire.
+const (
+	// ConfidenceHigh: the answer was found by its label or number and
+	// read exactly.
+	ConfidenceHigh = "high"
+	// ConfidenceMedium: read, but loosely, e.g. a design name inside a
+	// sentence or a date without a year.
+	ConfidenceMedium = "medium"
+	// ConfidenceLow: there is an answer that could not be read, or it was
+	// only placed by the order of the lines.
+	ConfidenceLow = "low"
+	// ConfidenceMissing: the questionnaire has no answer.
+	ConfidenceMissing = "missing"
+)
+
+var confidenceRank = map[string]int{ConfidenceMissing: 0, ConfidenceLow: 1, ConfidenceMedium: 2, ConfidenceHigh: 3}
+
+// ParsedField is one answer of a pasted questionnaire.
+type ParsedField struct {
+	Field string `json:"field"`
+	// Raw is the answer as the client wrote it.
+	Raw string `json:"raw"`
+	// Value is the answer as it goes into the invitation: a template code,
+	// a language, "YYYY-MM-DD", "HH:MM", or the text.
+	Value      string   `json:"value"`
+	Confidence string   `json:"confidence"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// QuestionnaireDraft is a pasted questionnaire read into an invitation for
+// the operator to check and create.
+type QuestionnaireDraft struct {
+	// Invitation is not validated or stored.
+	Invitation *domain.Invitation `json:"invitation"`
+	// Lang is the language the questionnaire was filled in.
+	Lang string `json:"lang"`
+	// Fields has every questionnaire field, in order.
+	Fields []ParsedField `json:"fields"`
+	// Warnings are about the text as a whole.
+	Warnings []string `json:"warnings"`
+}
+
+// questionnaireLabels are the labels of the fields. The full ones are the
+// labels of the questionnaire in WHATSAPP_TEMPLATES.md and also mark an
+// answer without a colon, "6. Название ресторана/места Rixos"; the short
+// ones only before a colon, "Ресторан: Rixos".
+var questionnaireLabels = map[string]struct{ full, short []string }{
+	FieldTemplate: {
+		[]string{"Выбранный дизайн", "Таңдалған дизайн", "Design"},
+		[]string{"дизайн", "шаблон", "үлгі", "template"}},
+	FieldLang: {
+		[]string{"Язык приглашения", "Шақыру тілі", "Invitation language"},
+		[]string{"язык", "тіл", "тілі", "language"}},
+	FieldNames: {
+		[]string{"Имена", "Есімдер", "Names"},
+		[]string{"имя", "жених и невеста", "есімі", "есімдері", "couple"}},
+	FieldDate: {
+		[]string{"Дата свадьбы", "Той күні", "Wedding date"},
+		[]string{"дата", "күні", "date"}},
+	FieldTime: {
+		[]string{"Время сбора гостей", "Қонақтардың жиналу уақыты", "Guest arrival time"},
+		[]string{"время", "уақыты", "уақыт", "time"}},
+	FieldVenue: {
+		[]string{"Название ресторана/места", "Мейрамхананың/орынның атауы", "Restaurant/venue name"},
+		[]string{"ресторан", "место", "название", "мейрамхана", "орны", "venue", "restaurant", "place"}},
+	FieldAddress: {
+		[]string{"Адрес", "Мекенжайы", "Address"},
+		[]string{"мекенжай"}},
+	FieldMapURL: {
+		[]string{"Ссылка на 2GIS/Google Maps", "2GIS/Google Maps сілтемесі", "2GIS/Google Maps link"},
+		[]string{"ссылка", "карта", "2gis", "google maps", "сілтеме", "сілтемесі", "link", "map"}},
+	FieldDressCode: {
+		[]string{"Дресс-код", "Dress code"},
+		[]string{"dresscode"}},
+	FieldPhoto: {
+		[]string{"Ваше фото", "Сіздің суретіңіз", "Your photo"},
+		[]string{"фото", "сурет", "суреті", "photo"}},
+}
+
+// questionnaireItemStart is the number of an item: "1.", "1)", "1:",
+// "1 -", "№1." It is not followed by a digit, so "1.08.2026" and "18:00"
+// are not items.
+var questionnaireItemStart = regexp.MustCompile(`^(?:№\s*)?(\d{1,2})\s*(?:[.):]|[-–—])(?:\s+(.*)|(\D.*))?$`)
+
+// keycaps turns emoji numbers, "1️⃣", into plain ones.
+var keycaps = strings.NewReplacer("\uFE0F\u20E3", ".", "\u20E3", ".", "🔟", "10.")
+
+// questionnaireItem is an answer as found in the text.
+type questionnaireItem struct {
+	// number is the item's number, 0 when it has none.
+	number int
+	// field is the field its label names, "" without a label.
+	field string
+	value string
+	// positional is set when the text had neither numbers nor labels and
+	// the item is a line placed by its order.
+	positional bool
+}
+
+// QuestionnaireUseCase reads the "Данные для приглашения" questionnaire
+// clients send back filled in, in any of the three languages, into a draft
+// invitation. It is deterministic: the same text always gives the same
+// draft.
+type QuestionnaireUseCase struct {
+	adminRepo domain.AdminRepository
+	now       func() time.Time
+}
+
+func NewQuestionnaireUseCase(adminRepo domain.AdminRepository) *QuestionnaireUseCase {
+	return &QuestionnaireUseCase{adminRepo: adminRepo, now: time.Now}
+}
+
+// Parse reads a pasted questionnaire. phone, the client's number, is
+// optional; the draft can't be created without it.
+func (u *QuestionnaireUseCase) Parse(text, phone string) (*QuestionnaireDraft, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, InputError("text is required")
+	}
+	if utf8.RuneCountInString(text) > MaxQuestionnaireLength {
+		return nil, InputError(fmt.Sprintf("text is longer than %d characters", MaxQuestionnaireLength))
+	}
+	templates, err := activeTemplates(u.adminRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &QuestionnaireDraft{Lang: detectLang(text), Warnings: []string{}, Invitation: &domain.Invitation{Content: map[string]interface{}{}}}
+	items := splitQuestionnaire(text)
+	if len(items) == 0 {
+		d.Warnings = append(d.Warnings, "no questionnaire answers found")
+	} else if items[0].positional {
+		d.Warnings = append(d.Warnings, "the answers have neither numbers nor labels; they were matched by line order")
+	}
+
+	found := map[string]questionnaireItem{}
+	fieldWarnings := map[string][]string{}
+	for _, it := range items {
+		field := it.field
+		if field == "" {
+			field = QuestionnaireFields[it.number-1]
+		} else if it.number != 0 && QuestionnaireFields[it.number-1] != field {
+			fieldWarnings[field] = append(fieldWarnings[field], fmt.Sprintf("numbered %d but labelled as %s", it.number, field))
+		}
+		if _, dup := found[field]; dup {
+			fieldWarnings[field] = append(fieldWarnings[field], "answered twice; the first answer is used")
+			continue
+		}
+		found[field] = it
+	}
+
+	for _, field := range QuestionnaireFields {
+		it, ok := found[field]
+		f := ParsedField{Field: field, Raw: it.value, Confidence: ConfidenceMissing}
+		if ok && it.value != "" {
+			f.Confidence = ConfidenceHigh
+			u.readField(d, &f, templates)
+			if it.positional {
+				f.capConfidence(ConfidenceLow)
+			}
+			if len(fieldWarnings[field]) > 0 {
+				f.capConfidence(ConfidenceMedium)
+			}
+		}
+		f.Warnings = append(fieldWarnings[field], f.Warnings...)
+		d.Fields = append(d.Fields, f)
+	}
+	u.fillInvitation(d, phone)
+	return d, nil
+}
+
+func (f *ParsedField) capConfidence(max string) {
+	if confidenceRank[f.Confidence] > confidenceRank[max] {
+		f.Confidence = max
+	}
+}
+
+func (f *ParsedField) warn(confidence, format string, args ...interface{}) {
+	f.capConfidence(confidence)
+	f.Warnings = append(f.Warnings, fmt.Sprintf(format, args...))
+}
+
+// readField reads the raw answer of f into its value.
+func (u *QuestionnaireUseCase) readField(d *QuestionnaireDraft, f *ParsedField, templates []domain.Template) {
+	raw := f.Raw
+	switch f.Field {
+	case FieldTemplate:
+		if choiceNumber.MatchString(raw) {
+			// The designs are sent as numbered screenshots, which need
+			// not be numbered as we list them.
+			code, _, ok := matchTemplate(templates, raw)
+			if !ok {
+				f.warn(ConfidenceLow, "design number %s is not one of ours", strings.TrimSpace(raw))
+				return
+			}
+			f.Value = code
+			f.warn(ConfidenceLow, "design given by number; check it against the screenshots sent")
+			return
+		}
+		code, exact, ok := matchTemplate(templates, raw)
+		switch {
+		case !ok:
+			f.warn(ConfidenceLow, "design not recognised; it may have been sent as a screenshot")
+		case !exact:
+			f.Value = code
+			f.warn(ConfidenceMedium, "design name found inside the answer")
+		default:
+			f.Value = code
+		}
+	case FieldLang:
+		lang, ok := ParseLang(raw)
+		if !ok {
+			f.warn(ConfidenceLow, "language not recognised")
+			return
+		}
+		f.Value = lang
+	case FieldNames:
+		groom, bride, ok := splitRoleNames(raw)
+		if !ok {
+			f.Value = raw
+			f.warn(ConfidenceLow, "could not tell the groom's name from the bride's")
+			return
+		}
+		f.Value = groom + "\n" + bride
+	case FieldDate:
+		day, ok := ParseEventDay(raw, u.now())
+		if !ok {
+			f.warn(ConfidenceLow, "date not recognised")
+			return
+		}
+		f.Value = day.Format(time.DateOnly)
+		if !answerHasYear(raw, day.Year()) {
+			f.warn(ConfidenceMedium, "no year given; %d assumed", day.Year())
+		}
+		if now := u.now(); day.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+			f.warn(ConfidenceLow, "the date is in the past")
+		}
+	case FieldTime:
+		clock, ok := ParseEventClock(raw)
+		if !ok {
+			if m := looseClock.FindString(raw); m != "" {
+				clock, ok = ParseEventClock(m)
+			}
+			if !ok {
+				f.warn(ConfidenceLow, "time not recognised")
+				return
+			}
+			f.capConfidence(ConfidenceMedium)
+		}
+		f.Value = clock
+	case FieldMapURL:
+		if IsSkipAnswer(raw) {
+			return
+		}
+		link, ok := FindLink(raw)
+		if !ok {
+			f.warn(ConfidenceLow, "no link found")
+			return
+		}
+		f.Value = link
+	case FieldDressCode:
+		if !IsSkipAnswer(raw) {
+			f.Value = raw
+		}
+	case FieldPhoto:
+		if !IsSkipAnswer(raw) {
+			f.warn(ConfidenceLow, "photos come as separate messages; attach it to the invitation by hand")
+		}
+	default:
+		f.Value = raw
+	}
+}
+
+// answerHasYear tells a date with its year, "15.08.26", from one whose
+// year ParseEventDay guessed.
+func answerHasYear(raw string, year int) bool {
+	return strings.Contains(raw, strconv.Itoa(year)) || shortYearDate.MatchString(raw)
+}
+
+var shortYearDate = regexp.MustCompile(`\d{1,2}[./-]\d{1,2}[./-]\d{2}\b`)
+
+// looseClock finds a time inside a longer answer, "с 18:00 до 23:00".
+var looseClock = regexp.MustCompile(`\b\d{1,2}[:.]\d{2}\b`)
+
+// fillInvitation puts the read answers into the draft invitation.
+func (u *QuestionnaireUseCase) fillInvitation(d *QuestionnaireDraft, phone string) {
+	inv := d.Invitation
+	values := map[string]string{}
+	for _, f := range d.Fields {
+		values[f.Field] = f.Value
+	}
+	inv.TemplateCode = values[FieldTemplate]
+	inv.Lang = values[FieldLang]
+	if inv.Lang == "" {
+		inv.Lang = d.Lang
+		d.Warnings = append(d.Warnings, fmt.Sprintf("no invitation language; %s, the language of the questionnaire, is used", d.Lang))
+	}
+	if groom, bride, ok := strings.Cut(values[FieldNames], "\n"); ok {
+		inv.GroomName, inv.BrideName = groom, bride
+	} else {
+		inv.GroomName = values[FieldNames]
+	}
+	inv.EventDate = values[FieldDate]
+	if inv.EventDate != "" && values[FieldTime] != "" {
+		inv.EventDate += "T" + values[FieldTime]
+	}
+	inv.EventLocation = values[FieldVenue]
+	for key, field := range map[string]string{
+		domain.ContentAddress: FieldAddress, domain.ContentMapURL: FieldMapURL, domain.ContentDressCode: FieldDressCode,
+	} {
+		if values[field] != "" {
+			inv.Content[key] = values[field]
+		}
+	}
+
+	if strings.TrimSpace(phone) == "" {
+		d.Warnings = append(d.Warnings, "no phone number; add the client's before creating the invitation")
+	} else if number, err := domain.NormalizePhone(phone); err == nil {
+		inv.PhoneNumber = number
+	} else {
+		inv.PhoneNumber = strings.TrimSpace(phone)
+		d.Warnings = append(d.Warnings, fmt.Sprintf("%q is not a phone number", phone))
+	}
+}
+
+// splitQuestionnaire finds the answers in a pasted questionnaire. An
+// answer starts at a numbered line or a line with a field label and a
+// colon, and goes on over the following lines. Lines before the first are
+// greetings and headings, unless no line is numbered or labelled at all;
+// then each line is an answer in questionnaire order.
+func splitQuestionnaire(text string) []questionnaireItem {
+	var items []questionnaireItem
+	var loose []string
+	for _, line := range strings.Split(keycaps.Replace(text), "\n") {
+		// WhatsApp bold, "*Имена:*", and the template's own "**Имена:**".
+		line = strings.ReplaceAll(line, "*", "")
+		line = strings.TrimSpace(strings.TrimLeft(line, "•▪· \t"))
+		if line == "" {
+			continue
+		}
+		if m := questionnaireItemStart.FindStringSubmatch(line); m != nil {
+			if n, _ := strconv.Atoi(m[1]); n >= 1 && n <= len(QuestionnaireFields) {
+				field, value := splitLabel(m[2]+m[3], true)
+				items = append(items, questionnaireItem{number: n, field: field, value: cleanAnswer(value)})
+				continue
+			}
+		}
+		if field, value := splitLabel(line, false); field != "" {
+			items = append(items, questionnaireItem{field: field, value: cleanAnswer(value)})
+			continue
+		}
+		if len(items) > 0 {
+			last := &items[len(items)-1]
+			last.value = strings.TrimSpace(last.value + "\n" + line)
+			continue
+		}
+		loose = append(loose, line)
+	}
+	if len(items) > 0 {
+		return items
+	}
+
+	// Headings such as "📋 Данные для приглашения:" end with a colon.
+	var lines []string
+	for _, line := range loose {
+		if !strings.HasSuffix(line, ":") {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < len(QuestionnaireFields)/2 || len(lines) > len(QuestionnaireFields) {
+		return nil
+	}
+	for i, line := range lines {
+		items = append(items, questionnaireItem{number: i + 1, value: line, positional: true})
+	}
+	return items
+}
+
+// splitLabel splits "Label: answer" into the field the label names and
+// the answer. Within a numbered item the full label of a field also
+// counts without a colon.
+func splitLabel(s string, numbered bool) (field, value string) {
+	if i := labelColon(s); i >= 0 {
+		if field, _ := matchLabel(s[:i], true); field != "" {
+			return field, s[i+1:]
+		}
+	}
+	if numbered {
+		if field, words := matchLabel(s, false); field != "" {
+			return field, cutWords(s, words)
+		}
+		return "", s
+	}
+	return "", ""
+}
+
+// labelColon returns the index of the colon that ends a label: not inside
+// brackets, as in "(ЧЧ:ММ)", a time or a link.
+func labelColon(s string) int {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ':':
+			if depth > 0 || strings.HasPrefix(s[i+1:], "//") {
+				continue
+			}
+			if i > 0 && i+1 < len(s) && isDigit(s[i-1]) && isDigit(s[i+1]) {
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+// matchLabel finds the field whose label s starts with, the longest label
+// winning, and returns the number of words of the label.
+func matchLabel(s string, short bool) (field string, words int) {
+	have := normalizeWords(s)
+	for f, labels := range questionnaireLabels {
+		candidates := labels.full
+		if short {
+			candidates = append(append([]string(nil), labels.full...), labels.short...)
+		}
+		for _, label := range candidates {
+			want := normalizeWords(label)
+			if len(want) > words && len(want) <= len(have) && equalWords(have[:len(want)], want) {
+				field, words = f, len(want)
+			}
+		}
+	}
+	return field, words
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// cutWords returns s after its first n words and whatever brackets,
+// dashes or colon follow them.
+func cutWords(s string, n int) string {
+	inWord := false
+	for i, r := range s {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if inWord && !word {
+			n--
+			if n == 0 {
+				return s[i:]
+			}
+		}
+		inWord = word
+	}
+	return ""
+}
+
+var leadingHint = regexp.MustCompile(`^[\s:\-–—]*\([^()\d]*\)`)
+
+// cleanAnswer drops what the questionnaire's hints leave in an answer:
+// "(ДД.ММ.ГГГГ) 15.08.2026" is "15.08.2026", an untouched "(название или
+// скрин)" is no answer.
+func cleanAnswer(s string) string {
+	for {
+		cut := leadingHint.ReplaceAllString(s, "")
+		if cut == s {
+			break
+		}
+		s = cut
+	}
+	return strings.TrimSpace(strings.TrimLeft(s, " :-–—\t"))
+}
+
+// groomWords and brideWords mark who is who in "Жених: Арман, невеста:
+// Айгерим".
+var (
+	groomWords = []string{"жених", "күйеу жігіт", "күйеу", "groom"}
+	brideWords = []string{"невеста", "қалыңдық", "bride"}
+)
+
+// splitRoleNames is SplitCoupleNames that also understands names given
+// with their role, in either order.
+func splitRoleNames(answer string) (groom, bride string, ok bool) {
+	first, second, ok := SplitCoupleNames(answer)
+	if !ok {
+		return "", "", false
+	}
+	firstRole, first := cutRole(first)
+	secondRole, second := cutRole(second)
+	if firstRole == "bride" || secondRole == "groom" {
+		first, second = second, first
+	}
+	return first, second, first != "" && second != ""
+}
+
+func cutRole(name string) (role, rest string) {
+	lower := strings.ToLower(name)
+	for role, words := range map[string][]string{"groom": groomWords, "bride": brideWords} {
+		for _, w := range words {
+			if strings.HasPrefix(lower, w) {
+				return role, strings.TrimSpace(strings.TrimLeft(name[len(w):], " :-–—"))
+			}
+		}
+	}
+	return "", strings.TrimSpace(name)
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newQuestionnaireTest(t *testing.T) *QuestionnaireUseCase {
+	adminRepo := new(MockAdminRepository)
+	adminRepo.On("GetTemplates").Return([]domain.Template{
+		// As GetTemplates returns them: active only, IsActive not read.
+		{Code: "starry-night", NameRu: "Звездная ночь", NameKk: "Жұлдызды түн", NameEn: "Starry Night"},
+		{Code: "silk-ivory", NameRu: "Шелк и слоновая кость", NameKk: "Жібек пен Піл сүйегі", NameEn: "Silk & Ivory"},
+	}, nil)
+	u := NewQuestionnaireUseCase(adminRepo)
+	u.now = func() time.Time { return time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC) }
+	return u
+}
+
+func fieldsByName(d *QuestionnaireDraft) map[string]ParsedField {
+	out := map[string]ParsedField{}
+	for _, f := range d.Fields {
+		out[f.Field] = f
+	}
+	return out
+}
+
+func TestQuestionnaire_ParsesFilledTemplate(t *testing.T) {
+	d, err := newQuestionnaireTest(t).Parse(`Здравствуйте! Вот данные:
+📋 **Данные для приглашения:**
+
+1. **Выбранный дизайн:** (Название или скрин) Звёздная ночь
+2. **Язык приглашения:** Русский
+3. **Имена:** Арман и Айгерим
+4. **Дата свадьбы:** 15.08.2026
+5. **Время сбора гостей:** (ЧЧ:ММ) 18.00
+6. **Название ресторана/места:** Rixos
+7. **Адрес (текстом):** пр. Достык, 1
+вход со стороны парка
+8. **Ссылка на 2GIS/Google Maps (желательно):** https://2gis.kz/almaty/firm/1
+9. **Дресс-код (если есть):** нет
+10. **Ваше фото** (если шаблон предусматривает фото, прикрепите его отдельно)`, "8 701 123 45 67")
+	require.NoError(t, err)
+
+	assert.Equal(t, &domain.Invitation{
+		PhoneNumber: "+77011234567", TemplateCode: "starry-night", Lang: "ru", GroomName: "Арман", BrideName: "Айгерим",
+		EventDate: "2026-08-15T18:00", EventLocation: "Rixos",
+		Content: map[string]interface{}{
+			domain.ContentAddress: "пр. Достык, 1\nвход со стороны парка",
+			domain.ContentMapURL:  "https://2gis.kz/almaty/firm/1",
+		},
+	}, d.Invitation)
+	assert.Equal(t, "ru", d.Lang)
+	assert.Empty(t, d.Warnings)
+	require.Len(t, d.Fields, len(QuestionnaireFields))
+	for _, f := range d.Fields {
+		switch f.Field {
+		case FieldPhoto:
+			assert.Equal(t, ConfidenceMissing, f.Confidence)
+		default:
+			assert.Equal(t, ConfidenceHigh, f.Confidence, f.Field)
+			assert.Empty(t, f.Warnings, f.Field)
+		}
+	}
+	assert.Equal(t, "18.00", fieldsByName(d)[FieldTime].Raw)
+	assert.Equal(t, "18:00", fieldsByName(d)[FieldTime].Value)
+}
+
+func TestQuestionnaire_ToleratesLooseAnswers(t *testing.T) {
+	d, err := newQuestionnaireTest(t).Parse(`1️⃣ Дизайн - мне нравится Silk&Ivory
+2) Тіл: қазақша
+3 - Күйеу жігіт Арман, қалыңдық Айгерим
+Той күні: 15 тамыз
+Уақыты: 18:00 - 23:00
+6. Мейрамхана: Rixos
+9) -
+8. 2gis Rixos`, "")
+	require.NoError(t, err)
+	f := fieldsByName(d)
+
+	assert.Equal(t, "silk-ivory", f[FieldTemplate].Value)
+	assert.Equal(t, ConfidenceMedium, f[FieldTemplate].Confidence)
+	assert.Equal(t, "kk", f[FieldLang].Value)
+	assert.Equal(t, "Арман\nАйгерим", f[FieldNames].Value)
+	assert.Equal(t, "2026-08-15", f[FieldDate].Value)
+	assert.Equal(t, ConfidenceMedium, f[FieldDate].Confidence)
+	assert.Equal(t, []string{"no year given; 2026 assumed"}, f[FieldDate].Warnings)
+	assert.Equal(t, "18:00", f[FieldTime].Value)
+	assert.Equal(t, ConfidenceMedium, f[FieldTime].Confidence)
+	assert.Equal(t, "Rixos", f[FieldVenue].Value)
+	assert.Equal(t, ConfidenceMissing, f[FieldAddress].Confidence)
+	assert.Equal(t, ConfidenceLow, f[FieldMapURL].Confidence)
+	assert.Empty(t, f[FieldDressCode].Value)
+
+	assert.Equal(t, "kk", d.Lang)
+	assert.Equal(t, "2026-08-15T18:00", d.Invitation.EventDate)
+	assert.Empty(t, d.Invitation.Content)
+	assert.Equal(t, []string{"no phone number; add the client's before creating the invitation"}, d.Warnings)
+}
+
+func TestQuestionnaire_EnglishWithRolesAndConflicts(t *testing.T) {
+	d, err := newQuestionnaireTest(t).Parse(`Design: 2
+Names: Bride Aigerim & Groom Arman
+Wedding date: August 15, 2025
+5. Address: Dostyk 1
+Time: 6:30 pm
+Date: 16.08.2026
+`, "+7 701 123 45 67")
+	require.NoError(t, err)
+	f := fieldsByName(d)
+
+	assert.Equal(t, "starry-night", f[FieldTemplate].Value)
+	assert.Equal(t, ConfidenceLow, f[FieldTemplate].Confidence)
+	assert.Equal(t, "Arman\nAigerim", f[FieldNames].Value)
+	assert.Equal(t, "2025-08-15", f[FieldDate].Value)
+	assert.Equal(t, ConfidenceLow, f[FieldDate].Confidence)
+	assert.Equal(t, []string{"answered twice; the first answer is used", "the date is in the past"}, f[FieldDate].Warnings)
+	assert.Equal(t, "Dostyk 1", f[FieldAddress].Value)
+	assert.Equal(t, ConfidenceMedium, f[FieldAddress].Confidence)
+	assert.Equal(t, []string{"numbered 5 but labelled as address"}, f[FieldAddress].Warnings)
+	assert.Equal(t, "18:30", f[FieldTime].Value)
+
+	assert.Equal(t, "en", d.Invitation.Lang)
+	assert.Equal(t, "+77011234567", d.Invitation.PhoneNumber)
+	assert.Equal(t, []string{"no invitation language; en, the language of the questionnaire, is used"}, d.Warnings)
+}
+
+func TestQuestionnaire_UnnumberedLinesGoInOrder(t *testing.T) {
+	d, err := newQuestionnaireTest(t).Parse("Данные для приглашения:\nЗвездная ночь\nрусский\nАрман и Айгерим\n15.08.2026\n18:00\nRixos", "")
+	require.NoError(t, err)
+	f := fieldsByName(d)
+	assert.Equal(t, "starry-night", f[FieldTemplate].Value)
+	assert.Equal(t, "Rixos", f[FieldVenue].Value)
+	for _, field := range QuestionnaireFields[:6] {
+		assert.Equal(t, ConfidenceLow, f[field].Confidence, field)
+	}
+	assert.Contains(t, d.Warnings, "the answers have neither numbers nor labels; they were matched by line order")
+
+	d, err = newQuestionnaireTest(t).Parse("Добрый день, когда будет готово?", "")
+	require.NoError(t, err)
+	assert.Contains(t, d.Warnings, "no questionnaire answers found")
+	for _, f := range d.Fields {
+		assert.Equal(t, ConfidenceMissing, f.Confidence, f.Field)
+	}
+}
+
+func TestQuestionnaire_RejectsBadInput(t *testing.T) {
+	u := newQuestionnaireTest(t)
+	var input InputError
+	_, err := u.Parse("  ", "")
+	assert.ErrorAs(t, err, &input)
+	_, err = u.Parse(string(make([]rune, MaxQuestionnaireLength+1)), "")
+	assert.ErrorAs(t, err, &input)
+}
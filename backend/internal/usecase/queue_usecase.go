@@ -0,0 +1,368 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+const (
+	// DefaultQueueJobsLimit and maxQueueJobsLimit bound a page of the
+	// admin queue list.
+	DefaultQueueJobsLimit = 50
+	maxQueueJobsLimit     = 500
+	// DefaultQueueRetention is how long finished jobs are kept.
+	DefaultQueueRetention = 7 * 24 * time.Hour
+)
+
+// QueueOptions tune the queue workers; zero fields take the defaults.
+type QueueOptions struct {
+	// Workers is how many jobs this replica runs at once (4).
+	Workers int
+	// PollInterval is how often idle workers look for due jobs (2s).
+	PollInterval time.Duration
+	// Lease is the visibility timeout: a job still running after it is
+	// cancelled and may be claimed by another worker (5m).
+	Lease time.Duration
+	// MaxAttempts is how often a job is tried before it is dead (8).
+	MaxAttempts int
+	// BaseBackoff is the wait before the first retry, doubling for each
+	// one after up to MaxBackoff (10s, 1h).
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// ShutdownGrace is how long jobs in progress may finish on shutdown
+	// before they are cancelled and handed back to the queue (10s).
+	ShutdownGrace time.Duration
+}
+
+func (o QueueOptions) withDefaults() QueueOptions {
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 2 * time.Second
+	}
+	if o.Lease <= 0 {
+		o.Lease = 5 * time.Minute
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 8
+	}
+	if o.BaseBackoff <= 0 {
+		o.BaseBackoff = 10 * time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = time.Hour
+	}
+	if o.ShutdownGrace <= 0 {
+		o.ShutdownGrace = 10 * time.Second
+	}
+	return o
+}
+
+// QueueJobHandler does the work of one kind of queue job. An error makes the
+// job retry later unless it is Permanent.
+type QueueJobHandler func(ctx context.Context, payload json.RawMessage) error
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a job error as one retrying won't fix, such as a payload
+// that can't be read, so the job is dead-lettered at once.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var perm permanentError
+	return errors.As(err, &perm)
+}
+
+// QueuePage is a page of the admin queue list, with the number of jobs in
+// each status across the whole queue.
+type QueuePage struct {
+	Jobs   []domain.QueueJob `json:"jobs"`
+	Total  int               `json:"total"`
+	Limit  int               `json:"limit"`
+	Offset int               `json:"offset"`
+	Counts map[string]int    `json:"counts"`
+}
+
+var queueStatuses = map[string]bool{
+	domain.QueuePending: true,
+	domain.QueueRunning: true,
+	domain.QueueDone:    true,
+	domain.QueueDead:    true,
+}
+
+// QueueUseCase runs slow work, such as documents and calls to other
+// services, off the request path. Jobs live in the database, so they
+// survive restarts and are shared by all replicas.
+type QueueUseCase struct {
+	repo     domain.QueueRepository
+	opts     QueueOptions
+	handlers map[string]QueueJobHandler
+	// wake lets Enqueue start an idle worker of this replica without
+	// waiting for the next poll.
+	wake chan struct{}
+}
+
+func NewQueueUseCase(repo domain.QueueRepository, opts QueueOptions) *QueueUseCase {
+	return &QueueUseCase{
+		repo:     repo,
+		opts:     opts.withDefaults(),
+		handlers: map[string]QueueJobHandler{},
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Handle registers the handler of a kind of job. Handlers are registered
+// before Run, and only registered kinds are claimed.
+func (q *QueueUseCase) Handle(kind string, h QueueJobHandler) {
+	if _, ok := q.handlers[kind]; ok || kind == "" {
+		panic(fmt.Sprintf("queue job kind %q is empty or registered twice", kind))
+	}
+	q.handlers[kind] = h
+}
+
+// HandleQueue registers a handler that gets the payload decoded as T. A
+// payload that doesn't decode kills the job.
+func HandleQueue[T any](q *QueueUseCase, kind string, h func(ctx context.Context, payload T) error) {
+	q.Handle(kind, func(ctx context.Context, raw json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return Permanent(fmt.Errorf("decoding payload: %w", err))
+		}
+		return h(ctx, payload)
+	})
+}
+
+// Enqueue queues a job of a registered kind to run as soon as a worker is
+// free.
+func (q *QueueUseCase) Enqueue(kind string, payload any) (*domain.QueueJob, error) {
+	return q.EnqueueAt(kind, payload, time.Time{})
+}
+
+// EnqueueAt queues a job to run at runAt, or now if it is zero.
+func (q *QueueUseCase) EnqueueAt(kind string, payload any, runAt time.Time) (*domain.QueueJob, error) {
+	if _, ok := q.handlers[kind]; !ok {
+		return nil, fmt.Errorf("no handler for queue job kind %q", kind)
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	job := &domain.QueueJob{Kind: kind, Payload: raw, MaxAttempts: q.opts.MaxAttempts, RunAt: runAt}
+	if err := q.repo.Enqueue(job); err != nil {
+		return nil, err
+	}
+	q.notify()
+	return job, nil
+}
+
+// notify wakes an idle worker of this replica for jobs just queued.
+func (q *QueueUseCase) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *QueueUseCase) kinds() []string {
+	kinds := make([]string, 0, len(q.handlers))
+	for kind := range q.handlers {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Run works the queue until ctx is done. Jobs in progress then get
+// ShutdownGrace to finish; those that don't are cancelled and handed back
+// without counting the attempt.
+func (q *QueueUseCase) Run(ctx context.Context) {
+	kinds := q.kinds()
+	if len(kinds) == 0 {
+		return
+	}
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+	stopGrace := context.AfterFunc(ctx, func() {
+		time.AfterFunc(q.opts.ShutdownGrace, cancelJobs)
+	})
+	defer stopGrace()
+
+	var wg sync.WaitGroup
+	for range q.opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx, jobCtx, kinds)
+		}()
+	}
+	wg.Wait()
+}
+
+// work claims and runs jobs one at a time until ctx is done.
+func (q *QueueUseCase) work(ctx, jobCtx context.Context, kinds []string) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-q.wake:
+		}
+		// select picks at random when the timer is also ready.
+		if ctx.Err() != nil {
+			return
+		}
+		jobs, err := q.repo.ClaimQueueJobs(kinds, 1, q.opts.Lease)
+		if err != nil {
+			log.Printf("queue: claiming jobs: %v", err)
+		}
+		if len(jobs) == 0 {
+			timer.Reset(q.opts.PollInterval)
+			continue
+		}
+		q.process(jobCtx, &jobs[0])
+		// Look for more work straight away.
+		timer.Reset(0)
+	}
+}
+
+// process runs a claimed job and records the outcome.
+func (q *QueueUseCase) process(ctx context.Context, job *domain.QueueJob) {
+	runCtx, cancel := context.WithTimeout(ctx, q.opts.Lease)
+	defer cancel()
+	err := q.safeHandle(runCtx, job)
+
+	switch {
+	case err == nil:
+		err = q.repo.CompleteQueueJob(job)
+	case ctx.Err() != nil:
+		log.Printf("queue: job %d (%s) interrupted by shutdown, handing it back", job.ID, job.Kind)
+		err = q.repo.ReleaseQueueJob(job)
+	case IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		log.Printf("queue: job %d (%s) is dead after %d attempts: %v", job.ID, job.Kind, job.Attempts, err)
+		err = q.repo.DeadLetterQueueJob(job, err.Error())
+	default:
+		wait := q.backoff(job.Attempts)
+		log.Printf("queue: job %d (%s) failed, retrying in %s: %v", job.ID, job.Kind, wait, err)
+		err = q.repo.FailQueueJob(job, err.Error(), wait)
+	}
+	if err != nil {
+		log.Printf("queue: recording job %d: %v", job.ID, err)
+	}
+}
+
+// safeHandle keeps a panicking handler from taking the server down.
+func (q *QueueUseCase) safeHandle(ctx context.Context, job *domain.QueueJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	h, ok := q.handlers[job.Kind]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for queue job kind %q", job.Kind))
+	}
+	return h(ctx, job.Payload)
+}
+
+// backoff is the wait after the given failed attempt: BaseBackoff doubled
+// for each attempt before it, up to MaxBackoff.
+func (q *QueueUseCase) backoff(attempt int) time.Duration {
+	wait := q.opts.BaseBackoff
+	for i := 1; i < attempt && wait < q.opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, q.opts.MaxBackoff)
+}
+
+// Jobs lists queue jobs for the admin, newest first; limit 0 means
+// DefaultQueueJobsLimit.
+func (q *QueueUseCase) Jobs(filter domain.QueueJobFilter) (*QueuePage, error) {
+	if filter.Status != "" && !queueStatuses[filter.Status] {
+		return nil, InputError(fmt.Sprintf("unknown status %q", filter.Status))
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultQueueJobsLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxQueueJobsLimit {
+		return nil, InputError(fmt.Sprintf("limit must be between 1 and %d", maxQueueJobsLimit))
+	}
+	if filter.Offset < 0 {
+		return nil, InputError("offset must not be negative")
+	}
+	jobs, total, err := q.repo.ListQueueJobs(filter)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := q.repo.CountQueueJobs()
+	if err != nil {
+		return nil, err
+	}
+	return &QueuePage{Jobs: jobs, Total: total, Limit: filter.Limit, Offset: filter.Offset, Counts: counts}, nil
+}
+
+func (q *QueueUseCase) Job(id int64) (*domain.QueueJob, error) {
+	return q.repo.GetQueueJob(id)
+}
+
+// Retry makes a dead job, or one waiting for its next attempt, due now
+// with a fresh set of attempts.
+func (q *QueueUseCase) Retry(id int64) (*domain.QueueJob, error) {
+	job, err := q.repo.RetryQueueJob(id)
+	if err != nil {
+		return nil, err
+	}
+	q.notify()
+	return job, nil
+}
+
+// ScheduledJobs are the housekeeping jobs of the queue: finished jobs are
+// deleted once they are older than retention.
+func (q *QueueUseCase) ScheduledJobs(retention time.Duration) []ScheduledJob {
+	if retention <= 0 {
+		retention = DefaultQueueRetention
+	}
+	return []ScheduledJob{
+		{
+			Name:        "purge-queue-jobs",
+			Description: "Delete finished queue jobs past the retention period; dead ones are kept",
+			Schedule:    "45 3 * * *",
+			Enabled:     true,
+			Run: func(context.Context) (int, error) {
+				return q.repo.PurgeQueueJobs(time.Now().Add(-retention))
+			},
+		},
+	}
+}
+
+// HTTPCallKind is the kind of queue job that makes an HTTP request, e.g. to
+// start an n8n workflow, with retries instead of inside a handler.
+const HTTPCallKind = "http.call"
+
+// HTTPCall is the payload of an HTTPCallKind job. Method defaults to POST
+// and Body is sent as JSON.
+type HTTPCall struct {
+	Method  string            `json:"method,omitempty"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type greeting struct {
+	Name string `json:"name"`
+}
+
+func TestQueue_EnqueueChecksKindAndEncodesPayload(t *testing.T) {
+	repo := new(MockQueueRepository)
+	q := NewQueueUseCase(repo, QueueOptions{MaxAttempts: 3})
+	HandleQueue(q, "greet", func(context.Context, greeting) error { return nil })
+
+	repo.On("Enqueue", mock.MatchedBy(func(job *domain.QueueJob) bool {
+		return job.Kind == "greet" && string(job.Payload) == `{"name":"Ali"}` && job.MaxAttempts == 3
+	})).Return(nil)
+
+	_, err := q.Enqueue("greet", greeting{Name: "Ali"})
+	require.NoError(t, err)
+	_, err = q.Enqueue("unknown", nil)
+	assert.Error(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestQueue_ProcessOutcomes(t *testing.T) {
+	boom := errors.New("boom")
+	tests := []struct {
+		name     string
+		attempts int
+		err      error
+		expect   func(repo *MockQueueRepository, job *domain.QueueJob)
+	}{
+		{"success", 1, nil, func(repo *MockQueueRepository, job *domain.QueueJob) {
+			repo.On("CompleteQueueJob", job).Return(nil)
+		}},
+		{"retried with backoff", 3, boom, func(repo *MockQueueRepository, job *domain.QueueJob) {
+			repo.On("FailQueueJob", job, "boom", 40*time.Second).Return(nil)
+		}},
+		{"dead after the last attempt", 5, boom, func(repo *MockQueueRepository, job *domain.QueueJob) {
+			repo.On("DeadLetterQueueJob", job, "boom").Return(nil)
+		}},
+		{"dead on a permanent error", 1, Permanent(boom), func(repo *MockQueueRepository, job *domain.QueueJob) {
+			repo.On("DeadLetterQueueJob", job, "boom").Return(nil)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockQueueRepository)
+			q := NewQueueUseCase(repo, QueueOptions{BaseBackoff: 10 * time.Second})
+			q.Handle("work", func(context.Context, json.RawMessage) error { return tt.err })
+			job := &domain.QueueJob{ID: 1, Kind: "work", Attempts: tt.attempts, MaxAttempts: 5}
+			tt.expect(repo, job)
+
+			q.process(context.Background(), job)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestQueue_BadPayloadAndPanicsAreHandled(t *testing.T) {
+	repo := new(MockQueueRepository)
+	q := NewQueueUseCase(repo, QueueOptions{})
+	HandleQueue(q, "greet", func(context.Context, greeting) error { return nil })
+	q.Handle("panic", func(context.Context, json.RawMessage) error { panic("oops") })
+
+	bad := &domain.QueueJob{ID: 1, Kind: "greet", Payload: []byte(`[1]`), Attempts: 1, MaxAttempts: 5}
+	repo.On("DeadLetterQueueJob", bad, mock.AnythingOfType("string")).Return(nil)
+	q.process(context.Background(), bad)
+
+	panicking := &domain.QueueJob{ID: 2, Kind: "panic", Attempts: 1, MaxAttempts: 5}
+	repo.On("FailQueueJob", panicking, "panic: oops", 10*time.Second).Return(nil)
+	q.process(context.Background(), panicking)
+	repo.AssertExpectations(t)
+}
+
+func TestQueue_Backoff(t *testing.T) {
+	q := NewQueueUseCase(nil, QueueOptions{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+	var got []time.Duration
+	for attempt := 1; attempt <= 6; attempt++ {
+		got = append(got, q.backoff(attempt))
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}, got)
+}
+
+func TestQueue_RunHandsBackJobsOnShutdown(t *testing.T) {
+	repo := new(MockQueueRepository)
+	q := NewQueueUseCase(repo, QueueOptions{Workers: 1, PollInterval: time.Hour, ShutdownGrace: 10 * time.Millisecond})
+	started := make(chan struct{})
+	q.Handle("slow", func(ctx context.Context, _ json.RawMessage) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	job := domain.QueueJob{ID: 1, Kind: "slow", Attempts: 1, MaxAttempts: 5}
+	repo.On("ClaimQueueJobs", []string{"slow"}, 1, 5*time.Minute).Return([]domain.QueueJob{job}, nil).Once()
+	repo.On("ReleaseQueueJob", mock.MatchedBy(func(j *domain.QueueJob) bool { return j.ID == 1 })).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+	<-started
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the shutdown grace")
+	}
+	repo.AssertExpectations(t)
+}
+
+func TestQueue_JobsValidatesFilter(t *testing.T) {
+	repo := new(MockQueueRepository)
+	q := NewQueueUseCase(repo, QueueOptions{})
+	repo.On("ListQueueJobs", domain.QueueJobFilter{Status: domain.QueueDead, Limit: DefaultQueueJobsLimit}).
+		Return([]domain.QueueJob{{ID: 3, Status: domain.QueueDead}}, 1, nil)
+	repo.On("CountQueueJobs").Return(map[string]int{domain.QueueDead: 1}, nil)
+
+	page, err := q.Jobs(domain.QueueJobFilter{Status: domain.QueueDead})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, 1, page.Counts[domain.QueueDead])
+
+	var input InputError
+	_, err = q.Jobs(domain.QueueJobFilter{Status: "lost"})
+	assert.ErrorAs(t, err, &input)
+	_, err = q.Jobs(domain.QueueJobFilter{Limit: 1000})
+	assert.ErrorAs(t, err, &input)
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+)
+
+// Message templates of the guest reminders, by audience, logged as the
+// notification's template.
+const (
+	// ReminderRSVP asks a guest to answer by the deadline.
+	ReminderRSVP = "rsvp_reminder"
+	// ReminderEvent reminds an attending guest of the day, time and place.
+	ReminderEvent = "event_reminder"
+)
+
+var reminderTemplateNames = map[string]string{
+	domain.ReminderPending:   ReminderRSVP,
+	domain.ReminderAttending: ReminderEvent,
+}
+
+// ReminderData is what reminder templates are rendered from.
+type ReminderData struct {
+	NotificationData
+	GuestName string
+	// Deadline and DeadlineWeekday are the RSVP deadline in the message
+	// language, "" when the invitation has none.
+	Deadline        string
+	DeadlineWeekday string
+	// When is the event day as seen from when the reminder is sent:
+	// "tomorrow", "today" or "on August 15, 2026".
+	When   string
+	MapURL string
+	// StopLink opts the guest out of reminders.
+	StopLink string
+}
+
+var reminderTemplateTexts = map[string]map[string]string{
+	ReminderRSVP: {
+		i18n.LangRu: `Здравствуйте{{if .GuestName}}, {{.GuestName}}{{end}}! 💌
+{{if .Couple}}{{.Couple}} ждут вас на свадьбе{{if .Date}} {{.Date}}{{end}}.
+{{end}}Пожалуйста, ответьте на приглашение{{if .Deadline}} до {{.Deadline}} ({{.DeadlineWeekday}}){{end}}:
+🔗 {{.ShortLink}}
+
+Не присылать напоминания: {{.StopLink}}`,
+		i18n.LangKk: `Сәлеметсіз бе{{if .GuestName}}, {{.GuestName}}{{end}}! 💌
+{{if .Couple}}{{.Couple}} сізді тойға шақырады{{if .Date}} ({{.Date}}){{end}}.
+{{end}}Шақыруға{{if .Deadline}} {{.Deadline}} ({{.DeadlineWeekday}}) дейін{{end}} жауап беріңізші:
+🔗 {{.ShortLink}}
+
+Еске салулардан бас тарту: {{.StopLink}}`,
+		i18n.LangEn: `Hello{{if .GuestName}}, {{.GuestName}}{{end}}! 💌
+{{if .Couple}}{{.Couple}} would love to see you at their wedding{{if .Date}} on {{.Date}}{{end}}.
+{{end}}Please RSVP{{if .Deadline}} by {{.DeadlineWeekday}}, {{.Deadline}}{{end}}:
+🔗 {{.ShortLink}}
+
+Stop reminders: {{.StopLink}}`,
+	},
+	ReminderEvent: {
+		i18n.LangRu: `Здравствуйте{{if .GuestName}}, {{.GuestName}}{{end}}! 🥂
+До встречи {{.When}}{{if .Location}} в {{.Location}}{{end}}{{if .Time}}, {{.Time}}{{end}}!{{if .Couple}} {{.Couple}} ждут вас.{{end}}
+{{if .MapURL}}📍 {{.MapURL}}
+{{end}}🔗 {{.ShortLink}}
+
+Не присылать напоминания: {{.StopLink}}`,
+		i18n.LangKk: `Сәлеметсіз бе{{if .GuestName}}, {{.GuestName}}{{end}}! 🥂
+Кездескенше: {{.When}}{{if .Location}}, {{.Location}}{{end}}{{if .Time}}, {{.Time}}{{end}}!{{if .Couple}} {{.Couple}} сізді күтеді.{{end}}
+{{if .MapURL}}📍 {{.MapURL}}
+{{end}}🔗 {{.ShortLink}}
+
+Еске салулардан бас тарту: {{.StopLink}}`,
+		i18n.LangEn: `Hello{{if .GuestName}}, {{.GuestName}}{{end}}! 🥂
+See you {{.When}}{{if .Location}} at {{.Location}}{{end}}{{if .Time}}, {{.Time}}{{end}}!{{if .Couple}} {{.Couple}} are looking forward to it.{{end}}
+{{if .MapURL}}📍 {{.MapURL}}
+{{end}}🔗 {{.ShortLink}}
+
+Stop reminders: {{.StopLink}}`,
+	},
+}
+
+// reminderTemplates holds the parsed templates by name and language.
+var reminderTemplates = func() map[string]map[string]*template.Template {
+	parsed := map[string]map[string]*template.Template{}
+	for name, langs := range reminderTemplateTexts {
+		parsed[name] = map[string]*template.Template{}
+		for lang, text := range langs {
+			parsed[name][lang] = template.Must(template.New(name + "." + lang).Parse(text))
+		}
+	}
+	return parsed
+}()
+
+// renderReminder renders the template name in lang.
+func renderReminder(name, lang string, data ReminderData) (string, error) {
+	var buf bytes.Buffer
+	if err := reminderTemplates[name][i18n.Normalize(lang)].Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,543 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/i18n"
+)
+
+const (
+	// DefaultReminderTimezone is the event timezone of invitations that
+	// don't set domain.ContentTimezone.
+	DefaultReminderTimezone = "Asia/Almaty"
+	// DefaultQuietHours is when no reminder goes out, in the event's
+	// timezone.
+	DefaultQuietHours = "22:00-09:00"
+	// reminderGrace is how late a reminder still goes out, after quiet
+	// hours or downtime. A policy added long after its time doesn't fire.
+	reminderGrace = 24 * time.Hour
+	// maxReminderOffset bounds ReminderPolicy.OffsetMinutes: 90 days.
+	maxReminderOffset = 90 * 24 * 60
+)
+
+// ReminderOptions configures the guest reminders.
+type ReminderOptions struct {
+	// Channel reminders are sent on, domain.ChannelWhatsApp by default. It
+	// must be one of the notification channels.
+	Channel string
+	// Timezone is the IANA timezone of events that don't set one,
+	// DefaultReminderTimezone by default.
+	Timezone string
+	// QuietHours is "22:00-09:00", DefaultQuietHours by default, or "off".
+	QuietHours string
+}
+
+// quietHours is a span of the day in minutes since midnight, which may
+// wrap around midnight. from == to is no quiet hours at all.
+type quietHours struct{ from, to int }
+
+func parseQuietHours(s string) (quietHours, error) {
+	if s == "off" {
+		return quietHours{}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return quietHours{}, fmt.Errorf("quiet hours %q are not HH:MM-HH:MM", s)
+	}
+	var q quietHours
+	for _, part := range []struct {
+		s string
+		m *int
+	}{{from, &q.from}, {to, &q.to}} {
+		t, err := time.Parse("15:04", strings.TrimSpace(part.s))
+		if err != nil {
+			return quietHours{}, fmt.Errorf("quiet hours %q are not HH:MM-HH:MM", s)
+		}
+		*part.m = t.Hour()*60 + t.Minute()
+	}
+	return q, nil
+}
+
+func (q quietHours) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if q.from <= q.to {
+		return m >= q.from && m < q.to
+	}
+	return m >= q.from || m < q.to
+}
+
+// ReminderPolicyInput is a reminder policy as the admin sets it. Enabled
+// defaults to true.
+type ReminderPolicyInput struct {
+	Anchor        string `json:"anchor" binding:"required"`
+	OffsetMinutes int    `json:"offsetMinutes"`
+	Audience      string `json:"audience" binding:"required"`
+	Enabled       *bool  `json:"enabled"`
+}
+
+// ReminderStop is what the opt-out page of a reminder shows.
+type ReminderStop struct {
+	Phone string
+	Lang  string
+}
+
+// ReminderUseCase sends guests the reminders of their invitation's
+// policies, "please RSVP by Friday" to those who haven't answered and "see
+// you tomorrow" to those who come. Reminders are notifications, sent and
+// retried by the queue; guests opt out with the link in each of them.
+type ReminderUseCase struct {
+	repo          domain.ReminderRepository
+	invRepo       domain.InvitationRepository
+	guestRepo     domain.GuestRepository
+	notifications *NotificationUseCase
+	baseURL       string
+	channel       string
+	loc           *time.Location
+	quiet         quietHours
+	now           func() time.Time
+}
+
+// NewReminderUseCase sends reminders through notifications. Links in them
+// point to baseURL.
+func NewReminderUseCase(repo domain.ReminderRepository, invRepo domain.InvitationRepository, guestRepo domain.GuestRepository,
+	notifications *NotificationUseCase, baseURL string, opts ReminderOptions) (*ReminderUseCase, error) {
+	if opts.Channel == "" {
+		opts.Channel = domain.ChannelWhatsApp
+	}
+	if opts.Timezone == "" {
+		opts.Timezone = DefaultReminderTimezone
+	}
+	if opts.QuietHours == "" {
+		opts.QuietHours = DefaultQuietHours
+	}
+	loc, err := time.LoadLocation(opts.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("reminder timezone: %w", err)
+	}
+	quiet, err := parseQuietHours(opts.QuietHours)
+	if err != nil {
+		return nil, err
+	}
+	return &ReminderUseCase{
+		repo:          repo,
+		invRepo:       invRepo,
+		guestRepo:     guestRepo,
+		notifications: notifications,
+		baseURL:       baseURL,
+		channel:       opts.Channel,
+		loc:           loc,
+		quiet:         quiet,
+		now:           time.Now,
+	}, nil
+}
+
+// Policies returns the reminder policies of the invitation uuid, with when
+// each is due.
+func (u *ReminderUseCase) Policies(uuid string) ([]domain.ReminderPolicy, error) {
+	inv, err := u.invRepo.GetByUUID(uuid)
+	if err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	list, err := u.repo.ListReminderPolicies(uuid)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		u.setDueAt(inv, &list[i])
+	}
+	return list, nil
+}
+
+func (u *ReminderUseCase) CreatePolicy(uuid string, in ReminderPolicyInput) (*domain.ReminderPolicy, error) {
+	p, err := reminderPolicy(in)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := u.invRepo.GetByUUID(uuid)
+	if err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	p.InvitationUUID = uuid
+	if err := u.repo.CreateReminderPolicy(p); err != nil {
+		return nil, err
+	}
+	u.setDueAt(inv, p)
+	return p, nil
+}
+
+// UpdatePolicy replaces the policy id of the invitation uuid. Guests who
+// got its reminder don't get it again.
+func (u *ReminderUseCase) UpdatePolicy(uuid string, id int64, in ReminderPolicyInput) (*domain.ReminderPolicy, error) {
+	p, err := reminderPolicy(in)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := u.invRepo.GetByUUID(uuid)
+	if err != nil {
+		return nil, domain.ErrInvitationNotFound
+	}
+	p.ID, p.InvitationUUID = id, uuid
+	if err := u.repo.UpdateReminderPolicy(p); err != nil {
+		return nil, err
+	}
+	u.setDueAt(inv, p)
+	return p, nil
+}
+
+func (u *ReminderUseCase) DeletePolicy(uuid string, id int64) error {
+	if _, err := u.invRepo.GetByUUID(uuid); err != nil {
+		return domain.ErrInvitationNotFound
+	}
+	return u.repo.DeleteReminderPolicy(uuid, id)
+}
+
+func reminderPolicy(in ReminderPolicyInput) (*domain.ReminderPolicy, error) {
+	if in.Anchor != domain.ReminderAnchorDeadline && in.Anchor != domain.ReminderAnchorEvent {
+		return nil, InputError(fmt.Sprintf("anchor %q is not %s or %s", in.Anchor, domain.ReminderAnchorDeadline, domain.ReminderAnchorEvent))
+	}
+	if _, ok := reminderTemplateNames[in.Audience]; !ok {
+		return nil, InputError(fmt.Sprintf("audience %q is not %s or %s", in.Audience, domain.ReminderPending, domain.ReminderAttending))
+	}
+	if in.OffsetMinutes < -maxReminderOffset || in.OffsetMinutes > maxReminderOffset {
+		return nil, InputError(fmt.Sprintf("offsetMinutes must be within ±%d", maxReminderOffset))
+	}
+	p := &domain.ReminderPolicy{Anchor: in.Anchor, OffsetMinutes: in.OffsetMinutes, Audience: in.Audience, Enabled: true}
+	if in.Enabled != nil {
+		p.Enabled = *in.Enabled
+	}
+	return p, nil
+}
+
+func (u *ReminderUseCase) setDueAt(inv *domain.Invitation, p *domain.ReminderPolicy) {
+	if at, ok := u.dueAt(inv, p, u.location(inv)); ok {
+		p.DueAt = &at
+	}
+}
+
+// location is the timezone of the invitation's event.
+func (u *ReminderUseCase) location(inv *domain.Invitation) *time.Location {
+	if tz := inv.ContentString(domain.ContentTimezone); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			return loc
+		}
+	}
+	return u.loc
+}
+
+// wallTime reads a date in the formats of EventDate as the wall clock of
+// loc, unless it carries a zone of its own.
+func wallTime(raw string, loc *time.Location) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(raw)); err == nil {
+		return t.In(loc), true
+	}
+	t, ok := (&domain.Invitation{EventDate: raw}).EventTime()
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), true
+}
+
+// dueAt is when p is due for inv. A deadline without a time of day is the
+// end of that day.
+func (u *ReminderUseCase) dueAt(inv *domain.Invitation, p *domain.ReminderPolicy, loc *time.Location) (time.Time, bool) {
+	var at time.Time
+	var ok bool
+	switch p.Anchor {
+	case domain.ReminderAnchorEvent:
+		at, ok = wallTime(inv.EventDate, loc)
+	case domain.ReminderAnchorDeadline:
+		at, ok = wallTime(inv.ContentString(domain.ContentRSVPDeadline), loc)
+		if ok && at.Hour() == 0 && at.Minute() == 0 && at.Second() == 0 {
+			at = at.AddDate(0, 0, 1)
+		}
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+	return at.Add(time.Duration(p.OffsetMinutes) * time.Minute), true
+}
+
+// SendDue queues the reminders that are due, outside quiet hours, to the
+// guests who haven't got them and haven't opted out. It returns how many
+// were queued.
+func (u *ReminderUseCase) SendDue(ctx context.Context) (int, error) {
+	policies, err := u.repo.ActiveReminderPolicies()
+	if err != nil {
+		return 0, err
+	}
+	byInvitation := map[string][]domain.ReminderPolicy{}
+	var order []string
+	for _, p := range policies {
+		if _, ok := byInvitation[p.InvitationUUID]; !ok {
+			order = append(order, p.InvitationUUID)
+		}
+		byInvitation[p.InvitationUUID] = append(byInvitation[p.InvitationUUID], p)
+	}
+
+	now := u.now()
+	sent := 0
+	var errs []error
+	for _, uuid := range order {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		n, err := u.sendInvitation(uuid, byInvitation[uuid], now)
+		sent += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invitation %s: %w", uuid, err))
+		}
+	}
+	if sent > 0 {
+		u.notifications.Queued()
+	}
+	return sent, errors.Join(errs...)
+}
+
+type reminderRecipient struct {
+	name, phone string
+}
+
+func (u *ReminderUseCase) sendInvitation(uuid string, policies []domain.ReminderPolicy, now time.Time) (int, error) {
+	inv, err := u.invRepo.GetByUUID(uuid)
+	if err != nil {
+		return 0, err
+	}
+	loc := u.location(inv)
+	local := now.In(loc)
+	if u.quiet.contains(local) {
+		return 0, nil
+	}
+	var due []domain.ReminderPolicy
+	for _, p := range policies {
+		if at, ok := u.dueAt(inv, &p, loc); ok && !now.Before(at) && now.Sub(at) < reminderGrace {
+			due = append(due, p)
+		}
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+	if !slices.Contains(u.notifications.Channels(), u.channel) {
+		return 0, fmt.Errorf("reminder channel %s is not configured", u.channel)
+	}
+
+	audiences := map[string][]reminderRecipient{}
+	var phones []string
+	for _, p := range due {
+		if _, ok := audiences[p.Audience]; ok {
+			continue
+		}
+		list, err := u.recipients(inv, p.Audience)
+		if err != nil {
+			return 0, err
+		}
+		audiences[p.Audience] = list
+		for _, r := range list {
+			phones = append(phones, r.phone)
+		}
+	}
+	optedOut, err := u.repo.OptedOutPhones(phones)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, p := range due {
+		reminded, err := u.repo.RemindedPhones(p.ID)
+		if err != nil {
+			return sent, err
+		}
+		for _, r := range audiences[p.Audience] {
+			if optedOut[r.phone] || reminded[r.phone] {
+				continue
+			}
+			created, err := u.remind(inv, p, r, local)
+			if err != nil {
+				return sent, err
+			}
+			if created {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// recipients returns the guests of inv with a phone number that audience
+// reminds, once per number. Roster guests count as answered when an RSVP
+// has their phone or, ignoring case, their name.
+func (u *ReminderUseCase) recipients(inv *domain.Invitation, audience string) ([]reminderRecipient, error) {
+	rsvps, err := u.invRepo.GetRSVPs(inv.UUID)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var list []reminderRecipient
+	add := func(name, phone string) {
+		if phone != "" && !seen[phone] {
+			seen[phone] = true
+			list = append(list, reminderRecipient{name: strings.TrimSpace(name), phone: phone})
+		}
+	}
+
+	if audience == domain.ReminderAttending {
+		for _, r := range rsvps {
+			if r.Attendance == domain.AttendanceYes {
+				add(r.GuestName, r.Phone)
+			}
+		}
+		return list, nil
+	}
+
+	answered := map[string]bool{}
+	for _, r := range rsvps {
+		if r.Attendance == domain.AttendanceMaybe {
+			add(r.GuestName, r.Phone)
+			continue
+		}
+		answered[strings.ToLower(strings.TrimSpace(r.GuestName))] = true
+		if r.Phone != "" {
+			answered[r.Phone] = true
+		}
+	}
+	guests, err := u.guestRepo.ListGuests(inv.UUID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range guests {
+		if !answered[g.Phone] && !answered[strings.ToLower(strings.TrimSpace(g.Name))] {
+			add(g.Name, g.Phone)
+		}
+	}
+	return list, nil
+}
+
+// remind renders the reminder of p for r and queues it, reporting false
+// when r already got it.
+func (u *ReminderUseCase) remind(inv *domain.Invitation, p domain.ReminderPolicy, r reminderRecipient, local time.Time) (bool, error) {
+	lang := i18n.Normalize(inv.Lang)
+	token := newReminderToken()
+	data := ReminderData{
+		NotificationData: newNotificationData(inv, lang, u.baseURL),
+		GuestName:        r.name,
+		MapURL:           inv.ContentString(domain.ContentMapURL),
+		StopLink:         u.baseURL + "/r/stop/" + token,
+	}
+	if t, ok := wallTime(inv.ContentString(domain.ContentRSVPDeadline), local.Location()); ok {
+		data.Deadline, data.DeadlineWeekday = i18n.FormatDate(t, lang), i18n.FormatWeekday(t, lang)
+	}
+	if t, ok := wallTime(inv.EventDate, local.Location()); ok {
+		data.When = relativeDay(t, local, lang)
+	}
+
+	template := reminderTemplateNames[p.Audience]
+	body, err := renderReminder(template, lang, data)
+	if err != nil {
+		return false, err
+	}
+	n := &domain.Notification{
+		InvitationUUID: inv.UUID,
+		Channel:        u.channel,
+		Template:       template,
+		Lang:           lang,
+		Recipient:      r.phone,
+		Body:           body,
+	}
+	d := &domain.ReminderDelivery{PolicyID: p.ID, InvitationUUID: inv.UUID, Phone: r.phone, Token: token}
+	return u.repo.CreateReminder(d, n, u.notifications.SendJob())
+}
+
+// relativeDay names the day of t as seen on the day of now: "today",
+// "tomorrow" or "on August 15, 2026".
+func relativeDay(t, now time.Time, lang string) string {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	switch day(t).Sub(day(now)) {
+	case 0:
+		return i18n.T(lang, "reminder_today")
+	case 24 * time.Hour:
+		return i18n.T(lang, "reminder_tomorrow")
+	}
+	return i18n.T(lang, "reminder_on_date", i18n.FormatDate(t, lang))
+}
+
+func newReminderToken() string {
+	b := make([]byte, 16)
+	// crypto/rand does not fail on supported platforms.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// StopPage returns what the opt-out page of the reminder token shows: the
+// phone number and the invitation's language.
+func (u *ReminderUseCase) StopPage(token string) (*ReminderStop, error) {
+	d, err := u.repo.GetReminderByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	stop := &ReminderStop{Phone: d.Phone, Lang: i18n.DefaultLang}
+	if inv, err := u.invRepo.GetByUUID(d.InvitationUUID); err == nil {
+		stop.Lang = i18n.Normalize(inv.Lang)
+	}
+	return stop, nil
+}
+
+// Stop opts the phone number the reminder token was sent to out of all
+// reminders.
+func (u *ReminderUseCase) Stop(token string) (*ReminderStop, error) {
+	stop, err := u.StopPage(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.AddReminderOptOut(stop.Phone); err != nil {
+		return nil, err
+	}
+	log.Printf("reminders: %s opted out", stop.Phone)
+	return stop, nil
+}
+
+func (u *ReminderUseCase) OptOuts() ([]domain.ReminderOptOut, error) {
+	return u.repo.ListReminderOptOuts()
+}
+
+// AddOptOut stops reminders to phone, for guests who ask the couple or
+// support rather than use the link.
+func (u *ReminderUseCase) AddOptOut(phone string) (*domain.ReminderOptOut, error) {
+	normalized, err := normalizePhone(phone)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.AddReminderOptOut(normalized); err != nil {
+		return nil, err
+	}
+	return &domain.ReminderOptOut{Phone: normalized, CreatedAt: u.now()}, nil
+}
+
+func (u *ReminderUseCase) RemoveOptOut(phone string) error {
+	normalized, err := normalizePhone(phone)
+	if err != nil {
+		return err
+	}
+	return u.repo.RemoveReminderOptOut(normalized)
+}
+
+// ScheduledJobs sends the reminders that fell due, every five minutes.
+func (u *ReminderUseCase) ScheduledJobs() []ScheduledJob {
+	return []ScheduledJob{
+		{
+			Name:        "send-reminders",
+			Description: "Queue the guest reminders of invitations that are due, outside quiet hours",
+			Schedule:    "*/5 * * * *",
+			Enabled:     true,
+			Run:         u.SendDue,
+		},
+	}
+}
@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var remindInvitation = &domain.Invitation{UUID: "inv-1", Lang: "en", GroomName: "Arman", BrideName: "Aigerim",
+	EventDate: "2026-08-15T18:00", EventLocation: "Rixos", ShortCode: "arman-aigerim",
+	Content: map[string]interface{}{domain.ContentRSVPDeadline: "2026-08-07", domain.ContentMapURL: "https://2gis.kz/rixos"}}
+
+func newReminderUseCase(t *testing.T, repo *MockReminderRepository, invRepo *MockInvitationRepository, guestRepo *MockGuestRepository, now time.Time) *ReminderUseCase {
+	notifications := newNotificationUseCase(new(MockNotificationRepository), invRepo, &fakeChannel{name: domain.ChannelWhatsApp})
+	u, err := NewReminderUseCase(repo, invRepo, guestRepo, notifications, "https://card-go.test", ReminderOptions{})
+	require.NoError(t, err)
+	u.now = func() time.Time { return now }
+	return u
+}
+
+func almaty(t *testing.T, month time.Month, day, hour, min int) time.Time {
+	loc, err := time.LoadLocation(DefaultReminderTimezone)
+	require.NoError(t, err)
+	return time.Date(2026, month, day, hour, min, 0, 0, loc)
+}
+
+func TestNewReminderUseCase_RejectsBadOptions(t *testing.T) {
+	notifications := newNotificationUseCase(new(MockNotificationRepository), new(MockInvitationRepository))
+	_, err := NewReminderUseCase(nil, nil, nil, notifications, "https://card-go.test", ReminderOptions{Timezone: "Mars/Olympus"})
+	assert.Error(t, err)
+	_, err = NewReminderUseCase(nil, nil, nil, notifications, "https://card-go.test", ReminderOptions{QuietHours: "late"})
+	assert.Error(t, err)
+	_, err = NewReminderUseCase(nil, nil, nil, notifications, "https://card-go.test", ReminderOptions{QuietHours: "off"})
+	assert.NoError(t, err)
+}
+
+func TestQuietHours(t *testing.T) {
+	night, err := parseQuietHours("22:00-09:00")
+	require.NoError(t, err)
+	day, err := parseQuietHours("13:00-14:30")
+	require.NoError(t, err)
+	off, err := parseQuietHours("off")
+	require.NoError(t, err)
+
+	at := func(hour, min int) time.Time { return time.Date(2026, 8, 14, hour, min, 0, 0, time.UTC) }
+	assert.True(t, night.contains(at(23, 0)))
+	assert.True(t, night.contains(at(8, 59)))
+	assert.False(t, night.contains(at(9, 0)))
+	assert.False(t, night.contains(at(21, 59)))
+	assert.True(t, day.contains(at(14, 0)))
+	assert.False(t, day.contains(at(14, 30)))
+	assert.False(t, off.contains(at(3, 0)))
+}
+
+func TestReminder_PoliciesAreDueInEventTimezone(t *testing.T) {
+	repo, invRepo := new(MockReminderRepository), new(MockInvitationRepository)
+	u := newReminderUseCase(t, repo, invRepo, new(MockGuestRepository), time.Now())
+	invRepo.On("GetByUUID", "inv-1").Return(remindInvitation, nil)
+	repo.On("ListReminderPolicies", "inv-1").Return([]domain.ReminderPolicy{
+		{ID: 1, Anchor: domain.ReminderAnchorDeadline, OffsetMinutes: -2 * 24 * 60, Audience: domain.ReminderPending},
+		{ID: 2, Anchor: domain.ReminderAnchorEvent, OffsetMinutes: -24 * 60, Audience: domain.ReminderAttending},
+	}, nil)
+
+	list, err := u.Policies("inv-1")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	// A deadline without a time is the end of the day.
+	assert.True(t, list[0].DueAt.Equal(almaty(t, time.August, 6, 0, 0)), list[0].DueAt)
+	assert.True(t, list[1].DueAt.Equal(almaty(t, time.August, 14, 18, 0)), list[1].DueAt)
+
+	moscow := *remindInvitation
+	moscow.Content = map[string]interface{}{domain.ContentTimezone: "Europe/Moscow"}
+	at, ok := u.dueAt(&moscow, &list[1], u.location(&moscow))
+	require.True(t, ok)
+	assert.Equal(t, "2026-08-14T18:00:00+03:00", at.Format(time.RFC3339))
+	_, ok = u.dueAt(&moscow, &list[0], u.location(&moscow))
+	assert.False(t, ok, "no deadline")
+
+	zoned := *remindInvitation
+	zoned.EventDate = "2026-08-15T13:00:00Z"
+	at, ok = u.dueAt(&zoned, &list[1], u.location(&zoned))
+	require.True(t, ok)
+	assert.True(t, at.Equal(time.Date(2026, 8, 14, 13, 0, 0, 0, time.UTC)))
+}
+
+func TestReminder_CreatePolicyValidates(t *testing.T) {
+	repo, invRepo := new(MockReminderRepository), new(MockInvitationRepository)
+	u := newReminderUseCase(t, repo, invRepo, new(MockGuestRepository), time.Now())
+	invRepo.On("GetByUUID", "inv-1").Return(remindInvitation, nil)
+	invRepo.On("GetByUUID", "missing").Return(nil, errors.New("no rows in result set"))
+	repo.On("CreateReminderPolicy", mock.MatchedBy(func(p *domain.ReminderPolicy) bool {
+		return p.InvitationUUID == "inv-1" && p.Enabled && p.OffsetMinutes == -24*60
+	})).Return(nil)
+
+	var input InputError
+	for _, in := range []ReminderPolicyInput{
+		{Anchor: "wedding", Audience: domain.ReminderPending},
+		{Anchor: domain.ReminderAnchorEvent, Audience: "everyone"},
+		{Anchor: domain.ReminderAnchorEvent, Audience: domain.ReminderPending, OffsetMinutes: -maxReminderOffset - 1},
+	} {
+		_, err := u.CreatePolicy("inv-1", in)
+		assert.ErrorAs(t, err, &input, in)
+	}
+	_, err := u.CreatePolicy("missing", ReminderPolicyInput{Anchor: domain.ReminderAnchorEvent, Audience: domain.ReminderPending})
+	assert.ErrorIs(t, err, domain.ErrInvitationNotFound)
+
+	p, err := u.CreatePolicy("inv-1", ReminderPolicyInput{Anchor: domain.ReminderAnchorEvent, OffsetMinutes: -24 * 60, Audience: domain.ReminderAttending})
+	require.NoError(t, err)
+	require.NotNil(t, p.DueAt)
+	assert.True(t, p.DueAt.Equal(almaty(t, time.August, 14, 18, 0)))
+	repo.AssertExpectations(t)
+}
+
+func TestReminder_SendDueRemindsEachGuestOnce(t *testing.T) {
+	repo, invRepo, guestRepo := new(MockReminderRepository), new(MockInvitationRepository), new(MockGuestRepository)
+	u := newReminderUseCase(t, repo, invRepo, guestRepo, almaty(t, time.August, 14, 18, 10))
+	repo.On("ActiveReminderPolicies").Return([]domain.ReminderPolicy{
+		{ID: 1, InvitationUUID: "inv-1", Anchor: domain.ReminderAnchorEvent, OffsetMinutes: -24 * 60, Audience: domain.ReminderAttending},
+		// Due a week ago: past the grace period.
+		{ID: 2, InvitationUUID: "inv-1", Anchor: domain.ReminderAnchorDeadline, OffsetMinutes: -2 * 24 * 60, Audience: domain.ReminderPending},
+		{ID: 3, InvitationUUID: "inv-1", Anchor: domain.ReminderAnchorEvent, OffsetMinutes: -24 * 60, Audience: domain.ReminderPending},
+	}, nil)
+	invRepo.On("GetByUUID", "inv-1").Return(remindInvitation, nil)
+	invRepo.On("GetRSVPs", "inv-1").Return([]domain.RSVPResponse{
+		{GuestName: "Aida", Attendance: domain.AttendanceYes, Phone: "+77010000001"},
+		{GuestName: "Bolat", Attendance: domain.AttendanceYes, Phone: "+77010000001"},
+		{GuestName: "Dana", Attendance: domain.AttendanceNo, Phone: "+77010000003"},
+		{GuestName: "Erlan", Attendance: domain.AttendanceMaybe, Phone: "+77010000004"},
+		{GuestName: "Gulnara", Attendance: domain.AttendanceYes},
+	}, nil)
+	guestRepo.On("ListGuests", "inv-1").Return([]domain.Guest{
+		{Name: "Dana S.", Phone: "+77010000003"},
+		{Name: "Farida", Phone: "+77010000005"},
+		{Name: "gulnara ", Phone: "+77010000006"},
+		{Name: "Hanna"},
+		{Name: "Ivan", Phone: "+77010000007"},
+	}, nil)
+	repo.On("OptedOutPhones", mock.Anything).Return(map[string]bool{"+77010000007": true}, nil)
+	repo.On("RemindedPhones", int64(1)).Return(map[string]bool{}, nil)
+	repo.On("RemindedPhones", int64(3)).Return(map[string]bool{"+77010000004": true}, nil)
+
+	job := domain.QueueJob{Kind: NotificationSendKind, MaxAttempts: 6}
+	var tokens []string
+	repo.On("CreateReminder", mock.MatchedBy(func(d *domain.ReminderDelivery) bool {
+		return d.PolicyID == 1 && d.Phone == "+77010000001"
+	}), mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Channel == domain.ChannelWhatsApp && n.Template == ReminderEvent && n.Recipient == "+77010000001" &&
+			strings.Contains(n.Body, "Hello, Aida!") &&
+			strings.Contains(n.Body, "See you tomorrow at Rixos, 18:00!") &&
+			strings.Contains(n.Body, "📍 https://2gis.kz/rixos")
+	}), job).Run(func(args mock.Arguments) {
+		d, n := args.Get(0).(*domain.ReminderDelivery), args.Get(1).(*domain.Notification)
+		tokens = append(tokens, d.Token)
+		assert.Contains(t, n.Body, "https://card-go.test/r/stop/"+d.Token)
+	}).Return(true, nil).Once()
+	repo.On("CreateReminder", mock.MatchedBy(func(d *domain.ReminderDelivery) bool {
+		return d.PolicyID == 3 && d.Phone == "+77010000005"
+	}), mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Template == ReminderRSVP && strings.Contains(n.Body, "Please RSVP by Friday, August 7, 2026:") &&
+			strings.Contains(n.Body, "https://card-go.test/s/arman-aigerim")
+	}), job).Run(func(args mock.Arguments) {
+		tokens = append(tokens, args.Get(0).(*domain.ReminderDelivery).Token)
+	}).Return(true, nil).Once()
+
+	sent, err := u.SendDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	require.Len(t, tokens, 2)
+	assert.Len(t, tokens[0], 32)
+	assert.NotEqual(t, tokens[0], tokens[1])
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "RemindedPhones", int64(2))
+}
+
+func TestReminder_SendDueWaitsOutQuietHours(t *testing.T) {
+	repo, invRepo := new(MockReminderRepository), new(MockInvitationRepository)
+	u := newReminderUseCase(t, repo, invRepo, new(MockGuestRepository), almaty(t, time.August, 14, 23, 30))
+	repo.On("ActiveReminderPolicies").Return([]domain.ReminderPolicy{
+		{ID: 1, InvitationUUID: "inv-1", Anchor: domain.ReminderAnchorEvent, OffsetMinutes: -24 * 60, Audience: domain.ReminderAttending},
+	}, nil)
+	invRepo.On("GetByUUID", "inv-1").Return(remindInvitation, nil)
+
+	sent, err := u.SendDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	invRepo.AssertNotCalled(t, "GetRSVPs", mock.Anything)
+}
+
+func TestReminder_SendDueNeedsItsChannel(t *testing.T) {
+	repo, invRepo := new(MockReminderRepository), new(MockInvitationRepository)
+	notifications := newNotificationUseCase(new(MockNotificationRepository), invRepo, &fakeChannel{name: domain.ChannelEmail})
+	u, err := NewReminderUseCase(repo, invRepo, new(MockGuestRepository), notifications, "https://card-go.test", ReminderOptions{})
+	require.NoError(t, err)
+	u.now = func() time.Time { return almaty(t, time.August, 14, 18, 10) }
+	repo.On("ActiveReminderPolicies").Return([]domain.ReminderPolicy{
+		{ID: 1, InvitationUUID: "inv-1", Anchor: domain.ReminderAnchorEvent, OffsetMinutes: -24 * 60, Audience: domain.ReminderAttending},
+	}, nil)
+	invRepo.On("GetByUUID", "inv-1").Return(remindInvitation, nil)
+
+	sent, err := u.SendDue(context.Background())
+	assert.ErrorContains(t, err, "reminder channel whatsapp is not configured")
+	assert.Zero(t, sent)
+}
+
+func TestReminder_StopOptsOut(t *testing.T) {
+	repo, invRepo := new(MockReminderRepository), new(MockInvitationRepository)
+	u := newReminderUseCase(t, repo, invRepo, new(MockGuestRepository), time.Now())
+	repo.On("GetReminderByToken", "abc").Return(&domain.ReminderDelivery{InvitationUUID: "inv-1", Phone: "+77010000001"}, nil)
+	repo.On("GetReminderByToken", "nope").Return(nil, domain.ErrReminderTokenNotFound)
+	invRepo.On("GetByUUID", "inv-1").Return(remindInvitation, nil)
+	repo.On("AddReminderOptOut", "+77010000001").Return(nil)
+
+	stop, err := u.Stop("abc")
+	require.NoError(t, err)
+	assert.Equal(t, &ReminderStop{Phone: "+77010000001", Lang: "en"}, stop)
+	_, err = u.Stop("nope")
+	assert.ErrorIs(t, err, domain.ErrReminderTokenNotFound)
+	repo.AssertNumberOfCalls(t, "AddReminderOptOut", 1)
+
+	var input InputError
+	_, err = u.AddOptOut("call me")
+	assert.ErrorAs(t, err, &input)
+}
+
+func TestReminder_TemplatesRenderInEveryLanguage(t *testing.T) {
+	for _, name := range []string{ReminderRSVP, ReminderEvent} {
+		for _, lang := range []string{"ru", "kk", "en"} {
+			text, err := renderReminder(name, lang, ReminderData{NotificationData: newNotificationData(remindInvitation, lang, "https://card-go.test")})
+			require.NoError(t, err, name+"."+lang)
+			assert.NotContains(t, text, "<no value>")
+		}
+	}
+	assert.Equal(t, "today", relativeDay(almaty(t, time.August, 15, 18, 0), almaty(t, time.August, 15, 9, 0), "en"))
+	assert.Equal(t, "завтра", relativeDay(almaty(t, time.August, 15, 18, 0), almaty(t, time.August, 14, 23, 0), "ru"))
+	assert.Equal(t, "on August 15, 2026", relativeDay(almaty(t, time.August, 15, 18, 0), almaty(t, time.August, 10, 9, 0), "en"))
+}
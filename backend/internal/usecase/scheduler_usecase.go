@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+const (
+	// DefaultJobRunsLimit and maxJobRunsLimit bound the run history
+	// returned by JobRuns.
+	DefaultJobRunsLimit = 50
+	maxJobRunsLimit     = 500
+)
+
+// ErrJobRunning is returned by RunJob when the job is already running,
+// here or on another replica.
+var ErrJobRunning = errors.New("job is already running")
+
+// ScheduledJob is a job run on a cron schedule.
+type ScheduledJob struct {
+	Name        string
+	Description string
+	Schedule    string
+	// Enabled is the default until an admin sets the flag.
+	Enabled bool
+	// Run does the work and returns how many rows it changed.
+	Run func(ctx context.Context) (int, error)
+}
+
+// JobInfo describes a job and its last run for the admin.
+type JobInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Schedule    string         `json:"schedule"`
+	Enabled     bool           `json:"enabled"`
+	NextRun     *time.Time     `json:"nextRun"`
+	LastRun     *domain.JobRun `json:"lastRun"`
+}
+
+type scheduledJob struct {
+	ScheduledJob
+	schedule *CronSchedule
+}
+
+// enabled applies the admin's flag, if any, over the default.
+func (j *scheduledJob) enabled(flags map[string]bool) bool {
+	if enabled, ok := flags[j.Name]; ok {
+		return enabled
+	}
+	return j.Enabled
+}
+
+// SchedulerUseCase runs jobs on their schedules, in UTC. Every replica
+// ticks, but each run is recorded once per scheduled minute and a job
+// runs under a lock, so only one replica does the work.
+type SchedulerUseCase struct {
+	repo    domain.JobRepository
+	jobs    map[string]*scheduledJob
+	names   []string
+	wg      sync.WaitGroup
+	now     func() time.Time
+	timeout time.Duration
+}
+
+// NewSchedulerUseCase checks the job names and schedules.
+func NewSchedulerUseCase(repo domain.JobRepository, jobs ...ScheduledJob) (*SchedulerUseCase, error) {
+	u := &SchedulerUseCase{repo: repo, jobs: map[string]*scheduledJob{}, now: time.Now, timeout: 30 * time.Minute}
+	for _, j := range jobs {
+		if _, ok := u.jobs[j.Name]; ok || j.Name == "" {
+			return nil, fmt.Errorf("job name %q is empty or repeated", j.Name)
+		}
+		s, err := ParseCron(j.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", j.Name, err)
+		}
+		u.jobs[j.Name] = &scheduledJob{ScheduledJob: j, schedule: s}
+		u.names = append(u.names, j.Name)
+	}
+	sort.Strings(u.names)
+	return u, nil
+}
+
+// Run starts the jobs due each minute until ctx is done, then waits for
+// the runs in progress, which see ctx cancelled.
+func (u *SchedulerUseCase) Run(ctx context.Context) {
+	defer u.wg.Wait()
+	for {
+		now := u.now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		u.tick(ctx, next.UTC())
+	}
+}
+
+// tick starts the enabled jobs due in minute.
+func (u *SchedulerUseCase) tick(ctx context.Context, minute time.Time) {
+	var due []*scheduledJob
+	for _, name := range u.names {
+		if j := u.jobs[name]; j.schedule.Matches(minute) {
+			due = append(due, j)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+	flags, err := u.repo.JobFlags()
+	if err != nil {
+		log.Printf("jobs: reading flags: %v", err)
+		return
+	}
+	for _, j := range due {
+		if !j.enabled(flags) {
+			continue
+		}
+		u.wg.Add(1)
+		go func() {
+			defer u.wg.Done()
+			if _, err := u.run(ctx, j, minute, false); err != nil && !errors.Is(err, ErrJobRunning) {
+				log.Printf("jobs: %s: %v", j.Name, err)
+			}
+		}()
+	}
+}
+
+// run does one run of j under its lock. It returns nil and no error when
+// another replica already did the run due at scheduledFor.
+func (u *SchedulerUseCase) run(ctx context.Context, j *scheduledJob, scheduledFor time.Time, manual bool) (*domain.JobRun, error) {
+	unlock, ok, err := u.repo.TryJobLock(j.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrJobRunning
+	}
+	defer unlock()
+
+	run, started, err := u.repo.StartJobRun(j.Name, scheduledFor, manual)
+	if err != nil || !started {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	affected, err := u.safeRun(ctx, j)
+	run.Affected = affected
+	run.Status = domain.JobSucceeded
+	if err != nil {
+		run.Status, run.Error = domain.JobFailed, err.Error()
+		log.Printf("jobs: %s failed: %v", j.Name, err)
+	} else if affected > 0 {
+		log.Printf("jobs: %s changed %d rows", j.Name, affected)
+	}
+	if err := u.repo.FinishJobRun(run); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+// safeRun keeps a panicking job from taking the server down.
+func (u *SchedulerUseCase) safeRun(ctx context.Context, j *scheduledJob) (affected int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.Run(ctx)
+}
+
+// RunJob runs a job now, whether it is enabled or not, and returns the
+// finished run.
+func (u *SchedulerUseCase) RunJob(ctx context.Context, name string) (*domain.JobRun, error) {
+	j, ok := u.jobs[name]
+	if !ok {
+		return nil, domain.ErrJobNotFound
+	}
+	run, err := u.run(ctx, j, u.now().UTC().Truncate(time.Millisecond), true)
+	if err == nil && run == nil {
+		err = ErrJobRunning
+	}
+	return run, err
+}
+
+// Jobs lists the jobs with their flags and last runs, by name.
+func (u *SchedulerUseCase) Jobs() ([]JobInfo, error) {
+	flags, err := u.repo.JobFlags()
+	if err != nil {
+		return nil, err
+	}
+	last, err := u.repo.LastJobRuns()
+	if err != nil {
+		return nil, err
+	}
+	now := u.now().UTC()
+	list := make([]JobInfo, 0, len(u.names))
+	for _, name := range u.names {
+		j := u.jobs[name]
+		info := JobInfo{Name: name, Description: j.Description, Schedule: j.Schedule, Enabled: j.enabled(flags)}
+		if next := j.schedule.Next(now); info.Enabled && !next.IsZero() {
+			info.NextRun = &next
+		}
+		if run, ok := last[name]; ok {
+			info.LastRun = &run
+		}
+		list = append(list, info)
+	}
+	return list, nil
+}
+
+// SetJobEnabled turns a job on or off on every replica.
+func (u *SchedulerUseCase) SetJobEnabled(name string, enabled bool) error {
+	if _, ok := u.jobs[name]; !ok {
+		return domain.ErrJobNotFound
+	}
+	return u.repo.SetJobEnabled(name, enabled)
+}
+
+// JobRuns returns the latest runs of a job, newest first; limit 0 means
+// DefaultJobRunsLimit.
+func (u *SchedulerUseCase) JobRuns(name string, limit int) ([]domain.JobRun, error) {
+	if _, ok := u.jobs[name]; !ok {
+		return nil, domain.ErrJobNotFound
+	}
+	if limit == 0 {
+		limit = DefaultJobRunsLimit
+	}
+	if limit < 0 || limit > maxJobRunsLimit {
+		return nil, InputError(fmt.Sprintf("limit must be between 1 and %d", maxJobRunsLimit))
+	}
+	return u.repo.ListJobRuns(name, limit)
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var tenPast = time.Date(2026, 5, 1, 12, 10, 0, 0, time.UTC)
+
+func countingJob(name, schedule string, enabled bool, runs *int, err error) ScheduledJob {
+	return ScheduledJob{Name: name, Schedule: schedule, Enabled: enabled, Run: func(context.Context) (int, error) {
+		*runs++
+		return 3, err
+	}}
+}
+
+func TestScheduler_TickRunsDueEnabledJobs(t *testing.T) {
+	repo := new(MockJobRepository)
+	var due, notDue, disabled, switchedOff int
+	uc, err := NewSchedulerUseCase(repo,
+		countingJob("due", "*/5 * * * *", true, &due, nil),
+		countingJob("not-due", "*/7 * * * *", true, &notDue, nil),
+		countingJob("disabled", "*/5 * * * *", false, &disabled, nil),
+		countingJob("switched-off", "*/5 * * * *", true, &switchedOff, nil),
+	)
+	require.NoError(t, err)
+
+	repo.On("JobFlags").Return(map[string]bool{"switched-off": false}, nil)
+	repo.On("TryJobLock", "due").Return(true, nil)
+	repo.On("StartJobRun", "due", tenPast, false).Return(&domain.JobRun{ID: 1, Job: "due"}, true, nil)
+	repo.On("FinishJobRun", mock.MatchedBy(func(run *domain.JobRun) bool {
+		return run.Status == domain.JobSucceeded && run.Affected == 3
+	})).Return(nil)
+
+	uc.tick(context.Background(), tenPast)
+	uc.wg.Wait()
+	assert.Equal(t, []int{1, 0, 0, 0}, []int{due, notDue, disabled, switchedOff})
+	repo.AssertExpectations(t)
+}
+
+func TestScheduler_RunsOncePerMinuteAcrossReplicas(t *testing.T) {
+	repo := new(MockJobRepository)
+	var runs int
+	uc, err := NewSchedulerUseCase(repo, countingJob("job", "* * * * *", true, &runs, nil))
+	require.NoError(t, err)
+
+	// Another replica holds the lock, or already did this minute's run.
+	repo.On("JobFlags").Return(map[string]bool{}, nil)
+	repo.On("TryJobLock", "job").Return(false, nil).Once()
+	repo.On("TryJobLock", "job").Return(true, nil)
+	repo.On("StartJobRun", "job", tenPast, false).Return(nil, false, nil)
+
+	uc.tick(context.Background(), tenPast)
+	uc.tick(context.Background(), tenPast)
+	uc.wg.Wait()
+	assert.Zero(t, runs)
+	repo.AssertNotCalled(t, "FinishJobRun", mock.Anything)
+}
+
+func TestScheduler_RecordsFailures(t *testing.T) {
+	repo := new(MockJobRepository)
+	uc, err := NewSchedulerUseCase(repo, ScheduledJob{Name: "boom", Schedule: "@daily", Run: func(context.Context) (int, error) {
+		panic("nil map")
+	}})
+	require.NoError(t, err)
+	uc.now = func() time.Time { return tenPast }
+
+	repo.On("TryJobLock", "boom").Return(true, nil)
+	repo.On("StartJobRun", "boom", tenPast, true).Return(&domain.JobRun{ID: 1, Job: "boom"}, true, nil)
+	repo.On("FinishJobRun", mock.Anything).Return(nil)
+
+	// A manual run ignores the enable flag.
+	run, err := uc.RunJob(context.Background(), "boom")
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobFailed, run.Status)
+	assert.Equal(t, "panic: nil map", run.Error)
+
+	_, err = uc.RunJob(context.Background(), "nope")
+	assert.ErrorIs(t, err, domain.ErrJobNotFound)
+}
+
+func TestScheduler_Jobs(t *testing.T) {
+	repo := new(MockJobRepository)
+	var runs int
+	uc, err := NewSchedulerUseCase(repo,
+		countingJob("b-job", "0 * * * *", true, &runs, nil),
+		countingJob("a-job", "0 * * * *", false, &runs, errors.New("x")),
+	)
+	require.NoError(t, err)
+	uc.now = func() time.Time { return tenPast }
+
+	repo.On("JobFlags").Return(map[string]bool{"a-job": true}, nil)
+	repo.On("LastJobRuns").Return(map[string]domain.JobRun{"b-job": {ID: 9, Job: "b-job"}}, nil)
+
+	list, err := uc.Jobs()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "a-job", list[0].Name)
+	assert.True(t, list[0].Enabled)
+	assert.Nil(t, list[0].LastRun)
+	assert.Equal(t, time.Date(2026, 5, 1, 13, 0, 0, 0, time.UTC), *list[1].NextRun)
+	assert.Equal(t, int64(9), list[1].LastRun.ID)
+
+	assert.ErrorIs(t, uc.SetJobEnabled("c-job", true), domain.ErrJobNotFound)
+	var input InputError
+	_, err = uc.JobRuns("a-job", 1000)
+	assert.ErrorAs(t, err, &input)
+
+	_, err = NewSchedulerUseCase(repo, countingJob("x", "* * *", true, &runs, nil))
+	assert.Error(t, err)
+	_, err = NewSchedulerUseCase(repo, countingJob("x", "@daily", true, &runs, nil), countingJob("x", "@daily", true, &runs, nil))
+	assert.Error(t, err)
+}
+
+func TestLifecycleJobs(t *testing.T) {
+	repo := new(MockLifecycleRepository)
+	jobs := LifecycleJobs(repo, 0)
+	uc, err := NewSchedulerUseCase(new(MockJobRepository), jobs...)
+	require.NoError(t, err)
+	require.NotNil(t, uc)
+
+	repo.On("ExpireInvitations", mock.Anything).Return(2, nil)
+	repo.On("MarkPostEvent", mock.MatchedBy(func(day time.Time) bool {
+		return day.Equal(day.Truncate(24*time.Hour)) && time.Since(day) >= 24*time.Hour
+	})).Return(1, nil)
+	repo.On("PurgeExpiredTrials", mock.MatchedBy(func(cutoff time.Time) bool {
+		return time.Since(cutoff) >= DefaultTrialRetention
+	})).Return(0, nil)
+
+	enabled := map[string]bool{}
+	for _, j := range jobs {
+		_, err := j.Run(context.Background())
+		assert.NoError(t, err, j.Name)
+		enabled[j.Name] = j.Enabled
+	}
+	assert.Equal(t, map[string]bool{"expire-invitations": true, "post-event": true, "purge-expired-trials": false}, enabled)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// DefaultShortCodeAlphabet leaves out characters that are easy to
+	// misread or mistype from a printed card: 0/O/o, 1/l/I and i.
+	DefaultShortCodeAlphabet = "23456789abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+	DefaultShortCodeLength   = 6
+
+	minShortCodeLength = 4
+	// maxShortCodeLength matches invitations.short_code.
+	maxShortCodeLength = 64
+	minSlugLength      = 3
+	// maxShortCodeAttempts bounds retries after a collision. With the
+	// default alphabet and length there are 5e10 codes, so a second try is
+	// already rare.
+	maxShortCodeAttempts = 5
+)
+
+// reservedSlugs cannot be requested as vanity short links: they are routes
+// of the site, or would read as official pages of the service.
+var reservedSlugs = map[string]bool{
+	"admin": true, "api": true, "assets": true, "images": true, "static": true,
+	"s": true, "i": true, "login": true, "logout": true, "health": true,
+	"favicon": true, "robots": true, "sitemap": true, "index": true,
+	"help": true, "support": true, "about": true, "contact": true,
+	"pay": true, "payment": true, "billing": true, "terms": true, "privacy": true,
+	"www": true, "mail": true, "test": true, "demo": true, "null": true, "undefined": true,
+	"card-go": true, "cardgo": true,
+}
+
+// ShortCodeGenerator allocates random short codes. The zero value uses
+// DefaultShortCodeLength and DefaultShortCodeAlphabet.
+type ShortCodeGenerator struct {
+	Length   int
+	Alphabet string
+}
+
+// Validate checks a configured generator.
+func (g ShortCodeGenerator) Validate() error {
+	if g.Length != 0 && (g.Length < minShortCodeLength || g.Length > maxShortCodeLength) {
+		return fmt.Errorf("short code length must be between %d and %d", minShortCodeLength, maxShortCodeLength)
+	}
+	if g.Alphabet == "" {
+		return nil
+	}
+	seen := map[rune]bool{}
+	for _, r := range g.Alphabet {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return fmt.Errorf("short code alphabet may only contain ASCII letters and digits, got %q", r)
+		}
+		if seen[r] {
+			return fmt.Errorf("short code alphabet repeats %q", r)
+		}
+		seen[r] = true
+	}
+	if len(seen) < 16 {
+		return fmt.Errorf("short code alphabet needs at least 16 characters")
+	}
+	return nil
+}
+
+// Generate returns a uniformly random code that is not a reserved word.
+func (g ShortCodeGenerator) Generate() string {
+	length, alphabet := g.Length, g.Alphabet
+	if length == 0 {
+		length = DefaultShortCodeLength
+	}
+	if alphabet == "" {
+		alphabet = DefaultShortCodeAlphabet
+	}
+	max := big.NewInt(int64(len(alphabet)))
+	b := make([]byte, length)
+	for {
+		for i := range b {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				// crypto/rand does not fail on supported platforms.
+				panic(err)
+			}
+			b[i] = alphabet[n.Int64()]
+		}
+		if code := string(b); !reservedSlugs[strings.ToLower(code)] {
+			return code
+		}
+	}
+}
+
+// NormalizeSlug lower-cases a requested vanity short link and checks that it
+// is 3-64 letters, digits and single hyphens, and not a reserved word.
+func NormalizeSlug(raw string) (string, error) {
+	slug := strings.ToLower(strings.TrimSpace(raw))
+	if len(slug) < minSlugLength || len(slug) > maxShortCodeLength {
+		return slug, InputError(fmt.Sprintf("shortCode must be %d to %d characters", minSlugLength, maxShortCodeLength))
+	}
+	for _, r := range slug {
+		if !slugRune(r) {
+			return slug, InputError("shortCode may only contain latin letters, digits and hyphens")
+		}
+	}
+	if slug[0] == '-' || slug[len(slug)-1] == '-' || strings.Contains(slug, "--") {
+		return slug, InputError("shortCode cannot start or end with a hyphen or repeat it")
+	}
+	if reservedSlugs[slug] {
+		return slug, InputError(fmt.Sprintf("shortCode %q is reserved", slug))
+	}
+	return slug, nil
+}
+
+func slugRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-'
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortCodeGenerator(t *testing.T) {
+	code := ShortCodeGenerator{}.Generate()
+	assert.Len(t, code, DefaultShortCodeLength)
+	for _, r := range code {
+		assert.Contains(t, DefaultShortCodeAlphabet, string(r))
+	}
+	assert.NotContains(t, DefaultShortCodeAlphabet, "0")
+	assert.NotContains(t, DefaultShortCodeAlphabet, "O")
+	assert.NotContains(t, DefaultShortCodeAlphabet, "l")
+	assert.NotContains(t, DefaultShortCodeAlphabet, "I")
+
+	g := ShortCodeGenerator{Length: 8, Alphabet: "abcdefghjkmnpqrs"}
+	assert.NoError(t, g.Validate())
+	code = g.Generate()
+	assert.Len(t, code, 8)
+	assert.Empty(t, strings.Trim(code, g.Alphabet))
+
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		seen[ShortCodeGenerator{}.Generate()] = true
+	}
+	assert.Len(t, seen, 1000)
+}
+
+func TestShortCodeGeneratorValidate(t *testing.T) {
+	assert.NoError(t, ShortCodeGenerator{}.Validate())
+	assert.Error(t, ShortCodeGenerator{Length: 3}.Validate())
+	assert.Error(t, ShortCodeGenerator{Length: 65}.Validate())
+	assert.Error(t, ShortCodeGenerator{Alphabet: "abc"}.Validate())
+	assert.Error(t, ShortCodeGenerator{Alphabet: "abcdefghjkmnpqr-"}.Validate())
+	assert.Error(t, ShortCodeGenerator{Alphabet: "aacdefghjkmnpqrs"}.Validate())
+}
+
+func TestNormalizeSlug(t *testing.T) {
+	slug, err := NormalizeSlug("  Arman-Aigerim ")
+	assert.NoError(t, err)
+	assert.Equal(t, "arman-aigerim", slug)
+
+	for _, raw := range []string{"ab", "арман", "arman_aigerim", "-arman", "arman-", "arman--aigerim", "admin", "API", strings.Repeat("a", 65)} {
+		_, err := NormalizeSlug(raw)
+		var input InputError
+		assert.ErrorAs(t, err, &input, raw)
+	}
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTrial is how long an unpaid invitation stays online unless
+	// configured otherwise.
+	DefaultTrial = time.Hour
+	// maxTrial bounds configured trials and admin extensions alike.
+	maxTrial = 366 * 24 * time.Hour
+)
+
+// TrialPolicy sets how long a new unpaid invitation stays online. The zero
+// value gives every template DefaultTrial.
+type TrialPolicy struct {
+	Default time.Duration
+	// Templates overrides Default by template code.
+	Templates map[string]time.Duration
+}
+
+// ParseTrialPolicy reads a default duration such as "24h" and overrides
+// such as "silk-ivory=48h,classic=2h". Empty strings keep the defaults.
+func ParseTrialPolicy(def, overrides string) (TrialPolicy, error) {
+	var p TrialPolicy
+	if def != "" {
+		d, err := parseTrial(def)
+		if err != nil {
+			return p, err
+		}
+		p.Default = d
+	}
+	for _, item := range strings.Split(overrides, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(item, "=")
+		code = strings.TrimSpace(code)
+		if !ok || code == "" {
+			return p, fmt.Errorf("trial override must be template=duration, got %q", item)
+		}
+		d, err := parseTrial(strings.TrimSpace(value))
+		if err != nil {
+			return p, fmt.Errorf("%s: %w", code, err)
+		}
+		if p.Templates == nil {
+			p.Templates = map[string]time.Duration{}
+		}
+		p.Templates[code] = d
+	}
+	return p, nil
+}
+
+func parseTrial(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid trial duration %q", s)
+	}
+	if d <= 0 || d > maxTrial {
+		return 0, fmt.Errorf("trial duration must be positive and at most %s, got %s", maxTrial, d)
+	}
+	return d, nil
+}
+
+// For returns the trial length of a template.
+func (p TrialPolicy) For(templateCode string) time.Duration {
+	if d, ok := p.Templates[templateCode]; ok {
+		return d
+	}
+	if p.Default > 0 {
+		return p.Default
+	}
+	return DefaultTrial
+}
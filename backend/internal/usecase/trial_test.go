@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrialPolicy(t *testing.T) {
+	assert.Equal(t, DefaultTrial, TrialPolicy{}.For("silk-ivory"))
+
+	p, err := ParseTrialPolicy("24h", " silk-ivory=48h, starry-night = 2h ,")
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, p.For("classic"))
+	assert.Equal(t, 48*time.Hour, p.For("silk-ivory"))
+	assert.Equal(t, 2*time.Hour, p.For("starry-night"))
+
+	for _, c := range [][2]string{
+		{"forever", ""},
+		{"-1h", ""},
+		{"", "silk-ivory"},
+		{"", "=1h"},
+		{"", "silk-ivory=0s"},
+		{"", "silk-ivory=9000h"},
+	} {
+		_, err := ParseTrialPolicy(c[0], c[1])
+		assert.Error(t, err, c)
+	}
+}
@@ -0,0 +1,315 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+)
+
+const (
+	// WebhookDeliveryKind is the queue job kind that makes one attempt of
+	// a webhook delivery; the queue retries it with backoff.
+	WebhookDeliveryKind = "webhook.deliver"
+	// DefaultWebhookDeliveriesLimit and maxWebhookDeliveriesLimit bound
+	// the delivery log returned by Deliveries.
+	DefaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 500
+	// DefaultWebhookRetention is how long relayed events and their
+	// deliveries are kept.
+	DefaultWebhookRetention = 30 * 24 * time.Hour
+	// webhookDispatchBatch is how many outbox events one relay pass takes.
+	webhookDispatchBatch = 100
+	minWebhookSecret     = 16
+	maxWebhookResponse   = 1024
+)
+
+// Headers of a webhook delivery, besides the signature ones the sender
+// adds.
+const (
+	WebhookEventHeader    = "X-Webhook-Event"
+	WebhookDeliveryHeader = "X-Webhook-Delivery"
+)
+
+// WebhookSender makes one attempt of a delivery: it posts body to url,
+// signed with secret, and returns the HTTP status, if any, and the start
+// of the answer.
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret string, header http.Header, body []byte) (status int, response string, err error)
+}
+
+// WebhookPayload is the JSON body of a delivery. ID is the event's, the
+// same on every delivery and redelivery of it, so receivers can drop
+// repeats.
+type WebhookPayload struct {
+	ID        int64           `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookInput is a subscription as the admin sends it. Active defaults
+// to true, and an empty Secret is generated on create and kept on update.
+type WebhookInput struct {
+	URL         string   `json:"url" binding:"required"`
+	Events      []string `json:"events" binding:"required"`
+	Active      *bool    `json:"active"`
+	Description string   `json:"description"`
+	Secret      string   `json:"secret"`
+}
+
+type webhookDeliveryJob struct {
+	DeliveryID int64 `json:"deliveryId"`
+}
+
+// WebhookUseCase manages the webhook subscriptions and sends them the
+// events written to the outbox: Run relays new events into deliveries, and
+// the queue makes and retries each one.
+type WebhookUseCase struct {
+	repo   domain.WebhookRepository
+	queue  *QueueUseCase
+	sender WebhookSender
+	poll   time.Duration
+}
+
+// NewWebhookUseCase registers the delivery handler on queue.
+func NewWebhookUseCase(repo domain.WebhookRepository, queue *QueueUseCase, sender WebhookSender) *WebhookUseCase {
+	u := &WebhookUseCase{repo: repo, queue: queue, sender: sender, poll: time.Second}
+	HandleQueue(queue, WebhookDeliveryKind, u.deliver)
+	return u
+}
+
+func (u *WebhookUseCase) deliveryJob() domain.QueueJob {
+	return domain.QueueJob{Kind: WebhookDeliveryKind, MaxAttempts: u.queue.opts.MaxAttempts}
+}
+
+// Run relays outbox events into deliveries until ctx is done.
+func (u *WebhookUseCase) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.poll)
+	defer ticker.Stop()
+	for {
+		u.dispatch()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch relays events until the outbox is empty.
+func (u *WebhookUseCase) dispatch() {
+	for {
+		n, err := u.repo.DispatchWebhookEvents(webhookDispatchBatch, u.deliveryJob())
+		if err != nil {
+			log.Printf("webhooks: relaying events: %v", err)
+			return
+		}
+		if n > 0 {
+			u.queue.notify()
+		}
+		if n < webhookDispatchBatch {
+			return
+		}
+	}
+}
+
+// deliver makes one attempt of a delivery and records it. Any failure is
+// retried by the queue, except for a disabled or deleted subscription.
+func (u *WebhookUseCase) deliver(ctx context.Context, job webhookDeliveryJob) error {
+	d, sub, ev, err := u.repo.GetWebhookDelivery(job.DeliveryID)
+	if errors.Is(err, domain.ErrWebhookDeliveryNotFound) || errors.Is(err, domain.ErrWebhookNotFound) {
+		return Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+	if d.DeliveredAt != nil {
+		// A worker that died after sending; the receiver has it.
+		return nil
+	}
+
+	d.ResponseStatus, d.ResponseBody, d.Error = nil, "", ""
+	if !sub.Active {
+		d.Error = "subscription is disabled"
+		if err := u.repo.RecordWebhookAttempt(d); err != nil {
+			return err
+		}
+		return Permanent(errors.New(d.Error))
+	}
+
+	body, err := json.Marshal(WebhookPayload{ID: ev.ID, Event: ev.Event, CreatedAt: ev.CreatedAt, Data: ev.Data})
+	if err != nil {
+		return Permanent(err)
+	}
+	header := http.Header{}
+	header.Set(WebhookEventHeader, ev.Event)
+	header.Set(WebhookDeliveryHeader, fmt.Sprint(d.ID))
+	status, response, err := u.sender.Send(ctx, sub.URL, sub.Secret, header, body)
+	if status != 0 {
+		d.ResponseStatus = &status
+	}
+	if len(response) > maxWebhookResponse {
+		response = response[:maxWebhookResponse]
+	}
+	d.ResponseBody = strings.ToValidUTF8(response, "")
+	switch {
+	case err != nil:
+		d.Error = err.Error()
+	case status < 200 || status >= 300:
+		d.Error = fmt.Sprintf("endpoint answered %d", status)
+	default:
+		d.Status = domain.WebhookSucceeded
+	}
+	if err := u.repo.RecordWebhookAttempt(d); err != nil {
+		log.Printf("webhooks: recording delivery %d: %v", d.ID, err)
+	}
+	if d.Status == domain.WebhookSucceeded {
+		return nil
+	}
+	return errors.New(d.Error)
+}
+
+func (u *WebhookUseCase) Webhooks() ([]domain.WebhookSubscription, error) {
+	return u.repo.ListWebhooks()
+}
+
+func (u *WebhookUseCase) Webhook(id int64) (*domain.WebhookSubscription, error) {
+	return u.repo.GetWebhook(id)
+}
+
+// CreateWebhook adds a subscription; it gets events from now on.
+func (u *WebhookUseCase) CreateWebhook(in WebhookInput) (*domain.WebhookSubscription, error) {
+	sub := &domain.WebhookSubscription{}
+	if err := applyWebhookInput(sub, in); err != nil {
+		return nil, err
+	}
+	if sub.Secret == "" {
+		sub.Secret = newWebhookSecret()
+	}
+	if err := u.repo.CreateWebhook(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// UpdateWebhook replaces a subscription. Deliveries already queued go to
+// the new URL with the new secret.
+func (u *WebhookUseCase) UpdateWebhook(id int64, in WebhookInput) (*domain.WebhookSubscription, error) {
+	sub, err := u.repo.GetWebhook(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyWebhookInput(sub, in); err != nil {
+		return nil, err
+	}
+	if err := u.repo.UpdateWebhook(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (u *WebhookUseCase) DeleteWebhook(id int64) error {
+	return u.repo.DeleteWebhook(id)
+}
+
+func applyWebhookInput(sub *domain.WebhookSubscription, in WebhookInput) error {
+	link, err := url.Parse(strings.TrimSpace(in.URL))
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return InputError("url must be an absolute http or https URL")
+	}
+	var events []string
+	for _, ev := range in.Events {
+		if !slices.Contains(domain.WebhookEvents, ev) {
+			return InputError(fmt.Sprintf("unknown event %q, expected one of %s", ev, strings.Join(domain.WebhookEvents, ", ")))
+		}
+		if !slices.Contains(events, ev) {
+			events = append(events, ev)
+		}
+	}
+	if len(events) == 0 {
+		return InputError("at least one event is required")
+	}
+	if len(in.Description) > 255 {
+		return InputError("description must be at most 255 characters")
+	}
+	if in.Secret != "" && len(in.Secret) < minWebhookSecret {
+		return InputError(fmt.Sprintf("secret must be at least %d characters", minWebhookSecret))
+	}
+
+	sub.URL, sub.Events, sub.Description = link.String(), events, in.Description
+	sub.Active = in.Active == nil || *in.Active
+	if in.Secret != "" {
+		sub.Secret = in.Secret
+	}
+	return nil
+}
+
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	// crypto/rand does not fail on supported platforms.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Deliveries returns the delivery log of a subscription, newest first;
+// limit 0 means DefaultWebhookDeliveriesLimit.
+func (u *WebhookUseCase) Deliveries(id int64, limit int) ([]domain.WebhookDelivery, error) {
+	if limit == 0 {
+		limit = DefaultWebhookDeliveriesLimit
+	}
+	if limit < 0 || limit > maxWebhookDeliveriesLimit {
+		return nil, InputError(fmt.Sprintf("limit must be between 1 and %d", maxWebhookDeliveriesLimit))
+	}
+	if _, err := u.repo.GetWebhook(id); err != nil {
+		return nil, err
+	}
+	return u.repo.ListWebhookDeliveries(id, limit)
+}
+
+// Redeliver sends the event of a delivery of subscription id again, as a
+// new delivery.
+func (u *WebhookUseCase) Redeliver(id, deliveryID int64) (*domain.WebhookDelivery, error) {
+	d, _, _, err := u.repo.GetWebhookDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if d.SubscriptionID != id {
+		return nil, domain.ErrWebhookDeliveryNotFound
+	}
+	d, err = u.repo.RedeliverWebhook(deliveryID, u.deliveryJob())
+	if err != nil {
+		return nil, err
+	}
+	u.queue.notify()
+	return d, nil
+}
+
+// ScheduledJobs are the housekeeping jobs of the webhooks: relayed events
+// are deleted with their deliveries once older than retention.
+func (u *WebhookUseCase) ScheduledJobs(retention time.Duration) []ScheduledJob {
+	if retention <= 0 {
+		retention = DefaultWebhookRetention
+	}
+	return []ScheduledJob{
+		{
+			Name:        "purge-webhook-events",
+			Description: "Delete relayed webhook events and their delivery log past the retention period",
+			Schedule:    "50 3 * * *",
+			Enabled:     true,
+			Run: func(context.Context) (int, error) {
+				return u.repo.PurgeWebhookEvents(time.Now().Add(-retention))
+			},
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWebhookSender struct {
+	status   int
+	response string
+	err      error
+	calls    []fakeWebhookCall
+}
+
+type fakeWebhookCall struct {
+	url, secret string
+	header      http.Header
+	body        []byte
+}
+
+func (s *fakeWebhookSender) Send(_ context.Context, url, secret string, header http.Header, body []byte) (int, string, error) {
+	s.calls = append(s.calls, fakeWebhookCall{url, secret, header, body})
+	return s.status, s.response, s.err
+}
+
+func newWebhookUseCase(repo *MockWebhookRepository, sender WebhookSender) *WebhookUseCase {
+	return NewWebhookUseCase(repo, NewQueueUseCase(new(MockQueueRepository), QueueOptions{MaxAttempts: 6}), sender)
+}
+
+var (
+	testSub   = &domain.WebhookSubscription{ID: 1, URL: "https://n8n.example/webhook/rsvp", Secret: "0123456789abcdef", Active: true}
+	testEvent = &domain.WebhookEvent{ID: 40, Event: domain.WebhookRSVPSubmitted,
+		Data: json.RawMessage(`{"rsvp":{"guestName":"Aru"}}`), CreatedAt: time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)}
+)
+
+func TestWebhook_DeliverSendsAndRecords(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := &fakeWebhookSender{status: http.StatusOK, response: "ok"}
+	u := newWebhookUseCase(repo, sender)
+
+	repo.On("GetWebhookDelivery", int64(7)).Return(&domain.WebhookDelivery{ID: 7, SubscriptionID: 1, EventID: 40}, testSub, testEvent, nil)
+	repo.On("RecordWebhookAttempt", mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+		return d.Status == domain.WebhookSucceeded && *d.ResponseStatus == 200 && d.ResponseBody == "ok" && d.Error == ""
+	})).Return(nil)
+
+	require.NoError(t, u.deliver(context.Background(), webhookDeliveryJob{DeliveryID: 7}))
+	require.Len(t, sender.calls, 1)
+	call := sender.calls[0]
+	assert.Equal(t, testSub.URL, call.url)
+	assert.Equal(t, testSub.Secret, call.secret)
+	assert.Equal(t, domain.WebhookRSVPSubmitted, call.header.Get(WebhookEventHeader))
+	assert.Equal(t, "7", call.header.Get(WebhookDeliveryHeader))
+	assert.JSONEq(t, `{"id":40,"event":"rsvp.submitted","createdAt":"2026-05-01T10:00:00Z","data":{"rsvp":{"guestName":"Aru"}}}`, string(call.body))
+	repo.AssertExpectations(t)
+}
+
+func TestWebhook_DeliverFailuresAreRetried(t *testing.T) {
+	for _, sender := range []*fakeWebhookSender{
+		{status: http.StatusBadGateway, response: "upstream down"},
+		{err: errors.New("connection refused")},
+	} {
+		repo := new(MockWebhookRepository)
+		u := newWebhookUseCase(repo, sender)
+		repo.On("GetWebhookDelivery", int64(7)).Return(&domain.WebhookDelivery{ID: 7}, testSub, testEvent, nil)
+		repo.On("RecordWebhookAttempt", mock.MatchedBy(func(d *domain.WebhookDelivery) bool {
+			return d.Status != domain.WebhookSucceeded && d.Error != ""
+		})).Return(nil)
+
+		err := u.deliver(context.Background(), webhookDeliveryJob{DeliveryID: 7})
+		require.Error(t, err)
+		assert.False(t, IsPermanent(err))
+		repo.AssertExpectations(t)
+	}
+}
+
+func TestWebhook_DeliverToDisabledOrGoneIsPermanent(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	sender := &fakeWebhookSender{status: http.StatusOK}
+	u := newWebhookUseCase(repo, sender)
+	disabled := *testSub
+	disabled.Active = false
+	repo.On("GetWebhookDelivery", int64(7)).Return(&domain.WebhookDelivery{ID: 7}, &disabled, testEvent, nil)
+	repo.On("GetWebhookDelivery", int64(8)).Return(nil, nil, nil, domain.ErrWebhookDeliveryNotFound)
+	repo.On("RecordWebhookAttempt", mock.Anything).Return(nil)
+
+	assert.True(t, IsPermanent(u.deliver(context.Background(), webhookDeliveryJob{DeliveryID: 7})))
+	assert.True(t, IsPermanent(u.deliver(context.Background(), webhookDeliveryJob{DeliveryID: 8})))
+	assert.Empty(t, sender.calls)
+}
+
+func TestWebhook_DispatchDrainsOutbox(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	u := newWebhookUseCase(repo, nil)
+	job := domain.QueueJob{Kind: WebhookDeliveryKind, MaxAttempts: 6}
+	repo.On("DispatchWebhookEvents", webhookDispatchBatch, job).Return(webhookDispatchBatch, nil).Once()
+	repo.On("DispatchWebhookEvents", webhookDispatchBatch, job).Return(3, nil).Once()
+
+	u.dispatch()
+	repo.AssertExpectations(t)
+}
+
+func TestWebhook_CreateValidates(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	u := newWebhookUseCase(repo, nil)
+	repo.On("CreateWebhook", mock.Anything).Return(nil)
+
+	sub, err := u.CreateWebhook(WebhookInput{URL: "https://n8n.example/hook", Events: []string{"rsvp.submitted", "rsvp.submitted"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rsvp.submitted"}, sub.Events)
+	assert.True(t, sub.Active)
+	assert.Len(t, sub.Secret, 64)
+
+	var input InputError
+	for _, in := range []WebhookInput{
+		{URL: "ftp://n8n.example", Events: []string{"rsvp.submitted"}},
+		{URL: "/relative", Events: []string{"rsvp.submitted"}},
+		{URL: "https://n8n.example", Events: []string{"rsvp.deleted"}},
+		{URL: "https://n8n.example", Events: []string{}},
+		{URL: "https://n8n.example", Events: []string{"rsvp.submitted"}, Secret: "short"},
+	} {
+		_, err := u.CreateWebhook(in)
+		assert.ErrorAs(t, err, &input, in)
+	}
+}
+
+func TestWebhook_UpdateKeepsSecret(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	u := newWebhookUseCase(repo, nil)
+	current := *testSub
+	repo.On("GetWebhook", int64(1)).Return(&current, nil)
+	repo.On("UpdateWebhook", mock.Anything).Return(nil)
+
+	off := false
+	sub, err := u.UpdateWebhook(1, WebhookInput{URL: "https://n8n.example/v2", Events: []string{"invitation.paid"}, Active: &off})
+	require.NoError(t, err)
+	assert.Equal(t, testSub.Secret, sub.Secret)
+	assert.False(t, sub.Active)
+	assert.Equal(t, "https://n8n.example/v2", sub.URL)
+}
+
+func TestWebhook_RedeliverChecksSubscription(t *testing.T) {
+	repo := new(MockWebhookRepository)
+	u := newWebhookUseCase(repo, nil)
+	repo.On("GetWebhookDelivery", int64(7)).Return(&domain.WebhookDelivery{ID: 7, SubscriptionID: 1}, testSub, testEvent, nil)
+	repo.On("RedeliverWebhook", int64(7), domain.QueueJob{Kind: WebhookDeliveryKind, MaxAttempts: 6}).
+		Return(&domain.WebhookDelivery{ID: 9, SubscriptionID: 1, RedeliveryOf: new(int64)}, nil)
+
+	d, err := u.Redeliver(1, 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), d.ID)
+
+	_, err = u.Redeliver(2, 7)
+	assert.ErrorIs(t, err, domain.ErrWebhookDeliveryNotFound)
+}
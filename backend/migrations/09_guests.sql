@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS guests (
+    id SERIAL PRIMARY KEY,
+    invitation_uuid UUID NOT NULL REFERENCES invitations (uuid) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(20) NOT NULL DEFAULT '',
+    party_size INTEGER NOT NULL DEFAULT 1,
+    group_name VARCHAR(100) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_guests_invitation ON guests (invitation_uuid);
+
+-- A phone number appears at most once per roster; guests without a phone
+-- are told apart by name in the importer.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guests_invitation_phone ON guests (invitation_uuid, phone)
+WHERE
+    phone <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS guests;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Vanity short links such as /s/arman-aigerim are longer than random codes.
+ALTER TABLE invitations ALTER COLUMN short_code TYPE VARCHAR(64);
+-- +goose StatementEnd
+
+-- +goose Down
+-- Fails while slugs longer than 10 characters exist.
+-- +goose StatementBegin
+ALTER TABLE invitations ALTER COLUMN short_code TYPE VARCHAR(10);
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per short link redirect. visitor_hash is a keyed hash of the IP
+-- address; the address itself is never stored.
+CREATE TABLE IF NOT EXISTS short_link_clicks (
+    id BIGSERIAL PRIMARY KEY,
+    invitation_uuid UUID NOT NULL REFERENCES invitations (uuid) ON DELETE CASCADE,
+    short_code VARCHAR(64) NOT NULL,
+    clicked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    visitor_hash CHAR(32) NOT NULL,
+    ua_class VARCHAR(16) NOT NULL,
+    referrer VARCHAR(255) NOT NULL DEFAULT '',
+    country CHAR(2) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_short_link_clicks_invitation ON short_link_clicks (invitation_uuid, clicked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS short_link_clicks;
+-- +goose StatementEnd
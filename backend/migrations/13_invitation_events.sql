@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Beacon calls of the invitation page: a view, or a section scrolled into
+-- sight. visitor_hash is keyed like short_link_clicks.visitor_hash.
+CREATE TABLE IF NOT EXISTS invitation_events (
+    id BIGSERIAL PRIMARY KEY,
+    invitation_uuid UUID NOT NULL REFERENCES invitations (uuid) ON DELETE CASCADE,
+    event VARCHAR(16) NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    visitor_hash CHAR(32) NOT NULL,
+    ua_class VARCHAR(16) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitation_events_invitation ON invitation_events (invitation_uuid, event);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS invitation_events;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The admin analytics aggregate invitations and RSVPs by creation date.
+CREATE INDEX IF NOT EXISTS idx_invitations_created_at ON invitations (created_at);
+
+CREATE INDEX IF NOT EXISTS idx_rsvp_responses_created_at ON rsvp_responses (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_rsvp_responses_created_at;
+
+DROP INDEX IF EXISTS idx_invitations_created_at;
+-- +goose StatementEnd
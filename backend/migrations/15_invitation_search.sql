@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- event_date is free text, so the admin list filters and sorts on the day
+-- parsed from it. New rows get it from the server; old ones are parsed here
+-- for the two shapes in use, leaving anything unparseable NULL.
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS event_on DATE;
+
+DO $$
+DECLARE
+    r RECORD;
+BEGIN
+    FOR r IN SELECT id, event_date FROM invitations WHERE event_on IS NULL AND event_date <> '' LOOP
+        BEGIN
+            IF r.event_date ~ '^\d{4}-\d{2}-\d{2}' THEN
+                UPDATE invitations SET event_on = to_date(substr(r.event_date, 1, 10), 'YYYY-MM-DD') WHERE id = r.id;
+            ELSIF r.event_date ~ '^\d{2}\.\d{2}\.\d{4}' THEN
+                UPDATE invitations SET event_on = to_date(substr(r.event_date, 1, 10), 'DD.MM.YYYY') WHERE id = r.id;
+            END IF;
+        EXCEPTION WHEN others THEN
+            NULL;
+        END;
+    END LOOP;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_invitations_event_on ON invitations (event_on);
+
+-- Substring search over names, phone and short code. The expression must
+-- stay identical to invitationSearchText in the repository.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_invitations_search ON invitations USING GIN (
+    lower(COALESCE(groom_name, '') || ' ' || COALESCE(bride_name, '') || ' ' || phone_number || ' ' || COALESCE(short_code, '')) gin_trgm_ops
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_invitations_search;
+
+DROP INDEX IF EXISTS idx_invitations_event_on;
+
+ALTER TABLE invitations DROP COLUMN IF EXISTS event_on;
+-- +goose StatementEnd
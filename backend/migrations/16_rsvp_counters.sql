@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-invitation RSVP totals, kept in step with rsvp_responses by the
+-- repository in the same transaction as each change, so the admin list and
+-- stats don't aggregate every response on every load. The reconcile-rsvps
+-- command rebuilds them from the responses.
+CREATE TABLE IF NOT EXISTS invitation_rsvp_counters (
+    invitation_uuid UUID PRIMARY KEY REFERENCES invitations (uuid) ON DELETE CASCADE,
+    responses INTEGER NOT NULL DEFAULT 0,
+    attending INTEGER NOT NULL DEFAULT 0,
+    declined INTEGER NOT NULL DEFAULT 0,
+    maybe INTEGER NOT NULL DEFAULT 0,
+    guests INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO invitation_rsvp_counters (invitation_uuid, responses, attending, declined, maybe, guests)
+SELECT invitation_uuid,
+       COUNT(*),
+       COUNT(*) FILTER (WHERE attendance = 'yes'),
+       COUNT(*) FILTER (WHERE attendance = 'no'),
+       COUNT(*) FILTER (WHERE attendance = 'maybe'),
+       COALESCE(SUM(COALESCE(guest_count, 1)) FILTER (WHERE attendance = 'yes'), 0)
+FROM rsvp_responses
+WHERE invitation_uuid IS NOT NULL
+GROUP BY invitation_uuid
+ON CONFLICT (invitation_uuid) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS invitation_rsvp_counters;
+-- +goose StatementEnd
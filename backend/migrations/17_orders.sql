@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS orders (
+    id UUID PRIMARY KEY,
+    invitation_uuid UUID NOT NULL REFERENCES invitations (uuid) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    provider_ref VARCHAR(255) NOT NULL DEFAULT '',
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    payment_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    paid_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_invitation ON orders (invitation_uuid, created_at);
+
+-- Every webhook call that was applied, keyed by the provider's event id so
+-- redeliveries are recognised and skipped.
+CREATE TABLE IF NOT EXISTS payment_events (
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    order_id UUID NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    payload TEXT NOT NULL DEFAULT '',
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_events_order ON payment_events (order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS payment_events;
+
+DROP TABLE IF EXISTS orders;
+-- +goose StatementEnd
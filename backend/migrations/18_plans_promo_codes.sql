@@ -0,0 +1,73 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Prices are in minor units (tiyn for KZT).
+CREATE TABLE IF NOT EXISTS plans (
+    code VARCHAR(50) PRIMARY KEY,
+    name_ru VARCHAR(100) NOT NULL,
+    name_kk VARCHAR(100) NOT NULL DEFAULT '',
+    name_en VARCHAR(100) NOT NULL DEFAULT '',
+    price BIGINT NOT NULL CHECK (price >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'KZT',
+    features TEXT[] NOT NULL DEFAULT '{}',
+    hosting_days INTEGER NOT NULL DEFAULT 0 CHECK (hosting_days >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO plans (code, name_ru, name_kk, name_en, price, features, hosting_days, sort_order)
+VALUES
+    ('basic', 'Базовый', 'Негізгі', 'Basic', 990000,
+     '{rsvp,countdown,map}', 90, 1),
+    ('premium', 'Премиум', 'Премиум', 'Premium', 1990000,
+     '{rsvp,countdown,map,story,schedule,guest_list}', 365, 2)
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS promo_codes (
+    code VARCHAR(50) PRIMARY KEY,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('percent', 'fixed')),
+    value BIGINT NOT NULL CHECK (value > 0),
+    currency CHAR(3),
+    max_uses INTEGER CHECK (max_uses > 0),
+    used_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE invitations
+ADD COLUMN IF NOT EXISTS plan_code VARCHAR(50) REFERENCES plans (code),
+ADD COLUMN IF NOT EXISTS hosting_until TIMESTAMP;
+
+-- Orders keep what they were priced on. promo_redeemed tells whether the
+-- order holds one of its promo code's uses.
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS plan_code VARCHAR(50) REFERENCES plans (code),
+ADD COLUMN IF NOT EXISTS promo_code VARCHAR(50) REFERENCES promo_codes (code),
+ADD COLUMN IF NOT EXISTS list_price BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS discount BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS hosting_days INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS promo_redeemed BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE orders SET list_price = amount WHERE list_price = 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+DROP COLUMN IF EXISTS promo_redeemed,
+DROP COLUMN IF EXISTS hosting_days,
+DROP COLUMN IF EXISTS discount,
+DROP COLUMN IF EXISTS list_price,
+DROP COLUMN IF EXISTS promo_code,
+DROP COLUMN IF EXISTS plan_code;
+
+ALTER TABLE invitations
+DROP COLUMN IF EXISTS hosting_until,
+DROP COLUMN IF EXISTS plan_code;
+
+DROP TABLE IF EXISTS promo_codes;
+
+DROP TABLE IF EXISTS plans;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- An invitation goes offline at the end of its trial, or of its hosting once
+-- paid. The admin list of invitations about to expire ranges over this.
+CREATE INDEX IF NOT EXISTS idx_invitations_ends_at
+    ON invitations ((CASE WHEN is_paid THEN hosting_until ELSE expires_at END));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_invitations_ends_at;
+-- +goose StatementEnd
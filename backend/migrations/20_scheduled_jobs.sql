@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Enable flags set by admins; jobs without a row use their default.
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    name VARCHAR(100) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Run history. A job runs once per scheduled minute however many replicas
+-- tick, which the unique key enforces.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id BIGSERIAL PRIMARY KEY,
+    job VARCHAR(100) NOT NULL,
+    scheduled_for TIMESTAMP NOT NULL,
+    manual BOOLEAN NOT NULL DEFAULT false,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    affected INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    UNIQUE (job, scheduled_for)
+);
+
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS lifecycle VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS lifecycle_changed_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_invitations_lifecycle ON invitations (lifecycle);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_invitations_lifecycle;
+ALTER TABLE invitations DROP COLUMN IF EXISTS lifecycle_changed_at;
+ALTER TABLE invitations DROP COLUMN IF EXISTS lifecycle;
+DROP TABLE IF EXISTS job_runs;
+DROP TABLE IF EXISTS scheduled_jobs;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Durable job queue. Workers claim due rows with FOR UPDATE SKIP LOCKED and
+-- hold them until locked_until; a row still running after that belonged to
+-- a worker that died and is claimed again.
+CREATE TABLE IF NOT EXISTS queue_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+-- The claim query only looks at work that is due or stuck.
+CREATE INDEX IF NOT EXISTS idx_queue_jobs_due ON queue_jobs (run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_queue_jobs_locked ON queue_jobs (locked_until) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_queue_jobs_status ON queue_jobs (status, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS queue_jobs;
+-- +goose StatementEnd
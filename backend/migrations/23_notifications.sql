@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Send log of the messages to clients. Each try is made by the queue job
+-- in queue_job_id, whose status tells a message still being retried from
+-- one given up on.
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    invitation_uuid UUID NOT NULL REFERENCES invitations (uuid) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    lang VARCHAR(5) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    queue_job_id BIGINT,
+    attempts INT NOT NULL DEFAULT 0,
+    provider_id VARCHAR(255) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_attempt_at TIMESTAMP,
+    sent_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_invitation ON notifications (invitation_uuid, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS notifications;
+-- +goose StatementEnd
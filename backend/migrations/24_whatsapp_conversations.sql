@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- State of the WhatsApp onboarding bot per client phone number.
+CREATE TABLE IF NOT EXISTS whatsapp_conversations (
+    phone VARCHAR(20) PRIMARY KEY,
+    lang VARCHAR(5) NOT NULL,
+    step VARCHAR(20) NOT NULL,
+    answers JSONB NOT NULL DEFAULT '{}',
+    correcting BOOLEAN NOT NULL DEFAULT false,
+    -- Set before the invitation is created, so only one of two "yes"
+    -- messages creates it; hence not a foreign key.
+    invitation_uuid UUID,
+    last_message_id VARCHAR(255) NOT NULL DEFAULT '',
+    version INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_whatsapp_conversations_updated ON whatsapp_conversations (updated_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS whatsapp_conversations;
+-- +goose StatementEnd
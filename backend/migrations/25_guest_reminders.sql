@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Guests may leave a phone number with their answer to be reminded.
+ALTER TABLE rsvp_responses ADD COLUMN IF NOT EXISTS phone VARCHAR(20) NOT NULL DEFAULT '';
+
+-- Reminders to an invitation's guests, timed from the RSVP deadline or the
+-- event.
+CREATE TABLE IF NOT EXISTS reminder_policies (
+    id BIGSERIAL PRIMARY KEY,
+    invitation_uuid UUID NOT NULL REFERENCES invitations (uuid) ON DELETE CASCADE,
+    anchor VARCHAR(20) NOT NULL,
+    offset_minutes INT NOT NULL,
+    audience VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_reminder_policies_invitation ON reminder_policies (invitation_uuid);
+
+-- One row per reminder sent, so each guest gets a policy's reminder once.
+-- notification_id is written in the statement that creates the
+-- notification, hence not a foreign key.
+CREATE TABLE IF NOT EXISTS reminder_deliveries (
+    policy_id BIGINT NOT NULL REFERENCES reminder_policies (id) ON DELETE CASCADE,
+    phone VARCHAR(20) NOT NULL,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    notification_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (policy_id, phone)
+);
+
+-- Phone numbers that get no more reminders.
+CREATE TABLE IF NOT EXISTS reminder_opt_outs (
+    phone VARCHAR(20) PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reminder_opt_outs;
+DROP TABLE IF EXISTS reminder_deliveries;
+DROP TABLE IF EXISTS reminder_policies;
+ALTER TABLE rsvp_responses DROP COLUMN IF EXISTS phone;
+-- +goose StatementEnd
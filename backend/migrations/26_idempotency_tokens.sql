@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The reservation a key is held under. A request whose reservation went
+-- stale and was taken over no longer stores or releases the key.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS token VARCHAR(36) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS token;
+-- +goose StatementEnd
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationsList_PagingFiltersAndSearch(t *testing.T) {
+	r, _, adminRepo := setupTestRouter()
+
+	paid := false
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 8, 31, 0, 0, 0, 0, time.UTC)
+	filter := domain.InvitationFilter{
+		Paid:         &paid,
+		TemplateCode: "starry-night",
+		Lang:         "kk",
+		EventFrom:    &from,
+		EventTo:      &to,
+		Search:       "7011234567",
+		Sort:         domain.InvitationSortEvent,
+		Desc:         true,
+		Limit:        10,
+		Offset:       20,
+	}
+	adminRepo.On("GetInvitationsList", filter).Return(&domain.InvitationPage{
+		Items:  []domain.InvitationWithStats{{Invitation: domain.Invitation{UUID: "u1", PhoneNumber: "+77011234567"}, RSVPCount: 2}},
+		Total:  21,
+		Limit:  10,
+		Offset: 20,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations?paid=false&template=starry-night&lang=kk"+
+		"&eventFrom=2026-06-01&eventTo=2026-08-31&q=8+701+123+45+67&sort=-eventDate&limit=10&offset=20", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var page struct {
+		Items  []map[string]interface{} `json:"items"`
+		Total  int                      `json:"total"`
+		Limit  int                      `json:"limit"`
+		Offset int                      `json:"offset"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, 21, page.Total)
+	assert.Equal(t, 10, page.Limit)
+	assert.Equal(t, 20, page.Offset)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "u1", page.Items[0]["uuid"])
+	adminRepo.AssertExpectations(t)
+}
+
+func TestInvitationsList_DefaultPage(t *testing.T) {
+	r, _, adminRepo := setupTestRouter()
+	adminRepo.On("GetInvitationsList", domain.InvitationFilter{Limit: 50}).
+		Return(&domain.InvitationPage{Items: []domain.InvitationWithStats{}, Limit: 50}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[],"total":0,"limit":50,"offset":0}`, w.Body.String())
+}
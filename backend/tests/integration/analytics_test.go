@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortLink_RedirectRecordsClick(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByShortCode", "abc123").Return(&domain.Invitation{UUID: "uuid-1", ShortCode: "abc123"}, nil)
+	var stored []domain.Click
+	s.clickRepo.On("AddClicks", mock.Anything).Run(func(args mock.Arguments) {
+		stored = append(stored, args.Get(0).([]domain.Click)...)
+	}).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/s/abc123", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148")
+	req.Header.Set("Referer", "https://www.instagram.com/stories/someone/")
+	s.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/i/uuid-1", w.Header().Get("Location"))
+	assert.Equal(t, "private, max-age=300", w.Header().Get("Cache-Control"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.clicks.Run(ctx)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "uuid-1", stored[0].InvitationUUID)
+	assert.Equal(t, domain.UAClassMobile, stored[0].UAClass)
+	assert.Equal(t, "instagram.com", stored[0].Referrer)
+}
+
+func TestClickStats(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	s.clickRepo.On("GetClickStats", "uuid-1", mock.Anything, mock.Anything).Return(&domain.ClickStats{
+		Clicks:         3,
+		UniqueVisitors: 2,
+		BotClicks:      1,
+		Devices:        map[string]int{domain.UAClassMobile: 2},
+		Daily:          []domain.DailyClicks{{Date: "2026-05-02", Clicks: 3, UniqueVisitors: 2}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/uuid-1/clicks?from=2026-05-01&to=2026-05-03", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats domain.ClickStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, "2026-05-01", stats.From)
+	assert.Equal(t, "2026-05-03", stats.To)
+	assert.Equal(t, 3, stats.Clicks)
+	assert.Len(t, stats.Daily, 3)
+	assert.Equal(t, 3, stats.Daily[1].Clicks)
+}
+
+func TestClickStats_Errors(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	s.invRepo.On("GetByUUID", "missing").Return(nil, errors.New("no rows in result set"))
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/uuid-1/clicks?from=May", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/missing/clicks", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/admin/invitations/uuid-1/clicks", nil)
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+const beaconUUID = "0b9d6a1e-3c1f-4a8e-9a53-6f1c2b7d8e90"
+
+func TestEngagementBeacon(t *testing.T) {
+	s := newTestServer("dist")
+	var stored []domain.EngagementEvent
+	s.engRepo.On("AddEngagementEvents", mock.Anything).Run(func(args mock.Arguments) {
+		stored = append(stored, args.Get(0).([]domain.EngagementEvent)...)
+	}).Return(nil)
+
+	// sendBeacon posts strings as text/plain.
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/invitations/"+beaconUUID+"/events", strings.NewReader(`{"event":"rsvp_form"}`))
+	req.Header.Set("Content-Type", "text/plain;charset=UTF-8")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64)")
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	for _, body := range []string{`{"event":"gallery"}`, `event=view`} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/api/invitations/"+beaconUUID+"/events", strings.NewReader(body))
+		s.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.engagement.Run(ctx)
+	require.Len(t, stored, 1)
+	assert.Equal(t, domain.EngagementRSVPForm, stored[0].Event)
+	assert.Equal(t, domain.UAClassDesktop, stored[0].UAClass)
+}
+
+func TestEngagementFunnel(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	s.engRepo.On("GetFunnel", "uuid-1").Return(&domain.EngagementFunnel{Opened: 12, ReachedRSVP: 7, Responded: 5, BotViews: 3}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/uuid-1/engagement", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var funnel domain.EngagementFunnel
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &funnel))
+	assert.Equal(t, domain.EngagementFunnel{Opened: 12, ReachedRSVP: 7, Responded: 5, BotViews: 3}, funnel)
+}
+
+func TestBusinessAnalytics(t *testing.T) {
+	s := newTestServer("dist")
+	s.adminRepo.On("GetAnalytics", mock.Anything, mock.Anything, "day").Return(&domain.BusinessAnalytics{
+		Series:    []domain.AnalyticsPeriod{{Period: "2026-05-02", Invitations: 2, Paid: 1}},
+		Templates: []domain.AnalyticsShare{{Key: "starry-night", Name: "Звёздная ночь", Invitations: 2, Paid: 1}},
+		Languages: []domain.AnalyticsShare{{Key: "ru", Invitations: 2, Paid: 1}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/analytics?from=2026-05-01&to=2026-05-03", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var a domain.BusinessAnalytics
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &a))
+	assert.Equal(t, "day", a.Granularity)
+	assert.Len(t, a.Series, 3)
+	assert.Equal(t, 0.5, a.Series[1].Conversion)
+	assert.Equal(t, 2, a.Totals.Invitations)
+	assert.Equal(t, 1.0, a.Templates[0].Share)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/analytics?granularity=hour", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -13,27 +14,116 @@ import (
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/api"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/api/handlers"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/card"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/notify"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/payment"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/web"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/tests/mocks"
 	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// testServer is the router wired to mock repositories.
+type testServer struct {
+	router     *gin.Engine
+	invRepo    *mocks.MockInvitationRepository
+	adminRepo  *mocks.MockAdminRepository
+	guestRepo  *mocks.MockGuestRepository
+	idemRepo   *mocks.MockIdempotencyRepository
+	clickRepo  *mocks.MockClickRepository
+	clicks     *usecase.ClickUseCase
+	engRepo    *mocks.MockEngagementRepository
+	engagement *usecase.EngagementUseCase
+	orderRepo  *mocks.MockOrderRepository
+	pricing    *mocks.MockPricingRepository
+	jobRepo    *mocks.MockJobRepository
+	lifecycle  *mocks.MockLifecycleRepository
+	queueRepo  *mocks.MockQueueRepository
+	webhooks   *mocks.MockWebhookRepository
+	notifRepo  *mocks.MockNotificationRepository
+	convRepo   *mocks.MockConversationRepository
+	remindRepo *mocks.MockReminderRepository
+}
+
 func setupTestRouter() (*gin.Engine, *mocks.MockInvitationRepository, *mocks.MockAdminRepository) {
+	return setupTestRouterWithDist("dist")
+}
+
+func setupTestRouterWithDist(dist string) (*gin.Engine, *mocks.MockInvitationRepository, *mocks.MockAdminRepository) {
+	s := newTestServer(dist)
+	return s.router, s.invRepo, s.adminRepo
+}
+
+func newTestServer(dist string) *testServer {
 	gin.SetMode(gin.TestMode)
 
-	invRepo := new(mocks.MockInvitationRepository)
-	adminRepo := new(mocks.MockAdminRepository)
+	s := &testServer{
+		invRepo:    new(mocks.MockInvitationRepository),
+		adminRepo:  new(mocks.MockAdminRepository),
+		guestRepo:  new(mocks.MockGuestRepository),
+		idemRepo:   new(mocks.MockIdempotencyRepository),
+		clickRepo:  new(mocks.MockClickRepository),
+		engRepo:    new(mocks.MockEngagementRepository),
+		orderRepo:  new(mocks.MockOrderRepository),
+		pricing:    new(mocks.MockPricingRepository),
+		jobRepo:    new(mocks.MockJobRepository),
+		lifecycle:  new(mocks.MockLifecycleRepository),
+		queueRepo:  new(mocks.MockQueueRepository),
+		webhooks:   new(mocks.MockWebhookRepository),
+		notifRepo:  new(mocks.MockNotificationRepository),
+		convRepo:   new(mocks.MockConversationRepository),
+		remindRepo: new(mocks.MockReminderRepository),
+	}
 
 	jwtSecret := []byte("test-secret")
-	invUC := usecase.NewInvitationUseCase(invRepo)
-	adminUC := usecase.NewAdminUseCase(adminRepo, "admin", "password", jwtSecret)
+	invUC := usecase.NewInvitationUseCase(s.invRepo, usecase.ShortCodeGenerator{}, usecase.TrialPolicy{})
+	adminUC := usecase.NewAdminUseCase(s.adminRepo, "admin", "password", jwtSecret)
+	guestUC := usecase.NewGuestUseCase(s.guestRepo, s.invRepo)
+	idempotencyUC := usecase.NewIdempotencyUseCase(s.idemRepo)
+	s.clicks = usecase.NewClickUseCase(s.clickRepo, s.invRepo, nil, jwtSecret)
+	s.engagement = usecase.NewEngagementUseCase(s.engRepo, s.invRepo, jwtSecret)
 
-	invHandler := handlers.NewInvitationHandler(invUC)
-	adminHandler := handlers.NewAdminHandler(adminUC, invUC)
+	invHandler := handlers.NewInvitationHandler(invUC, s.clicks)
+	adminHandler := handlers.NewAdminHandler(adminUC, invUC, "https://card-go.test")
+	pages, err := web.NewPageRenderer()
+	if err != nil {
+		panic(err)
+	}
+	cards := card.NewRenderer(8)
+	pageHandler := handlers.NewPageHandler(invUC, cards, pages, filepath.Join(dist, "index.html"), "https://card-go.test")
+	exportHandler := handlers.NewExportHandler(invUC, adminUC, pages, cards, "https://card-go.test")
+	guestHandler := handlers.NewGuestHandler(guestUC)
+	analyticsHandler := handlers.NewAnalyticsHandler(s.clicks, s.engagement)
+	paymentUC := usecase.NewPaymentUseCase(s.orderRepo, s.pricing, s.invRepo,
+		payment.NewHMACProvider("hmac", []byte(webhookSecret), "https://pay.test/checkout?order={order}"),
+		payment.NewFakeProvider("https://card-go.test"))
+	paymentHandler := handlers.NewPaymentHandler(paymentUC)
+	pricingHandler := handlers.NewPricingHandler(usecase.NewPricingUseCase(s.pricing))
+	scheduler, err := usecase.NewSchedulerUseCase(s.jobRepo, usecase.LifecycleJobs(s.lifecycle, 0)...)
+	if err != nil {
+		panic(err)
+	}
+	jobHandler := handlers.NewJobHandler(scheduler)
+	queue := usecase.NewQueueUseCase(s.queueRepo, usecase.QueueOptions{})
+	queueHandler := handlers.NewQueueHandler(queue)
+	webhookHandler := handlers.NewWebhookHandler(usecase.NewWebhookUseCase(s.webhooks, queue, nil))
+	// The channels are only used by the queue, which doesn't run here.
+	whatsapp := notify.NewWhatsApp(http.DefaultClient, "http://whatsapp.invalid", "100", "token")
+	notifications := usecase.NewNotificationUseCase(s.notifRepo, s.invRepo, queue, "https://card-go.test",
+		whatsapp, notify.NewSMS(http.DefaultClient, "http://sms.invalid/send", "", "CardGo"))
+	notificationHandler := handlers.NewNotificationHandler(notifications)
+	onboardingHandler := handlers.NewOnboardingHandler(usecase.NewOnboardingUseCase(s.convRepo, invUC, s.adminRepo, queue,
+		whatsapp, notify.NewWhatsAppWebhook(whatsAppSecret), "verify-me", "https://card-go.test"))
+	questionnaireHandler := handlers.NewQuestionnaireHandler(usecase.NewQuestionnaireUseCase(s.adminRepo))
+	reminders, err := usecase.NewReminderUseCase(s.remindRepo, s.invRepo, s.guestRepo, notifications, "https://card-go.test", usecase.ReminderOptions{})
+	if err != nil {
+		panic(err)
+	}
+	reminderHandler := handlers.NewReminderHandler(reminders, pages, "https://card-go.test")
 
-	r := api.SetupRouter(invHandler, adminHandler, jwtSecret, "test-api-key", "dist")
-	return r, invRepo, adminRepo
+	s.router = api.SetupRouter(invHandler, adminHandler, pageHandler, exportHandler, guestHandler, analyticsHandler, paymentHandler, pricingHandler, jobHandler, queueHandler, webhookHandler, notificationHandler, onboardingHandler, questionnaireHandler, reminderHandler, idempotencyUC, jwtSecret, "test-api-key", dist)
+	return s
 }
 
 func TestHealthCheck(t *testing.T) {
@@ -133,3 +223,120 @@ func TestCreateInvitation_Success(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w.Code)
 	invRepo.AssertExpectations(t)
 }
+
+func TestCreateInvitation_InvalidInput(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	w := httptest.NewRecorder()
+	req := adminRequest("POST", "/api/admin/invitations", strings.NewReader(`{"templateCode":"starry-night"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"phoneNumber is required"}`, w.Body.String())
+	invRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+type batchResponse struct {
+	Created int                          `json:"created"`
+	Failed  int                          `json:"failed"`
+	Results []domain.InvitationBatchItem `json:"results"`
+}
+
+func postBatch(r *gin.Engine, body string) (*httptest.ResponseRecorder, batchResponse) {
+	w := httptest.NewRecorder()
+	req := adminRequest("POST", "/api/admin/invitations/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	var resp batchResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestCreateInvitationsBatch_Atomic(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+	invRepo.On("CreateMany", mock.MatchedBy(func(invs []*domain.Invitation) bool { return len(invs) == 2 })).Return(nil)
+
+	w, resp := postBatch(r, `{"invitations":[
+		{"phoneNumber":"+77011234567","templateCode":"starry-night"},
+		{"phoneNumber":"+77021234567","templateCode":"silk-ivory","lang":"kk"}
+	]}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 2, resp.Created)
+	for i, item := range resp.Results {
+		assert.Equal(t, i, item.Index)
+		assert.NotEmpty(t, item.UUID)
+		assert.Equal(t, "https://card-go.test/s/"+item.ShortCode, item.ShortLink)
+	}
+	invRepo.AssertExpectations(t)
+}
+
+func TestCreateInvitationsBatch_AtomicRejected(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	w, resp := postBatch(r, `{"mode":"atomic","invitations":[
+		{"phoneNumber":"+77011234567","templateCode":"starry-night"},
+		{"phoneNumber":"+77021234567","templateCode":"silk-ivory","lang":"fr"}
+	]}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, 2, resp.Failed)
+	assert.Equal(t, "batch_rejected", resp.Results[0].Error)
+	assert.Equal(t, `unsupported lang: "fr"`, resp.Results[1].Error)
+	invRepo.AssertNotCalled(t, "CreateMany", mock.Anything)
+}
+
+func TestCreateInvitationsBatch_BestEffort(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+	invRepo.On("Create", mock.Anything).Return(nil).Once()
+
+	w, resp := postBatch(r, `{"mode":"bestEffort","invitations":[
+		{"phoneNumber":"+77011234567","templateCode":"starry-night"},
+		{"templateCode":"silk-ivory"}
+	]}`)
+
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Equal(t, 1, resp.Created)
+	assert.NotEmpty(t, resp.Results[0].ShortLink)
+	assert.Equal(t, "phoneNumber is required", resp.Results[1].Error)
+	assert.Empty(t, resp.Results[1].ShortLink)
+	invRepo.AssertExpectations(t)
+}
+
+func TestCreateInvitationsBatch_BadRequest(t *testing.T) {
+	r, _, _ := setupTestRouter()
+	for _, body := range []string{`{"invitations":[]}`, `{"mode":"yolo","invitations":[{}]}`, `[]`} {
+		w, _ := postBatch(r, body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func TestCreateInvitation_VanitySlugTaken(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+	invRepo.On("Create", mock.Anything).Return(domain.ErrShortCodeTaken)
+
+	w := httptest.NewRecorder()
+	req := adminRequest("POST", "/api/admin/invitations", strings.NewReader(`{"phoneNumber":"+77011234567","templateCode":"starry-night","shortCode":"arman-aigerim"}`))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"error":"short_code_taken"}`, w.Body.String())
+}
+
+func TestShortCodeAvailability(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+	invRepo.On("ShortCodeExists", "arman-aigerim").Return(true, nil)
+	invRepo.On("ShortCodeExists", "dana-timur").Return(false, nil)
+
+	for code, want := range map[string]string{
+		"Arman-Aigerim": `{"shortCode":"arman-aigerim","available":false,"error":"short_code_taken"}`,
+		"dana-timur":    `{"shortCode":"dana-timur","available":true,"shortLink":"https://card-go.test/s/dana-timur"}`,
+		"api":           `{"shortCode":"api","available":false,"error":"shortCode \"api\" is reserved"}`,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, adminRequest("GET", "/api/admin/short-codes/"+code, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, want, w.Body.String(), code)
+	}
+}
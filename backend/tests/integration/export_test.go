@@ -0,0 +1,201 @@
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// adminRequest builds a request carrying a valid admin session.
+func adminRequest(method, target string, body io.Reader) *http.Request {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"admin": true,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, _ := token.SignedString([]byte("test-secret"))
+	req, _ := http.NewRequest(method, target, body)
+	req.AddCookie(&http.Cookie{Name: "admin_token", Value: tokenString})
+	return req
+}
+
+func TestExportStaticSite(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	past := time.Now().Add(-time.Hour)
+	inv := &domain.Invitation{
+		UUID:          "uuid-zip",
+		ShortCode:     "abc123",
+		TemplateCode:  "starry-night",
+		Lang:          "en",
+		GroomName:     "Arman",
+		BrideName:     "Aigerim",
+		EventDate:     "2026-07-15 18:00:00",
+		EventLocation: "Rixos Almaty",
+		ExpiresAt:     &past,
+	}
+	invRepo.On("GetByUUID", "uuid-zip").Return(inv, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/uuid-zip/export.zip", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="invitation-abc123.zip"`, w.Header().Get("Content-Disposition"))
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+
+	require.Contains(t, files, "index.html")
+	require.Contains(t, files, "style.css")
+	require.Contains(t, files, "card.png")
+	page := files["index.html"]
+	assert.Contains(t, page, `<link rel="stylesheet" href="style.css"`)
+	assert.Contains(t, page, `<meta property="og:image" content="card.png" />`)
+	assert.Contains(t, page, "Arman")
+	assert.NotContains(t, page, "<form")
+	assert.NotContains(t, page, "<script")
+	assert.True(t, bytes.HasPrefix([]byte(files["card.png"]), []byte("\x89PNG")))
+}
+
+func TestExportStaticSite_NotFound(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+	invRepo.On("GetByUUID", "missing").Return(nil, errors.New("not found"))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/missing/export.zip", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestExportStaticSite_RequiresAdmin(t *testing.T) {
+	r, _, _ := setupTestRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/admin/invitations/uuid-zip/export.zip", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestExportGuestListPDF(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	inv := &domain.Invitation{UUID: "uuid-pdf", ShortCode: "abc123", Lang: "kk", GroomName: "Нұрлан", BrideName: "Әйгерім", EventDate: "2026-07-15 18:00:00"}
+	invRepo.On("GetByUUID", "uuid-pdf").Return(inv, nil)
+	invRepo.On("GetRSVPs", "uuid-pdf").Return([]domain.RSVPResponse{
+		{GuestName: "Ғалым", Attendance: "yes", GuestCount: 2, CreatedAt: time.Now()},
+		{GuestName: "Өмір", Attendance: "no", GuestCount: 1, CreatedAt: time.Now()},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/uuid-pdf/guests.pdf?lang=ru", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="invitation-abc123-guests.pdf"`, w.Header().Get("Content-Disposition"))
+	assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+	invRepo.AssertExpectations(t)
+}
+
+func TestExportCardPDF(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	inv := &domain.Invitation{UUID: "uuid-pdf", ShortCode: "abc123", TemplateCode: "silk-ivory", Lang: "ru", GroomName: "Арман", BrideName: "Айгерим"}
+	invRepo.On("GetByUUID", "uuid-pdf").Return(inv, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/uuid-pdf/card.pdf", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="invitation-abc123-card.pdf"`, w.Header().Get("Content-Disposition"))
+	assert.True(t, bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")))
+	assert.Contains(t, w.Body.String(), "/MediaBox [0 0 419.53 595.28]")
+}
+
+func TestExportRSVPs_CSV(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	inv := &domain.Invitation{UUID: "uuid-csv", ShortCode: "abc123", Lang: "kk"}
+	invRepo.On("GetByUUID", "uuid-csv").Return(inv, nil)
+	invRepo.On("GetRSVPs", "uuid-csv").Return([]domain.RSVPResponse{
+		{GuestName: "Ғалым, Әсел", Attendance: "yes", GuestCount: 2, CreatedAt: time.Date(2026, 6, 1, 12, 30, 0, 0, time.UTC)},
+		{GuestName: "Өмір", Attendance: "no", GuestCount: 1},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/uuid-csv/rsvps/export", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="invitation-abc123-rsvps.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "\uFEFF"+
+		"Аты-жөні,Жауап,Қонақтар,Жауап күні\n"+
+		"\"Ғалым, Әсел\",Келеді,2,2026-06-01 12:30\n"+
+		"Өмір,Келмейді,1,\n", w.Body.String())
+}
+
+func TestExportInvitations_XLSXWithFilters(t *testing.T) {
+	r, _, adminRepo := setupTestRouter()
+
+	paid := true
+	filter := domain.InvitationFilter{Paid: &paid, TemplateCode: "silk-ivory"}
+	adminRepo.On("EachInvitation", filter).Return([]domain.InvitationWithStats{
+		{Invitation: domain.Invitation{UUID: "u1", ShortCode: "abc123", GroomName: "Арман", BrideName: "Айгерим", IsPaid: true}, RSVPCount: 3, ApprovedGuests: 5, TemplateName: "Шелк"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/export?format=xlsx&paid=true&template=silk-ivory", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", w.Header().Get("Content-Type"))
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			sheet = string(data)
+		}
+	}
+	assert.Contains(t, sheet, ">Оплачено<")
+	assert.Contains(t, sheet, ">Арман и Айгерим<")
+	assert.Contains(t, sheet, ">Шелк<")
+	assert.Contains(t, sheet, ">Да<")
+	adminRepo.AssertExpectations(t)
+}
+
+func TestExportInvitations_BadRequest(t *testing.T) {
+	r, _, _ := setupTestRouter()
+
+	for _, target := range []string{
+		"/api/admin/invitations/export?format=pdf",
+		"/api/admin/invitations/export?paid=maybe",
+		"/api/admin/invitations?expired=soon",
+		"/api/admin/invitations/export?sort=phoneNumber",
+		"/api/admin/invitations?eventFrom=01.06.2026",
+		"/api/admin/invitations?limit=1000",
+		"/api/admin/invitations?offset=x",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, adminRequest("GET", target, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func guestImportRequest(t *testing.T, target, filename, content string, fields map[string]string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	fw.Write([]byte(content))
+	for k, v := range fields {
+		mw.WriteField(k, v)
+	}
+	require.NoError(t, mw.Close())
+
+	req := adminRequest("POST", target, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+const rosterCSV = "ФИО;Телефон;Гости\nАсқар;8 701 123 45 67;2\nДина;+7 701 123 45 67;1\n"
+
+func TestImportGuests_DryRun(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	s.guestRepo.On("ListGuests", "uuid-1").Return([]domain.Guest{}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, guestImportRequest(t, "/api/admin/invitations/uuid-1/guests/import", "roster.csv", rosterCSV, map[string]string{"dryRun": "true"}))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var report domain.GuestImportReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.Rows)
+	assert.Equal(t, []domain.GuestImportError{{Row: 3, Field: "phone", Error: "duplicate_of_row_2"}}, report.Errors)
+	s.guestRepo.AssertNotCalled(t, "AddGuests", mock.Anything)
+}
+
+func TestImportGuests_Commit(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	s.guestRepo.On("ListGuests", "uuid-1").Return([]domain.Guest{}, nil)
+	s.guestRepo.On("AddGuests", mock.MatchedBy(func(g []domain.Guest) bool {
+		return len(g) == 1 && g[0].Phone == "+77011234567" && g[0].PartySize == 2
+	})).Return(nil)
+
+	csv := "ФИО;Телефон;Гости\nАсқар;8 701 123 45 67;2\n"
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, guestImportRequest(t, "/api/admin/invitations/uuid-1/guests/import", "roster.csv", csv, nil))
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	s.guestRepo.AssertExpectations(t)
+}
+
+func TestImportGuests_RejectedWithErrors(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	s.guestRepo.On("ListGuests", "uuid-1").Return([]domain.Guest{}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, guestImportRequest(t, "/api/admin/invitations/uuid-1/guests/import", "roster.csv", rosterCSV, nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	s.guestRepo.AssertNotCalled(t, "AddGuests", mock.Anything)
+}
+
+func TestImportGuests_BadRequests(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	s.guestRepo.On("ListGuests", "uuid-1").Return([]domain.Guest{}, nil)
+
+	for _, req := range []*http.Request{
+		guestImportRequest(t, "/api/admin/invitations/uuid-1/guests/import", "roster.pdf", rosterCSV, nil),
+		guestImportRequest(t, "/api/admin/invitations/uuid-1/guests/import", "roster.csv", rosterCSV, map[string]string{"mapping": "[1]"}),
+		guestImportRequest(t, "/api/admin/invitations/uuid-1/guests/import", "roster.csv", "A;B\nx;y\n", nil),
+	} {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	}
+}
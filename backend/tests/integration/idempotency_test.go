@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const createBody = `{"phoneNumber":"+77011234567","templateCode":"starry-night"}`
+
+func idempotentCreate(s *testServer, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := adminRequest("POST", "/api/admin/invitations", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_StoresFirstResponse(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("Create", mock.Anything).Return(nil).Once()
+	s.idemRepo.On("Reserve", "wa-77011234567-1", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	var stored []byte
+	s.idemRepo.On("Complete", "wa-77011234567-1", mock.Anything, http.StatusCreated, "application/json; charset=utf-8", mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(4).([]byte) }).Return(nil)
+
+	w := idempotentCreate(s, "wa-77011234567-1", createBody)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, w.Body.String(), string(stored))
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	s.idemRepo.AssertExpectations(t)
+}
+
+// firstCreate runs an idempotent create on a fresh server and returns the
+// response together with the record that would have been stored.
+func firstCreate(t *testing.T, key string) (*httptest.ResponseRecorder, domain.IdempotencyRecord) {
+	s := newTestServer("dist")
+	s.invRepo.On("Create", mock.Anything).Return(nil)
+	rec := domain.IdempotencyRecord{Key: key}
+	var token string
+	s.idemRepo.On("Reserve", key, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		token, rec.RequestHash = args.String(1), args.String(2)
+	}).Return(nil, nil)
+	s.idemRepo.On("Complete", key, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		// Only the reservation's own request stores the response.
+		assert.Equal(t, token, args.String(1))
+		rec.StatusCode, rec.ContentType, rec.Body = args.Int(2), args.String(3), args.Get(4).([]byte)
+	}).Return(nil)
+
+	w := idempotentCreate(s, key, createBody)
+	require.Equal(t, http.StatusCreated, w.Code)
+	return w, rec
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	original, rec := firstCreate(t, "key-1")
+
+	s := newTestServer("dist")
+	s.idemRepo.On("Reserve", "key-1", mock.Anything, rec.RequestHash, mock.Anything, mock.Anything).Return(&rec, nil)
+	w := idempotentCreate(s, "key-1", createBody)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, original.Body.String(), w.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	s.invRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestIdempotency_RejectsDifferentBody(t *testing.T) {
+	_, rec := firstCreate(t, "key-1")
+
+	s := newTestServer("dist")
+	s.idemRepo.On("Reserve", "key-1", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&rec, nil)
+	w := idempotentCreate(s, "key-1", `{"phoneNumber":"+77019999999","templateCode":"starry-night"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"error":"idempotency_key_reused"}`, w.Body.String())
+	s.invRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestIdempotency_RetryWhileRunning(t *testing.T) {
+	_, rec := firstCreate(t, "key-1")
+	rec.StatusCode = 0
+
+	s := newTestServer("dist")
+	s.idemRepo.On("Reserve", "key-1", mock.Anything, rec.RequestHash, mock.Anything, mock.Anything).Return(&rec, nil)
+	w := idempotentCreate(s, "key-1", createBody)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	s.invRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestIdempotency_MultipartBoundaryIgnored(t *testing.T) {
+	s := newTestServer("dist")
+	s.idemRepo.On("Reserve", "import-1", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&domain.IdempotencyRecord{Key: "import-1", StatusCode: 201}, nil)
+
+	var hashes []string
+	for i := 0; i < 2; i++ {
+		// Each request gets a fresh random boundary.
+		req := guestImportRequest(t, "/api/admin/invitations/uuid-1/guests/import", "roster.csv", rosterCSV, nil)
+		req.Header.Set("Idempotency-Key", "import-1")
+		s.router.ServeHTTP(httptest.NewRecorder(), req)
+		hashes = append(hashes, s.idemRepo.Calls[i].Arguments.String(2))
+	}
+	assert.Equal(t, hashes[0], hashes[1])
+}
+
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	s := newTestServer("dist")
+	s.idemRepo.On("Reserve", "key-1", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	s.invRepo.On("Create", mock.Anything).Return(errors.New("connection reset"))
+	s.idemRepo.On("Release", "key-1", mock.Anything).Return(nil)
+
+	w := idempotentCreate(s, "key-1", createBody)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	s.idemRepo.AssertExpectations(t)
+	s.idemRepo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotency_IgnoredWithoutHeaderAndOnReads(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("Create", mock.Anything).Return(nil)
+	s.adminRepo.On("GetTemplates").Return([]domain.Template{}, nil)
+
+	w := httptest.NewRecorder()
+	req := adminRequest("POST", "/api/admin/invitations", strings.NewReader(createBody))
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	req = adminRequest("GET", "/api/admin/templates", nil)
+	req.Header.Set("Idempotency-Key", "key-1")
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	s.idemRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
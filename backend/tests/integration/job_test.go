@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminJobs_List(t *testing.T) {
+	s := newTestServer("dist")
+	s.jobRepo.On("JobFlags").Return(map[string]bool{"post-event": false}, nil)
+	s.jobRepo.On("LastJobRuns").Return(map[string]domain.JobRun{}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/jobs", nil))
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list []usecase.JobInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	enabled := map[string]bool{}
+	for _, j := range list {
+		enabled[j.Name] = j.Enabled
+	}
+	assert.Equal(t, map[string]bool{"expire-invitations": true, "post-event": false, "purge-expired-trials": false}, enabled)
+}
+
+func TestAdminJobs_SetEnabled(t *testing.T) {
+	s := newTestServer("dist")
+	s.jobRepo.On("SetJobEnabled", "purge-expired-trials", true).Return(nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/jobs/purge-expired-trials", strings.NewReader(`{"enabled":true}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/jobs/purge-expired-trials", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/jobs/nope", strings.NewReader(`{"enabled":true}`)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	s.jobRepo.AssertExpectations(t)
+}
+
+func TestAdminJobs_Run(t *testing.T) {
+	s := newTestServer("dist")
+	s.lifecycle.On("ExpireInvitations", mock.Anything).Return(4, nil)
+	s.jobRepo.On("TryJobLock", "expire-invitations").Return(true, nil).Once()
+	s.jobRepo.On("TryJobLock", "expire-invitations").Return(false, nil)
+	s.jobRepo.On("StartJobRun", "expire-invitations", mock.Anything, true).Return(&domain.JobRun{ID: 7, Job: "expire-invitations", Manual: true}, true, nil)
+	s.jobRepo.On("FinishJobRun", mock.Anything).Return(nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/jobs/expire-invitations/run", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var run domain.JobRun
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+	assert.Equal(t, domain.JobSucceeded, run.Status)
+	assert.Equal(t, 4, run.Affected)
+
+	// Still running elsewhere.
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/jobs/expire-invitations/run", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAdminJobs_Runs(t *testing.T) {
+	s := newTestServer("dist")
+	s.jobRepo.On("ListJobRuns", "post-event", 10).Return([]domain.JobRun{{ID: 1, Job: "post-event"}}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/jobs/post-event/runs?limit=10", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/jobs/post-event/runs?limit=x", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
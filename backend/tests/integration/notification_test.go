@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var readyInvitation = &domain.Invitation{UUID: "inv-1", PhoneNumber: "87007007070", Lang: "ru",
+	GroomName: "Arman", BrideName: "Aia", EventDate: "2026-08-15", ShortCode: "arman-aia"}
+
+func TestAdminNotifications_Templates(t *testing.T) {
+	s := newTestServer("dist")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/notification-templates", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Templates []usecase.NotificationTemplate `json:"templates"`
+		Channels  []string                       `json:"channels"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Templates, 3)
+	assert.Equal(t, []string{"sms", "whatsapp"}, body.Channels)
+}
+
+func TestAdminNotifications_Preview(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "inv-1").Return(readyInvitation, nil)
+	s.invRepo.On("GetByUUID", "missing").Return(nil, errors.New("no rows"))
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/inv-1/notifications/preview?template=invitation_ready&lang=en", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var n domain.Notification
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &n))
+	assert.Equal(t, "Your invitation is ready", n.Subject)
+	assert.Contains(t, n.Body, "Arman & Aia, August 15, 2026")
+	assert.Contains(t, n.Body, "https://card-go.test/s/arman-aia")
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/inv-1/notifications/preview?template=unknown", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/missing/notifications/preview?template=questionnaire", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminNotifications_SendAndLog(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "inv-1").Return(readyInvitation, nil)
+	s.notifRepo.On("CreateNotification", mock.MatchedBy(func(n *domain.Notification) bool {
+		return n.Recipient == "+77007007070" && n.Channel == "whatsapp" && strings.Contains(n.Body, "Данные для приглашения")
+	}), domain.QueueJob{Kind: usecase.NotificationSendKind, MaxAttempts: 8}).Run(func(args mock.Arguments) {
+		n := args.Get(0).(*domain.Notification)
+		n.ID, n.Status = 12, domain.NotificationQueued
+	}).Return(nil)
+	s.notifRepo.On("ListNotifications", "inv-1").Return([]domain.Notification{{ID: 12, Status: domain.NotificationSent}}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/inv-1/notifications",
+		strings.NewReader(`{"template":"questionnaire","channel":"whatsapp"}`)))
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var n domain.Notification
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &n))
+	assert.Equal(t, int64(12), n.ID)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/inv-1/notifications",
+		strings.NewReader(`{"template":"questionnaire","channel":"email","to":"aia@example.com"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/inv-1/notifications", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var log []domain.Notification
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &log))
+	assert.Len(t, log, 1)
+	s.notifRepo.AssertExpectations(t)
+}
@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/notify"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const whatsAppSecret = "test-app-secret"
+
+func whatsAppRequest(body, secret string) *http.Request {
+	req, _ := http.NewRequest("POST", "/api/whatsapp/webhook", strings.NewReader(body))
+	req.Header.Set(notify.WhatsAppSignatureHeader, notify.SignWhatsApp(secret, []byte(body)))
+	return req
+}
+
+func TestWhatsAppWebhook_Verify(t *testing.T) {
+	s := newTestServer("dist")
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/whatsapp/webhook?hub.mode=subscribe&hub.verify_token=verify-me&hub.challenge=1158201444", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1158201444", w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/whatsapp/webhook?hub.mode=subscribe&hub.verify_token=guess&hub.challenge=1", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestWhatsAppWebhook_GreetsNewClient(t *testing.T) {
+	s := newTestServer("dist")
+	s.convRepo.On("GetConversation", "+77011234567").Return(nil, domain.ErrConversationNotFound)
+	s.convRepo.On("SaveConversation", mock.MatchedBy(func(c *domain.Conversation) bool {
+		return c.Step == usecase.FieldTemplate && c.Lang == "ru" && c.LastMessageID == "wamid.1"
+	})).Return(nil)
+	s.adminRepo.On("GetTemplates").Return([]domain.Template{
+		{Code: "starry-night", NameRu: "Звездная ночь"},
+		{Code: "silk-ivory", NameRu: "Шелк и слоновая кость"},
+	}, nil)
+	s.queueRepo.On("Enqueue", mock.MatchedBy(func(job *domain.QueueJob) bool {
+		var reply struct{ To, Text string }
+		return job.Kind == usecase.WhatsAppReplyKind && json.Unmarshal(job.Payload, &reply) == nil &&
+			reply.To == "+77011234567" && strings.Contains(reply.Text, "1. Шелк и слоновая кость\n2. Звездная ночь")
+	})).Return(nil)
+
+	body := `{"object":"whatsapp_business_account","entry":[{"id":"1","changes":[{"field":"messages","value":{
+		"messaging_product":"whatsapp","contacts":[{"profile":{"name":"Arman"},"wa_id":"77011234567"}],
+		"messages":[{"from":"77011234567","id":"wamid.1","timestamp":"1780000000","type":"text","text":{"body":"Здравствуйте!"}}]}}]}]}`
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, whatsAppRequest(body, whatsAppSecret))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	s.convRepo.AssertExpectations(t)
+	s.queueRepo.AssertExpectations(t)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, whatsAppRequest(body, "forged"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdminConversations(t *testing.T) {
+	s := newTestServer("dist")
+	s.convRepo.On("ListConversations", 50).Return([]domain.Conversation{{Phone: "+77011234567", Step: usecase.FieldDate}}, nil)
+	s.convRepo.On("DeleteConversation", "+77011234567").Return(nil)
+	s.convRepo.On("DeleteConversation", "+77770000000").Return(domain.ErrConversationNotFound)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/conversations", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []domain.Conversation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, "date", list[0].Step)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/conversations/87011234567", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/conversations/87770000000", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/conversations?limit=1000", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,257 @@
+package integration
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/card"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testIndexHTML = `<!doctype html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8" />
+    <title>Generic title</title>
+    <meta name="description" content="Generic description" />
+    <meta property="og:title" content="Generic OG title" />
+    <meta property="og:image" content="https://card-go.asia/images/landing_hero_bg.jpg" />
+    <meta property="twitter:title" content="Generic Twitter title" />
+  </head>
+  <body><div id="app"></div></body>
+</html>`
+
+func writeTestDist(t *testing.T) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(testIndexHTML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestInvitationPage_InjectsMeta(t *testing.T) {
+	r, invRepo, _ := setupTestRouterWithDist(writeTestDist(t))
+
+	inv := &domain.Invitation{
+		UUID:          "uuid-1",
+		TemplateCode:  "silk-ivory",
+		Lang:          "ru",
+		GroomName:     "Арман",
+		BrideName:     "Айгерим",
+		EventDate:     "2026-07-15 18:00:00",
+		EventLocation: "Rixos Almaty",
+		IsPaid:        true,
+	}
+	invRepo.On("GetByUUID", "uuid-1").Return(inv, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/i/uuid-1", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "<title>Арман и Айгерим | Приглашение на свадьбу</title>")
+	assert.Contains(t, body, `content="Арман и Айгерим приглашают вас на свадьбу 15 июля 2026, 18:00. Rixos Almaty"`)
+	assert.Contains(t, body, `<meta property="og:image" content="https://card-go.test/api/invitations/uuid-1/card.png?v=`+card.Key(inv)+`" />`)
+	assert.Contains(t, body, `<meta property="og:image:width" content="1200" />`)
+	assert.Contains(t, body, `<meta property="og:url" content="https://card-go.test/i/uuid-1" />`)
+	assert.Contains(t, body, `<meta property="og:locale" content="ru_RU" />`)
+	assert.NotContains(t, body, "Generic")
+	assert.Contains(t, body, `<div id="app"><div class="ssr-invitation ssr-silk-ivory" lang="ru">`)
+	assert.Contains(t, body, `<form method="post" action="/i/uuid-1/rsvp">`)
+	assert.Contains(t, body, `<script id="invitation-data" type="application/json">{"id":0,"uuid":"uuid-1"`)
+}
+
+func TestInvitationPage_ExpiredIsNeutral(t *testing.T) {
+	r, invRepo, _ := setupTestRouterWithDist(writeTestDist(t))
+
+	past := time.Now().Add(-time.Hour)
+	inv := &domain.Invitation{UUID: "uuid-2", GroomName: "Данияр", BrideName: "Жанар", ExpiresAt: &past}
+	invRepo.On("GetByUUID", "uuid-2").Return(inv, nil)
+
+	invRepo.On("GetByUUID", "uuid-missing").Return(nil, errors.New("no rows in result set"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/i/uuid-2", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), "Данияр")
+	assert.Contains(t, w.Body.String(), `<meta name="robots" content="noindex, nofollow" />`)
+
+	// An expired link can't be told from one that never existed.
+	missing := httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/i/uuid-missing", nil)
+	r.ServeHTTP(missing, req)
+	assert.Equal(t, w.Code, missing.Code)
+	assert.Equal(t, w.Body.String(), missing.Body.String())
+
+	for _, id := range []string{"uuid-2", "uuid-missing"} {
+		card := httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/invitations/"+id+"/card.png", nil)
+		r.ServeHTTP(card, req)
+		assert.Equal(t, http.StatusNotFound, card.Code, id)
+		assert.JSONEq(t, `{"error":"invitation not found"}`, card.Body.String(), id)
+	}
+}
+
+func TestShortLink_PreviewBotGetsMeta(t *testing.T) {
+	r, invRepo, _ := setupTestRouterWithDist(writeTestDist(t))
+
+	inv := &domain.Invitation{UUID: "uuid-3", ShortCode: "abc123", Lang: "en", GroomName: "Arman", BrideName: "Aigerim", IsPaid: true}
+	invRepo.On("GetByShortCode", "abc123").Return(inv, nil)
+	invRepo.On("GetByUUID", "uuid-3").Return(inv, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/s/abc123", nil)
+	req.Header.Set("User-Agent", "WhatsApp/2.23.20.0 A")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "<title>Arman &amp; Aigerim | Wedding Invitation</title>")
+
+	// Browsers still get the redirect.
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/s/abc123", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/i/uuid-3", w.Header().Get("Location"))
+}
+
+func TestShortLink_PreviewBotMissingCode(t *testing.T) {
+	r, invRepo, _ := setupTestRouterWithDist(writeTestDist(t))
+	invRepo.On("GetByShortCode", "nope").Return(nil, errors.New("no rows in result set"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/s/nope", nil)
+	req.Header.Set("User-Agent", "TelegramBot (like TwitterBot)")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "<title>Приглашение на свадьбу | Wedding Invitation</title>")
+}
+
+func TestCardImage(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	inv := &domain.Invitation{UUID: "uuid-4", TemplateCode: "starry-night", Lang: "kk", GroomName: "Әлібек", BrideName: "Ұлжан", EventDate: "2026-09-01", IsPaid: true}
+	invRepo.On("GetByUUID", "uuid-4").Return(inv, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/invitations/uuid-4/card.png", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	img, err := png.Decode(w.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, card.Width, card.Height), img.Bounds())
+
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, `"`+card.Key(inv)+`"`, etag)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/invitations/uuid-4/card.png", nil)
+	req.Header.Set("If-None-Match", etag)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func TestInvitationDocument_NoJS(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	inv := &domain.Invitation{
+		UUID:          "uuid-5",
+		TemplateCode:  "starry-night",
+		Lang:          "kk",
+		GroomName:     "Данияр",
+		BrideName:     "Жанар",
+		EventDate:     "2026-08-20 17:00:00",
+		EventLocation: "Royal Tulip",
+		IsPaid:        true,
+		Content: map[string]interface{}{
+			"story":    "Бұл махаббат хикаясы...",
+			"mapUrl":   "javascript:alert(1)",
+			"schedule": []interface{}{map[string]interface{}{"time": "17:00", "name": "Той", "description": "Банкет"}},
+		},
+	}
+	invRepo.On("GetByUUID", "uuid-5").Return(inv, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/i/uuid-5/html?rsvp=ok", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `<html lang="kk">`)
+	assert.NotContains(t, body, "<script")
+	assert.Contains(t, body, `<time datetime="2026-08-20T17:00">2026 жылғы 20 тамыз, 17:00</time>`)
+	assert.Contains(t, body, "Бұл махаббат хикаясы...")
+	assert.Contains(t, body, "<strong>Той</strong>")
+	assert.NotContains(t, body, "javascript:")
+	assert.Contains(t, body, `<div role="status">`)
+}
+
+func TestInvitationDocument_Expired(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	past := time.Now().Add(-time.Hour)
+	invRepo.On("GetByUUID", "uuid-6").Return(&domain.Invitation{UUID: "uuid-6", GroomName: "Арман", ExpiresAt: &past}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/i/uuid-6/html", nil)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.NotContains(t, w.Body.String(), "Арман")
+	assert.Contains(t, w.Body.String(), "Срок действия ссылки истек")
+}
+
+func TestSubmitRSVPForm(t *testing.T) {
+	r, invRepo, _ := setupTestRouter()
+
+	invRepo.On("GetByUUID", "uuid-7").Return(&domain.Invitation{UUID: "uuid-7", IsPaid: true}, nil)
+	invRepo.On("AddRSVP", mock.MatchedBy(func(rsvp *domain.RSVPResponse) bool {
+		return rsvp.InvitationUUID == "uuid-7" && rsvp.GuestName == "Ivan" && rsvp.Attendance == "yes" && rsvp.GuestCount == 2
+	})).Return(nil)
+
+	w := httptest.NewRecorder()
+	form := url.Values{"guestName": {" Ivan "}, "attendance": {"yes"}, "guestCount": {"2"}}
+	req, _ := http.NewRequest("POST", "/i/uuid-7/rsvp", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/i/uuid-7/html?rsvp=ok#rsvp", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	form = url.Values{"guestName": {""}, "attendance": {"maybe"}}
+	req, _ = http.NewRequest("POST", "/i/uuid-7/rsvp", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/i/uuid-7/html?rsvp=invalid#rsvp", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	form = url.Values{"guestName": {"Ivan"}, "attendance": {"yes"}, "phone": {"call me"}}
+	req, _ = http.NewRequest("POST", "/i/uuid-7/rsvp", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "/i/uuid-7/html?rsvp=invalid_phone#rsvp", w.Header().Get("Location"))
+	invRepo.AssertNumberOfCalls(t, "AddRSVP", 1)
+}
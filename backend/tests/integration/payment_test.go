@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/infra/payment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	webhookSecret = "test-webhook-secret"
+	testOrderID   = "5f0c3a52-8d1e-4b8e-9c4a-2f6d7e8a9b10"
+)
+
+func webhookRequest(provider, body string, secret string) *http.Request {
+	req, _ := http.NewRequest("POST", "/api/payments/"+provider+"/webhook", strings.NewReader(body))
+	ts := time.Now().Unix()
+	req.Header.Set(payment.TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(payment.SignatureHeader, "sha256="+hex.EncodeToString(payment.Sign([]byte(secret), ts, []byte(body))))
+	return req
+}
+
+func TestCreateOrder(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	s.pricing.On("GetPlan", "basic").Return(&domain.Plan{Code: "basic", Price: 990000, Currency: "KZT", HostingDays: 90, IsActive: true}, nil)
+	s.pricing.On("GetPromoCode", "EXPIRED").Return(&domain.PromoCode{Code: "EXPIRED", Kind: domain.PromoPercent, Value: 10, IsActive: false}, nil)
+	s.orderRepo.On("CreateOrder", mock.Anything).Return(nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/uuid-1/orders", strings.NewReader(`{"provider":"hmac","plan":"basic"}`)))
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var order domain.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &order))
+	assert.Equal(t, int64(990000), order.Amount)
+	assert.Equal(t, int64(990000), order.ListPrice)
+	assert.Equal(t, "basic", order.PlanCode)
+	assert.Equal(t, "KZT", order.Currency)
+	assert.Equal(t, domain.OrderPending, order.Status)
+	assert.Equal(t, "https://pay.test/checkout?order="+order.ID, order.PaymentURL)
+
+	// Two providers are configured, so one must be named.
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/uuid-1/orders", strings.NewReader(`{"plan":"basic"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/uuid-1/orders", strings.NewReader(`{"provider":"hmac","plan":"basic","promoCode":"expired"}`)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	s := newTestServer("dist")
+	s.orderRepo.On("GetOrder", testOrderID).Return(&domain.Order{ID: testOrderID, Provider: "hmac", Amount: 1500000, Currency: "KZT", Status: domain.OrderPending}, nil)
+	s.orderRepo.On("ApplyPaymentEvent", mock.MatchedBy(func(ev *domain.PaymentEvent) bool {
+		return ev.Provider == "hmac" && ev.EventID == "ev-1" && ev.Status == domain.OrderPaid
+	})).Return(&domain.Order{ID: testOrderID, Status: domain.OrderPaid}, false, nil)
+
+	body := `{"id":"ev-1","orderId":"` + testOrderID + `","status":"paid","amount":1500000,"currency":"KZT"}`
+
+	// A redelivery is answered like the first delivery.
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, webhookRequest("hmac", body, webhookSecret))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.JSONEq(t, `{"orderId":"`+testOrderID+`","status":"paid"}`, w.Body.String())
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, webhookRequest("hmac", body, "forged"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, webhookRequest("hmac", strings.Replace(body, "1500000", "100", 1), webhookSecret))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, webhookRequest("kaspi", body, webhookSecret))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// The order belongs to the hmac provider, not the fake one.
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, webhookRequest("fake", body, ""))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	s.orderRepo.AssertNumberOfCalls(t, "ApplyPaymentEvent", 2)
+}
+
+func TestPaymentWebhook_Fake(t *testing.T) {
+	s := newTestServer("dist")
+	s.orderRepo.On("GetOrder", testOrderID).Return(&domain.Order{ID: testOrderID, Provider: "fake", Amount: 990000, Currency: "KZT", Status: domain.OrderPending}, nil)
+	s.orderRepo.On("ApplyPaymentEvent", mock.MatchedBy(func(ev *domain.PaymentEvent) bool {
+		return ev.Provider == "fake" && ev.Status == domain.OrderPaid
+	})).Return(&domain.Order{ID: testOrderID, Status: domain.OrderPaid}, true, nil)
+
+	// The fake provider settles orders without naming the amount.
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, webhookRequest("fake", `{"orderId":"`+testOrderID+`","status":"paid"}`, ""))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"orderId":"`+testOrderID+`","status":"paid"}`, w.Body.String())
+}
@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicPlans(t *testing.T) {
+	s := newTestServer("dist")
+	s.pricing.On("ListPlans", true).Return([]domain.Plan{{Code: "basic", NameRu: "Базовый", Price: 990000, Currency: "KZT", IsActive: true}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/plans", nil)
+	s.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []domain.Plan
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "basic", list[0].Code)
+}
+
+func TestAdminPlans_Save(t *testing.T) {
+	s := newTestServer("dist")
+	s.pricing.On("SavePlan", mock.MatchedBy(func(p *domain.Plan) bool {
+		return p.Code == "gold" && p.Currency == "KZT"
+	})).Return(nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/plans/gold", strings.NewReader(`{"nameRu":"Золотой","price":2990000,"hostingDays":730,"isActive":true}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/plans/gold", strings.NewReader(`{"price":100}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminPromoCodes(t *testing.T) {
+	s := newTestServer("dist")
+	s.pricing.On("CreatePromoCode", mock.MatchedBy(func(p *domain.PromoCode) bool { return p.Code == "SPRING20" })).Return(nil)
+	s.pricing.On("CreatePromoCode", mock.MatchedBy(func(p *domain.PromoCode) bool { return p.Code == "TAKEN" })).Return(domain.ErrPromoCodeExists)
+	s.pricing.On("DeactivatePromoCode", "SPRING20").Return(nil)
+	s.pricing.On("DeactivatePromoCode", "NOPE").Return(domain.ErrPromoCodeNotFound)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/promo-codes", strings.NewReader(`{"code":"spring20","kind":"percent","value":20}`)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var p domain.PromoCode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, "SPRING20", p.Code)
+	assert.True(t, p.IsActive)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/promo-codes", strings.NewReader(`{"code":"taken","kind":"percent","value":20}`)))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/promo-codes", strings.NewReader(`{"code":"half","kind":"percent","value":150}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/promo-codes/spring20", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/promo-codes/nope", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuestionnaire(t *testing.T) {
+	s := newTestServer("dist")
+	s.adminRepo.On("GetTemplates").Return([]domain.Template{
+		{Code: "starry-night", NameRu: "Звездная ночь"},
+	}, nil)
+
+	body, _ := json.Marshal(map[string]string{
+		"text":        "1. Выбранный дизайн: Звездная ночь\n2. Язык приглашения: русский\n3. Имена: Арман и Айгерим\n4. Дата свадьбы: 15.08.2030\n5. Время: 18:00\n6. Ресторан: Rixos",
+		"phoneNumber": "87011234567",
+	})
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/questionnaires/parse", strings.NewReader(string(body))))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var draft usecase.QuestionnaireDraft
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &draft))
+	assert.Equal(t, "starry-night", draft.Invitation.TemplateCode)
+	assert.Equal(t, "+77011234567", draft.Invitation.PhoneNumber)
+	assert.Equal(t, "2030-08-15T18:00", draft.Invitation.EventDate)
+	assert.Equal(t, "Rixos", draft.Invitation.EventLocation)
+	require.Len(t, draft.Fields, len(usecase.QuestionnaireFields))
+	assert.Equal(t, usecase.ConfidenceHigh, draft.Fields[0].Confidence)
+	assert.Equal(t, usecase.ConfidenceMissing, draft.Fields[6].Confidence)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/questionnaires/parse", strings.NewReader(`{"phoneNumber":"87011234567"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/admin/questionnaires/parse", strings.NewReader(string(body)))
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminQueue_ListDeadJobs(t *testing.T) {
+	s := newTestServer("dist")
+	s.queueRepo.On("ListQueueJobs", domain.QueueJobFilter{Status: "dead", Kind: "http.call", Limit: 20}).
+		Return([]domain.QueueJob{{ID: 9, Kind: "http.call", Status: "dead", LastError: "POST https://n8n: 404 Not Found"}}, 1, nil)
+	s.queueRepo.On("CountQueueJobs").Return(map[string]int{"dead": 1, "done": 12}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/queue/jobs?status=dead&kind=http.call&limit=20", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page usecase.QueuePage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, 12, page.Counts["done"])
+	require.Len(t, page.Jobs, 1)
+	assert.Contains(t, page.Jobs[0].LastError, "404")
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/queue/jobs?status=lost", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminQueue_GetAndRetry(t *testing.T) {
+	s := newTestServer("dist")
+	s.queueRepo.On("GetQueueJob", int64(9)).Return(&domain.QueueJob{ID: 9, Status: "dead"}, nil)
+	s.queueRepo.On("GetQueueJob", int64(10)).Return(nil, domain.ErrQueueJobNotFound)
+	s.queueRepo.On("RetryQueueJob", int64(9)).Return(&domain.QueueJob{ID: 9, Status: "pending"}, nil)
+	s.queueRepo.On("RetryQueueJob", int64(11)).Return(nil, domain.ErrQueueJobNotRetryable)
+
+	cases := []struct {
+		method, target string
+		code           int
+	}{
+		{"GET", "/api/admin/queue/jobs/9", http.StatusOK},
+		{"GET", "/api/admin/queue/jobs/10", http.StatusNotFound},
+		{"GET", "/api/admin/queue/jobs/x", http.StatusBadRequest},
+		{"POST", "/api/admin/queue/jobs/9/retry", http.StatusOK},
+		{"POST", "/api/admin/queue/jobs/11/retry", http.StatusConflict},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, adminRequest(tc.method, tc.target, nil))
+		assert.Equal(t, tc.code, w.Code, tc.target)
+	}
+}
@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminderPolicies(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1", EventDate: "2030-08-15T18:00"}, nil)
+	s.remindRepo.On("CreateReminderPolicy", mock.MatchedBy(func(p *domain.ReminderPolicy) bool {
+		return p.InvitationUUID == "uuid-1" && p.Anchor == domain.ReminderAnchorEvent && p.OffsetMinutes == -1440 && p.Enabled
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*domain.ReminderPolicy).ID = 7
+	}).Return(nil)
+	s.remindRepo.On("UpdateReminderPolicy", mock.Anything).Return(domain.ErrReminderPolicyNotFound)
+	s.remindRepo.On("DeleteReminderPolicy", "uuid-1", int64(7)).Return(nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/uuid-1/reminders",
+		strings.NewReader(`{"anchor":"event","offsetMinutes":-1440,"audience":"attending"}`)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"id":7`)
+	assert.Contains(t, w.Body.String(), `"dueAt":"2030-08-14T18:00:00+05:00"`)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/uuid-1/reminders",
+		strings.NewReader(`{"anchor":"event","audience":"everyone"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/invitations/uuid-1/reminders/8",
+		strings.NewReader(`{"anchor":"deadline","audience":"pending"}`)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/invitations/uuid-1/reminders/7", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/admin/invitations/uuid-1/reminders", nil)
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestReminderOptOuts(t *testing.T) {
+	s := newTestServer("dist")
+	s.remindRepo.On("AddReminderOptOut", "+77011234567").Return(nil)
+	s.remindRepo.On("RemoveReminderOptOut", "+77011234567").Return(nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/reminder-opt-outs", strings.NewReader(`{"phone":"8 701 123 45 67"}`)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"phone":"+77011234567"`)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/reminder-opt-outs", strings.NewReader(`{"phone":"call me"}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/reminder-opt-outs/+77011234567", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	s.remindRepo.AssertExpectations(t)
+}
+
+func TestReminderStopLink(t *testing.T) {
+	s := newTestServer("dist")
+	s.remindRepo.On("GetReminderByToken", "tok").Return(&domain.ReminderDelivery{InvitationUUID: "uuid-1", Phone: "+77011234567"}, nil)
+	s.remindRepo.On("GetReminderByToken", "nope").Return(nil, domain.ErrReminderTokenNotFound)
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1", Lang: "en"}, nil)
+	s.remindRepo.On("AddReminderOptOut", "+77011234567").Return(nil)
+
+	// Opening the link, as link previews do, only asks to confirm.
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/r/stop/tok", nil)
+	s.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Stop sending reminders to &#43;77011234567?")
+	assert.Contains(t, w.Body.String(), `<form method="post" action="/r/stop/tok">`)
+	s.remindRepo.AssertNotCalled(t, "AddReminderOptOut", mock.Anything)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/r/stop/tok", nil)
+	s.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Done: &#43;77011234567 will get no more reminders.")
+	s.remindRepo.AssertNumberOfCalls(t, "AddReminderOptOut", 1)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/r/stop/nope", nil)
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Ссылка недействительна.")
+}
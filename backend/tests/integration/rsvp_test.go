@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminRSVPs_List(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1"}, nil)
+	s.invRepo.On("GetRSVPs", "uuid-1").Return([]domain.RSVPResponse{{ID: 3, InvitationUUID: "uuid-1", GuestName: "Aigerim", Attendance: "yes", GuestCount: 2}}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/uuid-1/rsvps", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var list []domain.RSVPResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, 3, list[0].ID)
+}
+
+func TestAdminRSVPs_Update(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("UpdateRSVP", &domain.RSVPResponse{ID: 3, InvitationUUID: "uuid-1", GuestName: "Aigerim", Attendance: "no", GuestCount: 1}).Return(nil)
+	s.invRepo.On("UpdateRSVP", &domain.RSVPResponse{ID: 4, InvitationUUID: "uuid-1", GuestName: "Aigerim", Attendance: "no", GuestCount: 1}).Return(domain.ErrRSVPNotFound)
+
+	body := `{"guestName":"Aigerim","attendance":"no","guestCount":1}`
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/invitations/uuid-1/rsvps/3", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/invitations/uuid-1/rsvps/4", strings.NewReader(body)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/invitations/uuid-1/rsvps/3", strings.NewReader(`{"guestName":"Aigerim","attendance":"later","guestCount":1}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	s.invRepo.AssertExpectations(t)
+}
+
+func TestAdminRSVPs_Delete(t *testing.T) {
+	s := newTestServer("dist")
+	s.invRepo.On("DeleteRSVP", "uuid-1", 3).Return(nil)
+	s.invRepo.On("DeleteRSVP", "uuid-1", 4).Return(domain.ErrRSVPNotFound)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/invitations/uuid-1/rsvps/3", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	for _, id := range []string{"4", "x"} {
+		w = httptest.NewRecorder()
+		s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/invitations/uuid-1/rsvps/"+id, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code, id)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/admin/invitations/uuid-1/rsvps/3", nil)
+	s.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,78 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminExtendTrial(t *testing.T) {
+	s := newTestServer("dist")
+	lapsed := time.Now().Add(-time.Hour)
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1", ExpiresAt: &lapsed}, nil)
+	s.invRepo.On("GetByUUID", "uuid-2").Return(&domain.Invitation{UUID: "uuid-2", IsPaid: true}, nil)
+	s.invRepo.On("SetExpiresAt", "uuid-1", mock.Anything).Return(nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/uuid-1/trial/extend", strings.NewReader(`{"days":2}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var inv domain.Invitation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &inv))
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), *inv.ExpiresAt, time.Minute)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/uuid-1/trial/extend", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/invitations/uuid-2/trial/renew", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAdminSetHosting(t *testing.T) {
+	s := newTestServer("dist")
+	until := time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC)
+	s.invRepo.On("GetByUUID", "uuid-1").Return(&domain.Invitation{UUID: "uuid-1", IsPaid: true}, nil)
+	s.invRepo.On("SetHostingUntil", "uuid-1", &until).Return(nil)
+	s.invRepo.On("SetHostingUntil", "uuid-1", (*time.Time)(nil)).Return(nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/invitations/uuid-1/hosting", strings.NewReader(`{"hostingUntil":"2027-05-01T00:00:00Z"}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/invitations/uuid-1/hosting", strings.NewReader(`{"forever":true}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// An empty body must not take the end date off by accident.
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/invitations/uuid-1/hosting", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	s.invRepo.AssertExpectations(t)
+}
+
+func TestAdminExpiringInvitations(t *testing.T) {
+	s := newTestServer("dist")
+	ends := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	s.adminRepo.On("ListExpiring", mock.Anything, mock.Anything).Return([]domain.ExpiringInvitation{{UUID: "uuid-1", EndsAt: ends}}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/expiring?within=24h", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list []domain.ExpiringInvitation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, ends, list[0].EndsAt)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/invitations/expiring?within=soon", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/domain"
+	"github.com/madiyarrakhman/wedding-invitation/backend/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminWebhooks_Create(t *testing.T) {
+	s := newTestServer("dist")
+	s.webhooks.On("CreateWebhook", mock.MatchedBy(func(sub *domain.WebhookSubscription) bool {
+		return sub.URL == "https://n8n.example/webhook/rsvp" && len(sub.Secret) == 64 && sub.Active
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*domain.WebhookSubscription).ID = 3
+	}).Return(nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/webhooks",
+		strings.NewReader(`{"url":"https://n8n.example/webhook/rsvp","events":["rsvp.submitted"],"description":"WhatsApp to the couple"}`)))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var sub domain.WebhookSubscription
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sub))
+	assert.Equal(t, int64(3), sub.ID)
+	assert.NotEmpty(t, sub.Secret)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/webhooks",
+		strings.NewReader(`{"url":"https://n8n.example","events":["guest.left"]}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdminWebhooks_UpdateAndDelete(t *testing.T) {
+	s := newTestServer("dist")
+	s.webhooks.On("GetWebhook", int64(3)).Return(&domain.WebhookSubscription{ID: 3, Secret: "0123456789abcdef"}, nil)
+	s.webhooks.On("GetWebhook", int64(4)).Return(nil, domain.ErrWebhookNotFound)
+	s.webhooks.On("UpdateWebhook", mock.Anything).Return(nil)
+	s.webhooks.On("DeleteWebhook", int64(3)).Return(nil)
+	s.webhooks.On("DeleteWebhook", int64(4)).Return(domain.ErrWebhookNotFound)
+
+	body := `{"url":"https://n8n.example/v2","events":["invitation.paid"],"active":false}`
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/webhooks/3", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("PUT", "/api/admin/webhooks/4", strings.NewReader(body)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/webhooks/3", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("DELETE", "/api/admin/webhooks/4", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminWebhooks_DeliveriesAndRedeliver(t *testing.T) {
+	s := newTestServer("dist")
+	status := 500
+	s.webhooks.On("GetWebhook", int64(3)).Return(&domain.WebhookSubscription{ID: 3}, nil)
+	s.webhooks.On("ListWebhookDeliveries", int64(3), 20).Return([]domain.WebhookDelivery{
+		{ID: 7, SubscriptionID: 3, Event: "rsvp.submitted", Status: domain.WebhookFailed, Attempts: 8, ResponseStatus: &status},
+	}, nil)
+	s.webhooks.On("GetWebhookDelivery", int64(7)).Return(&domain.WebhookDelivery{ID: 7, SubscriptionID: 3}, nil, nil, nil)
+	s.webhooks.On("RedeliverWebhook", int64(7), mock.MatchedBy(func(job domain.QueueJob) bool {
+		return job.Kind == usecase.WebhookDeliveryKind
+	})).Return(&domain.WebhookDelivery{ID: 9, SubscriptionID: 3, Status: domain.WebhookPending}, nil)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("GET", "/api/admin/webhooks/3/deliveries?limit=20", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var log []domain.WebhookDelivery
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &log))
+	require.Len(t, log, 1)
+	assert.Equal(t, domain.WebhookFailed, log[0].Status)
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/webhooks/3/deliveries/7/redeliver", nil))
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"id":9`)
+
+	// The delivery belongs to another subscription.
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, adminRequest("POST", "/api/admin/webhooks/5/deliveries/7/redeliver", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
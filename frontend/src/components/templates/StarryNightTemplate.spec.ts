@@ -53,4 +53,27 @@ describe('StarryNightTemplate.vue', () => {
             expect(wrapper.text()).toContain('Спасибо!')
         })
     })
+
+    it('sends the optional phone number with the RSVP', async () => {
+        vi.mocked(fetch).mockResolvedValueOnce({
+            ok: true,
+            json: async () => ({ success: true })
+        } as Response)
+
+        const wrapper = mount(StarryNightTemplate, {
+            props: {
+                invitation: mockInvitation
+            }
+        })
+
+        await wrapper.find('input[type="text"]').setValue('Guest Name')
+        await wrapper.find('input[type="tel"]').setValue('+7 701 123 45 67')
+        await wrapper.find('form').trigger('submit')
+
+        await vi.waitFor(() => {
+            expect(fetch).toHaveBeenCalled()
+        })
+        const body = JSON.parse(vi.mocked(fetch).mock.calls[0][1]!.body as string)
+        expect(body.phone).toBe('+7 701 123 45 67')
+    })
 })
@@ -0,0 +1,60 @@
+import { onBeforeUnmount } from 'vue'
+
+// Page sections reported to the engagement beacon, by the ids the
+// templates give them. Starry Night calls its location block "details".
+const sections: Record<string, string> = {
+    story: '#story',
+    location: '#location, #details',
+    rsvp_form: '#rsvp',
+}
+
+const send = (uuid: string, event: string) => {
+    const url = `/api/invitations/${uuid}/events`
+    const body = JSON.stringify({ event })
+    if (navigator.sendBeacon?.(url, body)) return
+    fetch(url, { method: 'POST', body, keepalive: true }).catch(() => {})
+}
+
+// useEngagementBeacon reports that the invitation was opened, then each
+// section once it has scrolled into view. Templates load asynchronously,
+// so sections are picked up as they appear under root.
+export function useEngagementBeacon() {
+    let cleanup = () => {}
+    onBeforeUnmount(() => cleanup())
+
+    const track = (uuid: string, root: HTMLElement) => {
+        send(uuid, 'view')
+        if (typeof IntersectionObserver === 'undefined') return
+
+        const events = new Map<Element, string>()
+        const pending = new Map(Object.entries(sections))
+        const io = new IntersectionObserver((entries) => {
+            for (const entry of entries) {
+                if (!entry.isIntersecting) continue
+                io.unobserve(entry.target)
+                send(uuid, events.get(entry.target)!)
+            }
+        }, { threshold: 0.3 })
+
+        const attach = () => {
+            for (const [event, selector] of pending) {
+                const el = root.querySelector(selector)
+                if (!el) continue
+                pending.delete(event)
+                events.set(el, event)
+                io.observe(el)
+            }
+            if (pending.size === 0) mo.disconnect()
+        }
+        const mo = new MutationObserver(attach)
+        mo.observe(root, { childList: true, subtree: true })
+        attach()
+
+        cleanup = () => {
+            io.disconnect()
+            mo.disconnect()
+        }
+    }
+
+    return { track }
+}